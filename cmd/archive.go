@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"ramp/internal/config"
+	"ramp/internal/operations"
+)
+
+var archiveDelete bool
+
+var archiveCmd = &cobra.Command{
+	Use:   "archive [feature]",
+	Short: "Save a feature and free its worktrees so it can be restored later",
+	Long: `Archive a feature instead of destroying it. Each repo's branch head and any
+uncommitted changes, untracked files included, are saved as commits under
+refs/ramp/archive/<feature>/ in the source repo, and the feature's display
+name, ports and branch prefix are recorded in .ramp/archive/<feature>.json.
+The feature is then removed like 'ramp down --force' does: worktrees and local
+branches are deleted, ports are released and the cleanup script and hooks run.
+
+Nothing is pushed; the archive lives in the source repos on this machine.
+Bring the feature back with 'ramp restore <feature>'.
+
+Without a feature, lists the archived features. With --delete, permanently
+discards a feature's archive.
+
+Examples:
+  ramp archive my-feature
+  ramp archive
+  ramp archive --delete my-feature`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		featureName := ""
+		if len(args) == 1 {
+			featureName = strings.TrimRight(args[0], "/")
+		}
+
+		if err := runArchive(featureName, archiveDelete); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	archiveCmd.Flags().BoolVar(&archiveDelete, "delete", false, "Permanently discard the feature's archive")
+	rootCmd.AddCommand(archiveCmd)
+}
+
+func runArchive(featureName string, deleteArchive bool) error {
+	wd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+
+	projectDir, err := config.FindRampProject(wd)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.LoadConfig(projectDir)
+	if err != nil {
+		return err
+	}
+
+	if featureName == "" {
+		if deleteArchive {
+			return fmt.Errorf("--delete needs the feature whose archive to discard")
+		}
+		return listArchives(projectDir)
+	}
+
+	if deleteArchive {
+		if err := operations.DeleteArchive(projectDir, cfg, featureName); err != nil {
+			return err
+		}
+		fmt.Printf("Deleted the archive of '%s'\n", featureName)
+		return nil
+	}
+
+	result, err := operations.ArchiveFeature(operations.ArchiveOptions{
+		FeatureName: featureName,
+		ProjectDir:  projectDir,
+		Config:      cfg,
+		Progress:    operations.NewCLIProgressReporter(),
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Println()
+	for _, repo := range result.Archive.Repos {
+		if repo.Snapshot != "" {
+			fmt.Printf("  %s: %s with uncommitted changes\n", repo.Repo, repo.Branch)
+		} else {
+			fmt.Printf("  %s: %s\n", repo.Repo, repo.Branch)
+		}
+	}
+	fmt.Printf("\nFeature '%s' archived; bring it back with 'ramp restore %s'\n", featureName, featureName)
+	return nil
+}
+
+func listArchives(projectDir string) error {
+	archives, err := operations.ListArchives(projectDir)
+	if err != nil {
+		return err
+	}
+	if len(archives) == 0 {
+		fmt.Println("No archived features")
+		return nil
+	}
+
+	fmt.Println("📦 Archived features:")
+	fmt.Println()
+	for _, a := range archives {
+		name := a.Feature
+		if a.DisplayName != "" {
+			name = fmt.Sprintf("%s (%s)", a.DisplayName, a.Feature)
+		}
+		changed := 0
+		for _, repo := range a.Repos {
+			if repo.Snapshot != "" {
+				changed++
+			}
+		}
+
+		fmt.Printf("  • %s, archived %s\n", name, a.Archived.Format("2006-01-02 15:04"))
+		if changed > 0 {
+			fmt.Printf("    %d repo%s, uncommitted changes in %d\n", len(a.Repos), pluralize(len(a.Repos)), changed)
+		} else {
+			fmt.Printf("    %d repo%s\n", len(a.Repos), pluralize(len(a.Repos)))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestArchiveAndRestore tests archiving a feature and bringing it back
+func TestArchiveAndRestore(t *testing.T) {
+	tp := NewTestProject(t)
+	repo1 := tp.InitRepo("repo1")
+
+	cleanup := tp.ChangeToProjectDir()
+	defer cleanup()
+
+	if err := runUp("my-feature", "", "", ""); err != nil {
+		t.Fatalf("runUp() error = %v", err)
+	}
+	notes := filepath.Join(tp.TreesDir, "my-feature", "repo1", "notes.txt")
+	os.WriteFile(notes, []byte("unsaved\n"), 0644)
+
+	if err := runArchive("my-feature", false); err != nil {
+		t.Fatalf("runArchive() error = %v", err)
+	}
+	if tp.FeatureExists("my-feature") || repo1.BranchExists(t, "feature/my-feature") {
+		t.Error("archiving should remove the worktree and branch")
+	}
+	if err := runArchive("", false); err != nil {
+		t.Errorf("runArchive() listing error = %v", err)
+	}
+
+	if err := runRestore("my-feature"); err != nil {
+		t.Fatalf("runRestore() error = %v", err)
+	}
+	if !tp.WorktreeExists("my-feature", "repo1") || !repo1.BranchExists(t, "feature/my-feature") {
+		t.Error("restoring should recreate the worktree and branch")
+	}
+	if content, _ := os.ReadFile(notes); string(content) != "unsaved\n" {
+		t.Errorf("notes.txt = %q, want the untracked file restored", content)
+	}
+	if err := runRestore("my-feature"); err == nil {
+		t.Error("restoring a feature that was already restored should fail")
+	}
+}
+
+// TestArchiveDelete tests discarding an archive
+func TestArchiveDelete(t *testing.T) {
+	tp := NewTestProject(t)
+	tp.InitRepo("repo1")
+
+	cleanup := tp.ChangeToProjectDir()
+	defer cleanup()
+
+	if err := runUp("my-feature", "", "", ""); err != nil {
+		t.Fatalf("runUp() error = %v", err)
+	}
+	if err := runArchive("my-feature", false); err != nil {
+		t.Fatalf("runArchive() error = %v", err)
+	}
+
+	if err := runArchive("my-feature", true); err != nil {
+		t.Fatalf("runArchive() --delete error = %v", err)
+	}
+	if err := runRestore("my-feature"); err == nil {
+		t.Error("restoring a deleted archive should fail")
+	}
+}
@@ -0,0 +1,182 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"ramp/internal/config"
+	"ramp/internal/envfile"
+)
+
+var cacheEnvFilesOnly bool
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Inspect and clear cached env script output and secrets",
+	Long: `Inspect and clear the caches under .ramp/cache.
+
+Env scripts with a cache TTL store their output in .ramp/cache/env_files, keyed
+by the script path, its content and the values of the RAMP_* variables it
+references. Secret providers with a cache TTL store encrypted values in
+.ramp/cache/secrets.`,
+}
+
+var cacheListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List cached env script output and secret caches",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runCacheList(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Remove cached env script output and secret caches",
+	Long: `Remove cached env script output and secret caches. The next ramp up or
+ramp env sync re-runs cached scripts and looks secrets up again.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runCacheClear(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	cacheListCmd.Flags().BoolVar(&cacheEnvFilesOnly, "env-files", false, "Only include cached env script output")
+	cacheClearCmd.Flags().BoolVar(&cacheEnvFilesOnly, "env-files", false, "Only clear cached env script output")
+	cacheCmd.AddCommand(cacheListCmd)
+	cacheCmd.AddCommand(cacheClearCmd)
+	rootCmd.AddCommand(cacheCmd)
+}
+
+func runCacheList() error {
+	wd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+
+	projectDir, err := config.FindRampProject(wd)
+	if err != nil {
+		return err
+	}
+
+	entries, err := envfile.ListCache(projectDir)
+	if err != nil {
+		return err
+	}
+
+	if len(entries) == 0 {
+		fmt.Println("No cached env script output.")
+	} else {
+		fmt.Printf("Env scripts (%d):\n", len(entries))
+		for _, entry := range entries {
+			script := entry.Script
+			if rel, err := filepath.Rel(projectDir, script); err == nil && !strings.HasPrefix(rel, "..") {
+				script = rel
+			}
+
+			state := fmt.Sprintf("cached %s ago, ttl %s", formatAge(time.Since(entry.Created)), entry.TTL)
+			if entry.Expired() {
+				state = "expired"
+			}
+
+			fmt.Printf("  %s  %s  (%d hits, %d misses, %d bytes)\n", script, state, entry.Hits, entry.Misses, entry.Size)
+			if len(entry.Inputs) > 0 {
+				fmt.Printf("    inputs: %s\n", strings.Join(entry.Inputs, ", "))
+			}
+		}
+	}
+
+	if cacheEnvFilesOnly {
+		return nil
+	}
+
+	providers, err := listSecretCaches(projectDir)
+	if err != nil {
+		return err
+	}
+	if len(providers) > 0 {
+		fmt.Printf("Secret providers (%d):\n", len(providers))
+		for _, provider := range providers {
+			fmt.Printf("  %s\n", provider)
+		}
+	}
+
+	return nil
+}
+
+// listSecretCaches returns the names of providers with an encrypted secret cache
+func listSecretCaches(projectDir string) ([]string, error) {
+	files, err := os.ReadDir(filepath.Dir(envfile.SecretCachePath(projectDir, "")))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read secret cache directory: %w", err)
+	}
+
+	var providers []string
+	for _, file := range files {
+		if name, ok := strings.CutSuffix(file.Name(), ".enc"); ok {
+			providers = append(providers, name)
+		}
+	}
+	sort.Strings(providers)
+	return providers, nil
+}
+
+// formatAge renders a duration as a short human-readable age
+func formatAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}
+
+func runCacheClear() error {
+	wd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+
+	projectDir, err := config.FindRampProject(wd)
+	if err != nil {
+		return err
+	}
+
+	removed, err := envfile.ClearCache(projectDir)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Removed %d env script cache files\n", removed)
+
+	if cacheEnvFilesOnly {
+		return nil
+	}
+
+	removed, err = envfile.ClearSecretsCache(projectDir)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Removed %d secret cache files\n", removed)
+
+	return nil
+}
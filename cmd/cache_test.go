@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"ramp/internal/config"
+	"ramp/internal/envfile"
+)
+
+// TestCacheListAndClear tests clearing env script caches with and without --env-files
+func TestCacheListAndClear(t *testing.T) {
+	tp := NewTestProject(t)
+	cleanup := tp.ChangeToProjectDir()
+	defer cleanup()
+	defer func() { cacheEnvFilesOnly = false }()
+
+	repo := tp.InitRepo("app")
+	tp.Config.Repos[0].EnvFiles = []config.EnvFile{{Source: "env.sh", Dest: ".env", Cache: "1h"}}
+	if err := config.SaveConfig(tp.Config, tp.Dir); err != nil {
+		t.Fatalf("failed to save config: %v", err)
+	}
+	os.WriteFile(filepath.Join(repo.SourceDir, "env.sh"), []byte("#!/bin/bash\necho \"PORT=$RAMP_PORT\"\n"), 0755)
+
+	if err := runUp("cache-feature", "", "", ""); err != nil {
+		t.Fatalf("runUp() error = %v", err)
+	}
+
+	entries, err := envfile.ListCache(tp.Dir)
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("ListCache() = %v, %v; want 1 entry", entries, err)
+	}
+	if err := runCacheList(); err != nil {
+		t.Errorf("runCacheList() error = %v", err)
+	}
+
+	// A secret cache survives --env-files
+	secretCache := envfile.SecretCachePath(tp.Dir, "vault")
+	os.MkdirAll(filepath.Dir(secretCache), 0700)
+	os.WriteFile(secretCache, []byte("x"), 0600)
+
+	cacheEnvFilesOnly = true
+	if err := runCacheClear(); err != nil {
+		t.Fatalf("runCacheClear() error = %v", err)
+	}
+	if entries, _ := envfile.ListCache(tp.Dir); len(entries) != 0 {
+		t.Errorf("env script cache not cleared: %v", entries)
+	}
+	if _, err := os.Stat(secretCache); err != nil {
+		t.Error("--env-files should not remove secret caches")
+	}
+
+	cacheEnvFilesOnly = false
+	if err := runCacheClear(); err != nil {
+		t.Fatalf("runCacheClear() error = %v", err)
+	}
+	if _, err := os.Stat(secretCache); !os.IsNotExist(err) {
+		t.Error("secret cache should be removed")
+	}
+}
+
+// TestStatusJSONEnvCache tests the cache statistics reported by status --json
+func TestStatusJSONEnvCache(t *testing.T) {
+	tp := NewTestProject(t)
+	cleanup := tp.ChangeToProjectDir()
+	defer cleanup()
+
+	repo := tp.InitRepo("app")
+	autoRefresh := false
+	tp.Config.Repos[0].AutoRefresh = &autoRefresh
+	tp.Config.Repos[0].EnvFiles = []config.EnvFile{{Source: "env.sh", Dest: ".env", Cache: "1h"}}
+	if err := config.SaveConfig(tp.Config, tp.Dir); err != nil {
+		t.Fatalf("failed to save config: %v", err)
+	}
+	os.WriteFile(filepath.Join(repo.SourceDir, "env.sh"), []byte("#!/bin/bash\necho A=1\n"), 0755)
+
+	for _, name := range []string{"one", "two"} {
+		if err := runUp(name, "", "", ""); err != nil {
+			t.Fatalf("runUp(%s) error = %v", name, err)
+		}
+	}
+
+	status := envCacheStatus(tp.Dir)
+	if status == nil {
+		t.Fatal("envCacheStatus() = nil, want cache statistics")
+	}
+	if *status != (jsonEnvCache{Entries: 1, Hits: 1, Misses: 1}) {
+		t.Errorf("envCacheStatus() = %+v, want 1 entry with 1 hit and 1 miss", *status)
+	}
+}
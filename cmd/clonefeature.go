@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"ramp/internal/config"
+	"ramp/internal/operations"
+)
+
+var (
+	cloneUncommitted bool
+	cloneDisplayName string
+)
+
+var cloneFeatureCmd = &cobra.Command{
+	Use:   "clone-feature <feature> <new-feature>",
+	Short: "Duplicate a feature into a new one",
+	Long: `Create a new feature whose branches start where an existing feature's
+branches are now, to explore another approach without losing the first one.
+
+Each repository gets a new branch at the HEAD of the source feature's branch,
+then the new feature goes through the same steps as 'ramp up': fresh ports are
+allocated, env files are generated and the setup script and up hooks run.
+Repositories without a worktree in the source feature branch off their
+default branch as usual.
+
+With --uncommitted, staged and unstaged changes and untracked files are copied
+from the source worktrees before the setup script runs; the source feature is
+left untouched. Untracked files the new feature already has, such as generated
+env files, are kept and reported.
+
+Examples:
+  ramp clone-feature my-feature my-feature-alt
+  ramp clone-feature my-feature my-feature-alt --uncommitted
+  ramp clone-feature my-feature experiment --name "Experimental approach"`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		sourceFeature := strings.TrimRight(args[0], "/")
+		featureName := strings.TrimRight(args[1], "/")
+
+		if err := runCloneFeature(sourceFeature, featureName, cloneUncommitted, cloneDisplayName); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	cloneFeatureCmd.Flags().BoolVar(&cloneUncommitted, "uncommitted", false, "Also copy uncommitted changes and untracked files")
+	cloneFeatureCmd.Flags().StringVar(&cloneDisplayName, "name", "", "Set a human-readable display name for the new feature")
+	rootCmd.AddCommand(cloneFeatureCmd)
+}
+
+func runCloneFeature(sourceFeature, featureName string, uncommitted bool, displayName string) error {
+	wd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+
+	projectDir, err := config.FindRampProject(wd)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.LoadConfig(projectDir)
+	if err != nil {
+		return err
+	}
+
+	if strings.Contains(featureName, "/") {
+		return fmt.Errorf("feature name cannot contain slashes")
+	}
+
+	// Auto-prompt for local config if needed (CLI-specific, uses interactive prompts)
+	if err := EnsureLocalConfig(projectDir, cfg); err != nil {
+		return fmt.Errorf("failed to configure local preferences: %w", err)
+	}
+
+	result, err := operations.CloneFeature(operations.CloneFeatureOptions{
+		SourceFeature: sourceFeature,
+		FeatureName:   featureName,
+		ProjectDir:    projectDir,
+		Config:        cfg,
+		Progress:      operations.NewCLIProgressReporter(),
+		DisplayName:   displayName,
+		Uncommitted:   uncommitted,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Feature '%s' cloned from '%s' at %s\n", result.FeatureName, sourceFeature, result.TreesDir)
+	return nil
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestCloneFeatureWithUncommitted tests cloning a feature along with its uncommitted work
+func TestCloneFeatureWithUncommitted(t *testing.T) {
+	tp := NewTestProject(t)
+	repo1 := tp.InitRepo("repo1")
+
+	cleanup := tp.ChangeToProjectDir()
+	defer cleanup()
+
+	if err := runUp("original", "", "", ""); err != nil {
+		t.Fatalf("runUp() error = %v", err)
+	}
+	os.WriteFile(filepath.Join(tp.TreesDir, "original", "repo1", "draft.txt"), []byte("draft"), 0644)
+
+	if err := runCloneFeature("original", "copy", true, ""); err != nil {
+		t.Fatalf("runCloneFeature() error = %v", err)
+	}
+
+	if !repo1.BranchExists(t, "feature/copy") {
+		t.Error("clone should create feature/copy")
+	}
+	if content, _ := os.ReadFile(filepath.Join(tp.TreesDir, "copy", "repo1", "draft.txt")); string(content) != "draft" {
+		t.Error("untracked file should be copied to the clone")
+	}
+}
+
+// TestCloneFeatureNotFound tests cloning a feature that doesn't exist
+func TestCloneFeatureNotFound(t *testing.T) {
+	tp := NewTestProject(t)
+	tp.InitRepo("repo1")
+
+	cleanup := tp.ChangeToProjectDir()
+	defer cleanup()
+
+	if err := runCloneFeature("missing", "copy", false, ""); err == nil {
+		t.Error("runCloneFeature() should fail for a missing feature")
+	}
+	if tp.FeatureExists("copy") {
+		t.Error("no feature should be created")
+	}
+}
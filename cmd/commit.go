@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"ramp/internal/config"
+	"ramp/internal/operations"
+)
+
+var (
+	commitMessageFlag string
+	commitAllFlag     bool
+	commitAmendFlag   bool
+	commitTrailerFlag bool
+	commitReposFlag   []string
+	commitExcludeFlag []string
+)
+
+var commitCmd = &cobra.Command{
+	Use:   "commit [feature-name] -m <message>",
+	Short: "Commit changes in every repository of a feature with one message",
+	Long: `Stage and commit the changes in every worktree of a feature with the same
+commit message. Worktrees without changes are left alone, and the new commit's
+short hash is shown for each repository that was committed.
+
+Changes to tracked files are staged; use --all to include untracked files too.
+Use --repo to commit only in some repositories, or --exclude to leave some out.
+With --trailer, a "Ramp-Feature: <feature>" trailer is added so the related
+commits can be found later (git log --grep "Ramp-Feature: my-feature").
+
+If no feature name is provided, ramp will attempt to auto-detect the feature
+based on your current working directory.
+
+Examples:
+  ramp commit my-feature -m "Add billing endpoint"
+  ramp commit -am "Wire up billing" --trailer
+  ramp commit my-feature -m "Fix typo" --repo api,web
+  ramp commit my-feature --amend`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		featureName := ""
+		if len(args) > 0 {
+			featureName = strings.TrimRight(args[0], "/")
+		}
+
+		opts := operations.CommitOptions{
+			Message: commitMessageFlag,
+			All:     commitAllFlag,
+			Amend:   commitAmendFlag,
+			Trailer: commitTrailerFlag,
+			Include: commitReposFlag,
+			Exclude: commitExcludeFlag,
+		}
+		if err := runCommit(featureName, opts); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	commitCmd.Flags().StringVarP(&commitMessageFlag, "message", "m", "", "Commit message (required unless --amend)")
+	commitCmd.Flags().BoolVarP(&commitAllFlag, "all", "a", false, "Also stage untracked files")
+	commitCmd.Flags().BoolVar(&commitAmendFlag, "amend", false, "Amend the last commit in each repository with changes")
+	commitCmd.Flags().BoolVar(&commitTrailerFlag, "trailer", false, "Add a Ramp-Feature: <feature> trailer to the message")
+	commitCmd.Flags().StringSliceVar(&commitReposFlag, "repo", nil, "Only commit in these repositories (comma-separated or repeated)")
+	commitCmd.Flags().StringSliceVar(&commitExcludeFlag, "exclude", nil, "Don't commit in these repositories (comma-separated or repeated)")
+	rootCmd.AddCommand(commitCmd)
+}
+
+// runCommit fills in ProjectDir, Config, Progress and FeatureName of opts and commits.
+func runCommit(featureName string, opts operations.CommitOptions) error {
+	wd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+
+	projectDir, err := config.FindRampProject(wd)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.LoadConfig(projectDir)
+	if err != nil {
+		return err
+	}
+
+	// Auto-detect feature name if not provided
+	if featureName == "" {
+		detected, err := config.DetectFeatureFromWorkingDir(projectDir)
+		if err != nil {
+			return fmt.Errorf("failed to detect feature from working directory: %w", err)
+		}
+		if detected == "" {
+			return fmt.Errorf("no feature name provided and could not auto-detect from current directory")
+		}
+		featureName = detected
+		fmt.Printf("Auto-detected feature: %s\n", featureName)
+	}
+
+	opts.FeatureName = featureName
+	opts.ProjectDir = projectDir
+	opts.Config = cfg
+	opts.Progress = operations.NewCLIProgressReporter()
+
+	result, err := operations.Commit(opts)
+	if err != nil {
+		return err
+	}
+
+	if failed := result.Failed(); len(failed) > 0 {
+		return fmt.Errorf("failed to commit in %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
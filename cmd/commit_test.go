@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ramp/internal/operations"
+)
+
+// TestCommitAutoDetectsFeature tests committing in the feature of the current worktree
+func TestCommitAutoDetectsFeature(t *testing.T) {
+	tp := NewTestProject(t)
+	tp.InitRepo("repo1")
+
+	cleanup := tp.ChangeToProjectDir()
+	defer cleanup()
+
+	if err := runUp("my-feature", "", "", ""); err != nil {
+		t.Fatalf("runUp() error = %v", err)
+	}
+
+	worktreeDir := filepath.Join(tp.TreesDir, "my-feature", "repo1")
+	os.WriteFile(filepath.Join(worktreeDir, "notes.txt"), []byte("notes"), 0644)
+	if err := os.Chdir(worktreeDir); err != nil {
+		t.Fatalf("failed to change to worktree: %v", err)
+	}
+
+	if err := runCommit("", operations.CommitOptions{Message: "Add notes", All: true}); err != nil {
+		t.Fatalf("runCommit() error = %v", err)
+	}
+
+	output, _ := exec.Command("git", "-C", worktreeDir, "log", "-1", "--format=%s").Output()
+	if got := strings.TrimSpace(string(output)); got != "Add notes" {
+		t.Errorf("last commit = %q, want %q", got, "Add notes")
+	}
+}
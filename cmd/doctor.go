@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"ramp/internal/config"
+	"ramp/internal/operations"
+)
+
+var (
+	doctorFix     bool
+	doctorJSON    bool
+	doctorOffline bool
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Find and repair project inconsistencies",
+	Long: `Check the project for state that has drifted out of sync and report it:
+
+  • tools the configuration needs that aren't installed (git, bash, git-lfs,
+    secret provider commands)
+  • remotes of source repositories that can't be reached
+  • up, down or prune operations that were interrupted
+  • worktrees git lost track of, as after moving the project directory
+  • worktrees registered in git whose directory is gone
+  • directories under trees/ that aren't registered worktrees
+  • feature branches left behind by features that no longer exist
+  • port allocations and feature metadata of features that no longer exist
+
+With --fix, moved worktrees are reconnected with 'git worktree repair',
+worktree registrations are pruned, empty unregistered directories are removed,
+leftover branches already merged into the base branch are deleted and stale
+ports and metadata are released. Unregistered directories with files in them,
+unmerged leftover branches, missing tools, unreachable remotes and interrupted
+operations are reported with what to do about them. Run without --fix first to
+review what would be removed.
+
+Leftover branches are only looked for when the project has a
+default-branch-prefix, since otherwise any branch could be a feature's.
+
+Exits with a non-zero status when issues remain.
+
+Examples:
+  ramp doctor
+  ramp doctor --fix
+  ramp doctor --offline --json`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runDoctor(doctorFix, doctorJSON, doctorOffline); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	doctorCmd.Flags().BoolVar(&doctorFix, "fix", false, "Repair the issues that can be repaired automatically")
+	doctorCmd.Flags().BoolVar(&doctorJSON, "json", false, "Output the issues as JSON (useful for scripts)")
+	doctorCmd.Flags().BoolVar(&doctorOffline, "offline", false, "Skip checking that remotes are reachable")
+	rootCmd.AddCommand(doctorCmd)
+}
+
+func runDoctor(fix, asJSON, offline bool) error {
+	wd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+
+	projectDir, err := config.FindRampProject(wd)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.LoadConfig(projectDir)
+	if err != nil {
+		return err
+	}
+
+	progress := planProgress(asJSON)
+	result, err := operations.Doctor(operations.DoctorOptions{
+		ProjectDir: projectDir,
+		Config:     cfg,
+		Progress:   progress,
+		Fix:        fix,
+		Offline:    offline,
+	})
+	progress.Stop()
+	if err != nil {
+		return err
+	}
+
+	if asJSON {
+		data, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal doctor result: %w", err)
+		}
+		fmt.Println(string(data))
+	} else {
+		printDoctorResult(result, fix)
+	}
+
+	if unresolved := result.Unresolved(); unresolved > 0 {
+		return fmt.Errorf("%d issue(s) remain", unresolved)
+	}
+	return nil
+}
+
+func printDoctorResult(result *operations.DoctorResult, fixed bool) {
+	if len(result.Issues) == 0 {
+		fmt.Println("\n✅ No issues found")
+		return
+	}
+
+	fmt.Println("\n🩺 Issues:")
+	fmt.Println()
+	fixable := 0
+	for _, issue := range result.Issues {
+		switch {
+		case issue.Fixed:
+			fmt.Printf("  ✅ %s (fixed: %s)\n", issue.Detail, issue.Fix)
+		case issue.FixError != "":
+			fmt.Printf("  ❌ %s (fix failed: %s)\n", issue.Detail, issue.FixError)
+		default:
+			fmt.Printf("  • %s\n", issue.Detail)
+			if issue.Fixable {
+				fixable++
+				fmt.Printf("    --fix will %s\n", issue.Fix)
+			} else {
+				fmt.Printf("    To fix: %s\n", issue.Fix)
+			}
+		}
+	}
+
+	if !fixed && fixable > 0 {
+		fmt.Printf("\nRun 'ramp doctor --fix' to repair %d of them\n", fixable)
+	}
+}
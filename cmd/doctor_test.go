@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestDoctorFix tests that doctor fails while issues remain and --fix repairs them
+func TestDoctorFix(t *testing.T) {
+	tp := NewTestProject(t)
+	repo1 := tp.InitRepo("repo1")
+
+	cleanup := tp.ChangeToProjectDir()
+	defer cleanup()
+
+	if err := runDoctor(false, false, true); err != nil {
+		t.Fatalf("runDoctor() on a clean project error = %v", err)
+	}
+
+	if err := runUp("my-feature", "", "", ""); err != nil {
+		t.Fatalf("runUp() error = %v", err)
+	}
+	os.RemoveAll(filepath.Join(tp.TreesDir, "my-feature"))
+
+	err := runDoctor(false, true, true)
+	if err == nil || !strings.Contains(err.Error(), "issue(s) remain") {
+		t.Fatalf("runDoctor() error = %v, want issues to remain", err)
+	}
+	if !repo1.BranchExists(t, "feature/my-feature") {
+		t.Fatal("runDoctor() without --fix should not delete branches")
+	}
+
+	if err := runDoctor(true, false, true); err != nil {
+		t.Fatalf("runDoctor(--fix) error = %v", err)
+	}
+	if repo1.BranchExists(t, "feature/my-feature") {
+		t.Error("runDoctor(--fix) should delete the branch of the removed feature")
+	}
+}
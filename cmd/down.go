@@ -24,7 +24,11 @@ var downCmd = &cobra.Command{
 4. Prompting for confirmation if there are uncommitted changes
 
 If no feature name is provided, ramp will attempt to auto-detect the feature
-based on your current working directory.`,
+based on your current working directory.
+
+Use --dry-run to see which worktrees, branches, ports and scripts would be
+affected, and any uncommitted changes that would be lost, without changing
+anything. Add --json for a machine-readable plan.`,
 	Args: cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		featureName := ""
@@ -38,8 +42,12 @@ based on your current working directory.`,
 	},
 }
 
+var downDryRun bool
+var downJSON bool
+
 func init() {
 	rootCmd.AddCommand(downCmd)
+	addDryRunFlags(downCmd, &downDryRun, &downJSON)
 }
 
 func runDown(featureName string) error {
@@ -58,14 +66,20 @@ func runDown(featureName string) error {
 		return err
 	}
 
-	// Auto-install if needed
-	if err := AutoInstallIfNeeded(projectDir, cfg); err != nil {
-		return fmt.Errorf("auto-installation failed: %w", err)
+	if err := checkDryRunFlags(downDryRun, downJSON); err != nil {
+		return err
 	}
 
-	// Auto-prompt for local config if needed
-	if err := EnsureLocalConfig(projectDir, cfg); err != nil {
-		return fmt.Errorf("failed to configure local preferences: %w", err)
+	if !downDryRun {
+		// Auto-install if needed
+		if err := AutoInstallIfNeeded(projectDir, cfg); err != nil {
+			return fmt.Errorf("auto-installation failed: %w", err)
+		}
+
+		// Auto-prompt for local config if needed
+		if err := EnsureLocalConfig(projectDir, cfg); err != nil {
+			return fmt.Errorf("failed to configure local preferences: %w", err)
+		}
 	}
 
 	// Auto-detect feature name if not provided
@@ -76,16 +90,34 @@ func runDown(featureName string) error {
 		}
 		if detected != "" {
 			featureName = detected
-			fmt.Printf("Auto-detected feature: %s\n", featureName)
+			if !downJSON {
+				fmt.Printf("Auto-detected feature: %s\n", featureName)
+			}
 		} else {
 			return fmt.Errorf("no feature name provided and could not auto-detect from current directory")
 		}
 	}
 
+	opts := operations.DownOptions{
+		FeatureName: featureName,
+		ProjectDir:  projectDir,
+		Config:      cfg,
+		Progress:    planProgress(downJSON),
+		AutoInstall: downDryRun, // Only lists the clones; AutoInstallIfNeeded does them otherwise
+	}
+
+	// Uncommitted changes show up as warnings in the plan
+	if downDryRun {
+		plan, err := operations.PlanDown(opts)
+		if err != nil {
+			return err
+		}
+		return printPlan(opts.Progress, plan, downJSON)
+	}
+
 	treesDir := filepath.Join(projectDir, "trees", featureName)
 
 	// Check for uncommitted changes BEFORE starting spinner (so prompt is visible)
-	force := false
 	if _, err := os.Stat(treesDir); err == nil {
 		reposWithChanges, err := operations.CheckForUncommittedChanges(cfg, treesDir)
 		if err != nil {
@@ -103,18 +135,12 @@ func runDown(featureName string) error {
 				fmt.Println("Cleanup cancelled.")
 				return nil
 			}
-			force = true // User confirmed (or non-interactive), skip check in Down()
+			opts.Force = true // User confirmed (or non-interactive), skip check in Down()
 		}
 	}
 
 	// Call operations.Down() with CLI progress reporter
-	_, err = operations.Down(operations.DownOptions{
-		FeatureName: featureName,
-		ProjectDir:  projectDir,
-		Config:      cfg,
-		Progress:    operations.NewCLIProgressReporter(),
-		Force:       force,
-	})
+	_, err = operations.Down(opts)
 
 	return err
 }
@@ -733,3 +733,31 @@ func TestDownAutoDetectWithExplicitNameOverridesDetection(t *testing.T) {
 		t.Error("feature-b branch should be deleted")
 	}
 }
+
+// TestDownDryRun tests that --dry-run plans the removal without removing anything
+func TestDownDryRun(t *testing.T) {
+	tp := NewTestProject(t)
+	tp.InitRepo("repo1")
+
+	cleanup := tp.ChangeToProjectDir()
+	defer cleanup()
+
+	if err := runUp("keep-me", "", "", ""); err != nil {
+		t.Fatalf("runUp() error = %v", err)
+	}
+
+	downDryRun = true
+	downJSON = true
+	defer func() { downDryRun, downJSON = false, false }()
+
+	if err := runDown("keep-me"); err != nil {
+		t.Fatalf("runDown() error = %v", err)
+	}
+
+	if !tp.FeatureExists("keep-me") {
+		t.Error("feature should not be removed by a dry run")
+	}
+	if !tp.Repos["repo1"].BranchExists(t, "feature/keep-me") {
+		t.Error("branch should not be deleted by a dry run")
+	}
+}
@@ -0,0 +1,327 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"ramp/internal/config"
+	"ramp/internal/operations"
+)
+
+var envCmd = &cobra.Command{
+	Use:   "env",
+	Short: "Manage generated env files for features",
+	Long: `Manage the env files that ramp generates from env_files configuration
+when a feature is created.`,
+}
+
+var envSyncCmd = &cobra.Command{
+	Use:   "sync [feature-name]",
+	Short: "Re-render env files for existing features",
+	Long: `Re-render env files for an existing feature using its stored ports and
+display name, then overwrite the files in its worktrees.
+
+Use this after editing a source env file (e.g. .env.example) or changing
+env_files rules in ramp.yaml, instead of recreating the feature.
+
+A diff is shown for every file that would change and you are asked to
+confirm before anything is written (skip the prompt with -y).
+
+ramp records a checksum of every env file it generates. If a file was edited
+in the worktree since then and its regenerated content changed, sync stops
+unless you choose how to handle the local edits:
+  --keep   leave the edited file untouched
+  --merge  three-way merge the local edits into the new content
+  --force  overwrite the local edits
+
+If no feature name is provided, ramp will attempt to auto-detect the feature
+based on your current working directory.
+
+Examples:
+  ramp env sync my-feature            # Show diffs and update after confirmation
+  ramp env sync my-feature --dry-run  # Only show what would change
+  ramp env sync --all -y              # Update every feature without prompting
+  ramp env sync my-feature --merge    # Keep local edits and merge in new values`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		featureName := ""
+		if len(args) > 0 {
+			featureName = strings.TrimRight(args[0], "/")
+		}
+		if err := runEnvSync(featureName); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+var envCheckCmd = &cobra.Command{
+	Use:   "check [feature-name]",
+	Short: "Report env values that are missing for existing features",
+	Long: `Check the env files in a feature's worktrees for values nothing provided:
+keys listed under required: in env_files that are missing or empty, and
+${VAR} placeholders that were left unresolved.
+
+Files are checked as they are on disk, so values filled in by hand count as
+provided. Exits with an error if any required key has no value.
+
+If no feature name is provided, ramp will attempt to auto-detect the feature
+based on your current working directory.
+
+Examples:
+  ramp env check my-feature
+  ramp env check --all`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		featureName := ""
+		if len(args) > 0 {
+			featureName = strings.TrimRight(args[0], "/")
+		}
+		if err := runEnvCheck(featureName); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+var envCheckAll bool
+
+var (
+	envSyncAll     bool
+	envSyncDryRun  bool
+	envSyncRefresh bool
+	envSyncKeep    bool
+	envSyncMerge   bool
+	envSyncForce   bool
+)
+
+func init() {
+	envSyncCmd.Flags().BoolVar(&envSyncAll, "all", false, "Sync env files for every feature")
+	envSyncCmd.Flags().BoolVar(&envSyncDryRun, "dry-run", false, "Show diffs without writing any files")
+	envSyncCmd.Flags().BoolVar(&envSyncRefresh, "refresh", false, "Re-run script sources instead of using cached output")
+	envSyncCmd.Flags().BoolVar(&envSyncKeep, "keep", false, "Leave env files edited since generation untouched")
+	envSyncCmd.Flags().BoolVar(&envSyncMerge, "merge", false, "Three-way merge local edits into regenerated env files")
+	envSyncCmd.Flags().BoolVar(&envSyncForce, "force", false, "Overwrite env files edited since generation")
+
+	envCheckCmd.Flags().BoolVar(&envCheckAll, "all", false, "Check env files for every feature")
+
+	envCmd.AddCommand(envSyncCmd)
+	envCmd.AddCommand(envCheckCmd)
+	rootCmd.AddCommand(envCmd)
+}
+
+func runEnvSync(featureName string) error {
+	wd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+
+	projectDir, err := config.FindRampProject(wd)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.LoadConfig(projectDir)
+	if err != nil {
+		return err
+	}
+
+	conflict, err := envSyncConflictPolicy()
+	if err != nil {
+		return err
+	}
+
+	featureNames, err := envFeatureNames(projectDir, featureName, envSyncAll)
+	if err != nil || len(featureNames) == 0 {
+		return err
+	}
+
+	if !operations.HasEnvFiles(cfg.GetRepos()) {
+		fmt.Println("No env_files configured.")
+		return nil
+	}
+
+	for _, name := range featureNames {
+		if err := syncFeatureEnvFiles(projectDir, cfg, name, conflict); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// envFeatureNames returns every feature with --all, otherwise the named or auto-detected feature
+func envFeatureNames(projectDir string, featureName string, all bool) ([]string, error) {
+	if all {
+		if featureName != "" {
+			return nil, fmt.Errorf("cannot specify a feature name with --all")
+		}
+		featureNames, err := operations.ListFeatures(projectDir)
+		if err != nil {
+			return nil, err
+		}
+		if len(featureNames) == 0 {
+			fmt.Println("No features found.")
+		}
+		return featureNames, nil
+	}
+
+	// Auto-detect feature name if not provided
+	if featureName == "" {
+		detected, err := config.DetectFeatureFromWorkingDir(projectDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to detect feature from working directory: %w", err)
+		}
+		if detected == "" {
+			return nil, fmt.Errorf("no feature name provided and could not auto-detect from current directory")
+		}
+		featureName = detected
+		fmt.Printf("Auto-detected feature: %s\n", featureName)
+	}
+	return []string{featureName}, nil
+}
+
+// syncFeatureEnvFiles shows the env file diffs for one feature and applies them after confirmation
+func syncFeatureEnvFiles(projectDir string, cfg *config.Config, featureName string, conflict operations.EnvConflictPolicy) error {
+	progress := operations.NewCLIProgressReporter()
+
+	result, err := operations.PlanEnvSync(operations.EnvSyncOptions{
+		FeatureName:  featureName,
+		ProjectDir:   projectDir,
+		Config:       cfg,
+		Progress:     progress,
+		ForceRefresh: envSyncRefresh,
+		Conflict:     conflict,
+	})
+	if err != nil {
+		var driftErr *operations.EnvDriftError
+		if errors.As(err, &driftErr) {
+			return fmt.Errorf("%w\nRe-run with --keep, --merge or --force", err)
+		}
+		return err
+	}
+
+	if len(result.Changes) == 0 {
+		return nil
+	}
+
+	fmt.Println()
+	for _, change := range result.Changes {
+		if change.Created {
+			fmt.Printf("New file: %s/%s\n", change.Repo, change.Dest)
+		} else if change.Drifted && conflict == operations.EnvConflictMerge {
+			fmt.Printf("Merged local edits: %s/%s\n", change.Repo, change.Dest)
+		} else if change.Drifted {
+			fmt.Printf("Overwriting local edits: %s/%s\n", change.Repo, change.Dest)
+		}
+		fmt.Print(change.Diff())
+		fmt.Println()
+	}
+
+	if envSyncDryRun {
+		fmt.Printf("Dry run: %d file(s) would be updated for '%s'\n", len(result.Changes), featureName)
+		return nil
+	}
+
+	// In non-interactive mode, auto-confirm
+	if !NonInteractive && !confirmEnvSync(featureName, len(result.Changes)) {
+		fmt.Println("Env sync cancelled.")
+		return nil
+	}
+
+	return operations.ApplyEnvSync(result, progress)
+}
+
+func runEnvCheck(featureName string) error {
+	wd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+
+	projectDir, err := config.FindRampProject(wd)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.LoadConfig(projectDir)
+	if err != nil {
+		return err
+	}
+
+	featureNames, err := envFeatureNames(projectDir, featureName, envCheckAll)
+	if err != nil || len(featureNames) == 0 {
+		return err
+	}
+
+	if !operations.HasEnvFiles(cfg.GetRepos()) {
+		fmt.Println("No env_files configured.")
+		return nil
+	}
+
+	var failed []string
+	for _, name := range featureNames {
+		result, err := operations.CheckEnv(operations.EnvCheckOptions{
+			FeatureName: name,
+			ProjectDir:  projectDir,
+			Config:      cfg,
+		})
+		if err != nil {
+			return err
+		}
+
+		if len(result.Issues) == 0 {
+			fmt.Printf("✅ %s: %d env file(s) OK\n", name, result.Checked)
+			continue
+		}
+
+		fmt.Printf("⚠️  %s: %d of %d env file(s) have unresolved values\n", name, len(result.Issues), result.Checked)
+		for _, issue := range result.Issues {
+			fmt.Printf("  %s\n", issue.Describe())
+		}
+		if result.Err() != nil {
+			failed = append(failed, name)
+		}
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("required env values are missing for %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// envSyncConflictPolicy returns the policy selected by --keep, --merge or --force
+func envSyncConflictPolicy() (operations.EnvConflictPolicy, error) {
+	policy := operations.EnvConflictFail
+	selected := 0
+	if envSyncKeep {
+		policy = operations.EnvConflictKeep
+		selected++
+	}
+	if envSyncMerge {
+		policy = operations.EnvConflictMerge
+		selected++
+	}
+	if envSyncForce {
+		policy = operations.EnvConflictForce
+		selected++
+	}
+	if selected > 1 {
+		return policy, fmt.Errorf("only one of --keep, --merge or --force can be used")
+	}
+	return policy, nil
+}
+
+func confirmEnvSync(featureName string, count int) bool {
+	fmt.Printf("Overwrite %d env file(s) for feature '%s'? (y/N): ", count, featureName)
+
+	reader := bufio.NewReader(os.Stdin)
+	input, _ := reader.ReadString('\n')
+	input = strings.TrimSpace(strings.ToLower(input))
+
+	return input == "y" || input == "yes"
+}
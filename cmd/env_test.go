@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ramp/internal/config"
+)
+
+// setupEnvSyncFeature creates a feature whose .env is generated from .env.example,
+// then edits the source so the feature is stale
+func setupEnvSyncFeature(t *testing.T, tp *TestProject) string {
+	t.Helper()
+
+	repo := tp.InitRepo("app")
+	tp.Config.Repos[0].EnvFiles = []config.EnvFile{{Source: ".env.example", Dest: ".env"}}
+	if err := config.SaveConfig(tp.Config, tp.Dir); err != nil {
+		t.Fatalf("failed to save config: %v", err)
+	}
+
+	sourceEnv := filepath.Join(repo.SourceDir, ".env.example")
+	os.WriteFile(sourceEnv, []byte("NAME=${RAMP_WORKTREE_NAME}\n"), 0644)
+
+	if err := runUp("stale", "", "", ""); err != nil {
+		t.Fatalf("runUp() error = %v", err)
+	}
+
+	os.WriteFile(sourceEnv, []byte("NAME=${RAMP_WORKTREE_NAME}\nPORT=${RAMP_PORT}\n"), 0644)
+
+	return filepath.Join(tp.TreesDir, "stale", "app", ".env")
+}
+
+// TestEnvSyncDryRun tests that --dry-run leaves files untouched
+func TestEnvSyncDryRun(t *testing.T) {
+	tp := NewTestProject(t)
+	cleanup := tp.ChangeToProjectDir()
+	defer cleanup()
+
+	envPath := setupEnvSyncFeature(t, tp)
+
+	envSyncDryRun = true
+	defer func() { envSyncDryRun = false }()
+
+	if err := runEnvSync("stale"); err != nil {
+		t.Fatalf("runEnvSync() error = %v", err)
+	}
+
+	content, _ := os.ReadFile(envPath)
+	if string(content) != "NAME=stale\n" {
+		t.Errorf(".env content = %q, want unchanged %q", string(content), "NAME=stale\n")
+	}
+}
+
+// TestEnvSyncAll tests syncing every feature non-interactively
+func TestEnvSyncAll(t *testing.T) {
+	tp := NewTestProject(t)
+	cleanup := tp.ChangeToProjectDir()
+	defer cleanup()
+
+	envPath := setupEnvSyncFeature(t, tp)
+
+	origNonInteractive := NonInteractive
+	NonInteractive = true
+	envSyncAll = true
+	defer func() {
+		NonInteractive = origNonInteractive
+		envSyncAll = false
+	}()
+
+	if err := runEnvSync(""); err != nil {
+		t.Fatalf("runEnvSync() error = %v", err)
+	}
+
+	content, _ := os.ReadFile(envPath)
+	if string(content) != "NAME=stale\nPORT=3000\n" {
+		t.Errorf(".env content = %q, want %q", string(content), "NAME=stale\nPORT=3000\n")
+	}
+}
+
+// TestEnvSyncFeatureNotFound tests syncing a feature that does not exist
+func TestEnvSyncFeatureNotFound(t *testing.T) {
+	tp := NewTestProject(t)
+	tp.InitRepo("app")
+	tp.Config.Repos[0].EnvFiles = []config.EnvFile{{Source: ".env", Dest: ".env"}}
+	config.SaveConfig(tp.Config, tp.Dir)
+
+	cleanup := tp.ChangeToProjectDir()
+	defer cleanup()
+
+	if err := runEnvSync("missing"); err == nil {
+		t.Error("runEnvSync() should fail for a missing feature")
+	}
+}
+
+// TestEnvSyncLocalEdits tests that edited env files need --keep, --merge or --force
+func TestEnvSyncLocalEdits(t *testing.T) {
+	tp := NewTestProject(t)
+	cleanup := tp.ChangeToProjectDir()
+	defer cleanup()
+
+	envPath := setupEnvSyncFeature(t, tp)
+	os.WriteFile(envPath, []byte("DEBUG=1\nNAME=stale\n"), 0644)
+
+	origNonInteractive := NonInteractive
+	NonInteractive = true
+	defer func() {
+		NonInteractive = origNonInteractive
+		envSyncKeep = false
+		envSyncMerge = false
+	}()
+
+	if err := runEnvSync("stale"); err == nil {
+		t.Fatal("runEnvSync() should refuse to overwrite local edits")
+	}
+
+	envSyncKeep = true
+	envSyncMerge = true
+	if err := runEnvSync("stale"); err == nil {
+		t.Fatal("runEnvSync() should reject more than one conflict flag")
+	}
+
+	envSyncKeep = false
+	if err := runEnvSync("stale"); err != nil {
+		t.Fatalf("runEnvSync() with --merge error = %v", err)
+	}
+
+	content, _ := os.ReadFile(envPath)
+	want := "DEBUG=1\nNAME=stale\nPORT=3000\n"
+	if string(content) != want {
+		t.Errorf(".env content = %q, want %q", string(content), want)
+	}
+}
+
+// TestEnvCheck tests reporting missing required values for an existing feature
+func TestEnvCheck(t *testing.T) {
+	tp := NewTestProject(t)
+	cleanup := tp.ChangeToProjectDir()
+	defer cleanup()
+
+	repo := tp.InitRepo("app")
+	tp.Config.Repos[0].EnvFiles = []config.EnvFile{{Source: ".env.example", Dest: ".env", Required: []string{"STRIPE_KEY"}}}
+	if err := config.SaveConfig(tp.Config, tp.Dir); err != nil {
+		t.Fatalf("failed to save config: %v", err)
+	}
+	os.WriteFile(filepath.Join(repo.SourceDir, ".env.example"), []byte("STRIPE_KEY=sk_test\n"), 0644)
+
+	if err := runUp("checked", "", "", ""); err != nil {
+		t.Fatalf("runUp() error = %v", err)
+	}
+
+	if err := runEnvCheck("checked"); err != nil {
+		t.Errorf("runEnvCheck() error = %v, want nil", err)
+	}
+
+	// Blank the required key by hand
+	envPath := filepath.Join(tp.TreesDir, "checked", "app", ".env")
+	os.WriteFile(envPath, []byte("STRIPE_KEY=\n"), 0644)
+
+	err := runEnvCheck("checked")
+	if err == nil || !strings.Contains(err.Error(), "checked") {
+		t.Errorf("runEnvCheck() error = %v, want missing values for checked", err)
+	}
+}
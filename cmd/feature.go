@@ -0,0 +1,422 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"ramp/internal/config"
+	"ramp/internal/git"
+	"ramp/internal/operations"
+)
+
+var featureCmd = &cobra.Command{
+	Use:   "feature",
+	Short: "Inspect and adjust existing features",
+}
+
+var featureSparseCmd = &cobra.Command{
+	Use:   "sparse <feature> <repo> [list|add|remove] [path...]",
+	Short: "Show or change which directories a sparse worktree checks out",
+	Long: `Show or change the sparse-checkout cone of one repository in a feature.
+
+Repositories with a sparse: list in ramp.yaml get worktrees that only check out
+those directories (plus files at the repository root). Use add and remove to
+adjust an existing worktree; the change applies to that feature only and
+ramp.yaml is left as is.
+
+Examples:
+  ramp feature sparse my-feature monorepo
+  ramp feature sparse my-feature monorepo add services/billing
+  ramp feature sparse my-feature monorepo remove docs`,
+	Args: cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		action := "list"
+		if len(args) > 2 {
+			action = args[2]
+		}
+		var paths []string
+		if len(args) > 3 {
+			paths = args[3:]
+		}
+
+		if err := runFeatureSparse(strings.TrimRight(args[0], "/"), args[1], action, paths); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+var featureInfoJSON bool
+
+var featureInfoCmd = &cobra.Command{
+	Use:   "info <feature>",
+	Short: "Show a feature's creation info, branches, ports, notes, tags and links",
+	Long: `Show what ramp knows about a feature: when and by whom it was created, the
+target and branch prefix ramp up used, the commit each worktree started at,
+the branch each worktree has checked out, its ports, and its notes, tags and
+links.
+
+Features created before ramp recorded creation info only show their trees
+directory's modification time as the creation time.
+
+Examples:
+  ramp feature info my-feature
+  ramp feature info my-feature --json`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runFeatureInfo(strings.TrimRight(args[0], "/"), featureInfoJSON); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+var (
+	featureNoteLinks  []string
+	featureNoteUnlink []string
+	featureNoteClear  bool
+)
+
+var featureNoteCmd = &cobra.Command{
+	Use:   "note <feature> [text]",
+	Short: "Add notes and links to a feature",
+	Long: `Add a free-form note to a feature, or set links such as its ticket or pull
+request. Notes are kept in order with the time they were added; --clear
+removes the existing ones first.
+
+Links are given as label=url. Setting a label again replaces its URL and
+--unlink removes it.
+
+Without text or flags, shows the feature's notes and links.
+
+Examples:
+  ramp feature note my-feature "Waiting on the API review"
+  ramp feature note my-feature --link ticket=https://tracker.example.com/PROJ-42
+  ramp feature note my-feature --link pr=https://github.com/org/app/pull/7 --unlink draft
+  ramp feature note my-feature --clear`,
+	Args: cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		text := ""
+		if len(args) == 2 {
+			text = args[1]
+		}
+
+		if err := runFeatureNote(strings.TrimRight(args[0], "/"), text, featureNoteLinks, featureNoteUnlink, featureNoteClear); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+var featureTagRemove []string
+
+var featureTagCmd = &cobra.Command{
+	Use:   "tag <feature> [tag...]",
+	Short: "Tag a feature",
+	Long: `Add tags to a feature, or remove them with --remove. Tags are single
+lowercase words; 'ramp status --tag <tag>' shows only the features with a tag
+and 'ramp status --group-by-tag' lists features under each of their tags.
+
+Without tags or flags, shows the feature's tags.
+
+Examples:
+  ramp feature tag my-feature billing urgent
+  ramp feature tag my-feature --remove urgent
+  ramp feature tag my-feature`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runFeatureTag(strings.TrimRight(args[0], "/"), args[1:], featureTagRemove); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	featureInfoCmd.Flags().BoolVar(&featureInfoJSON, "json", false, "Output the feature's details as JSON (useful for scripts)")
+	featureNoteCmd.Flags().StringArrayVar(&featureNoteLinks, "link", nil, "Set a link as label=url (repeatable)")
+	featureNoteCmd.Flags().StringArrayVar(&featureNoteUnlink, "unlink", nil, "Remove the link with this label (repeatable)")
+	featureNoteCmd.Flags().BoolVar(&featureNoteClear, "clear", false, "Remove the feature's existing notes")
+	featureTagCmd.Flags().StringArrayVar(&featureTagRemove, "remove", nil, "Remove this tag (repeatable)")
+
+	featureCmd.AddCommand(featureSparseCmd)
+	featureCmd.AddCommand(featureInfoCmd)
+	featureCmd.AddCommand(featureNoteCmd)
+	featureCmd.AddCommand(featureTagCmd)
+	rootCmd.AddCommand(featureCmd)
+}
+
+// loadFeatureProject finds the project of the working directory and loads its config.
+func loadFeatureProject() (string, *config.Config, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to get current directory: %w", err)
+	}
+
+	projectDir, err := config.FindRampProject(wd)
+	if err != nil {
+		return "", nil, err
+	}
+
+	cfg, err := config.LoadConfig(projectDir)
+	if err != nil {
+		return "", nil, err
+	}
+	return projectDir, cfg, nil
+}
+
+func runFeatureInfo(featureName string, asJSON bool) error {
+	projectDir, cfg, err := loadFeatureProject()
+	if err != nil {
+		return err
+	}
+
+	details, err := operations.GetFeatureDetails(projectDir, cfg, featureName)
+	if err != nil {
+		return err
+	}
+
+	if asJSON {
+		data, err := json.MarshalIndent(details, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal feature details: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	if details.DisplayName != "" {
+		fmt.Printf("%s (%s)\n", details.DisplayName, details.Name)
+	} else {
+		fmt.Println(details.Name)
+	}
+	fmt.Printf("  Directory: %s\n", details.TreesDir)
+
+	created := details.Created.Format("2006-01-02 15:04")
+	if details.Creation != nil && details.Creation.By != "" {
+		created += " by " + details.Creation.By
+	}
+	fmt.Printf("  Created:   %s\n", created)
+	if details.Creation != nil && details.Creation.Target != "" {
+		fmt.Printf("  Target:    %s\n", details.Creation.Target)
+	}
+	if len(details.Ports) > 0 {
+		fmt.Printf("  Ports:     %s\n", strings.Trim(fmt.Sprint(details.Ports), "[]"))
+	}
+	if len(details.Tags) > 0 {
+		fmt.Printf("  Tags:      %s\n", strings.Join(details.Tags, ", "))
+	}
+
+	fmt.Println("\n  Branches:")
+	for _, repoName := range slices.Sorted(maps.Keys(details.Branches)) {
+		line := fmt.Sprintf("    %s: %s", repoName, details.Branches[repoName])
+		if details.Creation != nil {
+			if base, ok := details.Creation.BaseCommits[repoName]; ok && len(base) >= 7 {
+				line += fmt.Sprintf(" (started at %s)", base[:7])
+			}
+		}
+		fmt.Println(line)
+	}
+
+	printFeatureNotes(details)
+	return nil
+}
+
+func printFeatureNotes(details *operations.FeatureDetails) {
+	if len(details.Links) > 0 {
+		fmt.Println("\n  Links:")
+		for _, label := range slices.Sorted(maps.Keys(details.Links)) {
+			fmt.Printf("    %s: %s\n", label, details.Links[label])
+		}
+	}
+	if len(details.Notes) > 0 {
+		fmt.Println("\n  Notes:")
+		for _, note := range details.Notes {
+			fmt.Printf("    %s  %s\n", note.Added.Format("2006-01-02 15:04"), note.Text)
+		}
+	}
+}
+
+func runFeatureNote(featureName, text string, links, unlink []string, clear bool) error {
+	projectDir, cfg, err := loadFeatureProject()
+	if err != nil {
+		return err
+	}
+
+	linkChanges := make(map[string]string)
+	for _, link := range links {
+		label, url, ok := strings.Cut(link, "=")
+		if !ok {
+			return fmt.Errorf("invalid link '%s' (expected label=url)", link)
+		}
+		linkChanges[label] = url
+	}
+	for _, label := range unlink {
+		linkChanges[label] = ""
+	}
+
+	if text != "" || clear || len(linkChanges) > 0 {
+		err := operations.AnnotateFeature(operations.AnnotateOptions{
+			FeatureName: featureName,
+			ProjectDir:  projectDir,
+			Note:        text,
+			ClearNotes:  clear,
+			Links:       linkChanges,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	details, err := operations.GetFeatureDetails(projectDir, cfg, featureName)
+	if err != nil {
+		return err
+	}
+	if len(details.Notes) == 0 && len(details.Links) == 0 {
+		fmt.Printf("%s has no notes or links\n", featureName)
+		return nil
+	}
+	fmt.Print(featureName)
+	printFeatureNotes(details)
+	return nil
+}
+
+func runFeatureTag(featureName string, add, remove []string) error {
+	projectDir, cfg, err := loadFeatureProject()
+	if err != nil {
+		return err
+	}
+
+	if len(add) > 0 || len(remove) > 0 {
+		err := operations.AnnotateFeature(operations.AnnotateOptions{
+			FeatureName: featureName,
+			ProjectDir:  projectDir,
+			AddTags:     add,
+			RemoveTags:  remove,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	details, err := operations.GetFeatureDetails(projectDir, cfg, featureName)
+	if err != nil {
+		return err
+	}
+	if len(details.Tags) == 0 {
+		fmt.Printf("%s has no tags\n", featureName)
+	} else {
+		fmt.Printf("%s: %s\n", featureName, strings.Join(details.Tags, ", "))
+	}
+	return nil
+}
+
+func runFeatureSparse(featureName, repoName, action string, paths []string) error {
+	wd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+
+	projectDir, err := config.FindRampProject(wd)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.LoadConfig(projectDir)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := cfg.GetRepos()[repoName]; !ok {
+		return fmt.Errorf("repository '%s' not found in configuration", repoName)
+	}
+
+	worktreeDir := filepath.Join(projectDir, "trees", featureName, repoName)
+	if _, err := os.Stat(worktreeDir); os.IsNotExist(err) {
+		return fmt.Errorf("feature '%s' has no worktree for %s", featureName, repoName)
+	}
+
+	sparse := git.IsSparseCheckout(worktreeDir)
+
+	switch action {
+	case "list":
+		if !sparse {
+			fmt.Printf("%s/%s is a full checkout\n", featureName, repoName)
+			return nil
+		}
+		patterns, err := git.SparseCheckoutPatterns(worktreeDir)
+		if err != nil {
+			return err
+		}
+		printSparsePatterns(featureName, repoName, patterns)
+		return nil
+
+	case "add", "remove":
+		if len(paths) == 0 {
+			return fmt.Errorf("%s requires at least one path", action)
+		}
+		if !sparse {
+			return fmt.Errorf("%s/%s is a full checkout; add a sparse: list to %s in ramp.yaml to create sparse worktrees", featureName, repoName, repoName)
+		}
+
+	default:
+		return fmt.Errorf("unknown action '%s' (expected list, add or remove)", action)
+	}
+
+	for i, path := range paths {
+		paths[i] = normalizeSparsePath(path)
+	}
+
+	if action == "add" {
+		if err := git.AddSparseCheckout(worktreeDir, paths); err != nil {
+			return err
+		}
+	} else {
+		patterns, err := git.SparseCheckoutPatterns(worktreeDir)
+		if err != nil {
+			return err
+		}
+		for _, path := range paths {
+			if !slices.Contains(patterns, path) {
+				return fmt.Errorf("'%s' is not checked out in %s/%s", path, featureName, repoName)
+			}
+		}
+		remaining := slices.DeleteFunc(patterns, func(pattern string) bool {
+			return slices.Contains(paths, pattern)
+		})
+		if err := git.SetSparseCheckout(worktreeDir, remaining); err != nil {
+			return err
+		}
+	}
+
+	patterns, err := git.SparseCheckoutPatterns(worktreeDir)
+	if err != nil {
+		return err
+	}
+	printSparsePatterns(featureName, repoName, patterns)
+	return nil
+}
+
+// normalizeSparsePath turns a user-supplied directory into a cone pattern ("./src/" -> "src")
+func normalizeSparsePath(path string) string {
+	path = filepath.ToSlash(filepath.Clean(path))
+	return strings.Trim(path, "/")
+}
+
+func printSparsePatterns(featureName, repoName string, patterns []string) {
+	if len(patterns) == 0 {
+		fmt.Printf("%s/%s is sparse: only files at the repository root are checked out\n", featureName, repoName)
+		return
+	}
+	fmt.Printf("%s/%s is sparse, checking out:\n", featureName, repoName)
+	for _, pattern := range patterns {
+		fmt.Printf("  %s\n", pattern)
+	}
+}
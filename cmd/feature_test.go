@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"ramp/internal/config"
+	"ramp/internal/features"
+	"ramp/internal/git"
+)
+
+// TestFeatureSparse tests sparse worktrees from config and adjusting them later
+func TestFeatureSparse(t *testing.T) {
+	tp := NewTestProject(t)
+	repo := tp.InitRepo("mono")
+
+	for _, dir := range []string{"services/api", "services/web", "docs"} {
+		os.MkdirAll(filepath.Join(repo.SourceDir, dir), 0755)
+		os.WriteFile(filepath.Join(repo.SourceDir, dir, "main.txt"), []byte(dir), 0644)
+	}
+	runGitCmd(t, repo.SourceDir, "add", ".")
+	runGitCmd(t, repo.SourceDir, "commit", "-m", "add dirs")
+
+	tp.Config.Repos[0].Sparse = []string{"services/api"}
+	if err := config.SaveConfig(tp.Config, tp.Dir); err != nil {
+		t.Fatalf("failed to save config: %v", err)
+	}
+
+	cleanup := tp.ChangeToProjectDir()
+	defer cleanup()
+
+	if err := runUp("slim", "", "", ""); err != nil {
+		t.Fatalf("runUp() error = %v", err)
+	}
+
+	worktreeDir := filepath.Join(tp.TreesDir, "slim", "mono")
+	exists := func(path string) bool {
+		_, err := os.Stat(filepath.Join(worktreeDir, path))
+		return err == nil
+	}
+
+	if !exists("README.md") || !exists("services/api/main.txt") {
+		t.Error("sparse worktree should contain root files and services/api")
+	}
+	if exists("services/web") || exists("docs") {
+		t.Error("sparse worktree should not contain directories outside the cone")
+	}
+	if git.IsSparseCheckout(repo.SourceDir) {
+		t.Error("source repo should not become sparse")
+	}
+
+	status := getFeatureWorktreeStatus(tp.Dir, "slim", "mono", tp.Config.Repos[0])
+	if !status.sparse {
+		t.Error("status should report the worktree as sparse")
+	}
+
+	if err := runFeatureSparse("slim", "mono", "add", []string{"./docs/"}); err != nil {
+		t.Fatalf("runFeatureSparse(add) error = %v", err)
+	}
+	if !exists("docs/main.txt") {
+		t.Error("docs should be checked out after add")
+	}
+
+	if err := runFeatureSparse("slim", "mono", "remove", []string{"services/api"}); err != nil {
+		t.Fatalf("runFeatureSparse(remove) error = %v", err)
+	}
+	if exists("services/api") {
+		t.Error("services/api should be removed from the checkout")
+	}
+
+	patterns, err := git.SparseCheckoutPatterns(worktreeDir)
+	if err != nil || len(patterns) != 1 || patterns[0] != "docs" {
+		t.Errorf("patterns = %v (%v), want [docs]", patterns, err)
+	}
+
+	if err := runFeatureSparse("slim", "mono", "remove", []string{"services/web"}); err == nil {
+		t.Error("removing a directory that is not checked out should fail")
+	}
+	if err := runFeatureSparse("slim", "mono", "rename", nil); err == nil {
+		t.Error("unknown action should fail")
+	}
+	if err := runFeatureSparse("slim", "other", "list", nil); err == nil {
+		t.Error("unknown repo should fail")
+	}
+}
+
+// TestFeatureSparseFullCheckout tests that add and remove refuse full checkouts
+func TestFeatureSparseFullCheckout(t *testing.T) {
+	tp := NewTestProject(t)
+	tp.InitRepo("app")
+
+	cleanup := tp.ChangeToProjectDir()
+	defer cleanup()
+
+	if err := runUp("full", "", "", ""); err != nil {
+		t.Fatalf("runUp() error = %v", err)
+	}
+
+	if err := runFeatureSparse("full", "app", "list", nil); err != nil {
+		t.Errorf("runFeatureSparse(list) error = %v", err)
+	}
+	if err := runFeatureSparse("full", "app", "add", []string{"src"}); err == nil {
+		t.Error("add should fail for a full checkout")
+	}
+}
+
+// TestFeatureNoteAndTag tests annotating a feature with notes, links and tags
+func TestFeatureNoteAndTag(t *testing.T) {
+	tp := NewTestProject(t)
+	tp.InitRepo("app")
+
+	cleanup := tp.ChangeToProjectDir()
+	defer cleanup()
+
+	if err := runUp("billing", "", "", ""); err != nil {
+		t.Fatalf("runUp() error = %v", err)
+	}
+
+	if err := runFeatureNote("billing", "waiting on review", []string{"ticket=https://tracker.example.com/PROJ-42"}, nil, false); err != nil {
+		t.Fatalf("runFeatureNote() error = %v", err)
+	}
+	if err := runFeatureNote("billing", "", []string{"no-url"}, nil, false); err == nil {
+		t.Error("runFeatureNote() should reject a link without a URL")
+	}
+	if err := runFeatureTag("billing", []string{"Payments", "urgent"}, nil); err != nil {
+		t.Fatalf("runFeatureTag() error = %v", err)
+	}
+	if err := runFeatureTag("billing", nil, []string{"urgent"}); err != nil {
+		t.Fatalf("runFeatureTag(remove) error = %v", err)
+	}
+	if err := runFeatureInfo("billing", true); err != nil {
+		t.Fatalf("runFeatureInfo() error = %v", err)
+	}
+
+	meta := loadFeatureMetadata(t, tp.Dir, "billing")
+	if !slices.Equal(meta.Tags, []string{"payments"}) {
+		t.Errorf("Tags = %v, want [payments]", meta.Tags)
+	}
+	if len(meta.Notes) != 1 || meta.Links["ticket"] != "https://tracker.example.com/PROJ-42" {
+		t.Errorf("metadata = %+v, want the note and ticket link", meta)
+	}
+
+	if err := runFeatureNote("billing", "", nil, []string{"ticket"}, true); err != nil {
+		t.Fatalf("runFeatureNote(clear) error = %v", err)
+	}
+	meta = loadFeatureMetadata(t, tp.Dir, "billing")
+	if len(meta.Notes) != 0 || len(meta.Links) != 0 {
+		t.Errorf("metadata = %+v, want notes and links removed", meta)
+	}
+}
+
+func loadFeatureMetadata(t *testing.T, projectDir, featureName string) features.FeatureMetadata {
+	t.Helper()
+	store, err := features.NewMetadataStore(projectDir)
+	if err != nil {
+		t.Fatalf("failed to load metadata: %v", err)
+	}
+	return store.Get(featureName)
+}
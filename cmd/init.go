@@ -440,5 +440,5 @@ func promptInstallRepos(projectDir string) error {
 
 	// Run install (init always uses full clone for complete history)
 	fmt.Println()
-	return runInstallForProject(projectDir, cfg, false)
+	return runInstallForProject(projectDir, cfg, false, "", 0)
 }
@@ -3,22 +3,35 @@ package cmd
 import (
 	"fmt"
 	"os"
-	"path/filepath"
 
 	"github.com/spf13/cobra"
 
 	"ramp/internal/config"
 	"ramp/internal/git"
+	"ramp/internal/operations"
 	"ramp/internal/ui"
 )
 
 var shallowFlag bool
+var filterFlag string
+var installJobsFlag int
+var installDryRun bool
+var installJSON bool
 
 var installCmd = &cobra.Command{
 	Use:   "install",
 	Short: "Clone all configured repositories from ramp.yaml",
 	Long: `Clone all repositories specified in the .ramp/ramp.yaml configuration file
-into their configured locations.
+into their configured locations. Repositories are cloned in parallel, up to --jobs
+at a time (max_parallel in ramp.yaml, default 4).
+
+Use --filter=blob:none for a partial clone: full history is fetched, so ahead/behind
+and merge detection keep working, but file contents are downloaded on demand. Unlike
+--shallow this does not cut history. Per-repo filter and reference settings in
+ramp.yaml apply as well; --filter overrides the filter for every repository.
+
+Use --dry-run to list the repositories that would be cloned and the remotes that
+would be added without changing anything. Add --json for a machine-readable plan.
 
 This command must be run from within a directory containing a .ramp/ramp.yaml file.`,
 	Run: func(cmd *cobra.Command, args []string) {
@@ -32,6 +45,9 @@ This command must be run from within a directory containing a .ramp/ramp.yaml fi
 func init() {
 	rootCmd.AddCommand(installCmd)
 	installCmd.Flags().BoolVar(&shallowFlag, "shallow", false, "Perform a shallow clone (--depth 1) to reduce clone time and disk usage")
+	installCmd.Flags().StringVar(&filterFlag, "filter", "", "Partial clone filter for all repositories (e.g. blob:none)")
+	installCmd.Flags().IntVarP(&installJobsFlag, "jobs", "j", 0, "Maximum number of repositories to clone in parallel (defaults to config max_parallel, or 4)")
+	addDryRunFlags(installCmd, &installDryRun, &installJSON)
 }
 
 // isProjectInstalled checks if all configured repositories are present
@@ -55,7 +71,7 @@ func AutoInstallIfNeeded(projectDir string, cfg *config.Config) error {
 	progress := ui.NewProgress()
 	progress.Info("Repositories not installed, running auto-installation...")
 	progress.Stop()
-	return runInstallForProject(projectDir, cfg, false) // Auto-install always uses full clone
+	return runInstallForProject(projectDir, cfg, false, "", 0) // Auto-install always uses full clone
 }
 
 func runInstall() error {
@@ -74,39 +90,34 @@ func runInstall() error {
 		return err
 	}
 
-	return runInstallForProject(projectDir, cfg, shallowFlag)
-}
-
-func runInstallForProject(projectDir string, cfg *config.Config, shallow bool) error {
-	progress := ui.NewProgress()
-	progress.Info(fmt.Sprintf("Installing repositories for ramp project '%s'", cfg.Name))
-
-	repos := cfg.GetRepos()
-	progress.Info(fmt.Sprintf("Found %d repositories to clone", len(repos)))
-
-	for name, repo := range repos {
-		// Get the configured path for this repository
-		repoDir := repo.GetRepoPath(projectDir)
-
-		// Create parent directories if needed
-		if err := os.MkdirAll(filepath.Dir(repoDir), 0755); err != nil {
-			progress.Error(fmt.Sprintf("Failed to create directory %s", filepath.Dir(repoDir)))
-			return fmt.Errorf("failed to create directory %s: %w", filepath.Dir(repoDir), err)
-		}
-
-		if git.IsGitRepo(repoDir) {
-			progress.Info(fmt.Sprintf("%s: already exists at %s, skipping", name, repoDir))
-			continue
-		}
+	if err := checkDryRunFlags(installDryRun, installJSON); err != nil {
+		return err
+	}
 
-		gitURL := repo.GetGitURL()
-		progress.Info(fmt.Sprintf("%s: cloning from %s to %s", name, gitURL, repoDir))
-		if err := git.Clone(gitURL, repoDir, shallow); err != nil {
-			progress.Error(fmt.Sprintf("Failed to clone %s", name))
-			return fmt.Errorf("failed to clone %s: %w", name, err)
-		}
+	if installDryRun {
+		progress := planProgress(installJSON)
+		plan := operations.PlanInstall(operations.InstallOptions{
+			ProjectDir: projectDir,
+			Config:     cfg,
+			Progress:   progress,
+			Shallow:    shallowFlag,
+			Filter:     filterFlag,
+		})
+		return printPlan(progress, plan, installJSON)
 	}
 
-	progress.Success("Installation complete!")
-	return nil
+	return runInstallForProject(projectDir, cfg, shallowFlag, filterFlag, installJobsFlag)
+}
+
+func runInstallForProject(projectDir string, cfg *config.Config, shallow bool, filter string, jobs int) error {
+	progress := operations.NewCLIProgressReporter()
+	_, err := operations.Install(operations.InstallOptions{
+		ProjectDir: projectDir,
+		Config:     cfg,
+		Progress:   progress,
+		Shallow:    shallow,
+		Filter:     filter,
+		Workers:    jobs,
+	})
+	return err
 }
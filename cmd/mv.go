@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"ramp/internal/config"
+	"ramp/internal/operations"
+)
+
+var mvRemote bool
+
+var mvCmd = &cobra.Command{
+	Use:   "mv <feature> <new-name>",
+	Short: "Rename a feature's directory and branches",
+	Long: `Rename a feature without recreating it. Worktrees move from trees/<feature>
+to trees/<new-name> with 'git worktree move', keeping uncommitted changes, and
+every branch named after the feature is renamed (feature/<feature> becomes
+feature/<new-name>). The feature's ports and metadata move to the new name,
+and env files are re-rendered so values that embed the feature's name or path
+are updated, merging any local edits.
+
+If any step fails, the steps already done are undone and the feature keeps its
+old name.
+
+With --remote, renamed branches that were pushed are pushed under their new
+name and deleted from origin under the old one. Without it, the branches keep
+tracking their old name on origin until the next 'ramp push'.
+
+To only change the name shown in status output and the UI, use 'ramp rename'.
+
+Examples:
+  ramp mv my-feature better-name
+  ramp mv my-feature better-name --remote`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		featureName := strings.TrimRight(args[0], "/")
+		newName := strings.TrimRight(args[1], "/")
+
+		if err := runMv(featureName, newName, mvRemote); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	mvCmd.Flags().BoolVar(&mvRemote, "remote", false, "Also rename pushed branches on origin")
+	rootCmd.AddCommand(mvCmd)
+}
+
+func runMv(featureName, newName string, renameRemote bool) error {
+	wd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+
+	projectDir, err := config.FindRampProject(wd)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.LoadConfig(projectDir)
+	if err != nil {
+		return err
+	}
+
+	result, err := operations.MoveFeature(operations.MoveOptions{
+		FeatureName:  featureName,
+		NewName:      newName,
+		ProjectDir:   projectDir,
+		Config:       cfg,
+		Progress:     operations.NewCLIProgressReporter(),
+		RenameRemote: renameRemote,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, repo := range result.Repos {
+		switch {
+		case repo.Branch == repo.OldBranch:
+			fmt.Printf("  %s: kept branch %s\n", repo.Repo, repo.Branch)
+		case repo.RemoteRenamed:
+			fmt.Printf("  %s: %s → %s (also on origin)\n", repo.Repo, repo.OldBranch, repo.Branch)
+		default:
+			fmt.Printf("  %s: %s → %s\n", repo.Repo, repo.OldBranch, repo.Branch)
+		}
+	}
+
+	// The shell's working directory was moved away from under it
+	oldTreesDir := filepath.Join(projectDir, "trees", featureName)
+	if rel, err := filepath.Rel(oldTreesDir, wd); err == nil && !strings.HasPrefix(rel, "..") {
+		fmt.Printf("\nYour current directory moved; run: cd %s\n", filepath.Join(result.TreesDir, rel))
+	}
+	return nil
+}
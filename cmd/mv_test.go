@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestMvRenamesFeature tests moving a feature's worktrees and branches to a new name
+func TestMvRenamesFeature(t *testing.T) {
+	tp := NewTestProject(t)
+	repo1 := tp.InitRepo("repo1")
+
+	cleanup := tp.ChangeToProjectDir()
+	defer cleanup()
+
+	if err := runUp("my-feature", "", "", ""); err != nil {
+		t.Fatalf("runUp() error = %v", err)
+	}
+
+	if err := runMv("my-feature", "better-name", false); err != nil {
+		t.Fatalf("runMv() error = %v", err)
+	}
+
+	if tp.FeatureExists("my-feature") || !tp.WorktreeExists("better-name", "repo1") {
+		t.Error("feature should be moved to trees/better-name")
+	}
+	if repo1.BranchExists(t, "feature/my-feature") || !repo1.BranchExists(t, "feature/better-name") {
+		t.Error("branch should be renamed to feature/better-name")
+	}
+}
+
+// TestMvExistingFeature tests that a feature can't be moved onto another one
+func TestMvExistingFeature(t *testing.T) {
+	tp := NewTestProject(t)
+	tp.InitRepo("repo1")
+
+	cleanup := tp.ChangeToProjectDir()
+	defer cleanup()
+
+	for _, name := range []string{"first", "second"} {
+		if err := runUp(name, "", "", ""); err != nil {
+			t.Fatalf("runUp(%s) error = %v", name, err)
+		}
+	}
+
+	if err := runMv("first", "second", false); err == nil {
+		t.Fatal("runMv() onto an existing feature should fail")
+	}
+	if _, err := os.Stat(filepath.Join(tp.TreesDir, "first", "repo1")); err != nil {
+		t.Error("the feature should be left where it was")
+	}
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"ramp/internal/operations"
+)
+
+// addDryRunFlags registers --dry-run and --json on a command whose operation
+// can be planned without making changes.
+func addDryRunFlags(cmd *cobra.Command, dryRun, asJSON *bool) {
+	cmd.Flags().BoolVar(dryRun, "dry-run", false, "Show what would be done without making any changes")
+	cmd.Flags().BoolVar(asJSON, "json", false, "With --dry-run, print the plan as JSON")
+}
+
+// checkDryRunFlags rejects --json without --dry-run.
+func checkDryRunFlags(dryRun, asJSON bool) error {
+	if asJSON && !dryRun {
+		return fmt.Errorf("--json can only be used with --dry-run")
+	}
+	return nil
+}
+
+// planProgress returns the progress reporter used while planning: the usual
+// spinner for text output, nothing for JSON so stdout stays parseable.
+func planProgress(asJSON bool) operations.ProgressReporter {
+	if asJSON {
+		return operations.DiscardProgress{}
+	}
+	return operations.NewCLIProgressReporter()
+}
+
+// printPlan stops the planning progress and prints the plan of a --dry-run
+// as text or JSON.
+func printPlan(progress operations.ProgressReporter, planned operations.Planned, asJSON bool) error {
+	progress.Stop()
+
+	if asJSON {
+		data, err := json.MarshalIndent(planned, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal plan: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	plan := planned.Summary()
+	fmt.Printf("\n📋 Plan for %s '%s':\n\n", plan.Operation, plan.Target)
+	if len(plan.Steps) == 0 {
+		fmt.Println("  Nothing to do")
+	}
+	for _, step := range plan.Steps {
+		fmt.Printf("  • %s%s\n", stepPrefix(step), step.Detail)
+	}
+
+	if len(plan.Warnings) > 0 {
+		fmt.Println("\n⚠️  Warnings:")
+		for _, warning := range plan.Warnings {
+			fmt.Printf("  • %s\n", warning)
+		}
+	}
+
+	fmt.Println("\nDry run: no changes were made")
+	return nil
+}
+
+// stepPrefix returns "feature/repo: ", "repo: " or "" for a plan step.
+func stepPrefix(step operations.PlanStep) string {
+	switch {
+	case step.Feature != "" && step.Repo != "":
+		return fmt.Sprintf("%s/%s: ", step.Feature, step.Repo)
+	case step.Feature != "":
+		return step.Feature + ": "
+	case step.Repo != "":
+		return step.Repo + ": "
+	}
+	return ""
+}
@@ -4,7 +4,6 @@ import (
 	"bufio"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"sort"
 	"strings"
@@ -14,7 +13,7 @@ import (
 
 	"ramp/internal/config"
 	"ramp/internal/git"
-	"ramp/internal/ports"
+	"ramp/internal/operations"
 	"ramp/internal/ui"
 )
 
@@ -25,12 +24,25 @@ var pruneCmd = &cobra.Command{
 
 This command:
 1. Scans all features in the trees/ directory
-2. Identifies features that have been merged (based on git merge-base)
-3. Shows a summary of merged features
-4. Asks for confirmation once
+2. Identifies features that have been merged, checking in order:
+   - merge commits and fast-forwards (git merge-base)
+   - "rebase and merge": every commit has an equivalent patch in the default branch
+   - "squash and merge": merging the branch would not change the default branch
+3. Shows a summary of merged features, noting any not merged with a merge commit
+4. Asks for confirmation once, offering to archive the features instead
 5. Removes all confirmed merged features (worktrees, branches, and allocated resources)
 
-Features categorized as "CLEAN" (never had any commits) are not removed by this command.`,
+Features categorized as "CLEAN" (never had any commits) are not removed by this command.
+
+A pushed branch whose remote copy was deleted (noticed after git fetch --prune)
+may have been merged, or its pull request closed. Such features are listed
+separately and only removed if you confirm them too; --dry-run leaves them out.
+
+Answering "a" at the prompt, or passing --archive, archives each feature
+before removing it, so 'ramp restore <feature>' can bring it back.
+
+Use --dry-run to list the merged features and everything removing them would do
+without asking or changing anything. Add --json for a machine-readable plan.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		if err := runPrune(); err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
@@ -39,8 +51,14 @@ Features categorized as "CLEAN" (never had any commits) are not removed by this
 	},
 }
 
+var pruneDryRun bool
+var pruneJSON bool
+var pruneArchive bool
+
 func init() {
 	rootCmd.AddCommand(pruneCmd)
+	addDryRunFlags(pruneCmd, &pruneDryRun, &pruneJSON)
+	pruneCmd.Flags().BoolVar(&pruneArchive, "archive", false, "Archive merged features instead of deleting them")
 }
 
 type featureToClean struct {
@@ -65,38 +83,92 @@ func runPrune() error {
 		return err
 	}
 
+	if err := checkDryRunFlags(pruneDryRun, pruneJSON); err != nil {
+		return err
+	}
+
 	// Auto-install if needed
-	if err := AutoInstallIfNeeded(projectDir, cfg); err != nil {
-		return fmt.Errorf("auto-installation failed: %w", err)
+	if !pruneDryRun {
+		if err := AutoInstallIfNeeded(projectDir, cfg); err != nil {
+			return fmt.Errorf("auto-installation failed: %w", err)
+		}
 	}
 
 	progress := ui.NewProgress()
-	progress.Start("Analyzing features...")
+	if !pruneJSON {
+		progress.Start("Analyzing features...")
+	}
 
 	// Find all merged features
-	mergedFeatures, err := findMergedFeatures(projectDir, cfg)
+	mergedFeatures, remoteDeleted, err := findMergedFeatures(projectDir, cfg)
 	if err != nil {
 		progress.Error("Failed to analyze features")
 		return err
 	}
 
+	opts := operations.PruneOptions{
+		ProjectDir: projectDir,
+		Config:     cfg,
+		Progress:   operations.DiscardProgress{},
+		Archive:    pruneArchive,
+	}
+
+	if pruneDryRun {
+		opts.Features = featureNames(mergedFeatures)
+		plan := operations.PlanPrune(opts)
+		if !pruneJSON {
+			progress.Success("Analyzing features...")
+			if len(mergedFeatures) > 0 {
+				fmt.Println()
+				displayMergedFeaturesSummary(mergedFeatures)
+			}
+			if len(remoteDeleted) > 0 {
+				fmt.Println()
+				displayRemoteDeletedSummary(remoteDeleted)
+				fmt.Println("\nThese are left out of the plan; 'ramp prune' asks before removing them.")
+			}
+		}
+		return printPlan(opts.Progress, plan, pruneJSON)
+	}
+
 	progress.Success("Analyzing features...")
 	fmt.Println()
 
 	// If no merged features, exit early
-	if len(mergedFeatures) == 0 {
+	if len(mergedFeatures) == 0 && len(remoteDeleted) == 0 {
 		fmt.Println("✓ No merged features found to clean up")
 		return nil
 	}
 
 	// Display summary
-	displayMergedFeaturesSummary(mergedFeatures)
+	if len(mergedFeatures) > 0 {
+		displayMergedFeaturesSummary(mergedFeatures)
+	}
+
+	// Features whose remote branch was deleted need their own confirmation
+	if len(remoteDeleted) > 0 {
+		if len(mergedFeatures) > 0 {
+			fmt.Println()
+		}
+		displayRemoteDeletedSummary(remoteDeleted)
+		if confirmRemoteDeleted(len(remoteDeleted)) {
+			mergedFeatures = append(mergedFeatures, remoteDeleted...)
+		}
+		if len(mergedFeatures) == 0 {
+			fmt.Println("\nPrune cancelled.")
+			return nil
+		}
+	}
 
 	// Ask for confirmation
-	if !confirmPrune(len(mergedFeatures)) {
+	proceed, archive := confirmPrune(len(mergedFeatures), pruneArchive)
+	if !proceed {
 		fmt.Println("\nPrune cancelled.")
 		return nil
 	}
+	opts.Archive = archive
+	opts.Features = featureNames(mergedFeatures)
+	plan := operations.PlanPrune(opts)
 
 	fmt.Println()
 
@@ -104,39 +176,40 @@ func runPrune() error {
 	cleanupProgress := ui.NewProgress()
 	cleanupProgress.Start("Cleaning up merged features")
 
-	// Clean up each merged feature
-	successCount := 0
-	failedFeatures := []string{}
-
-	for _, feature := range mergedFeatures {
-		if err := cleanupFeatureWithProgress(projectDir, cfg, feature.name, cleanupProgress); err != nil {
-			failedFeatures = append(failedFeatures, fmt.Sprintf("%s: %v", feature.name, err))
-		} else {
-			successCount++
-		}
-	}
+	opts.Progress = &pruneProgress{progress: cleanupProgress}
+	result := operations.ApplyPrune(opts, plan)
 
 	cleanupProgress.Success("Cleanup completed")
 
+	failedFeatures := []string{}
+	for _, failure := range result.Failed {
+		failedFeatures = append(failedFeatures, fmt.Sprintf("%s: %s", failure.Feature, failure.Error))
+	}
+
 	// Display final summary
 	fmt.Println()
-	displayCleanupSummary(len(mergedFeatures), successCount, failedFeatures)
+	displayCleanupSummary(len(mergedFeatures), len(result.Pruned), failedFeatures)
+	if opts.Archive && len(result.Pruned) > 0 {
+		fmt.Println("\n💡 Bring one back with 'ramp restore <feature>'; 'ramp archive' lists them")
+	}
 
 	return nil
 }
 
-func findMergedFeatures(projectDir string, cfg *config.Config) ([]featureToClean, error) {
+// findMergedFeatures returns the merged features, and separately the features
+// that only look merged because their remote branches were deleted.
+func findMergedFeatures(projectDir string, cfg *config.Config) ([]featureToClean, []featureToClean, error) {
 	treesDir := filepath.Join(projectDir, "trees")
 
 	// Check if trees directory exists
 	if _, err := os.Stat(treesDir); os.IsNotExist(err) {
-		return []featureToClean{}, nil
+		return []featureToClean{}, nil, nil
 	}
 
 	// Read all feature directories
 	entries, err := os.ReadDir(treesDir)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read trees directory: %w", err)
+		return nil, nil, fmt.Errorf("failed to read trees directory: %w", err)
 	}
 
 	// Collect feature info with creation times
@@ -161,250 +234,118 @@ func findMergedFeatures(projectDir string, cfg *config.Config) ([]featureToClean
 	})
 
 	// Categorize features and collect merged ones
-	repos := cfg.GetRepos()
-	var mergedFeatures []featureToClean
+	var mergedFeatures, remoteDeleted []featureToClean
+	allStatuses := collectFeatureWorktreeStatuses(projectDir, cfg, features)
 
 	for _, feature := range features {
-		featureDir := filepath.Join(treesDir, feature.name)
-		featureEntries, err := os.ReadDir(featureDir)
-		if err != nil {
-			continue
-		}
-
-		var worktreeStatuses []featureWorktreeStatus
-		for _, entry := range featureEntries {
-			if entry.IsDir() {
-				repoName := entry.Name()
-				if repo, exists := repos[repoName]; exists {
-					status := getFeatureWorktreeStatus(projectDir, feature.name, repoName, repo)
-					worktreeStatuses = append(worktreeStatuses, status)
-				}
-			}
-		}
-
+		worktreeStatuses := allStatuses[feature.name]
 		if len(worktreeStatuses) == 0 {
 			continue
 		}
 
 		// Only include features that are merged (not clean, not in-flight)
+		toClean := featureToClean{
+			name:     feature.name,
+			modTime:  feature.modTime,
+			statuses: worktreeStatuses,
+		}
 		if isMerged(worktreeStatuses) {
-			mergedFeatures = append(mergedFeatures, featureToClean{
-				name:     feature.name,
-				modTime:  feature.modTime,
-				statuses: worktreeStatuses,
-			})
+			mergedFeatures = append(mergedFeatures, toClean)
+		} else if isRemoteDeleted(worktreeStatuses) {
+			remoteDeleted = append(remoteDeleted, toClean)
 		}
 	}
 
-	return mergedFeatures, nil
+	return mergedFeatures, remoteDeleted, nil
+}
+
+func featureNames(features []featureToClean) []string {
+	names := make([]string, len(features))
+	for i, feature := range features {
+		names[i] = feature.name
+	}
+	return names
 }
 
 func displayMergedFeaturesSummary(features []featureToClean) {
 	fmt.Printf("🧹 Found %d merged feature%s to clean up:\n\n", len(features), pluralize(len(features)))
 
 	for _, feature := range features {
-		fmt.Printf("  • %s\n", feature.name)
+		fmt.Printf("  • %s%s\n", feature.name, describeMergeMethods(feature.statuses))
+	}
+}
+
+func displayRemoteDeletedSummary(features []featureToClean) {
+	fmt.Printf("⚠️  Found %d feature%s whose remote branches were deleted but whose commits aren't in the base branch; closing a pull request deletes its branch too:\n\n", len(features), pluralize(len(features)))
+
+	for _, feature := range features {
+		ahead := 0
+		for _, status := range feature.statuses {
+			if status.mergeMethod == git.MergeMethodRemoteDeleted {
+				ahead += status.aheadCount
+			}
+		}
+		fmt.Printf("  • %s (%d unmerged commit%s)\n", feature.name, ahead, pluralize(ahead))
 	}
 }
 
-func confirmPrune(count int) bool {
-	fmt.Printf("\nRemove all %d merged feature%s? This will delete worktrees, branches, and release ports. (y/N): ", count, pluralize(count))
+// confirmRemoteDeleted asks whether to also remove features whose remote
+// branch was deleted. Anything but an explicit yes leaves them alone.
+func confirmRemoteDeleted(count int) bool {
+	fmt.Printf("\nAlso remove the %d feature%s above? Their unmerged commits are lost unless archived. (y/N): ", count, pluralize(count))
 
 	reader := bufio.NewReader(os.Stdin)
 	input, _ := reader.ReadString('\n')
 	input = strings.TrimSpace(strings.ToLower(input))
-
 	return input == "y" || input == "yes"
 }
 
-func cleanupFeature(projectDir string, cfg *config.Config, featureName string) error {
-	progress := ui.NewProgress()
-	progress.Start(fmt.Sprintf("Cleaning up %s", featureName))
-	err := cleanupFeatureWithProgress(projectDir, cfg, featureName, progress)
-	if err != nil {
-		progress.Error(fmt.Sprintf("Cleaning up %s", featureName))
+// confirmPrune asks whether to remove the merged features and whether to
+// archive them first. With archive already chosen, it only asks to proceed.
+func confirmPrune(count int, archive bool) (proceed bool, archived bool) {
+	if archive {
+		fmt.Printf("\nArchive all %d merged feature%s? This will save their branches and uncommitted changes, then remove worktrees, branches, and release ports. (y/N): ", count, pluralize(count))
 	} else {
-		progress.Success(fmt.Sprintf("Cleaned up %s", featureName))
-	}
-	return err
-}
-
-func cleanupFeatureWithProgress(projectDir string, cfg *config.Config, featureName string, progress *ui.ProgressUI) error {
-	// Use Update() instead of Start() since the spinner is already running from the caller
-	progress.Update(fmt.Sprintf("Cleaning up %s", featureName))
-
-	// Get config prefix for fallback when branch detection fails
-	configPrefix := cfg.GetBranchPrefix()
-
-	treesDir := filepath.Join(projectDir, "trees", featureName)
-
-	// Check if trees directory exists
-	treesDirExists := true
-	if _, err := os.Stat(treesDir); os.IsNotExist(err) {
-		treesDirExists = false
-
-		// Check if any worktrees or branches exist for this feature
-		// This distinguishes between orphaned worktrees and non-existent features
-		repos := cfg.GetRepos()
-		featureExists := false
-		for name, repo := range repos {
-			repoDir := repo.GetRepoPath(projectDir)
-			worktreeDir := filepath.Join(treesDir, name)
-
-			if git.IsGitRepo(repoDir) {
-				// Check if worktree is registered or branch exists
-				if git.WorktreeRegistered(repoDir, worktreeDir) {
-					featureExists = true
-					break
-				}
-
-				// Check if branch exists
-				branchName := configPrefix + featureName
-				if exists, _ := git.LocalBranchExists(repoDir, branchName); exists {
-					featureExists = true
-					break
-				}
-			}
-		}
-
-		if !featureExists {
-			// Don't call Error() - let caller handle it
-			return fmt.Errorf("trees directory does not exist")
-		}
+		fmt.Printf("\nRemove all %d merged feature%s? This will delete worktrees, branches, and release ports. (y = delete, a = archive instead, N = cancel): ", count, pluralize(count))
 	}
 
-	// Note: We don't check for uncommitted changes here because merged features
-	// shouldn't have meaningful uncommitted changes, and we already confirmed the prune
-
-	// Run cleanup script if configured and directory exists
-	if cfg.Cleanup != "" && treesDirExists {
-		if err := runCleanupScriptQuiet(projectDir, treesDir, cfg.Cleanup); err != nil {
-			progress.Warning(fmt.Sprintf("%s: cleanup script failed", featureName))
-			// Continue anyway
-		}
-	}
-
-	// Remove git worktrees and branches
-	repos := cfg.GetRepos()
-	for name, repo := range repos {
-		repoDir := repo.GetRepoPath(projectDir)
-		worktreeDir := filepath.Join(treesDir, name)
-
-		if git.IsGitRepo(repoDir) {
-			var branchName string
-
-			// Try to detect the actual branch name from the worktree
-			if _, err := os.Stat(worktreeDir); err == nil {
-				if detectedBranch, err := git.GetWorktreeBranch(worktreeDir); err == nil {
-					branchName = detectedBranch
-				} else {
-					// Fallback to constructed branch name
-					branchName = configPrefix + featureName
-				}
-			} else {
-				// No worktree directory exists, use fallback branch name
-				branchName = configPrefix + featureName
-			}
-
-			// Always try to remove worktree (even if directory is missing)
-			// git worktree remove --force works for orphaned worktrees
-			// Use quiet version to avoid creating nested spinners
-			if err := git.RemoveWorktreeQuiet(repoDir, worktreeDir); err != nil {
-				progress.Warning(fmt.Sprintf("%s/%s: failed to remove worktree", featureName, name))
-				// If worktree removal failed, prune orphaned worktrees before deleting branch
-				// This handles cases where the worktree directory was manually deleted
-				_ = git.PruneWorktrees(repoDir)
-			}
-
-			// Delete branch (use quiet version to avoid creating nested spinners)
-			if err := git.DeleteBranchQuiet(repoDir, branchName); err != nil {
-				progress.Warning(fmt.Sprintf("%s/%s: failed to delete branch", featureName, name))
-				// Continue anyway
-			}
-
-			// Prune stale remote tracking branches (use quiet version to avoid creating another spinner)
-			if err := git.FetchPruneQuiet(repoDir); err != nil {
-				// Ignore prune errors - not critical
-			}
-		}
-	}
-
-	// Release allocated port
-	portAllocations, err := ports.NewPortAllocations(projectDir, cfg.GetBasePort(), cfg.GetMaxPorts())
-	if err == nil {
-		_ = portAllocations.ReleasePort(featureName)
-	}
+	reader := bufio.NewReader(os.Stdin)
+	input, _ := reader.ReadString('\n')
+	input = strings.TrimSpace(strings.ToLower(input))
 
-	// Remove trees directory if it exists
-	if treesDirExists {
-		if err := os.RemoveAll(treesDir); err != nil {
-			// Don't call Error() here - let the caller handle it
-			// This allows batch operations to continue without stopping the spinner
-			return fmt.Errorf("failed to remove trees directory: %w", err)
-		}
+	switch input {
+	case "y", "yes":
+		return true, archive
+	case "a", "archive":
+		return true, true
 	}
-
-	// Don't call Success() here - let the caller handle it
-	// This allows batch operations to keep the spinner running for multiple features
-	return nil
+	return false, archive
 }
 
-func runCleanupScriptQuiet(projectDir, treesDir, cleanupScript string) error {
-	scriptPath := filepath.Join(projectDir, ".ramp", cleanupScript)
-
-	if _, err := os.Stat(scriptPath); os.IsNotExist(err) {
-		return fmt.Errorf("cleanup script not found: %s", scriptPath)
-	}
-
-	// Extract feature name from treesDir path
-	featureName := filepath.Base(treesDir)
-
-	// Reuse the cleanup script logic from down.go
-	// We'll run it quietly without progress UI since we're in a batch operation
-	return runCleanupScriptWithoutProgress(projectDir, treesDir, featureName, cleanupScript)
+// pruneProgress shows the removal of many features on one spinner: updates
+// and warnings get through, while each feature's start and completion
+// messages are left to the batch summary.
+type pruneProgress struct {
+	progress *ui.ProgressUI
 }
 
-func runCleanupScriptWithoutProgress(projectDir, treesDir, featureName, cleanupScript string) error {
-	scriptPath := filepath.Join(projectDir, ".ramp", cleanupScript)
-
-	cmd := createCleanupCommand(projectDir, treesDir, featureName, scriptPath)
-
-	// Run quietly - capture output but don't display
-	output := &strings.Builder{}
-	cmd.Stdout = output
-	cmd.Stderr = output
+func (p *pruneProgress) Start(string)    {}
+func (p *pruneProgress) Stop()           {}
+func (p *pruneProgress) Success(string)  {}
+func (p *pruneProgress) Error(string)    {}
+func (p *pruneProgress) Info(string)     {}
+func (p *pruneProgress) Complete(string) {}
 
-	return cmd.Run()
+func (p *pruneProgress) Update(message string) {
+	p.progress.Update(message)
 }
 
-func createCleanupCommand(projectDir, treesDir, featureName, scriptPath string) *exec.Cmd {
-	cmd := exec.Command("/bin/bash", scriptPath)
-	cmd.Dir = treesDir
-
-	// Set up environment variables
-	cmd.Env = append(os.Environ(), fmt.Sprintf("RAMP_PROJECT_DIR=%s", projectDir))
-	cmd.Env = append(cmd.Env, fmt.Sprintf("RAMP_TREES_DIR=%s", treesDir))
-	cmd.Env = append(cmd.Env, fmt.Sprintf("RAMP_WORKTREE_NAME=%s", featureName))
-
-	// Add RAMP_PORT environment variables
-	cfg, err := config.LoadConfig(projectDir)
-	if err == nil {
-		portAllocations, err := ports.NewPortAllocations(projectDir, cfg.GetBasePort(), cfg.GetMaxPorts())
-		if err == nil {
-			if ports, exists := portAllocations.GetPorts(featureName); exists {
-				setPortEnvVars(cmd, ports)
-			}
-		}
-
-		repos := cfg.GetRepos()
-		for name, repo := range repos {
-			envVarName := config.GenerateEnvVarName(name)
-			repoPath := repo.GetRepoPath(projectDir)
-			cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", envVarName, repoPath))
-		}
-	}
+func (p *pruneProgress) UpdateWithProgress(message string, _ int) {
+	p.progress.Update(message)
+}
 
-	return cmd
+func (p *pruneProgress) Warning(message string) {
+	p.progress.Warning(message)
 }
 
 func displayCleanupSummary(total, success int, failed []string) {
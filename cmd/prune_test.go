@@ -4,10 +4,10 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"strings"
 	"testing"
 
 	"ramp/internal/config"
+	"ramp/internal/git"
 )
 
 // TestPruneNoFeatures tests prune with no features
@@ -27,7 +27,7 @@ func TestPruneNoFeatures(t *testing.T) {
 		t.Fatalf("LoadConfig() error = %v", err)
 	}
 
-	merged, err := findMergedFeatures(tp.Dir, cfg)
+	merged, _, err := findMergedFeatures(tp.Dir, cfg)
 	if err != nil {
 		t.Fatalf("findMergedFeatures() error = %v", err)
 	}
@@ -66,7 +66,7 @@ func TestPruneWithUnmergedFeatures(t *testing.T) {
 		t.Fatalf("LoadConfig() error = %v", err)
 	}
 
-	merged, err := findMergedFeatures(tp.Dir, cfg)
+	merged, _, err := findMergedFeatures(tp.Dir, cfg)
 	if err != nil {
 		t.Fatalf("findMergedFeatures() error = %v", err)
 	}
@@ -110,7 +110,7 @@ func TestPruneWithMergedFeature(t *testing.T) {
 		t.Fatalf("LoadConfig() error = %v", err)
 	}
 
-	merged, err := findMergedFeatures(tp.Dir, cfg)
+	merged, _, err := findMergedFeatures(tp.Dir, cfg)
 	if err != nil {
 		t.Fatalf("findMergedFeatures() error = %v", err)
 	}
@@ -125,6 +125,123 @@ func TestPruneWithMergedFeature(t *testing.T) {
 	}
 }
 
+// TestPruneWithSquashMergedFeature tests that squash-merged features are found even though the branch stays ahead
+func TestPruneWithSquashMergedFeature(t *testing.T) {
+	tp := NewTestProject(t)
+	repo1 := tp.InitRepo("repo1")
+
+	cleanup := tp.ChangeToProjectDir()
+	defer cleanup()
+
+	if err := runUp("squashed", "", "", ""); err != nil {
+		t.Fatalf("runUp() error = %v", err)
+	}
+
+	worktreeDir := filepath.Join(tp.TreesDir, "squashed", "repo1")
+	for _, name := range []string{"one.txt", "two.txt"} {
+		os.WriteFile(filepath.Join(worktreeDir, name), []byte(name), 0644)
+		runGitCmd(t, worktreeDir, "add", ".")
+		runGitCmd(t, worktreeDir, "commit", "-m", "add "+name)
+	}
+
+	// Squash and merge, as a hosting service would
+	runGitCmd(t, repo1.SourceDir, "checkout", "main")
+	runGitCmd(t, repo1.SourceDir, "merge", "--squash", "feature/squashed")
+	runGitCmd(t, repo1.SourceDir, "commit", "-m", "squashed (#1)")
+
+	cfg, err := config.LoadConfig(tp.Dir)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+
+	merged, _, err := findMergedFeatures(tp.Dir, cfg)
+	if err != nil {
+		t.Fatalf("findMergedFeatures() error = %v", err)
+	}
+
+	if len(merged) != 1 || merged[0].name != "squashed" {
+		t.Fatalf("findMergedFeatures() = %v, want the squash-merged feature", merged)
+	}
+	if got := describeMergeMethods(merged[0].statuses); got != " (squashed)" {
+		t.Errorf("describeMergeMethods() = %q, want %q", got, " (squashed)")
+	}
+}
+
+// TestPruneLeavesOutRemoteDeletedFeatures tests that a feature whose remote
+// branch was deleted without its commits reaching main isn't counted as merged
+func TestPruneLeavesOutRemoteDeletedFeatures(t *testing.T) {
+	tp := NewTestProject(t)
+	tp.InitRepo("repo1")
+
+	cleanup := tp.ChangeToProjectDir()
+	defer cleanup()
+
+	if err := runUp("closed", "", "", ""); err != nil {
+		t.Fatalf("runUp() error = %v", err)
+	}
+
+	// Push a commit, then delete the remote branch as closing a pull request would
+	worktreeDir := filepath.Join(tp.TreesDir, "closed", "repo1")
+	os.WriteFile(filepath.Join(worktreeDir, "work.txt"), []byte("work"), 0644)
+	runGitCmd(t, worktreeDir, "add", ".")
+	runGitCmd(t, worktreeDir, "commit", "-m", "unmerged work")
+	runGitCmd(t, worktreeDir, "push", "-u", "origin", "feature/closed")
+	runGitCmd(t, worktreeDir, "push", "origin", "--delete", "feature/closed")
+
+	cfg, err := config.LoadConfig(tp.Dir)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+
+	merged, remoteDeleted, err := findMergedFeatures(tp.Dir, cfg)
+	if err != nil {
+		t.Fatalf("findMergedFeatures() error = %v", err)
+	}
+	if len(merged) != 0 {
+		t.Errorf("findMergedFeatures() merged = %v, want none", merged)
+	}
+	if len(remoteDeleted) != 1 || remoteDeleted[0].name != "closed" {
+		t.Fatalf("findMergedFeatures() remote deleted = %v, want the closed feature", remoteDeleted)
+	}
+	if status := remoteDeleted[0].statuses[0]; status.isMerged || status.mergeMethod != git.MergeMethodRemoteDeleted {
+		t.Errorf("status = %+v, want remote deleted and not merged", status)
+	}
+}
+
+// TestPruneDryRun tests that --dry-run lists merged features without asking or removing them
+func TestPruneDryRun(t *testing.T) {
+	tp := NewTestProject(t)
+	repo1 := tp.InitRepo("repo1")
+
+	cleanup := tp.ChangeToProjectDir()
+	defer cleanup()
+
+	if err := runUp("merged-dry", "", "", ""); err != nil {
+		t.Fatalf("runUp() error = %v", err)
+	}
+
+	worktreeDir := filepath.Join(tp.TreesDir, "merged-dry", "repo1")
+	os.WriteFile(filepath.Join(worktreeDir, "feature.txt"), []byte("feature"), 0644)
+	runGitCmd(t, worktreeDir, "add", ".")
+	runGitCmd(t, worktreeDir, "commit", "-m", "add feature")
+	runGitCmd(t, repo1.SourceDir, "merge", "feature/merged-dry", "--no-ff", "-m", "merge feature")
+
+	pruneDryRun = true
+	defer func() { pruneDryRun = false }()
+
+	// Would block on the confirmation prompt if it weren't a dry run
+	if err := runPrune(); err != nil {
+		t.Fatalf("runPrune() error = %v", err)
+	}
+
+	if !tp.FeatureExists("merged-dry") {
+		t.Error("merged feature should not be removed by a dry run")
+	}
+	if !repo1.BranchExists(t, "feature/merged-dry") {
+		t.Error("branch should not be deleted by a dry run")
+	}
+}
+
 // TestPruneMultipleFeaturesPartiallyMerged tests mixed scenarios
 func TestPruneMultipleFeaturesPartiallyMerged(t *testing.T) {
 	tp := NewTestProject(t)
@@ -167,7 +284,7 @@ func TestPruneMultipleFeaturesPartiallyMerged(t *testing.T) {
 		t.Fatalf("LoadConfig() error = %v", err)
 	}
 
-	merged, err := findMergedFeatures(tp.Dir, cfg)
+	merged, _, err := findMergedFeatures(tp.Dir, cfg)
 	if err != nil {
 		t.Fatalf("findMergedFeatures() error = %v", err)
 	}
@@ -202,7 +319,7 @@ func TestPruneCleanFeature(t *testing.T) {
 		t.Fatalf("LoadConfig() error = %v", err)
 	}
 
-	merged, err := findMergedFeatures(tp.Dir, cfg)
+	merged, _, err := findMergedFeatures(tp.Dir, cfg)
 	if err != nil {
 		t.Fatalf("findMergedFeatures() error = %v", err)
 	}
@@ -252,7 +369,7 @@ func TestPruneWithNestedBranchViaPrefix(t *testing.T) {
 		t.Fatalf("LoadConfig() error = %v", err)
 	}
 
-	merged, err := findMergedFeatures(tp.Dir, cfg)
+	merged, _, err := findMergedFeatures(tp.Dir, cfg)
 	if err != nil {
 		t.Fatalf("findMergedFeatures() error = %v", err)
 	}
@@ -286,7 +403,7 @@ func TestFindMergedFeaturesWithEmptyTreesDir(t *testing.T) {
 		t.Fatalf("LoadConfig() error = %v", err)
 	}
 
-	merged, err := findMergedFeatures(tp.Dir, cfg)
+	merged, _, err := findMergedFeatures(tp.Dir, cfg)
 	if err != nil {
 		t.Fatalf("findMergedFeatures() error = %v", err)
 	}
@@ -337,7 +454,7 @@ func TestPruneWithMultipleRepos(t *testing.T) {
 		t.Fatalf("LoadConfig() error = %v", err)
 	}
 
-	merged, err := findMergedFeatures(tp.Dir, cfg)
+	merged, _, err := findMergedFeatures(tp.Dir, cfg)
 	if err != nil {
 		t.Fatalf("findMergedFeatures() error = %v", err)
 	}
@@ -369,320 +486,3 @@ func TestPluralize(t *testing.T) {
 		})
 	}
 }
-
-// TestCleanupFeature tests the cleanupFeature function
-func TestCleanupFeature(t *testing.T) {
-	tp := NewTestProject(t)
-	repo1 := tp.InitRepo("repo1")
-
-	cleanup := tp.ChangeToProjectDir()
-	defer cleanup()
-
-	// Create and merge a feature
-	err := runUp("to-cleanup", "", "", "")
-	if err != nil {
-		t.Fatalf("runUp() error = %v", err)
-	}
-
-	// Add work to the feature
-	worktreeDir := filepath.Join(tp.TreesDir, "to-cleanup", "repo1")
-	testFile := filepath.Join(worktreeDir, "work.txt")
-	os.WriteFile(testFile, []byte("work"), 0644)
-	runGitCmd(t, worktreeDir, "add", ".")
-	runGitCmd(t, worktreeDir, "commit", "-m", "work")
-
-	// Merge it
-	runGitCmd(t, repo1.SourceDir, "checkout", "main")
-	runGitCmd(t, repo1.SourceDir, "merge", "feature/to-cleanup", "--no-ff", "-m", "merge")
-
-	// Load config
-	cfg, err := config.LoadConfig(tp.Dir)
-	if err != nil {
-		t.Fatalf("LoadConfig() error = %v", err)
-	}
-
-	// Cleanup the feature
-	err = cleanupFeature(tp.Dir, cfg, "to-cleanup")
-	if err != nil {
-		t.Fatalf("cleanupFeature() error = %v", err)
-	}
-
-	// Verify feature is removed
-	if tp.FeatureExists("to-cleanup") {
-		t.Error("feature directory should be removed")
-	}
-
-	// Verify branch is deleted
-	if repo1.BranchExists(t, "feature/to-cleanup") {
-		t.Error("branch should be deleted")
-	}
-}
-
-// TestCleanupFeatureWithCleanupScript tests cleanupFeature with a cleanup script
-func TestCleanupFeatureWithCleanupScript(t *testing.T) {
-	tp := NewTestProject(t)
-	tp.InitRepo("repo1")
-
-	cleanup := tp.ChangeToProjectDir()
-	defer cleanup()
-
-	// Create a cleanup script
-	scriptPath := filepath.Join(tp.Dir, ".ramp", "scripts", "cleanup.sh")
-	os.MkdirAll(filepath.Dir(scriptPath), 0755)
-	scriptContent := `#!/bin/bash
-echo "cleanup executed for $RAMP_WORKTREE_NAME" > "$RAMP_PROJECT_DIR/.ramp/prune-cleanup-marker.txt"
-`
-	os.WriteFile(scriptPath, []byte(scriptContent), 0755)
-
-	// Update config
-	cfg, _ := config.LoadConfig(tp.Dir)
-	cfg.Cleanup = "scripts/cleanup.sh"
-	config.SaveConfig(cfg, tp.Dir)
-
-	// Create feature
-	runUp("scripted-cleanup", "", "", "")
-
-	// Cleanup the feature
-	cfg, _ = config.LoadConfig(tp.Dir)
-	err := cleanupFeature(tp.Dir, cfg, "scripted-cleanup")
-	if err != nil {
-		t.Fatalf("cleanupFeature() error = %v", err)
-	}
-
-	// Verify cleanup script was executed
-	markerFile := filepath.Join(tp.Dir, ".ramp", "prune-cleanup-marker.txt")
-	if _, err := os.Stat(markerFile); os.IsNotExist(err) {
-		t.Error("cleanup script was not executed")
-	}
-
-	// Verify feature is removed
-	if tp.FeatureExists("scripted-cleanup") {
-		t.Error("feature directory should be removed")
-	}
-}
-
-// TestCleanupFeatureNonExistent tests cleanup of non-existent feature
-func TestCleanupFeatureNonExistent(t *testing.T) {
-	tp := NewTestProject(t)
-	tp.InitRepo("repo1")
-
-	cleanup := tp.ChangeToProjectDir()
-	defer cleanup()
-
-	cfg, _ := config.LoadConfig(tp.Dir)
-
-	// Try to cleanup non-existent feature
-	err := cleanupFeature(tp.Dir, cfg, "does-not-exist")
-	if err == nil {
-		t.Error("cleanupFeature() should error for non-existent feature")
-	}
-
-	if !strings.Contains(err.Error(), "does not exist") {
-		t.Errorf("error should mention 'does not exist', got: %v", err)
-	}
-}
-
-// TestRunCleanupScriptQuiet tests the quiet cleanup script execution
-func TestRunCleanupScriptQuiet(t *testing.T) {
-	tp := NewTestProject(t)
-	tp.InitRepo("repo1")
-
-	cleanup := tp.ChangeToProjectDir()
-	defer cleanup()
-
-	// Create a cleanup script
-	scriptPath := filepath.Join(tp.Dir, ".ramp", "scripts", "cleanup.sh")
-	os.MkdirAll(filepath.Dir(scriptPath), 0755)
-	scriptContent := `#!/bin/bash
-echo "quiet cleanup" > "$RAMP_PROJECT_DIR/.ramp/quiet-marker.txt"
-exit 0
-`
-	os.WriteFile(scriptPath, []byte(scriptContent), 0755)
-
-	// Create feature
-	runUp("quiet-test", "", "", "")
-	treesDir := filepath.Join(tp.Dir, "trees", "quiet-test")
-
-	// Run cleanup script quietly
-	err := runCleanupScriptQuiet(tp.Dir, treesDir, "scripts/cleanup.sh")
-	if err != nil {
-		t.Fatalf("runCleanupScriptQuiet() error = %v", err)
-	}
-
-	// Verify script executed
-	markerFile := filepath.Join(tp.Dir, ".ramp", "quiet-marker.txt")
-	if _, err := os.Stat(markerFile); os.IsNotExist(err) {
-		t.Error("cleanup script was not executed")
-	}
-}
-
-// TestRunCleanupScriptQuietFailure tests cleanup script failure handling
-func TestRunCleanupScriptQuietFailure(t *testing.T) {
-	tp := NewTestProject(t)
-	tp.InitRepo("repo1")
-
-	cleanup := tp.ChangeToProjectDir()
-	defer cleanup()
-
-	// Create a failing cleanup script
-	scriptPath := filepath.Join(tp.Dir, ".ramp", "scripts", "cleanup.sh")
-	os.MkdirAll(filepath.Dir(scriptPath), 0755)
-	scriptContent := `#!/bin/bash
-exit 1
-`
-	os.WriteFile(scriptPath, []byte(scriptContent), 0755)
-
-	// Create feature
-	runUp("fail-test", "", "", "")
-	treesDir := filepath.Join(tp.Dir, "trees", "fail-test")
-
-	// Run cleanup script - should return error
-	err := runCleanupScriptQuiet(tp.Dir, treesDir, "scripts/cleanup.sh")
-	if err == nil {
-		t.Error("runCleanupScriptQuiet() should return error when script fails")
-	}
-}
-
-// TestRunCleanupScriptQuietMissingScript tests behavior with missing script
-func TestRunCleanupScriptQuietMissingScript(t *testing.T) {
-	tp := NewTestProject(t)
-	tp.InitRepo("repo1")
-
-	cleanup := tp.ChangeToProjectDir()
-	defer cleanup()
-
-	// Create feature
-	runUp("missing-script", "", "", "")
-	treesDir := filepath.Join(tp.Dir, "trees", "missing-script")
-
-	// Run non-existent cleanup script
-	err := runCleanupScriptQuiet(tp.Dir, treesDir, "scripts/nonexistent.sh")
-	if err == nil {
-		t.Error("runCleanupScriptQuiet() should error for missing script")
-	}
-
-	if !strings.Contains(err.Error(), "not found") {
-		t.Errorf("error should mention 'not found', got: %v", err)
-	}
-}
-
-// TestCreateCleanupCommand tests cleanup command creation
-func TestCreateCleanupCommand(t *testing.T) {
-	tp := NewTestProject(t)
-	tp.InitRepo("repo1")
-
-	cleanup := tp.ChangeToProjectDir()
-	defer cleanup()
-
-	// Create a simple script
-	scriptPath := filepath.Join(tp.Dir, ".ramp", "scripts", "test.sh")
-	os.MkdirAll(filepath.Dir(scriptPath), 0755)
-	os.WriteFile(scriptPath, []byte("#!/bin/bash\necho test\n"), 0755)
-
-	// Create feature to get proper environment
-	runUp("cmd-test", "", "", "")
-	treesDir := filepath.Join(tp.Dir, "trees", "cmd-test")
-
-	// Create cleanup command
-	cmd := createCleanupCommand(tp.Dir, treesDir, "cmd-test", scriptPath)
-
-	if cmd == nil {
-		t.Fatal("createCleanupCommand() returned nil")
-	}
-
-	// Verify command setup
-	if cmd.Path != "/bin/bash" {
-		t.Errorf("cmd.Path = %q, want %q", cmd.Path, "/bin/bash")
-	}
-
-	if cmd.Dir != treesDir {
-		t.Errorf("cmd.Dir = %q, want %q", cmd.Dir, treesDir)
-	}
-
-	// Verify environment variables are set
-	envMap := make(map[string]bool)
-	for _, env := range cmd.Env {
-		if strings.HasPrefix(env, "RAMP_") {
-			envMap[strings.Split(env, "=")[0]] = true
-		}
-	}
-
-	requiredVars := []string{"RAMP_PROJECT_DIR", "RAMP_TREES_DIR", "RAMP_WORKTREE_NAME", "RAMP_PORT"}
-	for _, varName := range requiredVars {
-		if !envMap[varName] {
-			t.Errorf("environment variable %s not set", varName)
-		}
-	}
-}
-
-// TestPruneOrphanedWorktree tests that prune handles manually deleted trees directory
-func TestPruneOrphanedWorktree(t *testing.T) {
-	tp := NewTestProject(t)
-	repo1 := tp.InitRepo("repo1")
-
-	cleanup := tp.ChangeToProjectDir()
-	defer cleanup()
-
-	// Create a feature
-	err := runUp("orphaned-merge", "", "", "")
-	if err != nil {
-		t.Fatalf("runUp() error = %v", err)
-	}
-
-	// Add and commit work in the feature
-	worktreeDir := filepath.Join(tp.TreesDir, "orphaned-merge", "repo1")
-	testFile := filepath.Join(worktreeDir, "feature.txt")
-	if err := os.WriteFile(testFile, []byte("feature"), 0644); err != nil {
-		t.Fatalf("failed to create file: %v", err)
-	}
-	runGitCmd(t, worktreeDir, "add", ".")
-	runGitCmd(t, worktreeDir, "commit", "-m", "feature work")
-
-	// Merge into main branch
-	runGitCmd(t, repo1.SourceDir, "merge", "feature/orphaned-merge", "--no-edit")
-
-	// Verify branch exists
-	if !repo1.BranchExists(t, "feature/orphaned-merge") {
-		t.Fatal("branch should exist before orphaning")
-	}
-
-	// Manually delete the trees directory (simulating user action)
-	treesDir := filepath.Join(tp.TreesDir, "orphaned-merge")
-	if err := os.RemoveAll(treesDir); err != nil {
-		t.Fatalf("failed to manually remove trees directory: %v", err)
-	}
-
-	// Verify directory is gone
-	if _, err := os.Stat(treesDir); !os.IsNotExist(err) {
-		t.Fatal("trees directory should be gone after manual removal")
-	}
-
-	// Find merged features - should still detect the orphaned merged feature
-	cfg, err := config.LoadConfig(tp.Dir)
-	if err != nil {
-		t.Fatalf("LoadConfig() error = %v", err)
-	}
-
-	merged, err := findMergedFeatures(tp.Dir, cfg)
-	if err != nil {
-		t.Fatalf("findMergedFeatures() error = %v", err)
-	}
-
-	// Should find the merged feature even though directory is gone
-	// Actually, it won't find it because there's no directory to scan
-	// This is acceptable behavior - if user deleted the directory, there's nothing to prune
-	if len(merged) != 0 {
-		t.Logf("Found %d merged features (orphaned worktree may not be detectable)", len(merged))
-	}
-
-	// Test cleanupFeature with orphaned worktree
-	err = cleanupFeature(tp.Dir, cfg, "orphaned-merge")
-	if err != nil {
-		t.Fatalf("cleanupFeature() should handle orphaned worktree gracefully, got error: %v", err)
-	}
-
-	// Verify git branch was cleaned up
-	if repo1.BranchExists(t, "feature/orphaned-merge") {
-		t.Error("branch should be deleted even with orphaned worktree")
-	}
-}
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"ramp/internal/config"
+	"ramp/internal/operations"
+)
+
+var forceWithLeaseFlag bool
+
+var pushCmd = &cobra.Command{
+	Use:   "push [feature-name]",
+	Short: "Push a feature's branches in every repository",
+	Long: `Push the feature branch of every worktree that has commits the remote
+doesn't have, and set upstream tracking so later pulls and pushes work.
+
+Repositories are pushed in parallel (up to max_parallel at a time). Repositories
+with nothing to push are skipped, and a summary is printed for each one. A
+branch that has diverged from its remote is not pushed unless
+--force-with-lease is given, e.g. after a rebase.
+
+If no feature name is provided, ramp will attempt to auto-detect the feature
+based on your current working directory.
+
+Examples:
+  ramp push my-feature
+  ramp push --force-with-lease`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		featureName := ""
+		if len(args) > 0 {
+			featureName = strings.TrimRight(args[0], "/")
+		}
+		if err := runPush(featureName, forceWithLeaseFlag); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	pushCmd.Flags().BoolVar(&forceWithLeaseFlag, "force-with-lease", false, "Push rewritten branches, unless the remote has commits you haven't fetched")
+	rootCmd.AddCommand(pushCmd)
+}
+
+func runPush(featureName string, forceWithLease bool) error {
+	wd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+
+	projectDir, err := config.FindRampProject(wd)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.LoadConfig(projectDir)
+	if err != nil {
+		return err
+	}
+
+	// Auto-detect feature name if not provided
+	if featureName == "" {
+		detected, err := config.DetectFeatureFromWorkingDir(projectDir)
+		if err != nil {
+			return fmt.Errorf("failed to detect feature from working directory: %w", err)
+		}
+		if detected == "" {
+			return fmt.Errorf("no feature name provided and could not auto-detect from current directory")
+		}
+		featureName = detected
+		fmt.Printf("Auto-detected feature: %s\n", featureName)
+	}
+
+	result, err := operations.Push(operations.PushOptions{
+		FeatureName:    featureName,
+		ProjectDir:     projectDir,
+		Config:         cfg,
+		Progress:       operations.NewCLIProgressReporter(),
+		ForceWithLease: forceWithLease,
+	})
+	if err != nil {
+		return err
+	}
+
+	if failed := result.Failed(); len(failed) > 0 {
+		return fmt.Errorf("failed to push %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
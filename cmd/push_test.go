@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestPushAutoDetectsFeature tests pushing the feature of the current worktree
+func TestPushAutoDetectsFeature(t *testing.T) {
+	tp := NewTestProject(t)
+	repo1 := tp.InitRepo("repo1")
+
+	cleanup := tp.ChangeToProjectDir()
+	defer cleanup()
+
+	if err := runUp("my-feature", "", "", ""); err != nil {
+		t.Fatalf("runUp() error = %v", err)
+	}
+
+	worktreeDir := filepath.Join(tp.TreesDir, "my-feature", "repo1")
+	runGitCmd(t, worktreeDir, "commit", "--allow-empty", "-m", "feature work")
+
+	if err := os.Chdir(worktreeDir); err != nil {
+		t.Fatalf("failed to change to worktree: %v", err)
+	}
+
+	if err := runPush("", false); err != nil {
+		t.Fatalf("runPush() error = %v", err)
+	}
+
+	runGitCmd(t, repo1.RemoteDir, "rev-parse", "--verify", "feature/my-feature")
+}
+
+// TestPushFeatureNotFound tests pushing a feature that doesn't exist
+func TestPushFeatureNotFound(t *testing.T) {
+	tp := NewTestProject(t)
+	tp.InitRepo("repo1")
+
+	cleanup := tp.ChangeToProjectDir()
+	defer cleanup()
+
+	if err := runPush("missing", false); err == nil {
+		t.Error("runPush() should fail for a missing feature")
+	}
+}
@@ -44,6 +44,15 @@ func main() {
 	apiRouter.HandleFunc("/projects/reorder", server.ReorderProjects).Methods("PUT")
 	apiRouter.HandleFunc("/projects/{id}", server.RemoveProject).Methods("DELETE")
 	apiRouter.HandleFunc("/projects/{id}/favorite", server.ToggleFavorite).Methods("PUT")
+	apiRouter.HandleFunc("/projects/{id}/plan", server.PreviewPlan).Methods("POST")
+	apiRouter.HandleFunc("/projects/{id}/interrupted", server.ListInterruptedOperations).Methods("GET")
+	apiRouter.HandleFunc("/projects/{id}/interrupted/{name}/resume", server.ResumeOperation).Methods("POST")
+	apiRouter.HandleFunc("/projects/{id}/interrupted/{name}/rollback", server.RollbackOperation).Methods("POST")
+	apiRouter.HandleFunc("/projects/{id}/doctor", server.CheckProject).Methods("GET")
+	apiRouter.HandleFunc("/projects/{id}/doctor/fix", server.FixProject).Methods("POST")
+	apiRouter.HandleFunc("/projects/{id}/archives", server.ListArchives).Methods("GET")
+	apiRouter.HandleFunc("/projects/{id}/archives/{name}/restore", server.RestoreFeature).Methods("POST")
+	apiRouter.HandleFunc("/projects/{id}/archives/{name}", server.DeleteArchive).Methods("DELETE")
 
 	// Feature routes
 	apiRouter.HandleFunc("/projects/{id}/features", server.ListFeatures).Methods("GET")
@@ -51,6 +60,13 @@ func main() {
 	apiRouter.HandleFunc("/projects/{id}/features/prune", server.PruneFeatures).Methods("POST")
 	apiRouter.HandleFunc("/projects/{id}/features/{name}", server.DeleteFeature).Methods("DELETE")
 	apiRouter.HandleFunc("/projects/{id}/features/{name}/rename", server.RenameFeature).Methods("PUT")
+	apiRouter.HandleFunc("/projects/{id}/features/{name}/info", server.GetFeatureInfo).Methods("GET")
+	apiRouter.HandleFunc("/projects/{id}/features/{name}/metadata", server.AnnotateFeature).Methods("PUT")
+	apiRouter.HandleFunc("/projects/{id}/features/{name}/move", server.MoveFeature).Methods("POST")
+	apiRouter.HandleFunc("/projects/{id}/features/{name}/clone", server.CloneFeature).Methods("POST")
+	apiRouter.HandleFunc("/projects/{id}/features/{name}/archive", server.ArchiveFeature).Methods("POST")
+	apiRouter.HandleFunc("/projects/{id}/features/{name}/env/sync", server.SyncFeatureEnv).Methods("POST")
+	apiRouter.HandleFunc("/projects/{id}/features/{name}/push", server.PushFeature).Methods("POST")
 
 	// Config routes (local preferences)
 	apiRouter.HandleFunc("/projects/{id}/config/status", server.GetConfigStatus).Methods("GET")
@@ -67,6 +83,7 @@ func main() {
 	apiRouter.HandleFunc("/projects/{id}/source-repos", server.GetSourceRepos).Methods("GET")
 	apiRouter.HandleFunc("/projects/{id}/source-repos/install", server.InstallSourceRepos).Methods("POST")
 	apiRouter.HandleFunc("/projects/{id}/source-repos/refresh", server.RefreshSourceRepos).Methods("POST")
+	apiRouter.HandleFunc("/projects/{id}/source-repos/fetch", server.FetchSourceRepos).Methods("POST")
 
 	// Terminal routes
 	apiRouter.HandleFunc("/terminal/open", server.OpenTerminal).Methods("POST")
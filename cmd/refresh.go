@@ -9,6 +9,7 @@ import (
 
 	"ramp/internal/config"
 	"ramp/internal/git"
+	"ramp/internal/operations"
 	"ramp/internal/ui"
 )
 
@@ -123,6 +124,18 @@ func RefreshRepositoriesParallel(projectDir string, repos map[string]*config.Rep
 				return
 			}
 
+			// Forks: make sure upstream is a remote so fetch --all picks it up
+			if r.Upstream != "" {
+				if err := git.EnsureRemote(repoDir, git.UpstreamRemote, r.Upstream); err != nil {
+					result.status = "warning"
+					result.message = err.Error()
+					mu.Lock()
+					results = append(results, result)
+					mu.Unlock()
+					return
+				}
+			}
+
 			// Fetch all remotes
 			if err := git.FetchAllQuiet(repoDir); err != nil {
 				result.status = "warning"
@@ -161,6 +174,12 @@ func RefreshRepositoriesParallel(projectDir string, repos map[string]*config.Rep
 				result.message = fmt.Sprintf("branch %s has no remote tracking branch, skipped pull", currentBranch)
 			}
 
+			// Bring submodules and LFS objects in line with what was pulled
+			if err := operations.UpdateSubmodulesAndLFS(r, repoDir); err != nil {
+				result.status = "warning"
+				result.message = err.Error()
+			}
+
 			mu.Lock()
 			results = append(results, result)
 			mu.Unlock()
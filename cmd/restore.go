@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"ramp/internal/config"
+	"ramp/internal/operations"
+)
+
+var restoreCmd = &cobra.Command{
+	Use:   "restore <feature>",
+	Short: "Bring back an archived feature",
+	Long: `Restore a feature saved by 'ramp archive'. Its branches are recreated at the
+commits they had when archived, worktrees are created again the way 'ramp up'
+creates them (env files, setup script and hooks included), and uncommitted
+changes are reapplied: what was staged is staged again, the rest, untracked
+files included, is left unstaged.
+
+The feature gets its old ports back unless another feature took them in the
+meantime, in which case new ones are allocated. Its display name is kept.
+
+If uncommitted changes can't be reapplied to a repo, for example because the
+setup script changed the same files, the restore still completes and the
+changes stay under refs/ramp/archive/<feature>/changes in that repo.
+
+Run 'ramp archive' to list the archived features.
+
+Examples:
+  ramp restore my-feature`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		featureName := strings.TrimRight(args[0], "/")
+
+		if err := runRestore(featureName); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(restoreCmd)
+}
+
+func runRestore(featureName string) error {
+	wd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+
+	projectDir, err := config.FindRampProject(wd)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.LoadConfig(projectDir)
+	if err != nil {
+		return err
+	}
+
+	// Auto-prompt for local config if needed (CLI-specific, uses interactive prompts)
+	if err := EnsureLocalConfig(projectDir, cfg); err != nil {
+		return fmt.Errorf("failed to configure local preferences: %w", err)
+	}
+
+	result, err := operations.RestoreFeature(operations.RestoreOptions{
+		FeatureName: featureName,
+		ProjectDir:  projectDir,
+		Config:      cfg,
+		Progress:    operations.NewCLIProgressReporter(),
+	})
+	if err != nil {
+		return err
+	}
+
+	if result.PortsChanged {
+		fmt.Printf("Note: the feature's old ports were taken; it now has %v\n", result.AllocatedPorts)
+	}
+	fmt.Printf("Feature '%s' restored at %s\n", result.FeatureName, result.TreesDir)
+	return nil
+}
@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"ramp/internal/config"
+	"ramp/internal/operations"
+)
+
+var resumeCmd = &cobra.Command{
+	Use:   "resume [feature-name]",
+	Short: "Finish an up, down or prune that was interrupted",
+	Long: `Finish an operation that was killed part-way, for example by Ctrl+C, a crash
+or closing the desktop app.
+
+While ramp up, down and prune run, each completed step is recorded in a journal
+under .ramp/journal/. When ramp finds a journal whose process is gone, it reports
+the interrupted operation. ramp resume picks it up from the first step that did
+not complete; ramp rollback undoes it instead.
+
+If no feature name is provided and a single operation was interrupted, that
+one is resumed.
+
+Examples:
+  ramp resume
+  ramp resume my-feature`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		featureName := ""
+		if len(args) > 0 {
+			featureName = strings.TrimRight(args[0], "/")
+		}
+		if err := runResume(featureName); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(resumeCmd)
+}
+
+func runResume(featureName string) error {
+	projectDir, cfg, featureName, err := loadInterruptedFeature(featureName)
+	if err != nil {
+		return err
+	}
+
+	return operations.ResumeOperation(operations.RecoverOptions{
+		ProjectDir:  projectDir,
+		Config:      cfg,
+		Progress:    operations.NewCLIProgressReporter(),
+		FeatureName: featureName,
+	})
+}
+
+// loadInterruptedFeature loads the project and picks the feature whose
+// interrupted operation resume or rollback acts on: the one given, or the only
+// one there is.
+func loadInterruptedFeature(featureName string) (string, *config.Config, string, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", nil, "", fmt.Errorf("failed to get current directory: %w", err)
+	}
+
+	projectDir, err := config.FindRampProject(wd)
+	if err != nil {
+		return "", nil, "", err
+	}
+
+	cfg, err := config.LoadConfig(projectDir)
+	if err != nil {
+		return "", nil, "", err
+	}
+
+	if featureName != "" {
+		return projectDir, cfg, featureName, nil
+	}
+
+	pending, err := operations.PendingJournals(projectDir)
+	if err != nil {
+		return "", nil, "", err
+	}
+	switch len(pending) {
+	case 0:
+		return "", nil, "", fmt.Errorf("no interrupted operations found")
+	case 1:
+		return projectDir, cfg, pending[0].Feature, nil
+	}
+
+	names := make([]string, len(pending))
+	for i, j := range pending {
+		names[i] = j.Feature
+	}
+	return "", nil, "", fmt.Errorf("several operations were interrupted, name one of: %s", strings.Join(names, ", "))
+}
+
+// warnInterruptedOperations reports operations that were killed part-way, so
+// they don't go unnoticed until the next up or down trips over them.
+func warnInterruptedOperations(cmd *cobra.Command) {
+	if cmd == resumeCmd || cmd == rollbackCmd || cmd.Hidden {
+		return
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		return
+	}
+	projectDir, err := config.FindRampProject(wd)
+	if err != nil {
+		return
+	}
+	pending, err := operations.PendingJournals(projectDir)
+	if err != nil {
+		return
+	}
+
+	for _, j := range pending {
+		fmt.Fprintf(os.Stderr, "⚠️  ramp %s of feature '%s' was interrupted %s ago (%d step%s completed).\n",
+			j.Operation, j.Feature, time.Since(j.Started).Round(time.Second), len(j.Done), pluralize(len(j.Done)))
+		fmt.Fprintf(os.Stderr, "   Run 'ramp resume %s' to finish it or 'ramp rollback %s' to undo it.\n", j.Feature, j.Feature)
+	}
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeInterruptedJournal leaves a journal as if ramp was killed while running
+// operation on featureName.
+func writeInterruptedJournal(t *testing.T, tp *TestProject, operation, featureName string) {
+	t.Helper()
+	cmd := exec.Command("true")
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("failed to run true: %v", err)
+	}
+
+	dir := filepath.Join(tp.Dir, ".ramp", "journal")
+	os.MkdirAll(dir, 0755)
+	journal := fmt.Sprintf(`{"operation": %q, "feature": %q, "pid": %d, "started": "2026-01-01T00:00:00Z", "done": []}`, operation, featureName, cmd.Process.Pid)
+	if err := os.WriteFile(filepath.Join(dir, featureName+".json"), []byte(journal), 0644); err != nil {
+		t.Fatalf("failed to write journal: %v", err)
+	}
+}
+
+// TestResumeWithoutInterruptedOperations tests that resume and rollback need something to act on
+func TestResumeWithoutInterruptedOperations(t *testing.T) {
+	tp := NewTestProject(t)
+	tp.InitRepo("repo1")
+
+	cleanup := tp.ChangeToProjectDir()
+	defer cleanup()
+
+	if err := runResume(""); err == nil || !strings.Contains(err.Error(), "no interrupted operations") {
+		t.Errorf("runResume() error = %v, want no interrupted operations", err)
+	}
+	if err := runRollback("missing"); err == nil || !strings.Contains(err.Error(), "no interrupted operation found") {
+		t.Errorf("runRollback() error = %v, want no interrupted operation found", err)
+	}
+}
+
+// TestRollbackPicksInterruptedFeature tests choosing which interrupted operation to roll back
+func TestRollbackPicksInterruptedFeature(t *testing.T) {
+	tp := NewTestProject(t)
+	tp.InitRepo("repo1")
+
+	cleanup := tp.ChangeToProjectDir()
+	defer cleanup()
+
+	writeInterruptedJournal(t, tp, "down", "first")
+	writeInterruptedJournal(t, tp, "prune", "second")
+
+	err := runRollback("")
+	if err == nil || !strings.Contains(err.Error(), "first, second") {
+		t.Fatalf("runRollback() error = %v, want the interrupted features listed", err)
+	}
+
+	// Nothing was removed yet, so the down's journal is just discarded
+	if err := runRollback("first"); err != nil {
+		t.Fatalf("runRollback() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(tp.Dir, ".ramp", "journal", "first.json")); !os.IsNotExist(err) {
+		t.Error("journal of the rolled back down should be removed")
+	}
+
+	// The only one left is picked without naming it
+	if err := runRollback(""); err != nil {
+		t.Fatalf("runRollback() error = %v", err)
+	}
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"ramp/internal/operations"
+)
+
+var rollbackCmd = &cobra.Command{
+	Use:   "rollback [feature-name]",
+	Short: "Undo an up that was interrupted",
+	Long: `Undo an operation that was killed part-way, using the journal ramp keeps under
+.ramp/journal/ while up, down and prune run.
+
+For ramp up, the worktrees and branches it created are removed, and its ports
+and feature metadata released, as if it had failed. A down or prune can only be
+rolled back before it removed any worktree or branch; after that, finish it
+with ramp resume.
+
+If no feature name is provided and a single operation was interrupted, that
+one is rolled back.
+
+Examples:
+  ramp rollback
+  ramp rollback my-feature`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		featureName := ""
+		if len(args) > 0 {
+			featureName = strings.TrimRight(args[0], "/")
+		}
+		if err := runRollback(featureName); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(rollbackCmd)
+}
+
+func runRollback(featureName string) error {
+	projectDir, cfg, featureName, err := loadInterruptedFeature(featureName)
+	if err != nil {
+		return err
+	}
+
+	return operations.RollbackOperation(operations.RecoverOptions{
+		ProjectDir:  projectDir,
+		Config:      cfg,
+		Progress:    operations.NewCLIProgressReporter(),
+		FeatureName: featureName,
+	})
+}
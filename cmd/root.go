@@ -32,6 +32,7 @@ commands to manage repositories and create feature branches.`,
 		verbose, _ := cmd.Flags().GetBool("verbose")
 		ui.Verbose = verbose
 		NonInteractive, _ = cmd.Flags().GetBool("yes")
+		warnInterruptedOperations(cmd)
 	},
 }
 
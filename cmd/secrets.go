@@ -0,0 +1,278 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/huh"
+	"github.com/spf13/cobra"
+
+	"ramp/internal/config"
+	"ramp/internal/envfile"
+)
+
+var secretsCmd = &cobra.Command{
+	Use:   "secrets",
+	Short: "Manage secrets stored in encrypted secrets files",
+	Long: `Manage secrets for providers of type "file" configured under secrets:
+in ramp.yaml. Values are encrypted with the passphrase in the provider's
+key_env variable (RAMP_SECRETS_KEY by default).
+
+Env files reference secrets as ${secret:<provider>/<name>}.`,
+}
+
+var secretsSetCmd = &cobra.Command{
+	Use:   "set <provider>/<name>",
+	Short: "Store a secret in an encrypted secrets file",
+	Long: `Store a secret in an encrypted secrets file.
+
+The value is read from stdin, or prompted for without echo when stdin is a
+terminal.
+
+Examples:
+  ramp secrets set vault/STRIPE_KEY
+  echo -n "$TOKEN" | ramp secrets set vault/github/token`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runSecretsSet(args[0], os.Stdin); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+var secretsListCmd = &cobra.Command{
+	Use:   "list [provider]",
+	Short: "List secret names in encrypted secrets files",
+	Long: `List the names of secrets stored in encrypted secrets files. Values are
+never printed.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		provider := ""
+		if len(args) > 0 {
+			provider = args[0]
+		}
+		if err := runSecretsList(provider); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+var secretsRmCmd = &cobra.Command{
+	Use:   "rm <provider>/<name>",
+	Short: "Remove a secret from an encrypted secrets file",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runSecretsRm(args[0]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	secretsCmd.AddCommand(secretsSetCmd)
+	secretsCmd.AddCommand(secretsListCmd)
+	secretsCmd.AddCommand(secretsRmCmd)
+	rootCmd.AddCommand(secretsCmd)
+}
+
+// secretsFile identifies the encrypted file behind a file provider
+type secretsFile struct {
+	provider   string
+	path       string
+	passphrase string
+}
+
+// loadSecretsProvider finds a file provider by name and reads its passphrase
+func loadSecretsProvider(providerName string) (*secretsFile, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current directory: %w", err)
+	}
+
+	projectDir, err := config.FindRampProject(wd)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := config.LoadConfig(projectDir)
+	if err != nil {
+		return nil, err
+	}
+
+	provider, ok := cfg.Secrets[providerName]
+	if !ok || provider == nil {
+		return nil, fmt.Errorf("secret provider '%s' is not configured in ramp.yaml", providerName)
+	}
+	if provider.Type != envfile.SecretProviderFile {
+		return nil, fmt.Errorf("secret provider '%s' has type %s; only file providers are managed by ramp", providerName, provider.Type)
+	}
+
+	passphrase, err := envfile.SecretsPassphrase(provider)
+	if err != nil {
+		return nil, err
+	}
+
+	return &secretsFile{
+		provider:   providerName,
+		path:       envfile.SecretsFilePath(projectDir, provider),
+		passphrase: passphrase,
+	}, nil
+}
+
+// splitSecretRef splits "provider/name" into its parts
+func splitSecretRef(ref string) (string, string, error) {
+	provider, name, ok := strings.Cut(ref, "/")
+	if !ok || provider == "" || name == "" {
+		return "", "", fmt.Errorf("expected <provider>/<name>, got '%s'", ref)
+	}
+	return provider, name, nil
+}
+
+func runSecretsSet(ref string, stdin io.Reader) error {
+	providerName, name, err := splitSecretRef(ref)
+	if err != nil {
+		return err
+	}
+
+	file, err := loadSecretsProvider(providerName)
+	if err != nil {
+		return err
+	}
+
+	value, err := readSecretValue(name, stdin)
+	if err != nil {
+		return err
+	}
+	if value == "" {
+		return fmt.Errorf("secret value is empty")
+	}
+
+	secrets, err := envfile.LoadSecretsFile(file.path, file.passphrase)
+	if err != nil {
+		return err
+	}
+	secrets[name] = value
+
+	if err := envfile.SaveSecretsFile(file.path, file.passphrase, secrets); err != nil {
+		return err
+	}
+
+	fmt.Printf("Stored secret '%s' in %s\n", ref, file.path)
+	return nil
+}
+
+// readSecretValue prompts for a value on a terminal, or reads it from stdin
+func readSecretValue(name string, stdin io.Reader) (string, error) {
+	if f, ok := stdin.(*os.File); ok {
+		if info, err := f.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 {
+			var value string
+			input := huh.NewInput().
+				Title(fmt.Sprintf("Value for %s", name)).
+				EchoMode(huh.EchoModePassword).
+				Value(&value)
+			if err := huh.NewForm(huh.NewGroup(input)).Run(); err != nil {
+				return "", err
+			}
+			return value, nil
+		}
+	}
+
+	data, err := io.ReadAll(bufio.NewReader(stdin))
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret from stdin: %w", err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+func runSecretsList(providerName string) error {
+	wd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+
+	projectDir, err := config.FindRampProject(wd)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.LoadConfig(projectDir)
+	if err != nil {
+		return err
+	}
+
+	var providers []string
+	for name, provider := range cfg.Secrets {
+		if provider != nil && provider.Type == envfile.SecretProviderFile && (providerName == "" || name == providerName) {
+			providers = append(providers, name)
+		}
+	}
+	sort.Strings(providers)
+
+	if len(providers) == 0 {
+		if providerName != "" {
+			return fmt.Errorf("no file secret provider named '%s'", providerName)
+		}
+		fmt.Println("No file secret providers configured.")
+		return nil
+	}
+
+	for _, name := range providers {
+		file, err := loadSecretsProvider(name)
+		if err != nil {
+			return err
+		}
+
+		secrets, err := envfile.LoadSecretsFile(file.path, file.passphrase)
+		if err != nil {
+			return err
+		}
+
+		names := make([]string, 0, len(secrets))
+		for secretName := range secrets {
+			names = append(names, secretName)
+		}
+		sort.Strings(names)
+
+		fmt.Printf("%s (%d):\n", name, len(names))
+		for _, secretName := range names {
+			fmt.Printf("  %s/%s\n", name, secretName)
+		}
+	}
+
+	return nil
+}
+
+func runSecretsRm(ref string) error {
+	providerName, name, err := splitSecretRef(ref)
+	if err != nil {
+		return err
+	}
+
+	file, err := loadSecretsProvider(providerName)
+	if err != nil {
+		return err
+	}
+
+	secrets, err := envfile.LoadSecretsFile(file.path, file.passphrase)
+	if err != nil {
+		return err
+	}
+	if _, ok := secrets[name]; !ok {
+		return fmt.Errorf("secret '%s' not found", ref)
+	}
+	delete(secrets, name)
+
+	if err := envfile.SaveSecretsFile(file.path, file.passphrase, secrets); err != nil {
+		return err
+	}
+
+	fmt.Printf("Removed secret '%s'\n", ref)
+	return nil
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ramp/internal/config"
+)
+
+// TestSecretsSetAndUp tests storing a secret and resolving it into a feature's env file
+func TestSecretsSetAndUp(t *testing.T) {
+	tp := NewTestProject(t)
+	cleanup := tp.ChangeToProjectDir()
+	defer cleanup()
+
+	t.Setenv("RAMP_SECRETS_KEY", "test-passphrase")
+
+	repo := tp.InitRepo("app")
+	tp.Config.Repos[0].EnvFiles = []config.EnvFile{{Source: ".env.example", Dest: ".env"}}
+	tp.Config.Secrets = map[string]*config.SecretProvider{"vault": {Type: "file"}}
+	if err := config.SaveConfig(tp.Config, tp.Dir); err != nil {
+		t.Fatalf("failed to save config: %v", err)
+	}
+	os.WriteFile(filepath.Join(repo.SourceDir, ".env.example"), []byte("API_KEY=${secret:vault/API_KEY}\n"), 0644)
+
+	if err := runSecretsSet("vault/API_KEY", strings.NewReader("sk_live_abc123\n")); err != nil {
+		t.Fatalf("runSecretsSet() error = %v", err)
+	}
+	if err := runSecretsSet("other/API_KEY", strings.NewReader("x")); err == nil {
+		t.Error("runSecretsSet() should fail for an unknown provider")
+	}
+
+	if err := runUp("secret-feature", "", "", ""); err != nil {
+		t.Fatalf("runUp() error = %v", err)
+	}
+
+	content, _ := os.ReadFile(filepath.Join(tp.TreesDir, "secret-feature", "app", ".env"))
+	if string(content) != "API_KEY=sk_live_abc123\n" {
+		t.Errorf(".env content = %q", string(content))
+	}
+
+	// Secret values never reach ramp's own files in plaintext
+	for _, name := range []string{"secrets.enc", "feature_metadata.json"} {
+		data, _ := os.ReadFile(filepath.Join(tp.Dir, ".ramp", name))
+		if strings.Contains(string(data), "sk_live_abc123") {
+			t.Errorf(".ramp/%s contains the secret value", name)
+		}
+	}
+
+	if err := runSecretsRm("vault/API_KEY"); err != nil {
+		t.Fatalf("runSecretsRm() error = %v", err)
+	}
+	if err := runSecretsRm("vault/API_KEY"); err == nil {
+		t.Error("runSecretsRm() should fail for a missing secret")
+	}
+}
@@ -14,8 +14,10 @@ import (
 	"github.com/spf13/cobra"
 
 	"ramp/internal/config"
+	"ramp/internal/envfile"
 	"ramp/internal/features"
 	"ramp/internal/git"
+	"ramp/internal/operations"
 	"ramp/internal/ports"
 	"ramp/internal/ui"
 )
@@ -24,6 +26,9 @@ var (
 	statusTreeOnly    bool
 	statusJSON        bool
 	statusRefreshFlag bool
+	statusNoFetch     bool
+	statusTag         string
+	statusGroupByTag  bool
 )
 
 var statusCmd = &cobra.Command{
@@ -32,7 +37,16 @@ var statusCmd = &cobra.Command{
 	Long: `Show comprehensive status information for the ramp project.
 
 Displays current branch and status for all source repositories,
-active features, and project configuration details.`,
+active features, and project configuration details.
+
+Features are listed oldest first. Use --tag to show only the features with a
+tag (see 'ramp feature tag'), and --group-by-tag to also list every feature
+under each of its tags.
+
+Examples:
+  ramp status
+  ramp status --tag billing
+  ramp status --group-by-tag`,
 	Run: func(cmd *cobra.Command, args []string) {
 		if err := runStatus(); err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
@@ -47,7 +61,11 @@ func init() {
 	statusCmd.Flags().BoolVar(&statusJSON, "json", false, "Output status as JSON (useful for scripts)")
 	statusCmd.Flags().BoolVarP(&statusRefreshFlag, "refresh", "r", false, "Refresh repositories before showing status")
 	statusCmd.MarkFlagsMutuallyExclusive("refresh", "tree")
+	statusCmd.Flags().BoolVar(&statusNoFetch, "no-fetch", false, "Skip fetching remotes and show status as of the last fetch")
 	statusCmd.MarkFlagsMutuallyExclusive("refresh", "json")
+	statusCmd.MarkFlagsMutuallyExclusive("refresh", "no-fetch")
+	statusCmd.Flags().StringVar(&statusTag, "tag", "", "Only show features with this tag")
+	statusCmd.Flags().BoolVar(&statusGroupByTag, "group-by-tag", false, "List features under each of their tags")
 }
 
 type repoStatus struct {
@@ -65,16 +83,18 @@ type featureInfo struct {
 }
 
 type featureWorktreeStatus struct {
-	repoName           string
-	branchName         string
-	hasUncommitted     bool
-	diffStats          *git.DiffStats
-	statusStats        *git.StatusStats
-	aheadCount         int
-	behindCount        int
-	isMerged           bool
-	defaultBranch      string
-	error              string
+	repoName       string
+	branchName     string
+	hasUncommitted bool
+	diffStats      *git.DiffStats
+	statusStats    *git.StatusStats
+	aheadCount     int
+	behindCount    int
+	isMerged       bool
+	mergeMethod    git.MergeMethod
+	sparse         bool
+	defaultBranch  string
+	error          string
 }
 
 func runStatus() error {
@@ -128,8 +148,8 @@ func runStatus() error {
 	}
 
 	// Fetch all repos in parallel to get accurate remote tracking info
-	// (skip if we just refreshed, which already fetches)
-	if !statusRefreshFlag {
+	// (skip if we just refreshed, which already fetches, or were asked not to)
+	if !statusRefreshFlag && !statusNoFetch {
 		progress := ui.NewProgress()
 		progress.Start("Fetching remote information...")
 
@@ -317,81 +337,43 @@ func displaySourceRepos(repoStatuses []repoStatus) {
 }
 
 func getFeatureWorktreeStatus(projectDir, featureName, repoName string, repo *config.Repo) featureWorktreeStatus {
-	worktreePath := filepath.Join(projectDir, "trees", featureName, repoName)
-	sourceRepoPath := repo.GetRepoPath(projectDir)
-
-	status := featureWorktreeStatus{
-		repoName: repoName,
-	}
-
-	// Check if worktree exists
-	if _, err := os.Stat(worktreePath); os.IsNotExist(err) {
-		status.error = "worktree not found"
-		return status
-	}
-
-	// Get branch name
-	branchName, err := git.GetWorktreeBranch(worktreePath)
-	if err != nil {
-		status.error = fmt.Sprintf("failed to get branch: %v", err)
-		return status
-	}
-	status.branchName = branchName
-
-	// Get default branch from source repo
-	defaultBranch, err := git.GetDefaultBranch(sourceRepoPath)
-	if err != nil {
-		status.error = fmt.Sprintf("failed to get default branch: %v", err)
-		return status
-	}
-	status.defaultBranch = defaultBranch
+	collector := operations.NewStatusCollector(projectDir, map[string]*config.Repo{repoName: repo}, 1)
+	return newFeatureWorktreeStatus(collector.Collect(featureName, repoName))
+}
 
-	// Check for uncommitted changes
-	hasUncommitted, err := git.HasUncommittedChanges(worktreePath)
-	if err != nil {
-		status.error = fmt.Sprintf("failed to check uncommitted changes: %v", err)
-		return status
+// collectFeatureWorktreeStatuses reads the status of every worktree in the given
+// features in parallel, keyed by feature name.
+func collectFeatureWorktreeStatuses(projectDir string, cfg *config.Config, features []featureInfo) map[string][]featureWorktreeStatus {
+	names := make([]string, len(features))
+	for i, feature := range features {
+		names[i] = feature.name
 	}
-	status.hasUncommitted = hasUncommitted
 
-	// Get diff stats and status stats if there are uncommitted changes
-	if hasUncommitted {
-		diffStats, err := git.GetDiffStats(worktreePath)
-		if err != nil {
-			status.diffStats = nil
-		} else {
-			status.diffStats = diffStats
-		}
-
-		statusStats, err := git.GetStatusStats(worktreePath)
-		if err != nil {
-			status.statusStats = nil
-		} else {
-			status.statusStats = statusStats
+	collector := operations.NewStatusCollector(projectDir, cfg.GetRepos(), cfg.GetMaxParallel())
+	result := make(map[string][]featureWorktreeStatus)
+	for featureName, statuses := range collector.CollectFeatures(names) {
+		for _, status := range statuses {
+			result[featureName] = append(result[featureName], newFeatureWorktreeStatus(status))
 		}
 	}
+	return result
+}
 
-	// Get ahead/behind count compared to default branch
-	ahead, behind, err := git.GetAheadBehindCount(worktreePath, defaultBranch)
-	if err != nil {
-		// Not a fatal error, just means we can't compare
-		status.aheadCount = 0
-		status.behindCount = 0
-	} else {
-		status.aheadCount = ahead
-		status.behindCount = behind
-	}
-
-	// Check if merged into default branch
-	isMerged, err := git.IsMergedInto(worktreePath, defaultBranch)
-	if err != nil {
-		// Not a fatal error
-		status.isMerged = false
-	} else {
-		status.isMerged = isMerged
+func newFeatureWorktreeStatus(status operations.FeatureRepoStatus) featureWorktreeStatus {
+	return featureWorktreeStatus{
+		repoName:       status.Repo,
+		branchName:     status.Branch,
+		hasUncommitted: status.HasUncommitted,
+		diffStats:      status.DiffStats,
+		statusStats:    status.StatusStats,
+		aheadCount:     status.Ahead,
+		behindCount:    status.Behind,
+		isMerged:       status.IsMerged(),
+		mergeMethod:    status.MergeMethod,
+		sparse:         status.Sparse,
+		defaultBranch:  status.BaseBranch,
+		error:          status.Error,
 	}
-
-	return status
 }
 
 func formatCompactStatus(status featureWorktreeStatus, showAll bool) string {
@@ -452,6 +434,9 @@ func formatCompactStatus(status featureWorktreeStatus, showAll bool) string {
 	if status.aheadCount > 0 {
 		parts = append(parts, fmt.Sprintf("%d ahead", status.aheadCount))
 	}
+	if status.mergeMethod == git.MergeMethodRemoteDeleted {
+		parts = append(parts, "remote deleted")
+	}
 
 	// Don't show "merged" or "behind" status in needs attention section
 	// It's confusing and not actionable - you only care about uncommitted/ahead
@@ -461,6 +446,11 @@ func formatCompactStatus(status featureWorktreeStatus, showAll bool) string {
 		return ""
 	}
 
+	// Only part of the tree is checked out, so the stats above only cover that part
+	if status.sparse {
+		parts = append(parts, "sparse")
+	}
+
 	// If showing all and no status, just show symbol
 	if len(parts) == 0 {
 		return symbol
@@ -483,15 +473,39 @@ func needsAttention(statuses []featureWorktreeStatus) bool {
 	return false
 }
 
+// mergeMethodLabels describes merge methods other than a plain merge commit or fast-forward
+var mergeMethodLabels = map[git.MergeMethod]string{
+	git.MergeMethodPatchID: "rebased",
+	git.MergeMethodTree:    "squashed",
+}
+
+// describeMergeMethods returns a suffix such as " (squashed)" naming how a feature's
+// repos were found merged, or "" if every repo was merged normally
+func describeMergeMethods(statuses []featureWorktreeStatus) string {
+	seen := make(map[string]bool)
+	var labels []string
+	for _, status := range statuses {
+		if label, ok := mergeMethodLabels[status.mergeMethod]; ok && !seen[label] {
+			seen[label] = true
+			labels = append(labels, label)
+		}
+	}
+	if len(labels) == 0 {
+		return ""
+	}
+	sort.Strings(labels)
+	return fmt.Sprintf(" (%s)", strings.Join(labels, ", "))
+}
+
 func isMerged(statuses []featureWorktreeStatus) bool {
 	anyBehind := false
 
 	for _, status := range statuses {
 		// Has pending work - not merged
-		if status.hasUncommitted || status.aheadCount > 0 {
+		if status.hasUncommitted {
 			return false
 		}
-		// Not merged according to git
+		// Not merged according to git (squash-merged branches stay ahead, so ahead commits alone don't count)
 		if !status.isMerged {
 			return false
 		}
@@ -506,6 +520,25 @@ func isMerged(statuses []featureWorktreeStatus) bool {
 	return anyBehind
 }
 
+// isRemoteDeleted reports whether every repo of a feature without uncommitted
+// changes is merged or had its remote branch deleted, at least one deleted.
+// Its commits may be nowhere else, so prune only removes it when confirmed.
+func isRemoteDeleted(statuses []featureWorktreeStatus) bool {
+	anyDeleted := false
+	for _, status := range statuses {
+		if status.hasUncommitted {
+			return false
+		}
+		switch {
+		case status.mergeMethod == git.MergeMethodRemoteDeleted:
+			anyDeleted = true
+		case !status.isMerged:
+			return false
+		}
+	}
+	return anyDeleted
+}
+
 func isClean(statuses []featureWorktreeStatus) bool {
 	for _, status := range statuses {
 		// Never had any commits (0 ahead, 0 behind or just behind)
@@ -527,8 +560,18 @@ func displayActiveFeatures(projectDir string, cfg *config.Config, progress *ui.P
 		return nil
 	}
 
-	// Load feature metadata for display names
+	tag := ""
+	if statusTag != "" {
+		var err error
+		if tag, err = features.NormalizeTag(statusTag); err != nil {
+			progress.Error("Analyzing features...")
+			return err
+		}
+	}
+
+	// Load feature metadata for display names, tags and creation times
 	metadataStore, _ := features.NewMetadataStore(projectDir)
+	metadata := make(map[string]features.FeatureMetadata)
 
 	// Helper to format feature name with display name
 	formatFeatureName := func(featureName string) string {
@@ -551,21 +594,27 @@ func displayActiveFeatures(projectDir string, cfg *config.Config, progress *ui.P
 	var features []featureInfo
 	for _, entry := range entries {
 		if entry.IsDir() {
-			featurePath := filepath.Join(treesDir, entry.Name())
-			stat, err := os.Stat(featurePath)
-			if err != nil {
+			if metadataStore != nil {
+				metadata[entry.Name()] = metadataStore.Get(entry.Name())
+			}
+			meta := metadata[entry.Name()]
+			if tag != "" && !meta.HasTag(tag) {
 				continue
 			}
 			features = append(features, featureInfo{
 				name:    entry.Name(),
-				modTime: stat.ModTime(),
+				modTime: operations.FeatureCreated(projectDir, entry.Name(), meta),
 			})
 		}
 	}
 
 	if len(features) == 0 {
 		progress.Success("Analyzing features...")
-		fmt.Println("🌿 No active features")
+		if tag != "" {
+			fmt.Printf("🌿 No active features tagged '%s'\n", tag)
+		} else {
+			fmt.Println("🌿 No active features")
+		}
 		return nil
 	}
 
@@ -575,32 +624,22 @@ func displayActiveFeatures(projectDir string, cfg *config.Config, progress *ui.P
 	})
 
 	// Categorize features
-	repos := cfg.GetRepos()
 	var inFlightFeatures []struct {
 		name     string
 		statuses []featureWorktreeStatus
 	}
 	var mergedFeatures []string
 	var cleanFeatures []string
+	mergeMethods := make(map[string]string)
+	driftedEnvFiles := make(map[string][]string)
+	allStatuses := collectFeatureWorktreeStatuses(projectDir, cfg, features)
 
 	for _, feature := range features {
-		featureDir := filepath.Join(treesDir, feature.name)
-		featureEntries, err := os.ReadDir(featureDir)
-		if err != nil {
-			continue
-		}
-
-		var worktreeStatuses []featureWorktreeStatus
-		for _, entry := range featureEntries {
-			if entry.IsDir() {
-				repoName := entry.Name()
-				if repo, exists := repos[repoName]; exists {
-					status := getFeatureWorktreeStatus(projectDir, feature.name, repoName, repo)
-					worktreeStatuses = append(worktreeStatuses, status)
-				}
-			}
+		if drifted, err := operations.DetectEnvFileDrift(projectDir, feature.name); err == nil && len(drifted) > 0 {
+			driftedEnvFiles[feature.name] = drifted
 		}
 
+		worktreeStatuses := allStatuses[feature.name]
 		if len(worktreeStatuses) == 0 {
 			continue
 		}
@@ -612,6 +651,7 @@ func displayActiveFeatures(projectDir string, cfg *config.Config, progress *ui.P
 			}{feature.name, worktreeStatuses})
 		} else if isMerged(worktreeStatuses) {
 			mergedFeatures = append(mergedFeatures, feature.name)
+			mergeMethods[feature.name] = describeMergeMethods(worktreeStatuses)
 		} else if isClean(worktreeStatuses) {
 			cleanFeatures = append(cleanFeatures, feature.name)
 		}
@@ -636,14 +676,22 @@ func displayActiveFeatures(projectDir string, cfg *config.Config, progress *ui.P
 	if cleanCount > 0 {
 		summaryParts = append(summaryParts, fmt.Sprintf("%d clean", cleanCount))
 	}
-	fmt.Printf("🌿 Features: %s\n\n", strings.Join(summaryParts, "  •  "))
+	if tag != "" {
+		fmt.Printf("🌿 Features tagged '%s': %s\n\n", tag, strings.Join(summaryParts, "  •  "))
+	} else {
+		fmt.Printf("🌿 Features: %s\n\n", strings.Join(summaryParts, "  •  "))
+	}
 
 	// Display in-flight features
 	if len(inFlightFeatures) > 0 {
 		fmt.Println("━━━ IN FLIGHT ━━━")
 		fmt.Println()
 		for _, feature := range inFlightFeatures {
-			fmt.Printf("%s\n", formatFeatureName(feature.name))
+			if tags := metadata[feature.name].Tags; len(tags) > 0 {
+				fmt.Printf("%s  [%s]\n", formatFeatureName(feature.name), strings.Join(tags, ", "))
+			} else {
+				fmt.Printf("%s\n", formatFeatureName(feature.name))
+			}
 			for _, status := range feature.statuses {
 				// Only show repos with local work (uncommitted or ahead)
 				hasLocalWork := status.hasUncommitted || status.aheadCount > 0
@@ -665,7 +713,7 @@ func displayActiveFeatures(projectDir string, cfg *config.Config, progress *ui.P
 		const maxWidth = 70
 		line := ""
 		for i, name := range mergedFeatures {
-			displayName := formatFeatureName(name)
+			displayName := formatFeatureName(name) + mergeMethods[name]
 			if i > 0 {
 				line += ", "
 			}
@@ -705,6 +753,49 @@ func displayActiveFeatures(projectDir string, cfg *config.Config, progress *ui.P
 		fmt.Println()
 	}
 
+	// Display features grouped by tag
+	if statusGroupByTag {
+		names := make([]string, len(features))
+		for i, feature := range features {
+			names[i] = feature.name
+		}
+		groups := operations.GroupFeaturesByTag(names, metadata)
+		tags := make([]string, 0, len(groups))
+		for t := range groups {
+			if t != "" {
+				tags = append(tags, t)
+			}
+		}
+		sort.Strings(tags)
+		if _, ok := groups[""]; ok {
+			tags = append(tags, "")
+		}
+
+		fmt.Println("━━━ BY TAG ━━━")
+		for _, t := range tags {
+			label := t
+			if label == "" {
+				label = "(untagged)"
+			}
+			displayNames := make([]string, len(groups[t]))
+			for i, name := range groups[t] {
+				displayNames[i] = formatFeatureName(name)
+			}
+			fmt.Printf("%s: %s\n", label, strings.Join(displayNames, ", "))
+		}
+		fmt.Println()
+	}
+
+	// Display env files edited since ramp generated them
+	if len(driftedEnvFiles) > 0 {
+		fmt.Println("⚠️  Env files edited since generation (ramp env sync will not overwrite them without --keep, --merge or --force):")
+		for _, feature := range features {
+			if files, ok := driftedEnvFiles[feature.name]; ok {
+				fmt.Printf("  %s: %s\n", formatFeatureName(feature.name), strings.Join(files, ", "))
+			}
+		}
+		fmt.Println()
+	}
 
 	return nil
 }
@@ -730,6 +821,9 @@ type jsonRepoStatus struct {
 	FilesChanged int    `json:"filesChanged"`
 	LinesAdded   int    `json:"linesAdded"`
 	LinesRemoved int    `json:"linesRemoved"`
+	Merged       bool   `json:"merged"`
+	MergeMethod  string `json:"mergeMethod,omitempty"` // ancestor, patch-id, tree, or remote-deleted (not merged)
+	Sparse       bool   `json:"sparse"`
 }
 
 type jsonSummary struct {
@@ -739,11 +833,20 @@ type jsonSummary struct {
 	TotalRemoved     int `json:"totalRemoved"`
 }
 
+type jsonEnvCache struct {
+	Entries int `json:"entries"`
+	Expired int `json:"expired"`
+	Hits    int `json:"hits"`
+	Misses  int `json:"misses"`
+}
+
 type jsonStatusOutput struct {
-	Tree    string           `json:"tree"`
-	InTree  bool             `json:"inTree"`
-	Repos   []jsonRepoStatus `json:"repos"`
-	Summary jsonSummary      `json:"summary"`
+	Tree            string           `json:"tree"`
+	InTree          bool             `json:"inTree"`
+	Repos           []jsonRepoStatus `json:"repos"`
+	Summary         jsonSummary      `json:"summary"`
+	DriftedEnvFiles []string         `json:"driftedEnvFiles,omitempty"`
+	EnvCache        *jsonEnvCache    `json:"envCache,omitempty"`
 }
 
 // runStatusJSON outputs status as JSON for scripting
@@ -760,6 +863,9 @@ func runStatusJSON(projectDir string) error {
 		Repos:  []jsonRepoStatus{},
 	}
 
+	// Env script cache is project-wide, so report it in and out of trees
+	output.EnvCache = envCacheStatus(projectDir)
+
 	// If not in a tree, just output the basic info
 	if featureName == "" {
 		return outputJSON(output)
@@ -774,7 +880,12 @@ func runStatusJSON(projectDir string) error {
 	repos := cfg.GetRepos()
 	treePath := filepath.Join(projectDir, "trees", featureName)
 
+	if drifted, err := operations.DetectEnvFileDrift(projectDir, featureName); err == nil {
+		output.DriftedEnvFiles = drifted
+	}
+
 	// Gather stats for each repo in the tree
+	collector := operations.NewStatusCollector(projectDir, repos, cfg.GetMaxParallel())
 	for repoName := range repos {
 		worktreePath := filepath.Join(treePath, repoName)
 
@@ -788,27 +899,20 @@ func runStatusJSON(projectDir string) error {
 			continue
 		}
 
-		// Check for uncommitted changes
-		hasChanges, err := git.HasUncommittedChanges(worktreePath)
-		if err != nil {
-			output.Repos = append(output.Repos, repoStatus)
-			continue
-		}
-		repoStatus.HasChanges = hasChanges
+		status := collector.Collect(featureName, repoName)
+		repoStatus.Sparse = status.Sparse
 
-		if hasChanges {
-			// Get status stats (file counts including untracked)
-			statusStats, err := git.GetStatusStats(worktreePath)
-			if err == nil {
-				repoStatus.FilesChanged = statusStats.UntrackedFiles + statusStats.ModifiedFiles + statusStats.StagedFiles
-			}
+		// Check whether the branch was merged, and how; a deleted remote branch is
+		// reported without counting as merged
+		repoStatus.Merged = status.IsMerged()
+		repoStatus.MergeMethod = string(status.MergeMethod)
+		repoStatus.HasChanges = status.HasUncommitted
 
-			// Get diff stats for tracked file changes
-			diffStats, err := git.GetDiffStats(worktreePath)
-			if err == nil {
-				repoStatus.LinesAdded = diffStats.Insertions
-				repoStatus.LinesRemoved = diffStats.Deletions
-			}
+		if status.HasUncommitted {
+			// File counts including untracked, and line counts for unstaged tracked changes
+			repoStatus.FilesChanged = status.StatusStats.UntrackedFiles + status.StatusStats.ModifiedFiles + status.StatusStats.StagedFiles
+			repoStatus.LinesAdded = status.DiffStats.Insertions
+			repoStatus.LinesRemoved = status.DiffStats.Deletions
 
 			// Also get staged diff stats
 			stagedStats, err := getStagedDiffStats(worktreePath)
@@ -836,6 +940,24 @@ func runStatusJSON(projectDir string) error {
 	return outputJSON(output)
 }
 
+// envCacheStatus summarizes the env script cache, or returns nil if it is empty
+func envCacheStatus(projectDir string) *jsonEnvCache {
+	entries, err := envfile.ListCache(projectDir)
+	if err != nil || len(entries) == 0 {
+		return nil
+	}
+
+	status := &jsonEnvCache{Entries: len(entries)}
+	for _, entry := range entries {
+		if entry.Expired() {
+			status.Expired++
+		}
+		status.Hits += entry.Hits
+		status.Misses += entry.Misses
+	}
+	return status
+}
+
 func outputJSON(output jsonStatusOutput) error {
 	encoder := json.NewEncoder(os.Stdout)
 	encoder.SetIndent("", "  ")
@@ -926,4 +1048,3 @@ func countUntrackedFileLines(repoDir string) (int, error) {
 
 	return totalLines, nil
 }
-
@@ -294,6 +294,11 @@ func TestIsMerged(t *testing.T) {
 			statuses: []featureWorktreeStatus{{aheadCount: 0, behindCount: 1, isMerged: true, hasUncommitted: true}},
 			want:     false,
 		},
+		{
+			name:     "squash merged (still ahead)",
+			statuses: []featureWorktreeStatus{{aheadCount: 3, behindCount: 2, isMerged: true, mergeMethod: git.MergeMethodTree}},
+			want:     true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -394,6 +399,12 @@ func TestFormatCompactStatus(t *testing.T) {
 			showAll:  false,
 			contains: "2 ahead",
 		},
+		{
+			name:     "sparse worktree",
+			status:   featureWorktreeStatus{hasUncommitted: false, aheadCount: 1, sparse: true},
+			showAll:  false,
+			contains: "1 ahead, sparse",
+		},
 		{
 			name: "diff stats",
 			status: featureWorktreeStatus{
@@ -591,3 +602,18 @@ func TestGetFeatureWorktreeStatusOrphaned(t *testing.T) {
 		t.Errorf("expected 'worktree not found' error, got: %q", status.error)
 	}
 }
+
+func TestDescribeMergeMethods(t *testing.T) {
+	statuses := []featureWorktreeStatus{
+		{mergeMethod: git.MergeMethodAncestor},
+		{mergeMethod: git.MergeMethodTree},
+		{mergeMethod: git.MergeMethodPatchID},
+		{mergeMethod: git.MergeMethodTree},
+	}
+	if got := describeMergeMethods(statuses); got != " (rebased, squashed)" {
+		t.Errorf("describeMergeMethods() = %q, want %q", got, " (rebased, squashed)")
+	}
+	if got := describeMergeMethods(statuses[:1]); got != "" {
+		t.Errorf("describeMergeMethods() for a merge commit = %q, want empty", got)
+	}
+}
@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"ramp/internal/config"
+	"ramp/internal/operations"
+)
+
+var (
+	syncMergeFlag    bool
+	syncRebaseFlag   bool
+	syncContinueFlag bool
+	syncAbortFlag    bool
+)
+
+var syncCmd = &cobra.Command{
+	Use:   "sync [feature-name]",
+	Short: "Rebase or merge a feature's branches onto the latest default branch",
+	Long: `Bring a feature up to date with each repository's default branch.
+
+For every worktree, ramp fetches the source repository and then rebases the
+feature branch onto origin's default branch (--rebase, the default) or merges it
+in (--merge). For repositories with an upstream: configured, upstream's default
+branch is used instead. Uncommitted changes are stashed first and restored
+afterwards.
+
+Repositories that conflict are left mid-rebase or mid-merge and listed with
+their conflicted files; the others are synced regardless. Resolve and stage the
+conflicts in each worktree, then run ramp sync --continue, or undo the stopped
+repositories with ramp sync --abort.
+
+If no feature name is provided, ramp will attempt to auto-detect the feature
+based on your current working directory.
+
+Examples:
+  ramp sync my-feature
+  ramp sync my-feature --merge
+  ramp sync my-feature --continue
+  ramp sync my-feature --abort`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		featureName := ""
+		if len(args) > 0 {
+			featureName = strings.TrimRight(args[0], "/")
+		}
+
+		mode := operations.SyncRebase
+		if syncMergeFlag {
+			mode = operations.SyncMerge
+		}
+
+		if err := runSync(featureName, mode, syncContinueFlag, syncAbortFlag); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	syncCmd.Flags().BoolVar(&syncMergeFlag, "merge", false, "Merge the default branch into each feature branch")
+	syncCmd.Flags().BoolVar(&syncRebaseFlag, "rebase", false, "Rebase each feature branch onto the default branch (default)")
+	syncCmd.Flags().BoolVar(&syncContinueFlag, "continue", false, "Continue a sync stopped on conflicts after resolving them")
+	syncCmd.Flags().BoolVar(&syncAbortFlag, "abort", false, "Abort a sync stopped on conflicts")
+	syncCmd.MarkFlagsMutuallyExclusive("merge", "rebase")
+	syncCmd.MarkFlagsMutuallyExclusive("continue", "abort")
+	syncCmd.MarkFlagsMutuallyExclusive("continue", "merge", "rebase")
+	syncCmd.MarkFlagsMutuallyExclusive("abort", "merge", "rebase")
+	rootCmd.AddCommand(syncCmd)
+}
+
+func runSync(featureName string, mode operations.SyncMode, continueSync, abortSync bool) error {
+	wd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+
+	projectDir, err := config.FindRampProject(wd)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.LoadConfig(projectDir)
+	if err != nil {
+		return err
+	}
+
+	// Auto-detect feature name if not provided
+	if featureName == "" {
+		detected, err := config.DetectFeatureFromWorkingDir(projectDir)
+		if err != nil {
+			return fmt.Errorf("failed to detect feature from working directory: %w", err)
+		}
+		if detected == "" {
+			return fmt.Errorf("no feature name provided and could not auto-detect from current directory")
+		}
+		featureName = detected
+		fmt.Printf("Auto-detected feature: %s\n", featureName)
+	}
+
+	opts := operations.SyncOptions{
+		FeatureName: featureName,
+		ProjectDir:  projectDir,
+		Config:      cfg,
+		Progress:    operations.NewCLIProgressReporter(),
+		Mode:        mode,
+	}
+
+	var result *operations.SyncResult
+	switch {
+	case continueSync:
+		result, err = operations.ContinueSync(opts)
+	case abortSync:
+		result, err = operations.AbortSync(opts)
+	default:
+		result, err = operations.Sync(opts)
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, repo := range result.Repos {
+		if repo.Status != operations.SyncStatusConflict || len(repo.Conflicts) == 0 {
+			continue
+		}
+		fmt.Printf("\n%s conflicts:\n", repo.Repo)
+		for _, file := range repo.Conflicts {
+			fmt.Printf("  %s\n", file)
+		}
+	}
+
+	if conflicted := result.Conflicted(); len(conflicted) > 0 {
+		return fmt.Errorf("sync stopped on conflicts in %s; resolve them, then run 'ramp sync %s --continue' (or --abort)", strings.Join(conflicted, ", "), featureName)
+	}
+	if failed := result.Failed(); len(failed) > 0 {
+		return fmt.Errorf("failed to sync %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
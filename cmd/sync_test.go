@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"ramp/internal/operations"
+)
+
+// TestSyncRebasesFeature tests syncing a feature after main moved on
+func TestSyncRebasesFeature(t *testing.T) {
+	tp := NewTestProject(t)
+	repo1 := tp.InitRepo("repo1")
+
+	cleanup := tp.ChangeToProjectDir()
+	defer cleanup()
+
+	if err := runUp("my-feature", "", "", ""); err != nil {
+		t.Fatalf("runUp() error = %v", err)
+	}
+
+	os.WriteFile(filepath.Join(repo1.SourceDir, "main.txt"), []byte("main"), 0644)
+	runGitCmd(t, repo1.SourceDir, "add", "main.txt")
+	runGitCmd(t, repo1.SourceDir, "commit", "-m", "main change")
+	runGitCmd(t, repo1.SourceDir, "push", "origin", "main")
+
+	if err := runSync("my-feature", operations.SyncRebase, false, false); err != nil {
+		t.Fatalf("runSync() error = %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(tp.TreesDir, "my-feature", "repo1", "main.txt")); err != nil {
+		t.Errorf("feature should include main's new commit: %v", err)
+	}
+}
+
+// TestSyncContinueWithoutSync tests --continue when no sync was stopped
+func TestSyncContinueWithoutSync(t *testing.T) {
+	tp := NewTestProject(t)
+	tp.InitRepo("repo1")
+
+	cleanup := tp.ChangeToProjectDir()
+	defer cleanup()
+
+	if err := runUp("my-feature", "", "", ""); err != nil {
+		t.Fatalf("runUp() error = %v", err)
+	}
+
+	if err := runSync("my-feature", operations.SyncRebase, true, false); err == nil {
+		t.Error("runSync() --continue should fail without a stopped sync")
+	}
+}
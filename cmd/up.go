@@ -18,6 +18,9 @@ var fromFlag string
 var refreshFlag bool
 var noRefreshFlag bool
 var displayNameFlag string
+var jobsFlag int
+var upDryRun bool
+var upJSON bool
 
 var upCmd = &cobra.Command{
 	Use:   "up [feature-name]",
@@ -41,7 +44,14 @@ Use the --from flag to create from a remote branch with automatic naming:
 The operation is atomic - if any step fails, all successful operations will be
 rolled back to ensure no partial feature state remains.
 
-After creating worktrees, runs any setup script specified in the configuration.`,
+Repositories are validated, checked out and have their env files generated in
+parallel, up to --jobs at a time (max_parallel in ramp.yaml, default 4).
+
+After creating worktrees, runs any setup script specified in the configuration.
+
+Use --dry-run to see which repositories would be cloned or refreshed, how each
+worktree's branch would be created, which ports would be allocated and which
+scripts would run, without changing anything. Add --json for a machine-readable plan.`,
 	Args: cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		var featureName string
@@ -100,6 +110,8 @@ func init() {
 	upCmd.Flags().BoolVar(&refreshFlag, "refresh", false, "Force refresh all repositories before creating feature (overrides auto_refresh config)")
 	upCmd.Flags().BoolVar(&noRefreshFlag, "no-refresh", false, "Skip refresh for all repositories (overrides auto_refresh config)")
 	upCmd.Flags().StringVar(&displayNameFlag, "name", "", "Set a human-readable display name for this feature")
+	upCmd.Flags().IntVarP(&jobsFlag, "jobs", "j", 0, "Maximum number of repositories to process in parallel (defaults to config max_parallel, or 4)")
+	addDryRunFlags(upCmd, &upDryRun, &upJSON)
 }
 
 func runUp(featureName, prefix, target, displayName string) error {
@@ -146,18 +158,23 @@ func runUp(featureName, prefix, target, displayName string) error {
 		}
 	}
 
+	if err := checkDryRunFlags(upDryRun, upJSON); err != nil {
+		return err
+	}
+
 	// Auto-prompt for local config if needed (CLI-specific, uses interactive prompts)
-	if err := EnsureLocalConfig(projectDir, cfg); err != nil {
-		return fmt.Errorf("failed to configure local preferences: %w", err)
+	if !upDryRun {
+		if err := EnsureLocalConfig(projectDir, cfg); err != nil {
+			return fmt.Errorf("failed to configure local preferences: %w", err)
+		}
 	}
 
-	// Call operations.Up() with CLI progress reporter
 	// Auto-refresh respects per-repo config by default (no explicit flag needed)
-	result, err := operations.Up(operations.UpOptions{
+	opts := operations.UpOptions{
 		FeatureName: featureName,
 		ProjectDir:  projectDir,
 		Config:      cfg,
-		Progress:    operations.NewCLIProgressReporter(),
+		Progress:    planProgress(upJSON),
 		// Branch configuration
 		Prefix:   prefix,
 		NoPrefix: noPrefixFlag,
@@ -168,8 +185,20 @@ func runUp(featureName, prefix, target, displayName string) error {
 		SkipRefresh:  noRefreshFlag, // --no-refresh skips all refresh
 		// Display name (optional human-readable name)
 		DisplayName: displayName,
-	})
+		// Concurrency (0 = config max_parallel)
+		Workers: jobsFlag,
+	}
+
+	if upDryRun {
+		plan, err := operations.PlanUp(opts)
+		if err != nil {
+			return err
+		}
+		return printPlan(opts.Progress, plan, upJSON)
+	}
 
+	// Call operations.Up() with CLI progress reporter
+	result, err := operations.Up(opts)
 	if err != nil {
 		return err
 	}
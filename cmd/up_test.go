@@ -755,3 +755,34 @@ func TestUpWithEnvFilesMissing(t *testing.T) {
 		t.Error("destination .env should not exist when source is missing")
 	}
 }
+
+// TestUpDryRun tests that --dry-run plans the feature without creating it
+func TestUpDryRun(t *testing.T) {
+	tp := NewTestProject(t)
+	tp.InitRepo("repo1")
+
+	cleanup := tp.ChangeToProjectDir()
+	defer cleanup()
+
+	upDryRun = true
+	defer func() { upDryRun = false }()
+
+	if err := runUp("planned", "", "", ""); err != nil {
+		t.Fatalf("runUp() error = %v", err)
+	}
+
+	if tp.FeatureExists("planned") {
+		t.Error("feature should not be created by a dry run")
+	}
+	if tp.Repos["repo1"].BranchExists(t, "feature/planned") {
+		t.Error("branch should not be created by a dry run")
+	}
+
+	// --json on its own is rejected
+	upDryRun = false
+	upJSON = true
+	defer func() { upJSON = false }()
+	if err := runUp("planned", "", "", ""); err == nil || !strings.Contains(err.Error(), "--dry-run") {
+		t.Errorf("runUp() with --json but no --dry-run error = %v, want it rejected", err)
+	}
+}
@@ -5,16 +5,23 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 
 	"gopkg.in/yaml.v3"
 )
 
 type EnvFile struct {
-	Source  string            `yaml:"source"`
-	Dest    string            `yaml:"dest"`
-	Cache   string            `yaml:"cache,omitempty"`
-	Replace map[string]string `yaml:"replace,omitempty"`
+	Source   string            `yaml:"source"`
+	Dest     string            `yaml:"dest"`
+	Cache    string            `yaml:"cache,omitempty"`
+	Replace  map[string]string `yaml:"replace,omitempty"`
+	Merge    []string          `yaml:"merge,omitempty"`    // Additional sources layered over Source, in order
+	Template bool              `yaml:"template,omitempty"` // Render Source with text/template instead of ${VAR} substitution
+	Format   string            `yaml:"format,omitempty"`   // dotenv, json, yaml, toml or properties (inferred from Dest if empty)
+	Set      map[string]string `yaml:"set,omitempty"`      // Key paths to patch in the copied file (e.g. server.port)
+	Required []string          `yaml:"required,omitempty"` // Keys that must have a value once rendered
+	Optional []string          `yaml:"optional,omitempty"` // Placeholders that may be left unresolved
 }
 
 // UnmarshalYAML implements custom unmarshaling to support both simple string
@@ -47,6 +54,12 @@ type Repo struct {
 	LocalName   string    `yaml:"local_name,omitempty"`
 	AutoRefresh *bool     `yaml:"auto_refresh,omitempty"`
 	EnvFiles    []EnvFile `yaml:"env_files,omitempty"`
+	Filter      string    `yaml:"filter,omitempty"`     // Partial clone filter for ramp install, e.g. blob:none
+	Reference   string    `yaml:"reference,omitempty"`  // Local clone to share objects with (git clone --reference)
+	Sparse      []string  `yaml:"sparse,omitempty"`     // Directories to check out in feature worktrees (sparse-checkout cone patterns)
+	Submodules  string    `yaml:"submodules,omitempty"` // "true" or "recursive": init submodules in worktrees and on refresh
+	LFS         bool      `yaml:"lfs,omitempty"`        // Pull Git LFS objects in worktrees and on refresh
+	Upstream    string    `yaml:"upstream,omitempty"`   // Repository git: was forked from; features branch off its default branch
 }
 
 type Command struct {
@@ -64,6 +77,16 @@ type Hook struct {
 	BaseDir string `yaml:"-"`             // Set during merge, excluded from YAML
 }
 
+// SecretProvider configures a source for ${secret:<provider>/<path>} references in env files.
+type SecretProvider struct {
+	Type    string `yaml:"type"`              // file, pass, keychain or exec
+	Path    string `yaml:"path,omitempty"`    // file: encrypted secrets file, relative to the project (default .ramp/secrets.enc)
+	KeyEnv  string `yaml:"key_env,omitempty"` // file and cache: env var holding the passphrase (default RAMP_SECRETS_KEY)
+	Service string `yaml:"service,omitempty"` // keychain: service name (default ramp)
+	Command string `yaml:"command,omitempty"` // exec: shell command that prints the secret for $RAMP_SECRET_PATH
+	Cache   string `yaml:"cache,omitempty"`   // Keep resolved values, encrypted, for this duration (e.g. 1h)
+}
+
 type PromptOption struct {
 	Value string `yaml:"value"`
 	Label string `yaml:"label"`
@@ -77,17 +100,20 @@ type Prompt struct {
 }
 
 type Config struct {
-	Name                string     `yaml:"name"`
-	Repos               []*Repo    `yaml:"repos"`
-	Setup               string     `yaml:"setup,omitempty"`
-	Cleanup             string     `yaml:"cleanup,omitempty"`
-	DefaultBranchPrefix string     `yaml:"default-branch-prefix,omitempty"`
-	Commands            []*Command `yaml:"commands,omitempty"`
-	Hooks               []*Hook    `yaml:"hooks,omitempty"`
-	BasePort            int        `yaml:"base_port,omitempty"`
-	MaxPorts            int        `yaml:"max_ports,omitempty"`
-	PortsPerFeature     int        `yaml:"ports_per_feature,omitempty"`
-	Prompts             []*Prompt  `yaml:"prompts,omitempty"`
+	Name                string                     `yaml:"name"`
+	Repos               []*Repo                    `yaml:"repos"`
+	Setup               string                     `yaml:"setup,omitempty"`
+	Cleanup             string                     `yaml:"cleanup,omitempty"`
+	DefaultBranchPrefix string                     `yaml:"default-branch-prefix,omitempty"`
+	Commands            []*Command                 `yaml:"commands,omitempty"`
+	Hooks               []*Hook                    `yaml:"hooks,omitempty"`
+	BasePort            int                        `yaml:"base_port,omitempty"`
+	MaxPorts            int                        `yaml:"max_ports,omitempty"`
+	PortsPerFeature     int                        `yaml:"ports_per_feature,omitempty"`
+	PortNames           []string                   `yaml:"port_names,omitempty"` // Names for allocated ports, in order (e.g. web, api)
+	Prompts             []*Prompt                  `yaml:"prompts,omitempty"`
+	Secrets             map[string]*SecretProvider `yaml:"secrets,omitempty"`      // Keyed by provider name
+	MaxParallel         int                        `yaml:"max_parallel,omitempty"` // Repos processed at once by ramp up (default 4)
 }
 
 type LocalConfig struct {
@@ -162,6 +188,14 @@ func (c *Config) GetPortsPerFeature() int {
 	return c.PortsPerFeature
 }
 
+// GetMaxParallel returns how many repositories may be processed concurrently.
+func (c *Config) GetMaxParallel() int {
+	if c.MaxParallel <= 0 {
+		return 4 // Default worker limit
+	}
+	return c.MaxParallel
+}
+
 func (c *Config) HasPortConfig() bool {
 	return c.BasePort > 0 || c.MaxPorts > 0
 }
@@ -213,6 +247,18 @@ func (c *Config) ValidateRepoNames() error {
 	return nil
 }
 
+// ValidateRepoOptions checks per-repository settings that only allow certain values.
+func (c *Config) ValidateRepoOptions() error {
+	for _, repo := range c.Repos {
+		switch repo.Submodules {
+		case "", "true", "false", "recursive":
+		default:
+			return fmt.Errorf("repository %q: submodules must be true, false or recursive, got %q", repo.Name(), repo.Submodules)
+		}
+	}
+	return nil
+}
+
 func LoadConfig(projectDir string) (*Config, error) {
 	configPath := filepath.Join(projectDir, ".ramp", "ramp.yaml")
 	
@@ -229,6 +275,9 @@ func LoadConfig(projectDir string) (*Config, error) {
 	if err := config.ValidateRepoNames(); err != nil {
 		return nil, fmt.Errorf("invalid config %s: %w", configPath, err)
 	}
+	if err := config.ValidateRepoOptions(); err != nil {
+		return nil, fmt.Errorf("invalid config %s: %w", configPath, err)
+	}
 
 	return &config, nil
 }
@@ -271,6 +320,34 @@ func (r *Repo) GetGitURL() string {
 	return r.Git
 }
 
+// SubmodulesEnabled reports whether submodules should be initialized for this repo.
+func (r *Repo) SubmodulesEnabled() bool {
+	return r.Submodules == "true" || r.Submodules == "recursive"
+}
+
+// SubmodulesRecursive reports whether nested submodules should be initialized too.
+func (r *Repo) SubmodulesRecursive() bool {
+	return r.Submodules == "recursive"
+}
+
+// GetReferencePath returns the reference repository path, resolved against
+// projectDir when relative and with a leading ~ expanded. Empty if unset.
+func (r *Repo) GetReferencePath(projectDir string) string {
+	ref := r.Reference
+	if ref == "" {
+		return ""
+	}
+	if ref == "~" || strings.HasPrefix(ref, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			ref = filepath.Join(home, ref[1:])
+		}
+	}
+	if !filepath.IsAbs(ref) {
+		ref = filepath.Join(projectDir, ref)
+	}
+	return ref
+}
+
 // ShouldAutoRefresh returns true if this repository should be auto-refreshed.
 // Defaults to true if not explicitly set to false.
 func (r *Repo) ShouldAutoRefresh() bool {
@@ -282,9 +359,20 @@ func (r *Repo) ShouldAutoRefresh() bool {
 
 // GenerateEnvVarName generates an environment variable name from a repo name
 func GenerateEnvVarName(repoName string) string {
+	return "RAMP_REPO_PATH_" + envVarSuffix(repoName)
+}
+
+// GeneratePortEnvVarName generates the environment variable name for a named port
+// Example: "api" -> "RAMP_PORT_API"
+func GeneratePortEnvVarName(portName string) string {
+	return "RAMP_PORT_" + envVarSuffix(portName)
+}
+
+// envVarSuffix converts a name into an uppercase environment variable suffix
+func envVarSuffix(name string) string {
 	// Convert to uppercase and replace hyphens with underscores
 	re := regexp.MustCompile(`[^A-Za-z0-9_]`)
-	cleaned := re.ReplaceAllString(repoName, "_")
+	cleaned := re.ReplaceAllString(name, "_")
 	cleaned = strings.ToUpper(cleaned)
 
 	// Remove multiple consecutive underscores
@@ -292,9 +380,7 @@ func GenerateEnvVarName(repoName string) string {
 	cleaned = re.ReplaceAllString(cleaned, "_")
 
 	// Trim leading/trailing underscores
-	cleaned = strings.Trim(cleaned, "_")
-
-	return "RAMP_REPO_PATH_" + cleaned
+	return strings.Trim(cleaned, "_")
 }
 
 // SaveConfig writes a Config structure to ramp.yaml with nice formatting
@@ -325,11 +411,32 @@ func SaveConfig(cfg *Config, projectDir string) error {
 			if repo.AutoRefresh != nil {
 				yamlBuilder.WriteString(fmt.Sprintf("    auto_refresh: %t\n", *repo.AutoRefresh))
 			}
+			if repo.Upstream != "" {
+				yamlBuilder.WriteString(fmt.Sprintf("    upstream: %s\n", repo.Upstream))
+			}
+			if repo.Filter != "" {
+				yamlBuilder.WriteString(fmt.Sprintf("    filter: %s\n", repo.Filter))
+			}
+			if repo.Reference != "" {
+				yamlBuilder.WriteString(fmt.Sprintf("    reference: %s\n", repo.Reference))
+			}
+			if repo.Submodules != "" {
+				yamlBuilder.WriteString(fmt.Sprintf("    submodules: %s\n", repo.Submodules))
+			}
+			if repo.LFS {
+				yamlBuilder.WriteString("    lfs: true\n")
+			}
+			if len(repo.Sparse) > 0 {
+				yamlBuilder.WriteString("    sparse:\n")
+				for _, pattern := range repo.Sparse {
+					yamlBuilder.WriteString(fmt.Sprintf("      - %s\n", pattern))
+				}
+			}
 			if len(repo.EnvFiles) > 0 {
 				yamlBuilder.WriteString("    env_files:\n")
 				for _, envFile := range repo.EnvFiles {
-					// Simple syntax if source and dest are the same, no cache, and no replacements
-					if envFile.Source == envFile.Dest && envFile.Cache == "" && len(envFile.Replace) == 0 {
+					// Simple syntax if source and dest are the same, no cache, no replacements, no merges, not a template, no structured patches and no key checks
+					if envFile.Source == envFile.Dest && envFile.Cache == "" && len(envFile.Replace) == 0 && len(envFile.Merge) == 0 && !envFile.Template && envFile.Format == "" && len(envFile.Set) == 0 && len(envFile.Required) == 0 && len(envFile.Optional) == 0 {
 						yamlBuilder.WriteString(fmt.Sprintf("      - %s\n", envFile.Source))
 					} else {
 						// Full object syntax
@@ -344,6 +451,36 @@ func SaveConfig(cfg *Config, projectDir string) error {
 								yamlBuilder.WriteString(fmt.Sprintf("          %s: %q\n", key, value))
 							}
 						}
+						if envFile.Template {
+							yamlBuilder.WriteString("        template: true\n")
+						}
+						if envFile.Format != "" {
+							yamlBuilder.WriteString(fmt.Sprintf("        format: %s\n", envFile.Format))
+						}
+						if len(envFile.Set) > 0 {
+							yamlBuilder.WriteString("        set:\n")
+							for path, value := range envFile.Set {
+								yamlBuilder.WriteString(fmt.Sprintf("          %s: %q\n", path, value))
+							}
+						}
+						if len(envFile.Merge) > 0 {
+							yamlBuilder.WriteString("        merge:\n")
+							for _, source := range envFile.Merge {
+								yamlBuilder.WriteString(fmt.Sprintf("          - %s\n", source))
+							}
+						}
+						if len(envFile.Required) > 0 {
+							yamlBuilder.WriteString("        required:\n")
+							for _, key := range envFile.Required {
+								yamlBuilder.WriteString(fmt.Sprintf("          - %s\n", key))
+							}
+						}
+						if len(envFile.Optional) > 0 {
+							yamlBuilder.WriteString("        optional:\n")
+							for _, key := range envFile.Optional {
+								yamlBuilder.WriteString(fmt.Sprintf("          - %s\n", key))
+							}
+						}
 					}
 				}
 			}
@@ -366,6 +503,12 @@ func SaveConfig(cfg *Config, projectDir string) error {
 	if cfg.PortsPerFeature > 0 {
 		yamlBuilder.WriteString(fmt.Sprintf("ports_per_feature: %d\n", cfg.PortsPerFeature))
 	}
+	if len(cfg.PortNames) > 0 {
+		yamlBuilder.WriteString(fmt.Sprintf("port_names: [%s]\n", strings.Join(cfg.PortNames, ", ")))
+	}
+	if cfg.MaxParallel > 0 {
+		yamlBuilder.WriteString(fmt.Sprintf("max_parallel: %d\n", cfg.MaxParallel))
+	}
 
 	// Setup and cleanup scripts
 	if cfg.Setup != "" {
@@ -416,6 +559,36 @@ func SaveConfig(cfg *Config, projectDir string) error {
 		}
 	}
 
+	// Secrets section
+	if len(cfg.Secrets) > 0 {
+		yamlBuilder.WriteString("\nsecrets:\n")
+		names := make([]string, 0, len(cfg.Secrets))
+		for name := range cfg.Secrets {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			provider := cfg.Secrets[name]
+			yamlBuilder.WriteString(fmt.Sprintf("  %s:\n", name))
+			yamlBuilder.WriteString(fmt.Sprintf("    type: %s\n", provider.Type))
+			if provider.Path != "" {
+				yamlBuilder.WriteString(fmt.Sprintf("    path: %s\n", provider.Path))
+			}
+			if provider.KeyEnv != "" {
+				yamlBuilder.WriteString(fmt.Sprintf("    key_env: %s\n", provider.KeyEnv))
+			}
+			if provider.Service != "" {
+				yamlBuilder.WriteString(fmt.Sprintf("    service: %s\n", provider.Service))
+			}
+			if provider.Command != "" {
+				yamlBuilder.WriteString(fmt.Sprintf("    command: %q\n", provider.Command))
+			}
+			if provider.Cache != "" {
+				yamlBuilder.WriteString(fmt.Sprintf("    cache: %s\n", provider.Cache))
+			}
+		}
+	}
+
 	// Write to file
 	if err := os.WriteFile(configPath, []byte(yamlBuilder.String()), 0644); err != nil {
 		return fmt.Errorf("failed to write config file: %w", err)
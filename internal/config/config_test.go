@@ -119,6 +119,26 @@ func TestGenerateEnvVarName(t *testing.T) {
 	}
 }
 
+// TestGeneratePortEnvVarName tests env var names for named ports
+func TestGeneratePortEnvVarName(t *testing.T) {
+	tests := []struct {
+		portName string
+		want     string
+	}{
+		{portName: "api", want: "RAMP_PORT_API"},
+		{portName: "web-ui", want: "RAMP_PORT_WEB_UI"},
+		{portName: "2", want: "RAMP_PORT_2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.portName, func(t *testing.T) {
+			if got := GeneratePortEnvVarName(tt.portName); got != tt.want {
+				t.Errorf("GeneratePortEnvVarName(%q) = %q, want %q", tt.portName, got, tt.want)
+			}
+		})
+	}
+}
+
 // TestConfigDefaults tests default value handling for config fields
 func TestConfigDefaults(t *testing.T) {
 	t.Run("GetBasePort default", func(t *testing.T) {
@@ -149,6 +169,20 @@ func TestConfigDefaults(t *testing.T) {
 		}
 	})
 
+	t.Run("GetMaxParallel default", func(t *testing.T) {
+		cfg := &Config{}
+		if got := cfg.GetMaxParallel(); got != 4 {
+			t.Errorf("GetMaxParallel() = %d, want 4", got)
+		}
+	})
+
+	t.Run("GetMaxParallel custom", func(t *testing.T) {
+		cfg := &Config{MaxParallel: 12}
+		if got := cfg.GetMaxParallel(); got != 12 {
+			t.Errorf("GetMaxParallel() = %d, want 12", got)
+		}
+	})
+
 	t.Run("HasPortConfig false by default", func(t *testing.T) {
 		cfg := &Config{}
 		if got := cfg.HasPortConfig(); got != false {
@@ -1225,6 +1259,69 @@ func TestSaveConfigWithEnvFiles(t *testing.T) {
 	}
 }
 
+// TestSaveConfigWithEnvFileOptions tests that merge, template, format/set, required/optional and port_names survive a round trip
+func TestSaveConfigWithEnvFileOptions(t *testing.T) {
+	tempDir := t.TempDir()
+
+	cfg := &Config{
+		Name:            "test-project",
+		BasePort:        3000,
+		PortsPerFeature: 2,
+		PortNames:       []string{"web", "api"},
+		Repos: []*Repo{
+			{
+				Path: "repos",
+				Git:  "git@github.com:owner/app.git",
+				EnvFiles: []EnvFile{
+					{Source: ".env.example", Dest: ".env", Merge: []string{".env.local"}},
+					{Source: "nginx.conf.tmpl", Dest: "nginx.conf", Template: true},
+					{Source: "config/app.json", Dest: "config/local.json", Format: "json", Set: map[string]string{"server.port": "${RAMP_PORT}"}},
+					{Source: ".env.stripe", Dest: ".env.stripe", Required: []string{"STRIPE_KEY"}, Optional: []string{"SENTRY_DSN"}},
+				},
+			},
+		},
+	}
+
+	if err := SaveConfig(cfg, tempDir); err != nil {
+		t.Fatalf("SaveConfig() error = %v", err)
+	}
+
+	loaded, err := LoadConfig(tempDir)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+
+	if len(loaded.PortNames) != 2 || loaded.PortNames[0] != "web" || loaded.PortNames[1] != "api" {
+		t.Errorf("PortNames = %v, want [web api]", loaded.PortNames)
+	}
+
+	envFiles := loaded.Repos[0].EnvFiles
+	if len(envFiles) != 4 {
+		t.Fatalf("expected 4 env files, got %d", len(envFiles))
+	}
+	if len(envFiles[0].Merge) != 1 || envFiles[0].Merge[0] != ".env.local" {
+		t.Errorf("EnvFiles[0].Merge = %v, want [.env.local]", envFiles[0].Merge)
+	}
+	if !envFiles[1].Template {
+		t.Error("EnvFiles[1].Template should be true")
+	}
+	if envFiles[1].Dest != "nginx.conf" {
+		t.Errorf("EnvFiles[1].Dest = %q, want %q", envFiles[1].Dest, "nginx.conf")
+	}
+	if envFiles[2].Format != "json" {
+		t.Errorf("EnvFiles[2].Format = %q, want %q", envFiles[2].Format, "json")
+	}
+	if envFiles[2].Set["server.port"] != "${RAMP_PORT}" {
+		t.Errorf("EnvFiles[2].Set[server.port] = %q, want %q", envFiles[2].Set["server.port"], "${RAMP_PORT}")
+	}
+	if len(envFiles[3].Required) != 1 || envFiles[3].Required[0] != "STRIPE_KEY" {
+		t.Errorf("EnvFiles[3].Required = %v, want [STRIPE_KEY]", envFiles[3].Required)
+	}
+	if len(envFiles[3].Optional) != 1 || envFiles[3].Optional[0] != "SENTRY_DSN" {
+		t.Errorf("EnvFiles[3].Optional = %v, want [SENTRY_DSN]", envFiles[3].Optional)
+	}
+}
+
 // TestSaveConfigWithPrompts tests that SaveConfig preserves prompts
 func TestSaveConfigWithPrompts(t *testing.T) {
 	tempDir := t.TempDir()
@@ -1557,6 +1654,94 @@ func TestSaveConfigWithLocalName(t *testing.T) {
 	}
 }
 
+func TestSaveConfigWithCloneOptions(t *testing.T) {
+	tempDir := t.TempDir()
+
+	cfg := &Config{
+		Name: "test-project",
+		Repos: []*Repo{
+			{
+				Path:       "repos",
+				Git:        "git@github.com:owner/monorepo.git",
+				Filter:     "blob:none",
+				Reference:  "~/src/monorepo",
+				Submodules: "true",
+				LFS:        true,
+				Upstream:   "git@github.com:org/monorepo.git",
+			},
+		},
+	}
+
+	if err := SaveConfig(cfg, tempDir); err != nil {
+		t.Fatalf("SaveConfig() error = %v", err)
+	}
+
+	loaded, err := LoadConfig(tempDir)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+
+	if loaded.Repos[0].Filter != "blob:none" {
+		t.Errorf("Filter = %q, want %q", loaded.Repos[0].Filter, "blob:none")
+	}
+	if loaded.Repos[0].Reference != "~/src/monorepo" {
+		t.Errorf("Reference = %q, want %q", loaded.Repos[0].Reference, "~/src/monorepo")
+	}
+	if loaded.Repos[0].Submodules != "true" || !loaded.Repos[0].LFS {
+		t.Errorf("Submodules = %q, LFS = %v, want true, true", loaded.Repos[0].Submodules, loaded.Repos[0].LFS)
+	}
+	if loaded.Repos[0].Upstream != "git@github.com:org/monorepo.git" {
+		t.Errorf("Upstream = %q, want %q", loaded.Repos[0].Upstream, "git@github.com:org/monorepo.git")
+	}
+}
+
+func TestValidateRepoOptions(t *testing.T) {
+	for _, value := range []string{"", "true", "false", "recursive"} {
+		cfg := &Config{Repos: []*Repo{{Path: "repos", Git: "git@github.com:org/app.git", Submodules: value}}}
+		if err := cfg.ValidateRepoOptions(); err != nil {
+			t.Errorf("ValidateRepoOptions() with submodules %q error = %v", value, err)
+		}
+	}
+
+	cfg := &Config{Repos: []*Repo{{Path: "repos", Git: "git@github.com:org/app.git", Submodules: "yes"}}}
+	if err := cfg.ValidateRepoOptions(); err == nil {
+		t.Error("ValidateRepoOptions() should reject submodules: yes")
+	}
+
+	recursive := &Repo{Submodules: "recursive"}
+	if !recursive.SubmodulesEnabled() || !recursive.SubmodulesRecursive() {
+		t.Error("submodules: recursive should enable recursive submodules")
+	}
+	disabled := &Repo{Submodules: "false"}
+	if disabled.SubmodulesEnabled() {
+		t.Error("submodules: false should not enable submodules")
+	}
+}
+
+func TestGetReferencePath(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skip("no home directory")
+	}
+
+	tests := []struct {
+		reference string
+		want      string
+	}{
+		{"", ""},
+		{"/srv/mirrors/app", "/srv/mirrors/app"},
+		{"../mirrors/app", "/work/mirrors/app"},
+		{"~/src/app", filepath.Join(home, "src/app")},
+	}
+
+	for _, tt := range tests {
+		repo := &Repo{Reference: tt.reference}
+		if got := repo.GetReferencePath("/work/project"); got != tt.want {
+			t.Errorf("GetReferencePath() with reference %q = %q, want %q", tt.reference, got, tt.want)
+		}
+	}
+}
+
 // TestLoadConfigRejectsDuplicateNames tests that LoadConfig rejects configs with duplicate repo names
 func TestLoadConfigRejectsDuplicateNames(t *testing.T) {
 	tempDir := t.TempDir()
@@ -1584,3 +1769,35 @@ repos:
 		t.Error("LoadConfig() should return error for duplicate repo names")
 	}
 }
+
+func TestSaveConfigWithSecrets(t *testing.T) {
+	tempDir := t.TempDir()
+
+	cfg := &Config{
+		Name: "test-project",
+		Secrets: map[string]*SecretProvider{
+			"vault": {Type: "file", Path: ".ramp/dev.enc", KeyEnv: "DEV_KEY"},
+			"op":    {Type: "exec", Command: `op read "op://dev/$RAMP_SECRET_PATH"`, Cache: "1h"},
+			"mac":   {Type: "keychain", Service: "acme"},
+		},
+	}
+
+	if err := SaveConfig(cfg, tempDir); err != nil {
+		t.Fatalf("SaveConfig() error = %v", err)
+	}
+
+	loaded, err := LoadConfig(tempDir)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+
+	if len(loaded.Secrets) != 3 {
+		t.Fatalf("expected 3 secret providers, got %d", len(loaded.Secrets))
+	}
+	for name, want := range cfg.Secrets {
+		got := loaded.Secrets[name]
+		if got == nil || *got != *want {
+			t.Errorf("Secrets[%s] = %+v, want %+v", name, got, want)
+		}
+	}
+}
@@ -14,6 +14,7 @@ type MergedConfig struct {
 	BasePort            int
 	MaxPorts            int
 	PortsPerFeature     int
+	PortNames           []string
 	Prompts             []*Prompt
 
 	// Merged from all levels (project > local > user precedence for commands)
@@ -57,6 +58,7 @@ func MergeConfigs(projectCfg *Config, localCfg *LocalConfig, userCfg *UserConfig
 		BasePort:            projectCfg.BasePort,
 		MaxPorts:            projectCfg.MaxPorts,
 		PortsPerFeature:     projectCfg.PortsPerFeature,
+		PortNames:           projectCfg.PortNames,
 		Prompts:             projectCfg.Prompts,
 		ProjectConfig:       projectCfg,
 	}
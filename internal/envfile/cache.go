@@ -0,0 +1,230 @@
+package envfile
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Cache statuses reported for env scripts with a cache TTL
+const (
+	CacheHit     = "hit"     // Output served from a fresh cache entry
+	CacheMiss    = "miss"    // No entry for the current script content and inputs
+	CacheExpired = "expired" // Entry existed but was older than the TTL
+	CacheRefresh = "refresh" // Cache bypassed by --refresh
+)
+
+// CacheEvent records how a cached env script was resolved during rendering.
+type CacheEvent struct {
+	Script string // Absolute path of the script
+	Status string // CacheHit, CacheMiss, CacheExpired or CacheRefresh
+}
+
+// CacheEntry describes one cached script output. Entries are stored as
+// <key>.cache with a <key>.json sidecar holding this metadata.
+type CacheEntry struct {
+	Key     string    `json:"-"`
+	Script  string    `json:"script"`
+	Inputs  []string  `json:"inputs,omitempty"` // Env vars the script references, part of the key
+	TTL     string    `json:"ttl"`
+	Created time.Time `json:"created"`
+	Hits    int       `json:"hits"`
+	Misses  int       `json:"misses"`
+	Size    int64     `json:"-"` // Size of the cached output in bytes
+}
+
+// Expired reports whether the entry is older than its TTL.
+func (e CacheEntry) Expired() bool {
+	duration, err := time.ParseDuration(e.TTL)
+	if err != nil {
+		return true
+	}
+	return time.Since(e.Created) > duration
+}
+
+// EnvFilesCacheDir returns the directory holding cached env script output.
+func EnvFilesCacheDir(projectDir string) string {
+	return filepath.Join(projectDir, ".ramp", "cache", "env_files")
+}
+
+// cacheKey derives the cache key for a script from its path, its content and
+// the values of the env vars it references. Editing the script or changing
+// one of those variables yields a new key.
+func cacheKey(scriptPath string, content []byte, envVars map[string]string) (string, []string) {
+	inputs := referencedVars(string(content), envVars)
+
+	hash := sha256.New()
+	contentHash := sha256.Sum256(content)
+	fmt.Fprintf(hash, "%s\x00%x\x00", scriptPath, contentHash)
+	for _, name := range inputs {
+		fmt.Fprintf(hash, "%s=%s\x00", name, envVars[name])
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)[:16]), inputs
+}
+
+// identifierPattern matches shell-style variable names
+var identifierPattern = regexp.MustCompile(`[A-Za-z_][A-Za-z0-9_]*`)
+
+// referencedVars returns the envVars names that appear as identifiers in content, sorted
+func referencedVars(content string, envVars map[string]string) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, word := range identifierPattern.FindAllString(content, -1) {
+		if _, ok := envVars[word]; ok && !seen[word] {
+			seen[word] = true
+			names = append(names, word)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// cachePaths returns the output and sidecar paths for a cache key
+func cachePaths(projectDir string, key string) (string, string) {
+	base := filepath.Join(EnvFilesCacheDir(projectDir), key)
+	return base + ".cache", base + ".json"
+}
+
+// readCacheEntry loads the sidecar for a key
+func readCacheEntry(projectDir string, key string) (*CacheEntry, error) {
+	_, metaPath := cachePaths(projectDir, key)
+	data, err := os.ReadFile(metaPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var entry CacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, err
+	}
+	entry.Key = key
+	return &entry, nil
+}
+
+// writeCacheEntry saves the sidecar for an entry
+func writeCacheEntry(projectDir string, entry *CacheEntry) error {
+	_, metaPath := cachePaths(projectDir, entry.Key)
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(metaPath, data, 0644)
+}
+
+// checkCache looks up fresh cached output for a key and records the hit.
+// Returns the output and CacheHit, or nil and the reason for the miss.
+func checkCache(projectDir string, key string) ([]byte, string) {
+	entry, err := readCacheEntry(projectDir, key)
+	if err != nil {
+		return nil, CacheMiss
+	}
+	if entry.Expired() {
+		return nil, CacheExpired
+	}
+
+	outputPath, _ := cachePaths(projectDir, key)
+	content, err := os.ReadFile(outputPath)
+	if err != nil {
+		return nil, CacheMiss
+	}
+
+	entry.Hits++
+	writeCacheEntry(projectDir, entry)
+
+	return content, CacheHit
+}
+
+// cacheOutput saves script output and its sidecar, carrying over hit counts
+// from a previous entry with the same key
+func cacheOutput(projectDir string, key string, entry CacheEntry, output []byte) error {
+	if err := os.MkdirAll(EnvFilesCacheDir(projectDir), 0755); err != nil {
+		return err
+	}
+
+	entry.Key = key
+	entry.Created = time.Now()
+	entry.Misses = 1
+	if previous, err := readCacheEntry(projectDir, key); err == nil {
+		entry.Hits = previous.Hits
+		entry.Misses = previous.Misses + 1
+	}
+
+	outputPath, _ := cachePaths(projectDir, key)
+	if err := os.WriteFile(outputPath, output, 0644); err != nil {
+		return err
+	}
+
+	return writeCacheEntry(projectDir, &entry)
+}
+
+// ListCache returns every cached env script output, newest first.
+func ListCache(projectDir string) ([]CacheEntry, error) {
+	files, err := os.ReadDir(EnvFilesCacheDir(projectDir))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read cache directory: %w", err)
+	}
+
+	var entries []CacheEntry
+	for _, file := range files {
+		key, ok := strings.CutSuffix(file.Name(), ".json")
+		if !ok {
+			continue
+		}
+
+		entry, err := readCacheEntry(projectDir, key)
+		if err != nil {
+			continue
+		}
+		outputPath, _ := cachePaths(projectDir, key)
+		if info, err := os.Stat(outputPath); err == nil {
+			entry.Size = info.Size()
+		}
+		entries = append(entries, *entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Created.After(entries[j].Created)
+	})
+
+	return entries, nil
+}
+
+// ClearCache removes all cached env script output and returns the number of
+// files removed.
+func ClearCache(projectDir string) (int, error) {
+	return clearDir(EnvFilesCacheDir(projectDir))
+}
+
+// ClearSecretsCache removes all encrypted secret caches and returns the number
+// of files removed.
+func ClearSecretsCache(projectDir string) (int, error) {
+	return clearDir(filepath.Dir(SecretCachePath(projectDir, "")))
+}
+
+// clearDir removes a directory and returns how many files it held
+func clearDir(dir string) (int, error) {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to read %s: %w", dir, err)
+	}
+
+	if err := os.RemoveAll(dir); err != nil {
+		return 0, fmt.Errorf("failed to remove %s: %w", dir, err)
+	}
+
+	return len(files), nil
+}
@@ -0,0 +1,147 @@
+package envfile
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"ramp/internal/config"
+)
+
+// TestCacheKeyInvalidation tests that script edits and input changes bypass the cache
+func TestCacheKeyInvalidation(t *testing.T) {
+	projectDir := t.TempDir()
+	sourceRepoDir := filepath.Join(projectDir, "repos", "app")
+	os.MkdirAll(sourceRepoDir, 0755)
+
+	counter := filepath.Join(projectDir, "calls")
+	scriptPath := filepath.Join(sourceRepoDir, "env.sh")
+	writeScript := func(body string) {
+		os.WriteFile(scriptPath, []byte("#!/bin/bash\necho x >> "+counter+"\n"+body), 0755)
+	}
+	render := func(envVars map[string]string) RenderedFile {
+		t.Helper()
+		envFiles := []config.EnvFile{{Source: "env.sh", Dest: ".env", Cache: "1h"}}
+		rendered, err := RenderEnvFiles("app", envFiles, sourceRepoDir, envVars, false, projectDir, nil)
+		if err != nil {
+			t.Fatalf("RenderEnvFiles() error = %v", err)
+		}
+		return rendered[0]
+	}
+	assertEvent := func(file RenderedFile, want string) {
+		t.Helper()
+		if len(file.CacheEvents) != 1 || file.CacheEvents[0].Status != want {
+			t.Errorf("CacheEvents = %v, want one %s event", file.CacheEvents, want)
+		}
+	}
+
+	writeScript("echo \"PORT=$RAMP_PORT\"\n")
+	vars := map[string]string{"RAMP_PORT": "3000", "RAMP_WORKTREE_NAME": "one"}
+
+	assertEvent(render(vars), CacheMiss)
+	assertEvent(render(vars), CacheHit)
+
+	// A variable the script does not reference leaves the key unchanged
+	vars["RAMP_WORKTREE_NAME"] = "two"
+	assertEvent(render(vars), CacheHit)
+
+	// A referenced variable changes the key
+	vars["RAMP_PORT"] = "3100"
+	file := render(vars)
+	assertEvent(file, CacheMiss)
+	if file.Content != "PORT=3100\n" {
+		t.Errorf("Content = %q, want fresh output", file.Content)
+	}
+
+	// Editing the script changes the key
+	writeScript("echo \"PORT=$RAMP_PORT\"\necho DEBUG=1\n")
+	file = render(vars)
+	assertEvent(file, CacheMiss)
+	if file.Content != "PORT=3100\nDEBUG=1\n" {
+		t.Errorf("Content = %q, want output of edited script", file.Content)
+	}
+
+	calls, _ := os.ReadFile(counter)
+	if got := strings.Count(string(calls), "x"); got != 3 {
+		t.Errorf("script ran %d times, want 3", got)
+	}
+}
+
+// TestCacheExpiry tests that entries older than their TTL are re-executed
+func TestCacheExpiry(t *testing.T) {
+	projectDir := t.TempDir()
+	sourceRepoDir := filepath.Join(projectDir, "repos", "app")
+	os.MkdirAll(sourceRepoDir, 0755)
+	os.WriteFile(filepath.Join(sourceRepoDir, "env.sh"), []byte("#!/bin/bash\necho A=1\n"), 0755)
+
+	envFiles := []config.EnvFile{{Source: "env.sh", Dest: ".env", Cache: "1h"}}
+	if _, err := RenderEnvFiles("app", envFiles, sourceRepoDir, nil, false, projectDir, nil); err != nil {
+		t.Fatalf("RenderEnvFiles() error = %v", err)
+	}
+
+	entries, _ := ListCache(projectDir)
+	if len(entries) != 1 {
+		t.Fatalf("ListCache() returned %d entries, want 1", len(entries))
+	}
+	entry := entries[0]
+	entry.Created = time.Now().Add(-2 * time.Hour)
+	writeCacheEntry(projectDir, &entry)
+
+	rendered, err := RenderEnvFiles("app", envFiles, sourceRepoDir, nil, false, projectDir, nil)
+	if err != nil {
+		t.Fatalf("RenderEnvFiles() error = %v", err)
+	}
+	if events := rendered[0].CacheEvents; len(events) != 1 || events[0].Status != CacheExpired {
+		t.Errorf("CacheEvents = %v, want one expired event", events)
+	}
+
+	entries, _ = ListCache(projectDir)
+	if len(entries) != 1 || entries[0].Expired() || entries[0].Misses != 2 {
+		t.Errorf("ListCache() = %+v, want one fresh entry with 2 misses", entries)
+	}
+
+	rendered, _ = RenderEnvFiles("app", envFiles, sourceRepoDir, nil, true, projectDir, nil)
+	if events := rendered[0].CacheEvents; len(events) != 1 || events[0].Status != CacheRefresh {
+		t.Errorf("CacheEvents with refresh = %v, want one refresh event", events)
+	}
+}
+
+// TestListAndClearCache tests listing and removing cache entries
+func TestListAndClearCache(t *testing.T) {
+	projectDir := t.TempDir()
+
+	if entries, err := ListCache(projectDir); err != nil || len(entries) != 0 {
+		t.Errorf("ListCache() on empty project = %v, %v", entries, err)
+	}
+
+	vars := map[string]string{"RAMP_PORT": "3000"}
+	key, inputs := cacheKey("/repo/env.sh", []byte("echo $RAMP_PORT"), vars)
+	if len(inputs) != 1 || inputs[0] != "RAMP_PORT" {
+		t.Errorf("cacheKey() inputs = %v, want [RAMP_PORT]", inputs)
+	}
+	if err := cacheOutput(projectDir, key, CacheEntry{Script: "/repo/env.sh", Inputs: inputs, TTL: "1h"}, []byte("PORT=3000\n")); err != nil {
+		t.Fatalf("cacheOutput() error = %v", err)
+	}
+	if _, status := checkCache(projectDir, key); status != CacheHit {
+		t.Errorf("checkCache() status = %s, want hit", status)
+	}
+
+	entries, err := ListCache(projectDir)
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("ListCache() = %v, %v; want 1 entry", entries, err)
+	}
+	got := entries[0]
+	if got.Script != "/repo/env.sh" || got.Hits != 1 || got.Misses != 1 || got.Size != int64(len("PORT=3000\n")) {
+		t.Errorf("ListCache() entry = %+v", got)
+	}
+
+	removed, err := ClearCache(projectDir)
+	if err != nil || removed != 2 {
+		t.Errorf("ClearCache() = %d, %v; want 2 files removed", removed, err)
+	}
+	if entries, _ := ListCache(projectDir); len(entries) != 0 {
+		t.Errorf("ListCache() after clear = %v", entries)
+	}
+}
@@ -0,0 +1,89 @@
+package envfile
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"ramp/internal/config"
+)
+
+// placeholderPattern matches ${VARIABLE_NAME} placeholders left after substitution
+var placeholderPattern = regexp.MustCompile(`\$\{([A-Za-z0-9_]+)\}`)
+
+// Unresolved lists the values an env file is missing once rendered.
+type Unresolved struct {
+	Missing      []string // Required keys with no value
+	Placeholders []string // ${NAME} placeholders nothing provided, other than optional ones
+}
+
+// Empty reports whether nothing is unresolved.
+func (u Unresolved) Empty() bool {
+	return len(u.Missing) == 0 && len(u.Placeholders) == 0
+}
+
+// CheckEnvFile reports the required keys and placeholders envFile's rendered
+// content leaves without a value.
+//
+// A required key is missing if a ${KEY} placeholder for it remains or, for
+// dotenv files, if it is not assigned or assigned an empty value. Placeholders
+// are not reported for template files, which leave ${...} untouched on purpose,
+// nor for keys the same dotenv file assigns, which loaders expand at runtime.
+func CheckEnvFile(envFile config.EnvFile, content string) Unresolved {
+	var result Unresolved
+
+	remaining := make(map[string]bool)
+	for _, match := range placeholderPattern.FindAllStringSubmatch(content, -1) {
+		remaining[match[1]] = true
+	}
+
+	format, err := resolveFormat(envFile)
+	if err != nil {
+		format = formatDotenv
+	}
+
+	var dotenv *dotenvFile
+	if format == formatDotenv {
+		dotenv = parseDotenv(content)
+	}
+
+	for _, key := range envFile.Required {
+		if remaining[key] {
+			result.Missing = append(result.Missing, key)
+			continue
+		}
+		if dotenv != nil {
+			if value, ok := dotenv.Get(key); !ok || strings.TrimSpace(value) == "" || placeholderPattern.MatchString(value) {
+				result.Missing = append(result.Missing, key)
+			}
+		}
+	}
+
+	if envFile.Template {
+		return result
+	}
+
+	optional := make(map[string]bool, len(envFile.Optional)+len(result.Missing))
+	for _, name := range envFile.Optional {
+		optional[name] = true
+	}
+	// Missing required keys are already reported
+	for _, name := range result.Missing {
+		optional[name] = true
+	}
+
+	for name := range remaining {
+		if optional[name] {
+			continue
+		}
+		if dotenv != nil {
+			if value, ok := dotenv.Get(name); ok && !strings.Contains(value, "${"+name+"}") {
+				continue
+			}
+		}
+		result.Placeholders = append(result.Placeholders, name)
+	}
+	sort.Strings(result.Placeholders)
+
+	return result
+}
@@ -0,0 +1,99 @@
+package envfile
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"ramp/internal/config"
+)
+
+func TestCheckEnvFile(t *testing.T) {
+	tests := []struct {
+		name             string
+		envFile          config.EnvFile
+		content          string
+		wantMissing      []string
+		wantPlaceholders []string
+	}{
+		{
+			name:    "everything resolved",
+			envFile: config.EnvFile{Dest: ".env", Required: []string{"PORT"}},
+			content: "PORT=3000\n",
+		},
+		{
+			name:             "unresolved placeholder",
+			envFile:          config.EnvFile{Dest: ".env"},
+			content:          "PORT=3000\nSTRIPE_KEY=${STRIPE_KEY}\nURL=https://${API_HOST}/v1\n",
+			wantPlaceholders: []string{"API_HOST", "STRIPE_KEY"},
+		},
+		{
+			name:        "required placeholder is reported once as missing",
+			envFile:     config.EnvFile{Dest: ".env", Required: []string{"STRIPE_KEY"}},
+			content:     "STRIPE_KEY=${STRIPE_KEY}\n",
+			wantMissing: []string{"STRIPE_KEY"},
+		},
+		{
+			name:        "required key empty or absent",
+			envFile:     config.EnvFile{Dest: ".env", Required: []string{"STRIPE_KEY", "DATABASE_URL", "PORT"}},
+			content:     "STRIPE_KEY=\nPORT=3000\n",
+			wantMissing: []string{"STRIPE_KEY", "DATABASE_URL"},
+		},
+		{
+			name:    "optional placeholder",
+			envFile: config.EnvFile{Dest: ".env", Optional: []string{"SENTRY_DSN"}},
+			content: "SENTRY_DSN=${SENTRY_DSN}\n",
+		},
+		{
+			name:    "reference to a key assigned in the same file",
+			envFile: config.EnvFile{Dest: ".env"},
+			content: "DB_HOST=localhost\nDATABASE_URL=postgres://${DB_HOST}/app\n",
+		},
+		{
+			name:    "template files keep placeholders on purpose",
+			envFile: config.EnvFile{Dest: "docker-compose.yml", Template: true},
+			content: "image: app:${TAG}\n",
+		},
+		{
+			name:        "non-dotenv required key only checks placeholders",
+			envFile:     config.EnvFile{Dest: "config.json", Required: []string{"API_KEY", "PORT"}},
+			content:     `{"apiKey": "${API_KEY}", "port": 3000}`,
+			wantMissing: []string{"API_KEY"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CheckEnvFile(tt.envFile, tt.content)
+			if !reflect.DeepEqual(got.Missing, tt.wantMissing) {
+				t.Errorf("Missing = %v, want %v", got.Missing, tt.wantMissing)
+			}
+			if !reflect.DeepEqual(got.Placeholders, tt.wantPlaceholders) {
+				t.Errorf("Placeholders = %v, want %v", got.Placeholders, tt.wantPlaceholders)
+			}
+			if got.Empty() != (tt.wantMissing == nil && tt.wantPlaceholders == nil) {
+				t.Errorf("Empty() = %v", got.Empty())
+			}
+		})
+	}
+}
+
+// TestRenderEnvFilesUnresolved tests that rendering reports values ramp could not fill in
+func TestRenderEnvFilesUnresolved(t *testing.T) {
+	sourceRepoDir := t.TempDir()
+	os.WriteFile(filepath.Join(sourceRepoDir, ".env.example"), []byte("PORT=${RAMP_PORT}\nSTRIPE_KEY=${STRIPE_KEY}\n"), 0644)
+
+	envFiles := []config.EnvFile{{Source: ".env.example", Dest: ".env", Required: []string{"STRIPE_KEY"}}}
+	rendered, err := RenderEnvFiles("app", envFiles, sourceRepoDir, map[string]string{"RAMP_PORT": "3000"}, false, t.TempDir(), nil)
+	if err != nil {
+		t.Fatalf("RenderEnvFiles() error = %v", err)
+	}
+
+	if got := rendered[0].Unresolved.Missing; len(got) != 1 || got[0] != "STRIPE_KEY" {
+		t.Errorf("Unresolved.Missing = %v, want [STRIPE_KEY]", got)
+	}
+	if got := rendered[0].Unresolved.Placeholders; len(got) != 0 {
+		t.Errorf("Unresolved.Placeholders = %v, want none", got)
+	}
+}
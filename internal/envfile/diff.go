@@ -0,0 +1,176 @@
+package envfile
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines shown around each change
+const diffContext = 3
+
+// maxDiffCells bounds the LCS table; larger inputs are shown as a full replacement
+const maxDiffCells = 4_000_000
+
+type diffLine struct {
+	op   byte // ' ', '-' or '+'
+	text string
+}
+
+// UnifiedDiff returns a unified diff between two versions of a file, or an
+// empty string if they are identical. name is used in the ---/+++ headers.
+func UnifiedDiff(name string, oldContent string, newContent string) string {
+	if oldContent == newContent {
+		return ""
+	}
+
+	oldLines := splitDiffLines(oldContent)
+	newLines := splitDiffLines(newContent)
+	lines := diffLines(oldLines, newLines)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- a/%s\n+++ b/%s\n", name, name)
+
+	// Walk the edit script, emitting hunks of changes with surrounding context
+	oldLine, newLine := 1, 1
+	for i := 0; i < len(lines); {
+		if lines[i].op == ' ' {
+			oldLine++
+			newLine++
+			i++
+			continue
+		}
+
+		// Start the hunk up to diffContext lines before the change
+		start := i
+		for start > 0 && i-start < diffContext && lines[start-1].op == ' ' {
+			start--
+		}
+		hunkOld, hunkNew := oldLine-(i-start), newLine-(i-start)
+
+		// Extend the hunk until diffContext*2 unchanged lines separate it from the next change
+		end := i
+		for end < len(lines) {
+			if lines[end].op != ' ' {
+				end++
+				continue
+			}
+			run := end
+			for run < len(lines) && lines[run].op == ' ' {
+				run++
+			}
+			if run == len(lines) || run-end > diffContext*2 {
+				end += min(diffContext, run-end)
+				break
+			}
+			end = run
+		}
+
+		oldCount, newCount := 0, 0
+		for _, line := range lines[start:end] {
+			if line.op != '+' {
+				oldCount++
+			}
+			if line.op != '-' {
+				newCount++
+			}
+		}
+
+		fmt.Fprintf(&b, "@@ -%s +%s @@\n", hunkRange(hunkOld, oldCount), hunkRange(hunkNew, newCount))
+		for _, line := range lines[start:end] {
+			b.WriteByte(line.op)
+			b.WriteString(line.text)
+			b.WriteByte('\n')
+		}
+
+		for _, line := range lines[i:end] {
+			if line.op != '+' {
+				oldLine++
+			}
+			if line.op != '-' {
+				newLine++
+			}
+		}
+		i = end
+	}
+
+	return b.String()
+}
+
+// splitDiffLines splits content into lines, marking a missing final newline
+func splitDiffLines(content string) []string {
+	if content == "" {
+		return nil
+	}
+	lines := strings.Split(content, "\n")
+	if lines[len(lines)-1] == "" {
+		return lines[:len(lines)-1]
+	}
+	lines[len(lines)-1] += "\n\\ No newline at end of file"
+	return lines
+}
+
+// diffLines computes a line edit script using the longest common subsequence
+func diffLines(oldLines []string, newLines []string) []diffLine {
+	n, m := len(oldLines), len(newLines)
+	if n*m > maxDiffCells {
+		var lines []diffLine
+		for _, line := range oldLines {
+			lines = append(lines, diffLine{'-', line})
+		}
+		for _, line := range newLines {
+			lines = append(lines, diffLine{'+', line})
+		}
+		return lines
+	}
+
+	// lcs[i][j] is the LCS length of oldLines[i:] and newLines[j:]
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if oldLines[i] == newLines[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	lines := make([]diffLine, 0, n+m)
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case oldLines[i] == newLines[j]:
+			lines = append(lines, diffLine{' ', oldLines[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			lines = append(lines, diffLine{'-', oldLines[i]})
+			i++
+		default:
+			lines = append(lines, diffLine{'+', newLines[j]})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		lines = append(lines, diffLine{'-', oldLines[i]})
+	}
+	for ; j < m; j++ {
+		lines = append(lines, diffLine{'+', newLines[j]})
+	}
+
+	return lines
+}
+
+// hunkRange formats a hunk line range ("start,count", or "start" for one line)
+func hunkRange(start int, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start-1)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
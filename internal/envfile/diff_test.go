@@ -0,0 +1,67 @@
+package envfile
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestUnifiedDiff tests unified diff output
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name string
+		old  string
+		new  string
+		want string
+	}{
+		{
+			name: "identical",
+			old:  "A=1\n",
+			new:  "A=1\n",
+			want: "",
+		},
+		{
+			name: "single change",
+			old:  "A=1\nB=2\nC=3\n",
+			new:  "A=1\nB=20\nC=3\n",
+			want: "--- a/.env\n+++ b/.env\n@@ -1,3 +1,3 @@\n A=1\n-B=2\n+B=20\n C=3\n",
+		},
+		{
+			name: "new file",
+			old:  "",
+			new:  "A=1\nB=2\n",
+			want: "--- a/.env\n+++ b/.env\n@@ -0,0 +1,2 @@\n+A=1\n+B=2\n",
+		},
+		{
+			name: "separate hunks",
+			old:  "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n",
+			new:  "one\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\ntwelve\n",
+			want: "--- a/.env\n+++ b/.env\n@@ -1,4 +1,4 @@\n-1\n+one\n 2\n 3\n 4\n@@ -9,4 +9,4 @@\n 9\n 10\n 11\n-12\n+twelve\n",
+		},
+		{
+			name: "missing trailing newline",
+			old:  "A=1",
+			new:  "A=1\n",
+			want: "--- a/.env\n+++ b/.env\n@@ -1 +1 @@\n-A=1\n\\ No newline at end of file\n+A=1\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := UnifiedDiff(".env", tt.old, tt.new)
+			if got != tt.want {
+				t.Errorf("UnifiedDiff() mismatch\ngot:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestUnifiedDiffLargeInput tests that oversized inputs fall back to a full replacement
+func TestUnifiedDiffLargeInput(t *testing.T) {
+	old := strings.Repeat("a\n", 3000)
+	new := strings.Repeat("b\n", 3000)
+
+	got := UnifiedDiff("big", old, new)
+	if !strings.HasPrefix(got, "--- a/big\n+++ b/big\n@@ -1,3000 +1,3000 @@\n") {
+		t.Errorf("unexpected diff header: %q", got[:60])
+	}
+}
@@ -0,0 +1,282 @@
+package envfile
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// dotenvKeyPattern matches valid dotenv keys (POSIX-style names, plus dots and dashes
+// which several loaders accept)
+var dotenvKeyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.\-]*$`)
+
+// dotenvEntry is a single logical entry in a dotenv file: a comment, a blank line,
+// or a KEY=value assignment (which may span several physical lines when quoted)
+type dotenvEntry struct {
+	raw    string // Original text, written back verbatim unless the entry was modified
+	key    string // Empty for comments, blank lines and unparseable lines
+	value  string // Unquoted value
+	export bool   // Whether the assignment used the "export KEY=" form
+}
+
+// dotenvFile is a parsed dotenv document that preserves comments and ordering
+type dotenvFile struct {
+	entries         []*dotenvEntry
+	trailingNewline bool
+	appended        bool // Set when keys missing from the source were appended
+}
+
+// parseDotenv parses dotenv content into entries.
+// Lines that are not valid assignments are kept verbatim so nothing is lost on output.
+func parseDotenv(content string) *dotenvFile {
+	file := &dotenvFile{
+		trailingNewline: strings.HasSuffix(content, "\n"),
+	}
+
+	content = strings.TrimSuffix(content, "\n")
+	if content == "" {
+		return file
+	}
+
+	lines := strings.Split(content, "\n")
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			file.entries = append(file.entries, &dotenvEntry{raw: line})
+			continue
+		}
+
+		export := false
+		assignment := trimmed
+		if strings.HasPrefix(assignment, "export ") {
+			export = true
+			assignment = strings.TrimSpace(strings.TrimPrefix(assignment, "export "))
+		}
+
+		eq := strings.Index(assignment, "=")
+		if eq <= 0 {
+			file.entries = append(file.entries, &dotenvEntry{raw: line})
+			continue
+		}
+
+		key := strings.TrimSpace(assignment[:eq])
+		if !dotenvKeyPattern.MatchString(key) {
+			file.entries = append(file.entries, &dotenvEntry{raw: line})
+			continue
+		}
+
+		rawValue := strings.TrimLeft(assignment[eq+1:], " \t")
+		raw := line
+
+		// Quoted values may continue onto following lines until the closing quote
+		if quote := leadingQuote(rawValue); quote != 0 {
+			for closingQuoteIndex(rawValue, quote) < 0 && i+1 < len(lines) {
+				i++
+				rawValue += "\n" + lines[i]
+				raw += "\n" + lines[i]
+			}
+		}
+
+		file.entries = append(file.entries, &dotenvEntry{
+			raw:    raw,
+			key:    key,
+			value:  parseDotenvValue(rawValue),
+			export: export,
+		})
+	}
+
+	return file
+}
+
+// leadingQuote returns the quote character a value starts with, or 0 if unquoted
+func leadingQuote(value string) byte {
+	if value == "" {
+		return 0
+	}
+	switch value[0] {
+	case '"', '\'', '`':
+		return value[0]
+	}
+	return 0
+}
+
+// closingQuoteIndex returns the index of the quote that closes a quoted value,
+// or -1 if the value is not terminated. Backslash escapes are honored in double quotes.
+func closingQuoteIndex(value string, quote byte) int {
+	for i := 1; i < len(value); i++ {
+		if quote == '"' && value[i] == '\\' {
+			i++
+			continue
+		}
+		if value[i] == quote {
+			return i
+		}
+	}
+	return -1
+}
+
+// parseDotenvValue converts the raw text after "=" into the value it represents
+func parseDotenvValue(rawValue string) string {
+	quote := leadingQuote(rawValue)
+	if quote == 0 {
+		// Unquoted values end at an inline comment (" #")
+		if idx := strings.Index(rawValue, " #"); idx >= 0 {
+			rawValue = rawValue[:idx]
+		}
+		if idx := strings.Index(rawValue, "\t#"); idx >= 0 {
+			rawValue = rawValue[:idx]
+		}
+		return strings.TrimSpace(rawValue)
+	}
+
+	end := closingQuoteIndex(rawValue, quote)
+	if end < 0 {
+		// Unterminated quote - take everything after the opening quote
+		return rawValue[1:]
+	}
+
+	inner := rawValue[1:end]
+	if quote != '"' {
+		return inner
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(inner); i++ {
+		if inner[i] == '\\' && i+1 < len(inner) {
+			i++
+			switch inner[i] {
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			default:
+				b.WriteByte(inner[i])
+			}
+			continue
+		}
+		b.WriteByte(inner[i])
+	}
+	return b.String()
+}
+
+// Get returns the value of the last assignment of key (dotenv semantics: last one wins)
+func (f *dotenvFile) Get(key string) (string, bool) {
+	if entry := f.find(key); entry != nil {
+		return entry.value, true
+	}
+	return "", false
+}
+
+// Keys returns all assigned keys in file order, without duplicates
+func (f *dotenvFile) Keys() []string {
+	seen := make(map[string]bool)
+	var keys []string
+	for _, entry := range f.entries {
+		if entry.key != "" && !seen[entry.key] {
+			seen[entry.key] = true
+			keys = append(keys, entry.key)
+		}
+	}
+	return keys
+}
+
+// Set assigns a value to key. An existing assignment is rewritten in place
+// (keeping its export prefix); a missing key is appended to the end of the file.
+func (f *dotenvFile) Set(key, value string) {
+	if entry := f.find(key); entry != nil {
+		entry.value = value
+		entry.raw = formatDotenvAssignment(key, value, entry.export)
+		return
+	}
+
+	f.entries = append(f.entries, &dotenvEntry{
+		raw:   formatDotenvAssignment(key, value, false),
+		key:   key,
+		value: value,
+	})
+	f.appended = true
+}
+
+// SetAll assigns every key in values. Keys missing from the file are appended
+// in sorted order so the output is deterministic.
+func (f *dotenvFile) SetAll(values map[string]string) {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		f.Set(key, values[key])
+	}
+}
+
+// Merge layers another dotenv file on top of this one: its assignments override
+// existing keys or are appended. Comments in the overlay are not copied.
+func (f *dotenvFile) Merge(overlay *dotenvFile) {
+	for _, entry := range overlay.entries {
+		if entry.key != "" {
+			f.Set(entry.key, entry.value)
+		}
+	}
+}
+
+// String renders the file, keeping untouched entries byte-for-byte
+func (f *dotenvFile) String() string {
+	lines := make([]string, 0, len(f.entries))
+	for _, entry := range f.entries {
+		lines = append(lines, entry.raw)
+	}
+
+	result := strings.Join(lines, "\n")
+	if len(lines) > 0 && (f.trailingNewline || f.appended) {
+		result += "\n"
+	}
+	return result
+}
+
+func (f *dotenvFile) find(key string) *dotenvEntry {
+	for i := len(f.entries) - 1; i >= 0; i-- {
+		if f.entries[i].key == key {
+			return f.entries[i]
+		}
+	}
+	return nil
+}
+
+// formatDotenvAssignment renders KEY=value, quoting the value when needed
+func formatDotenvAssignment(key, value string, export bool) string {
+	prefix := ""
+	if export {
+		prefix = "export "
+	}
+	return prefix + key + "=" + quoteDotenvValue(value)
+}
+
+// quoteDotenvValue double-quotes a value if it contains characters that would
+// otherwise change its meaning (whitespace at the edges, quotes, comments, newlines)
+func quoteDotenvValue(value string) string {
+	if !needsDotenvQuoting(value) {
+		return value
+	}
+
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`)
+	return `"` + replacer.Replace(value) + `"`
+}
+
+func needsDotenvQuoting(value string) bool {
+	if value == "" {
+		return false
+	}
+	if strings.TrimSpace(value) != value {
+		return true
+	}
+	if leadingQuote(value) != 0 {
+		return true
+	}
+	return strings.ContainsAny(value, "\n\r\\\"' \t#")
+}
@@ -0,0 +1,162 @@
+package envfile
+
+import (
+	"testing"
+)
+
+// TestParseDotenv tests parsing values in the common dotenv forms
+func TestParseDotenv(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		key     string
+		want    string
+	}{
+		{
+			name:    "plain value",
+			content: "PORT=3000\n",
+			key:     "PORT",
+			want:    "3000",
+		},
+		{
+			name:    "export prefix",
+			content: "export PORT=3000\n",
+			key:     "PORT",
+			want:    "3000",
+		},
+		{
+			name:    "double quoted with escapes",
+			content: `GREETING="hello \"world\"\nbye"` + "\n",
+			key:     "GREETING",
+			want:    "hello \"world\"\nbye",
+		},
+		{
+			name:    "single quoted is literal",
+			content: `RAW='a\nb #c'` + "\n",
+			key:     "RAW",
+			want:    `a\nb #c`,
+		},
+		{
+			name:    "inline comment stripped from unquoted value",
+			content: "DEBUG=true # enable debug\n",
+			key:     "DEBUG",
+			want:    "true",
+		},
+		{
+			name:    "multi-line quoted value",
+			content: "KEY=\"-----BEGIN-----\nabc\n-----END-----\"\nNEXT=1\n",
+			key:     "KEY",
+			want:    "-----BEGIN-----\nabc\n-----END-----",
+		},
+		{
+			name:    "last assignment wins",
+			content: "PORT=3000\nPORT=4000\n",
+			key:     "PORT",
+			want:    "4000",
+		},
+		{
+			name:    "spaces around equals",
+			content: "NAME = value\n",
+			key:     "NAME",
+			want:    "value",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseDotenv(tt.content).Get(tt.key)
+			if !ok {
+				t.Fatalf("key %s not found", tt.key)
+			}
+			if got != tt.want {
+				t.Errorf("Get(%q) = %q, want %q", tt.key, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestDotenvRoundTrip tests that unmodified content is preserved exactly
+func TestDotenvRoundTrip(t *testing.T) {
+	contents := []string{
+		"",
+		"PORT=3000\n",
+		"PORT=3000",
+		"# comment\n\nexport A=1\nB=\"multi\nline\"\n  C = spaced # note\nnot an assignment\n",
+	}
+
+	for _, content := range contents {
+		if got := parseDotenv(content).String(); got != content {
+			t.Errorf("round trip mismatch\ngot:  %q\nwant: %q", got, content)
+		}
+	}
+}
+
+// TestDotenvSet tests updating and appending keys
+func TestDotenvSet(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		values  map[string]string
+		want    string
+	}{
+		{
+			name:    "replace existing key keeps comments and order",
+			content: "# App\nPORT=3000\nDEBUG=true\n",
+			values:  map[string]string{"PORT": "4000"},
+			want:    "# App\nPORT=4000\nDEBUG=true\n",
+		},
+		{
+			name:    "export prefix preserved",
+			content: "export PORT=3000\n",
+			values:  map[string]string{"PORT": "4000"},
+			want:    "export PORT=4000\n",
+		},
+		{
+			name:    "quoted multi-line value replaced",
+			content: "KEY=\"line1\nline2\"\nNEXT=1\n",
+			values:  map[string]string{"KEY": "short"},
+			want:    "KEY=short\nNEXT=1\n",
+		},
+		{
+			name:    "missing keys appended in sorted order",
+			content: "PORT=3000\n",
+			values:  map[string]string{"B_KEY": "b", "A_KEY": "a"},
+			want:    "PORT=3000\nA_KEY=a\nB_KEY=b\n",
+		},
+		{
+			name:    "append to content without trailing newline",
+			content: "PORT=3000",
+			values:  map[string]string{"NEW": "1"},
+			want:    "PORT=3000\nNEW=1\n",
+		},
+		{
+			name:    "values that need quoting",
+			content: "NAME=x\n",
+			values:  map[string]string{"NAME": "my app # 1", "MULTI": "a\nb"},
+			want:    "NAME=\"my app # 1\"\nMULTI=\"a\\nb\"\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := parseDotenv(tt.content)
+			file.SetAll(tt.values)
+			if got := file.String(); got != tt.want {
+				t.Errorf("String() mismatch\ngot:  %q\nwant: %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestDotenvMerge tests layering one file over another
+func TestDotenvMerge(t *testing.T) {
+	base := parseDotenv("# base\nPORT=3000\nDEBUG=false\n")
+	overlay := parseDotenv("# overlay comment\nDEBUG=true\nAPI_KEY=abc\n")
+
+	base.Merge(overlay)
+
+	want := "# base\nPORT=3000\nDEBUG=true\nAPI_KEY=abc\n"
+	if got := base.String(); got != want {
+		t.Errorf("Merge() mismatch\ngot:  %q\nwant: %q", got, want)
+	}
+}
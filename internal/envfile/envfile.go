@@ -1,15 +1,11 @@
 package envfile
 
 import (
-	"crypto/sha256"
-	"encoding/hex"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"regexp"
-	"strings"
-	"time"
 
 	"ramp/internal/config"
 	"ramp/internal/ui"
@@ -32,8 +28,13 @@ func ProcessEnvFiles(repoName string, envFiles []config.EnvFile, sourceRepoDir s
 // ProcessEnvFilesWithProjectDir is the internal version that accepts an explicit projectDir
 // This is useful for testing
 func ProcessEnvFilesWithProjectDir(repoName string, envFiles []config.EnvFile, sourceRepoDir string, worktreeDir string, envVars map[string]string, shouldRefresh bool, projectDir string) error {
-	for _, envFile := range envFiles {
-		if err := processEnvFile(repoName, envFile, sourceRepoDir, worktreeDir, envVars, shouldRefresh, projectDir); err != nil {
+	rendered, err := RenderEnvFiles(repoName, envFiles, sourceRepoDir, envVars, shouldRefresh, projectDir, nil)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range rendered {
+		if err := WriteEnvFile(worktreeDir, file); err != nil {
 			return err
 		}
 	}
@@ -41,52 +42,135 @@ func ProcessEnvFilesWithProjectDir(repoName string, envFiles []config.EnvFile, s
 	return nil
 }
 
-// processEnvFile processes a single env file configuration
-func processEnvFile(repoName string, envFile config.EnvFile, sourceRepoDir string, worktreeDir string, envVars map[string]string, shouldRefresh bool, projectDir string) error {
+// RenderedFile is the generated content of one env file, before it is written
+type RenderedFile struct {
+	Dest        string // Destination path relative to the worktree
+	Content     string
+	Redacted    string       // Content with ${secret:...} references left unresolved
+	CacheEvents []CacheEvent // Cache outcome for each cached script that was read
+	Unresolved  Unresolved   // Required keys and placeholders left without a value
+}
+
+// RenderEnvFiles generates the content of each env file without writing anything.
+// Files whose source does not exist are skipped with a warning.
+// Secret references are resolved last, after script output has been read from or
+// written to the cache, so cached content never holds secret values. A nil
+// secrets resolver fails on any secret reference.
+func RenderEnvFiles(repoName string, envFiles []config.EnvFile, sourceRepoDir string, envVars map[string]string, shouldRefresh bool, projectDir string, secrets *SecretResolver) ([]RenderedFile, error) {
+	var rendered []RenderedFile
+
+	for _, envFile := range envFiles {
+		var events []CacheEvent
+		content, found, err := renderEnvFile(repoName, envFile, sourceRepoDir, envVars, shouldRefresh, projectDir, &events)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			continue
+		}
+
+		resolved, err := secrets.Expand(content)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve secrets for %s: %w", envFile.Dest, err)
+		}
+		rendered = append(rendered, RenderedFile{
+			Dest:        envFile.Dest,
+			Content:     resolved,
+			Redacted:    content,
+			CacheEvents: events,
+			Unresolved:  CheckEnvFile(envFile, resolved),
+		})
+	}
+
+	return rendered, nil
+}
+
+// WriteEnvFile writes a rendered env file into a worktree, creating parent directories
+func WriteEnvFile(worktreeDir string, file RenderedFile) error {
+	// Resolve destination path (relative to worktree directory)
+	destPath := filepath.Join(worktreeDir, file.Dest)
+
+	// Create parent directory if needed
+	destDir := filepath.Dir(destPath)
+	if err := os.MkdirAll(destDir, 0755); err != nil {
+		return fmt.Errorf("failed to create directory %s: %w", destDir, err)
+	}
+
+	// Write destination file
+	if err := os.WriteFile(destPath, []byte(file.Content), 0644); err != nil {
+		return fmt.Errorf("failed to write destination env file %s: %w", destPath, err)
+	}
+
+	return nil
+}
+
+// renderEnvFile processes a single env file configuration and returns its content.
+// Returns found=false if the source does not exist.
+func renderEnvFile(repoName string, envFile config.EnvFile, sourceRepoDir string, envVars map[string]string, shouldRefresh bool, projectDir string, events *[]CacheEvent) (string, bool, error) {
 	// Resolve source path (relative to source repo directory)
 	sourcePath := filepath.Join(sourceRepoDir, envFile.Source)
 
 	// Get content from either file or script
-	content, err := getContent(sourcePath, envFile.Cache, envVars, shouldRefresh, projectDir)
+	content, err := getContent(sourcePath, envFile.Cache, envVars, shouldRefresh, projectDir, events)
 	if err != nil {
 		// Check if it's a missing file
 		if os.IsNotExist(err) {
 			ui.Warning(fmt.Sprintf("Source env file not found for %s: %s", repoName, sourcePath))
-			return nil
+			return "", false, nil
 		}
-		return err
+		return "", false, err
 	}
 
 	contentStr := string(content)
 
+	// Layer additional sources on top of the base file
+	if len(envFile.Merge) > 0 {
+		contentStr, err = mergeSources(repoName, contentStr, envFile, sourceRepoDir, envVars, shouldRefresh, projectDir, events)
+		if err != nil {
+			return "", false, err
+		}
+	}
+
+	// Render Go templates before any key replacements
+	if envFile.Template {
+		contentStr, err = renderTemplate(envFile.Source, contentStr, envVars)
+		if err != nil {
+			return "", false, err
+		}
+	}
+
 	// Perform variable replacements
 	if envFile.Replace != nil && len(envFile.Replace) > 0 {
 		// Explicit replacements: only replace specified keys
 		contentStr = replaceExplicitKeys(contentStr, envFile.Replace, envVars)
-	} else {
+	} else if !envFile.Template {
 		// Auto-replace: replace all ${RAMP_*} variables
 		contentStr = replaceEnvVars(contentStr, envVars)
 	}
 
-	// Resolve destination path (relative to worktree directory)
-	destPath := filepath.Join(worktreeDir, envFile.Dest)
+	// Patch structured key paths (server.port, etc.) in the file's own format
+	if len(envFile.Set) > 0 {
+		format, err := resolveFormat(envFile)
+		if err != nil {
+			return "", false, err
+		}
 
-	// Create parent directory if needed
-	destDir := filepath.Dir(destPath)
-	if err := os.MkdirAll(destDir, 0755); err != nil {
-		return fmt.Errorf("failed to create directory %s: %w", destDir, err)
-	}
+		values := make(map[string]string, len(envFile.Set))
+		for path, value := range envFile.Set {
+			values[path] = replaceEnvVars(value, envVars)
+		}
 
-	// Write destination file
-	if err := os.WriteFile(destPath, []byte(contentStr), 0644); err != nil {
-		return fmt.Errorf("failed to write destination env file %s: %w", destPath, err)
+		contentStr, err = applySet(format, contentStr, values)
+		if err != nil {
+			return "", false, fmt.Errorf("failed to update %s: %w", envFile.Dest, err)
+		}
 	}
 
-	return nil
+	return contentStr, true, nil
 }
 
 // getContent retrieves content from either a regular file or an executable script
-func getContent(sourcePath string, cacheTTL string, envVars map[string]string, shouldRefresh bool, projectDir string) ([]byte, error) {
+func getContent(sourcePath string, cacheTTL string, envVars map[string]string, shouldRefresh bool, projectDir string, events *[]CacheEvent) ([]byte, error) {
 	// Check if source exists
 	info, err := os.Stat(sourcePath)
 	if err != nil {
@@ -95,7 +179,7 @@ func getContent(sourcePath string, cacheTTL string, envVars map[string]string, s
 
 	// Check if it's executable
 	if isExecutable(info) {
-		return executeScript(sourcePath, cacheTTL, envVars, shouldRefresh, projectDir)
+		return executeScript(sourcePath, cacheTTL, envVars, shouldRefresh, projectDir, events)
 	}
 
 	// Regular file - read it
@@ -108,14 +192,31 @@ func isExecutable(info os.FileInfo) bool {
 }
 
 // executeScript runs an executable script and returns its output
-// Handles caching if cacheTTL is set
-func executeScript(scriptPath string, cacheTTL string, envVars map[string]string, shouldRefresh bool, projectDir string) ([]byte, error) {
-	// Check cache if TTL is specified and refresh is not forced
-	if cacheTTL != "" && !shouldRefresh {
-		cacheContent, cacheHit := checkCache(scriptPath, cacheTTL, projectDir)
-		if cacheHit {
-			return cacheContent, nil
+// Handles caching if cacheTTL is set, recording the cache outcome in events
+func executeScript(scriptPath string, cacheTTL string, envVars map[string]string, shouldRefresh bool, projectDir string, events *[]CacheEvent) ([]byte, error) {
+	var key string
+	var entry CacheEntry
+	if cacheTTL != "" {
+		script, err := os.ReadFile(scriptPath)
+		if err != nil {
+			return nil, err
+		}
+
+		key, entry.Inputs = cacheKey(scriptPath, script, envVars)
+		entry.Script = scriptPath
+		entry.TTL = cacheTTL
+
+		// Check cache unless refresh is forced
+		status := CacheRefresh
+		if !shouldRefresh {
+			var cacheContent []byte
+			cacheContent, status = checkCache(projectDir, key)
+			if status == CacheHit {
+				recordCacheEvent(events, scriptPath, status)
+				return cacheContent, nil
+			}
 		}
+		recordCacheEvent(events, scriptPath, status)
 	}
 
 	// Execute the script through a login shell to ensure user's PATH is available
@@ -136,7 +237,7 @@ func executeScript(scriptPath string, cacheTTL string, envVars map[string]string
 
 	// Cache output if TTL is specified
 	if cacheTTL != "" {
-		if err := cacheOutput(scriptPath, output, projectDir); err != nil {
+		if err := cacheOutput(projectDir, key, entry, output); err != nil {
 			// Log warning but don't fail
 			ui.Warning(fmt.Sprintf("Failed to cache script output: %v", err))
 		}
@@ -145,60 +246,11 @@ func executeScript(scriptPath string, cacheTTL string, envVars map[string]string
 	return output, nil
 }
 
-// checkCache checks if a valid cache exists for a script
-// Returns (content, true) if cache hit, (nil, false) if cache miss
-func checkCache(scriptPath string, cacheTTL string, projectDir string) ([]byte, bool) {
-	cachePath := getCachePath(scriptPath, projectDir)
-
-	// Check if cache file exists
-	info, err := os.Stat(cachePath)
-	if err != nil {
-		return nil, false
+// recordCacheEvent appends a cache event if the caller is collecting them
+func recordCacheEvent(events *[]CacheEvent, scriptPath string, status string) {
+	if events != nil {
+		*events = append(*events, CacheEvent{Script: scriptPath, Status: status})
 	}
-
-	// Parse TTL duration
-	duration, err := time.ParseDuration(cacheTTL)
-	if err != nil {
-		// Invalid TTL format, treat as no cache
-		return nil, false
-	}
-
-	// Check if cache is still valid
-	if time.Since(info.ModTime()) > duration {
-		// Cache expired
-		return nil, false
-	}
-
-	// Read cache content
-	content, err := os.ReadFile(cachePath)
-	if err != nil {
-		return nil, false
-	}
-
-	return content, true
-}
-
-// cacheOutput saves script output to cache
-func cacheOutput(scriptPath string, output []byte, projectDir string) error {
-	cachePath := getCachePath(scriptPath, projectDir)
-
-	// Create cache directory
-	cacheDir := filepath.Dir(cachePath)
-	if err := os.MkdirAll(cacheDir, 0755); err != nil {
-		return err
-	}
-
-	// Write cache file
-	return os.WriteFile(cachePath, output, 0644)
-}
-
-// getCachePath generates a cache file path for a script
-func getCachePath(scriptPath string, projectDir string) string {
-	// Generate a hash of the script path for the cache key
-	hash := sha256.Sum256([]byte(scriptPath))
-	cacheKey := hex.EncodeToString(hash[:8])
-
-	return filepath.Join(projectDir, ".ramp", "cache", "env_files", cacheKey+".cache")
 }
 
 // buildScriptEnv builds the environment variable array for script execution
@@ -214,31 +266,43 @@ func buildScriptEnv(envVars map[string]string) []string {
 	return env
 }
 
-// replaceExplicitKeys replaces only the specified keys with their replacement values
-// Keys are matched as "KEY=..." patterns at the start of lines
-func replaceExplicitKeys(content string, replacements map[string]string, envVars map[string]string) string {
-	lines := strings.Split(content, "\n")
+// mergeSources layers each source listed in envFile.Merge over the base content.
+// Later sources win; keys they define that the base lacks are appended.
+func mergeSources(repoName string, base string, envFile config.EnvFile, sourceRepoDir string, envVars map[string]string, shouldRefresh bool, projectDir string, events *[]CacheEvent) (string, error) {
+	merged := parseDotenv(base)
 
-	for i, line := range lines {
-		// Skip empty lines and comments
-		trimmed := strings.TrimSpace(line)
-		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
-			continue
-		}
+	for _, source := range envFile.Merge {
+		sourcePath := filepath.Join(sourceRepoDir, source)
 
-		// Check if this line starts with one of our replacement keys
-		for key, replacementValue := range replacements {
-			if strings.HasPrefix(trimmed, key+"=") {
-				// Replace the value after the =
-				// First expand any ${RAMP_*} variables in the replacement value
-				expandedValue := replaceEnvVars(replacementValue, envVars)
-				lines[i] = key + "=" + expandedValue
-				break
+		content, err := getContent(sourcePath, envFile.Cache, envVars, shouldRefresh, projectDir, events)
+		if err != nil {
+			if os.IsNotExist(err) {
+				ui.Warning(fmt.Sprintf("Merge source not found for %s: %s", repoName, sourcePath))
+				continue
 			}
+			return "", err
 		}
+
+		merged.Merge(parseDotenv(string(content)))
+	}
+
+	return merged.String(), nil
+}
+
+// replaceExplicitKeys replaces only the specified keys with their replacement values.
+// Assignments are matched by key (including "export KEY=" and quoted values);
+// keys missing from the content are appended.
+func replaceExplicitKeys(content string, replacements map[string]string, envVars map[string]string) string {
+	file := parseDotenv(content)
+
+	expanded := make(map[string]string, len(replacements))
+	for key, replacementValue := range replacements {
+		// Expand any ${RAMP_*} variables in the replacement value
+		expanded[key] = replaceEnvVars(replacementValue, envVars)
 	}
+	file.SetAll(expanded)
 
-	return strings.Join(lines, "\n")
+	return file.String()
 }
 
 // replaceEnvVars replaces all ${VARIABLE_NAME} patterns with their values from envVars
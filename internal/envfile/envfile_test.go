@@ -174,6 +174,96 @@ APP_NAME=default
 		}
 	})
 
+	t.Run("replace handles export, quotes and missing keys", func(t *testing.T) {
+		tempDir := t.TempDir()
+		sourceRepoDir := filepath.Join(tempDir, "source")
+		worktreeDir := filepath.Join(tempDir, "worktree")
+
+		os.MkdirAll(sourceRepoDir, 0755)
+		os.MkdirAll(worktreeDir, 0755)
+
+		envContent := `# Server
+export PORT=3000
+APP_NAME="default app"
+`
+		os.WriteFile(filepath.Join(sourceRepoDir, ".env"), []byte(envContent), 0644)
+
+		envFiles := []config.EnvFile{
+			{
+				Source: ".env",
+				Dest:   ".env",
+				Replace: map[string]string{
+					"PORT":     "${RAMP_PORT}",
+					"APP_NAME": "app ${RAMP_WORKTREE_NAME}",
+					"API_URL":  "http://localhost:${RAMP_PORT}",
+				},
+			},
+		}
+
+		envVars := map[string]string{
+			"RAMP_PORT":          "4000",
+			"RAMP_WORKTREE_NAME": "my-feature",
+		}
+
+		err := ProcessEnvFiles("app", envFiles, sourceRepoDir, worktreeDir, envVars, false)
+		if err != nil {
+			t.Fatalf("ProcessEnvFiles() error = %v", err)
+		}
+
+		content, err := os.ReadFile(filepath.Join(worktreeDir, ".env"))
+		if err != nil {
+			t.Fatalf("failed to read destination file: %v", err)
+		}
+
+		expected := `# Server
+export PORT=4000
+APP_NAME="app my-feature"
+API_URL=http://localhost:4000
+`
+		if string(content) != expected {
+			t.Errorf("destination file content mismatch\ngot:\n%s\nwant:\n%s", string(content), expected)
+		}
+	})
+
+	t.Run("merge sources layered in order", func(t *testing.T) {
+		tempDir := t.TempDir()
+		sourceRepoDir := filepath.Join(tempDir, "source")
+		worktreeDir := filepath.Join(tempDir, "worktree")
+
+		os.MkdirAll(sourceRepoDir, 0755)
+		os.MkdirAll(worktreeDir, 0755)
+
+		os.WriteFile(filepath.Join(sourceRepoDir, ".env.example"), []byte("# Defaults\nPORT=3000\nDEBUG=false\n"), 0644)
+		os.WriteFile(filepath.Join(sourceRepoDir, ".env.dev"), []byte("DEBUG=true\nLOG_LEVEL=debug\n"), 0644)
+		os.WriteFile(filepath.Join(sourceRepoDir, ".env.team"), []byte("LOG_LEVEL=info\n"), 0644)
+
+		envFiles := []config.EnvFile{
+			{
+				Source:  ".env.example",
+				Dest:    ".env",
+				Merge:   []string{".env.dev", ".env.missing", ".env.team"},
+				Replace: map[string]string{"PORT": "${RAMP_PORT}"},
+			},
+		}
+
+		envVars := map[string]string{"RAMP_PORT": "4000"}
+
+		err := ProcessEnvFiles("app", envFiles, sourceRepoDir, worktreeDir, envVars, false)
+		if err != nil {
+			t.Fatalf("ProcessEnvFiles() error = %v", err)
+		}
+
+		content, err := os.ReadFile(filepath.Join(worktreeDir, ".env"))
+		if err != nil {
+			t.Fatalf("failed to read destination file: %v", err)
+		}
+
+		expected := "# Defaults\nPORT=4000\nDEBUG=true\nLOG_LEVEL=info\n"
+		if string(content) != expected {
+			t.Errorf("destination file content mismatch\ngot:\n%s\nwant:\n%s", string(content), expected)
+		}
+	})
+
 	t.Run("multiple env files", func(t *testing.T) {
 		tempDir := t.TempDir()
 		sourceRepoDir := filepath.Join(tempDir, "source")
@@ -0,0 +1,751 @@
+package envfile
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"ramp/internal/config"
+)
+
+// Supported formats for structured `set:` patches
+const (
+	formatDotenv     = "dotenv"
+	formatJSON       = "json"
+	formatYAML       = "yaml"
+	formatTOML       = "toml"
+	formatProperties = "properties"
+)
+
+var (
+	intPattern   = regexp.MustCompile(`^-?(0|[1-9][0-9]*)$`)
+	floatPattern = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][+-]?[0-9]+)?$`)
+)
+
+// resolveFormat returns the format of an env file, inferring it from the
+// destination extension when not set explicitly
+func resolveFormat(envFile config.EnvFile) (string, error) {
+	format := strings.ToLower(envFile.Format)
+	if format == "" {
+		switch strings.ToLower(filepath.Ext(envFile.Dest)) {
+		case ".json":
+			return formatJSON, nil
+		case ".yaml", ".yml":
+			return formatYAML, nil
+		case ".toml":
+			return formatTOML, nil
+		case ".properties":
+			return formatProperties, nil
+		default:
+			return formatDotenv, nil
+		}
+	}
+
+	switch format {
+	case formatDotenv, formatJSON, formatYAML, formatTOML, formatProperties:
+		return format, nil
+	case "yml":
+		return formatYAML, nil
+	case "env":
+		return formatDotenv, nil
+	}
+	return "", fmt.Errorf("unsupported env file format %q (expected dotenv, json, yaml, toml or properties)", envFile.Format)
+}
+
+// applySet patches each key path in values into content, keeping the rest of
+// the document intact. Paths are applied in sorted order so output is deterministic.
+func applySet(format string, content string, values map[string]string) (string, error) {
+	if format == formatDotenv {
+		file := parseDotenv(content)
+		file.SetAll(values)
+		return file.String(), nil
+	}
+
+	paths := make([]string, 0, len(values))
+	for path := range values {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	if format == formatYAML {
+		return setYAML(content, paths, values)
+	}
+
+	var err error
+	for _, path := range paths {
+		switch format {
+		case formatJSON:
+			content, err = setJSON(content, strings.Split(path, "."), values[path])
+		case formatTOML:
+			content, err = setTOML(content, path, values[path])
+		case formatProperties:
+			content = setProperty(content, path, values[path])
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to set %s: %w", path, err)
+		}
+	}
+
+	return content, nil
+}
+
+// isTypedLiteral reports whether a value should be written as a number or
+// boolean rather than a string in typed formats
+func isTypedLiteral(value string) bool {
+	return value == "true" || value == "false" || intPattern.MatchString(value) || floatPattern.MatchString(value)
+}
+
+// JSON
+
+// jsonValue is a parsed JSON value with the byte span it occupies in the source
+type jsonValue struct {
+	kind    byte // '{', '[', '"', or 'v' for numbers, booleans and null
+	start   int
+	end     int
+	members []jsonMember
+	elems   []*jsonValue
+}
+
+type jsonMember struct {
+	key      string
+	keyStart int
+	value    *jsonValue
+}
+
+type jsonParser struct {
+	s   string
+	pos int
+}
+
+func parseJSONSpans(content string) (*jsonValue, error) {
+	p := &jsonParser{s: content}
+	value, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	p.skipWhitespace()
+	if p.pos != len(p.s) {
+		return nil, fmt.Errorf("invalid JSON: unexpected content at offset %d", p.pos)
+	}
+	return value, nil
+}
+
+func (p *jsonParser) skipWhitespace() {
+	for p.pos < len(p.s) && strings.IndexByte(" \t\r\n", p.s[p.pos]) >= 0 {
+		p.pos++
+	}
+}
+
+func (p *jsonParser) parseValue() (*jsonValue, error) {
+	p.skipWhitespace()
+	if p.pos >= len(p.s) {
+		return nil, fmt.Errorf("invalid JSON: unexpected end of input")
+	}
+
+	value := &jsonValue{start: p.pos}
+	switch p.s[p.pos] {
+	case '{':
+		value.kind = '{'
+		p.pos++
+		p.skipWhitespace()
+		if p.pos < len(p.s) && p.s[p.pos] == '}' {
+			p.pos++
+			break
+		}
+		for {
+			p.skipWhitespace()
+			keyStart := p.pos
+			key, err := p.parseString()
+			if err != nil {
+				return nil, err
+			}
+			p.skipWhitespace()
+			if p.pos >= len(p.s) || p.s[p.pos] != ':' {
+				return nil, fmt.Errorf("invalid JSON: expected ':' at offset %d", p.pos)
+			}
+			p.pos++
+			member, err := p.parseValue()
+			if err != nil {
+				return nil, err
+			}
+			value.members = append(value.members, jsonMember{key: key, keyStart: keyStart, value: member})
+			done, err := p.endOfContainer('}')
+			if err != nil {
+				return nil, err
+			}
+			if done {
+				break
+			}
+		}
+	case '[':
+		value.kind = '['
+		p.pos++
+		p.skipWhitespace()
+		if p.pos < len(p.s) && p.s[p.pos] == ']' {
+			p.pos++
+			break
+		}
+		for {
+			elem, err := p.parseValue()
+			if err != nil {
+				return nil, err
+			}
+			value.elems = append(value.elems, elem)
+			done, err := p.endOfContainer(']')
+			if err != nil {
+				return nil, err
+			}
+			if done {
+				break
+			}
+		}
+	case '"':
+		value.kind = '"'
+		if _, err := p.parseString(); err != nil {
+			return nil, err
+		}
+	default:
+		value.kind = 'v'
+		for p.pos < len(p.s) && strings.IndexByte(",]} \t\r\n", p.s[p.pos]) < 0 {
+			p.pos++
+		}
+		if !json.Valid([]byte(p.s[value.start:p.pos])) {
+			return nil, fmt.Errorf("invalid JSON: unexpected %q at offset %d", p.s[value.start:p.pos], value.start)
+		}
+	}
+
+	value.end = p.pos
+	return value, nil
+}
+
+// endOfContainer consumes the separator after a member or element.
+// Returns true when the closing bracket was reached.
+func (p *jsonParser) endOfContainer(closing byte) (bool, error) {
+	p.skipWhitespace()
+	if p.pos >= len(p.s) {
+		return false, fmt.Errorf("invalid JSON: unexpected end of input")
+	}
+	switch p.s[p.pos] {
+	case ',':
+		p.pos++
+		return false, nil
+	case closing:
+		p.pos++
+		return true, nil
+	}
+	return false, fmt.Errorf("invalid JSON: unexpected %q at offset %d", p.s[p.pos], p.pos)
+}
+
+func (p *jsonParser) parseString() (string, error) {
+	if p.pos >= len(p.s) || p.s[p.pos] != '"' {
+		return "", fmt.Errorf("invalid JSON: expected string at offset %d", p.pos)
+	}
+	start := p.pos
+	for p.pos++; p.pos < len(p.s); p.pos++ {
+		switch p.s[p.pos] {
+		case '\\':
+			p.pos++
+		case '"':
+			p.pos++
+			var decoded string
+			if err := json.Unmarshal([]byte(p.s[start:p.pos]), &decoded); err != nil {
+				return "", fmt.Errorf("invalid JSON string at offset %d: %w", start, err)
+			}
+			return decoded, nil
+		}
+	}
+	return "", fmt.Errorf("invalid JSON: unterminated string at offset %d", start)
+}
+
+// setJSON sets the value at path, replacing only the bytes of the existing value
+// or inserting a new member into the deepest existing object
+func setJSON(content string, path []string, value string) (string, error) {
+	if strings.TrimSpace(content) == "" {
+		content = "{}\n"
+	}
+
+	root, err := parseJSONSpans(content)
+	if err != nil {
+		return "", err
+	}
+
+	node := root
+	for i, segment := range path {
+		switch node.kind {
+		case '{':
+			member := findJSONMember(node, segment)
+			if member == nil {
+				return insertJSONMember(content, node, path[i:], value), nil
+			}
+			node = member.value
+		case '[':
+			index, err := strconv.Atoi(segment)
+			if err != nil || index < 0 || index >= len(node.elems) {
+				return "", fmt.Errorf("index %q out of range for array at %s", segment, strings.Join(path[:i], "."))
+			}
+			node = node.elems[index]
+		default:
+			return "", fmt.Errorf("%s is not an object", strings.Join(path[:i], "."))
+		}
+	}
+
+	encoded := encodeJSONValue(value, node.kind == '"')
+	return content[:node.start] + encoded + content[node.end:], nil
+}
+
+func findJSONMember(object *jsonValue, key string) *jsonMember {
+	for i := len(object.members) - 1; i >= 0; i-- {
+		if object.members[i].key == key {
+			return &object.members[i]
+		}
+	}
+	return nil
+}
+
+// insertJSONMember adds path (creating nested objects as needed) to object,
+// following the indentation already used in the document
+func insertJSONMember(content string, object *jsonValue, path []string, value string) string {
+	unit := detectJSONIndent(content)
+	multiline := strings.Contains(content[object.start:object.end], "\n") || (len(object.members) == 0 && unit != "")
+
+	if !multiline {
+		member := buildJSONMember(path, value, "", "")
+		if len(object.members) == 0 {
+			return content[:object.start] + "{" + member + "}" + content[object.end:]
+		}
+		last := object.members[len(object.members)-1].value
+		return content[:last.end] + ", " + member + content[last.end:]
+	}
+
+	if len(object.members) == 0 {
+		base := lineIndent(content, object.start)
+		member := buildJSONMember(path, value, base+unit, unit)
+		return content[:object.start] + "{\n" + base + unit + member + "\n" + base + "}" + content[object.end:]
+	}
+
+	last := object.members[len(object.members)-1]
+	indent := lineIndent(content, last.keyStart)
+	member := buildJSONMember(path, value, indent, unit)
+	return content[:last.value.end] + ",\n" + indent + member + content[last.value.end:]
+}
+
+// buildJSONMember renders `"key": value`, nesting objects for the rest of path
+func buildJSONMember(path []string, value string, indent string, unit string) string {
+	key, _ := json.Marshal(path[0])
+	if len(path) == 1 {
+		return string(key) + ": " + encodeJSONValue(value, false)
+	}
+
+	if unit == "" {
+		return string(key) + ": {" + buildJSONMember(path[1:], value, "", "") + "}"
+	}
+	inner := indent + unit
+	return string(key) + ": {\n" + inner + buildJSONMember(path[1:], value, inner, unit) + "\n" + indent + "}"
+}
+
+// encodeJSONValue renders value as a JSON literal. Numbers and booleans are
+// written unquoted unless the value being replaced was a string.
+func encodeJSONValue(value string, forceString bool) string {
+	if !forceString && (isTypedLiteral(value) || value == "null") {
+		return value
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(value)
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// detectJSONIndent returns the indentation unit of a pretty-printed document,
+// or "" for compact JSON
+func detectJSONIndent(content string) string {
+	for _, line := range strings.Split(content, "\n")[1:] {
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed != "" && len(trimmed) < len(line) {
+			return line[:len(line)-len(trimmed)]
+		}
+	}
+	if strings.Contains(strings.TrimSpace(content), "\n") {
+		return "  "
+	}
+	return ""
+}
+
+// lineIndent returns the leading whitespace of the line containing offset
+func lineIndent(content string, offset int) string {
+	start := strings.LastIndexByte(content[:offset], '\n') + 1
+	end := start
+	for end < len(content) && (content[end] == ' ' || content[end] == '\t') {
+		end++
+	}
+	return content[start:end]
+}
+
+// YAML
+
+// setYAML patches key paths through the yaml.v3 node tree, which keeps
+// comments and key order while re-emitting the document
+func setYAML(content string, paths []string, values map[string]string) (string, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(content), &doc); err != nil {
+		return "", fmt.Errorf("invalid YAML: %w", err)
+	}
+	if doc.Kind == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+
+	for _, path := range paths {
+		if err := setYAMLPath(doc.Content[0], strings.Split(path, "."), values[path]); err != nil {
+			return "", fmt.Errorf("failed to set %s: %w", path, err)
+		}
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(detectYAMLIndent(content))
+	if err := encoder.Encode(&doc); err != nil {
+		return "", fmt.Errorf("failed to encode YAML: %w", err)
+	}
+	encoder.Close()
+
+	return buf.String(), nil
+}
+
+func setYAMLPath(node *yaml.Node, path []string, value string) error {
+	for i, segment := range path {
+		switch node.Kind {
+		case yaml.MappingNode:
+			var next *yaml.Node
+			for j := 0; j+1 < len(node.Content); j += 2 {
+				if node.Content[j].Value == segment {
+					next = node.Content[j+1]
+				}
+			}
+			if next == nil {
+				next = &yaml.Node{Kind: yaml.ScalarNode}
+				if i < len(path)-1 {
+					next = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+				}
+				node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: segment}, next)
+			}
+			node = next
+		case yaml.SequenceNode:
+			index, err := strconv.Atoi(segment)
+			if err != nil || index < 0 || index >= len(node.Content) {
+				return fmt.Errorf("index %q out of range for sequence at %s", segment, strings.Join(path[:i], "."))
+			}
+			node = node.Content[index]
+		default:
+			return fmt.Errorf("%s is not a mapping", strings.Join(path[:i], "."))
+		}
+	}
+
+	if node.Kind != yaml.ScalarNode {
+		return fmt.Errorf("%s is not a scalar value", strings.Join(path, "."))
+	}
+
+	keepString := node.Tag == "!!str" && node.Value != ""
+	node.Value = value
+	switch {
+	case keepString:
+		// Existing strings stay strings; the encoder quotes them if needed
+	case value == "true" || value == "false":
+		node.Tag, node.Style = "!!bool", 0
+	case intPattern.MatchString(value):
+		node.Tag, node.Style = "!!int", 0
+	case floatPattern.MatchString(value):
+		node.Tag, node.Style = "!!float", 0
+	default:
+		node.Tag = "!!str"
+	}
+	return nil
+}
+
+// detectYAMLIndent returns the smallest indentation used in the document (default 2)
+func detectYAMLIndent(content string) int {
+	indent := 0
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimLeft(line, " ")
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "- ") {
+			continue
+		}
+		if n := len(line) - len(trimmed); n > 0 && (indent == 0 || n < indent) {
+			indent = n
+		}
+	}
+	if indent < 2 {
+		return 2
+	}
+	return indent
+}
+
+// TOML
+
+var tomlHeaderPattern = regexp.MustCompile(`^\s*\[\s*([^\[\]]+?)\s*\]\s*(#.*)?$`)
+
+// tomlLine is a key/value line or table header found while scanning a TOML document
+type tomlLine struct {
+	table string // Table the line belongs to ("" for the root table)
+	key   string // Full dotted key relative to the table, empty for headers
+}
+
+// setTOML sets a dotted key path in a TOML document line by line. Existing keys
+// are rewritten in place (keeping inline comments); missing keys are appended to
+// their table, which is created at the end of the file if needed.
+func setTOML(content string, path string, value string) (string, error) {
+	lines := strings.Split(content, "\n")
+	table := ""
+	inArrayTable := false
+	tableEnd := map[string]int{"": -1} // Index of the last non-blank line of each table
+	rootEnd := len(lines)              // First header line (end of the root table)
+
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "[[") {
+			inArrayTable = true
+			if rootEnd == len(lines) {
+				rootEnd = i
+			}
+			continue
+		}
+		if match := tomlHeaderPattern.FindStringSubmatch(line); match != nil {
+			table = normalizeTOMLKey(match[1])
+			inArrayTable = false
+			tableEnd[table] = i
+			if rootEnd == len(lines) {
+				rootEnd = i
+			}
+			continue
+		}
+		if inArrayTable || trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		eq := strings.Index(line, "=")
+		if eq < 0 {
+			continue
+		}
+		tableEnd[table] = i
+
+		key := normalizeTOMLKey(line[:eq])
+		full := key
+		if table != "" {
+			full = table + "." + key
+		}
+		if full != path {
+			continue
+		}
+
+		rest := line[eq+1:]
+		valueText := strings.TrimLeft(rest, " \t")
+		valueEnd, err := tomlValueEnd(valueText)
+		if err != nil {
+			return "", fmt.Errorf("line %d: %w", i+1, err)
+		}
+		forceString := strings.HasPrefix(valueText, "\"") || strings.HasPrefix(valueText, "'")
+		prefix := line[:eq+1+len(rest)-len(valueText)]
+		lines[i] = prefix + encodeTOMLValue(value, forceString) + valueText[valueEnd:]
+		return strings.Join(lines, "\n"), nil
+	}
+
+	// Key not found: add it to the deepest existing table that prefixes the path
+	parent, key := "", path
+	for candidate := range tableEnd {
+		if candidate != "" && strings.HasPrefix(path, candidate+".") && len(candidate) > len(parent) {
+			parent, key = candidate, strings.TrimPrefix(path, candidate+".")
+		}
+	}
+	assignment := key + " = " + encodeTOMLValue(value, false)
+
+	if parent != "" {
+		at := tableEnd[parent] + 1
+		lines = append(lines[:at], append([]string{assignment}, lines[at:]...)...)
+		return strings.Join(lines, "\n"), nil
+	}
+
+	// No matching table: dotted paths get a new table at the end of the file
+	if idx := strings.LastIndex(path, "."); idx > 0 {
+		trailing := ""
+		if strings.HasSuffix(content, "\n") {
+			lines = lines[:len(lines)-1]
+			trailing = "\n"
+		}
+		if len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) != "" {
+			lines = append(lines, "")
+		}
+		lines = append(lines, "["+path[:idx]+"]", path[idx+1:]+" = "+encodeTOMLValue(value, false))
+		return strings.Join(lines, "\n") + trailing, nil
+	}
+
+	// Plain keys go at the end of the root table, before the first header
+	if strings.TrimSpace(content) == "" {
+		return assignment + "\n", nil
+	}
+	at := tableEnd[""] + 1
+	if at == 0 {
+		at = rootEnd
+		if at == len(lines) && strings.HasSuffix(content, "\n") {
+			at--
+		}
+	}
+	lines = append(lines[:at], append([]string{assignment}, lines[at:]...)...)
+	return strings.Join(lines, "\n"), nil
+}
+
+// normalizeTOMLKey strips whitespace and quotes around each part of a dotted key
+func normalizeTOMLKey(key string) string {
+	parts := strings.Split(strings.TrimSpace(key), ".")
+	for i, part := range parts {
+		parts[i] = strings.Trim(strings.TrimSpace(part), `"'`)
+	}
+	return strings.Join(parts, ".")
+}
+
+// tomlValueEnd returns the length of the value at the start of text,
+// excluding any trailing whitespace and comment
+func tomlValueEnd(text string) (int, error) {
+	if strings.HasPrefix(text, `"""`) || strings.HasPrefix(text, "'''") {
+		return 0, fmt.Errorf("multi-line strings cannot be replaced")
+	}
+	if text != "" && (text[0] == '"' || text[0] == '\'') {
+		for i := 1; i < len(text); i++ {
+			if text[0] == '"' && text[i] == '\\' {
+				i++
+				continue
+			}
+			if text[i] == text[0] {
+				return i + 1, nil
+			}
+		}
+		return 0, fmt.Errorf("unterminated string")
+	}
+	if strings.HasPrefix(text, "[") || strings.HasPrefix(text, "{") {
+		return 0, fmt.Errorf("arrays and inline tables cannot be replaced")
+	}
+	end := len(text)
+	if idx := strings.Index(text, "#"); idx >= 0 {
+		end = idx
+	}
+	return len(strings.TrimRight(text[:end], " \t\r")), nil
+}
+
+// encodeTOMLValue renders a TOML value, using a basic string unless the
+// value is a number or boolean and the existing value was not a string
+func encodeTOMLValue(value string, forceString bool) string {
+	if !forceString && isTypedLiteral(value) {
+		return value
+	}
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "\t", `\t`)
+	return `"` + replacer.Replace(value) + `"`
+}
+
+// Java properties
+
+// setProperty sets a key in a .properties document. The key is used as-is
+// (dots are part of property names); missing keys are appended.
+func setProperty(content string, key string, value string) string {
+	lines := strings.Split(content, "\n")
+	found := -1
+	foundEnd := -1
+
+	for i := 0; i < len(lines); i++ {
+		start := i
+		// Continuation lines end with an odd number of backslashes
+		for i < len(lines)-1 && strings.HasSuffix(lines[i], `\`) && (len(lines[i])-len(strings.TrimRight(lines[i], `\`)))%2 == 1 {
+			i++
+		}
+
+		trimmed := strings.TrimLeft(lines[start], " \t\f")
+		if trimmed == "" || trimmed[0] == '#' || trimmed[0] == '!' {
+			continue
+		}
+		if propertyKey(trimmed) == key {
+			found, foundEnd = start, i
+		}
+	}
+
+	if found >= 0 {
+		line := lines[found]
+		trimmed := strings.TrimLeft(line, " \t\f")
+		keyEnd := len(line) - len(trimmed) + propertyKeyLength(trimmed)
+		sep := propertySeparator(line[keyEnd:])
+		replaced := line[:keyEnd] + sep + escapePropertyValue(value)
+		lines = append(lines[:found], append([]string{replaced}, lines[foundEnd+1:]...)...)
+		return strings.Join(lines, "\n")
+	}
+
+	assignment := escapePropertyKey(key) + "=" + escapePropertyValue(value)
+	if content == "" {
+		return assignment + "\n"
+	}
+	if strings.HasSuffix(content, "\n") {
+		return content + assignment + "\n"
+	}
+	return content + "\n" + assignment + "\n"
+}
+
+// propertyKeyLength returns the length of the (escaped) key at the start of line
+func propertyKeyLength(line string) int {
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			i++
+		case '=', ':', ' ', '\t', '\f':
+			return i
+		}
+	}
+	return len(line)
+}
+
+// propertyKey returns the unescaped key at the start of line
+func propertyKey(line string) string {
+	raw := line[:propertyKeyLength(line)]
+	var b strings.Builder
+	for i := 0; i < len(raw); i++ {
+		if raw[i] == '\\' && i+1 < len(raw) {
+			i++
+		}
+		b.WriteByte(raw[i])
+	}
+	return b.String()
+}
+
+// propertySeparator returns the separator text following a key (e.g. "=", " = ", ": ")
+func propertySeparator(rest string) string {
+	i := 0
+	for i < len(rest) && (rest[i] == ' ' || rest[i] == '\t' || rest[i] == '\f') {
+		i++
+	}
+	if i < len(rest) && (rest[i] == '=' || rest[i] == ':') {
+		i++
+		for i < len(rest) && (rest[i] == ' ' || rest[i] == '\t' || rest[i] == '\f') {
+			i++
+		}
+	}
+	if i == 0 {
+		return "="
+	}
+	return rest[:i]
+}
+
+func escapePropertyKey(key string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, "=", `\=`, ":", `\:`, " ", `\ `)
+	return replacer.Replace(key)
+}
+
+func escapePropertyValue(value string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, "\n", `\n`, "\r", `\r`, "\t", `\t`)
+	escaped := replacer.Replace(value)
+	if strings.HasPrefix(escaped, " ") {
+		escaped = `\` + escaped
+	}
+	return escaped
+}
@@ -0,0 +1,238 @@
+package envfile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"ramp/internal/config"
+)
+
+// TestResolveFormat tests explicit and inferred formats
+func TestResolveFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		envFile config.EnvFile
+		want    string
+		wantErr bool
+	}{
+		{name: "explicit format", envFile: config.EnvFile{Dest: "settings", Format: "json"}, want: formatJSON},
+		{name: "yml alias", envFile: config.EnvFile{Dest: "app.conf", Format: "yml"}, want: formatYAML},
+		{name: "inferred json", envFile: config.EnvFile{Dest: "config/local.json"}, want: formatJSON},
+		{name: "inferred yaml", envFile: config.EnvFile{Dest: "application-local.yml"}, want: formatYAML},
+		{name: "inferred toml", envFile: config.EnvFile{Dest: "config.toml"}, want: formatTOML},
+		{name: "inferred properties", envFile: config.EnvFile{Dest: "app.properties"}, want: formatProperties},
+		{name: "default dotenv", envFile: config.EnvFile{Dest: ".env.local"}, want: formatDotenv},
+		{name: "unknown format", envFile: config.EnvFile{Dest: ".env", Format: "xml"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveFormat(tt.envFile)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolveFormat() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("resolveFormat() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestApplySet tests structured patches for each format
+func TestApplySet(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  string
+		content string
+		values  map[string]string
+		want    string
+	}{
+		{
+			name:    "json replaces nested value and keeps formatting",
+			format:  formatJSON,
+			content: "{\n  \"name\": \"app\",\n  \"server\": {\n    \"port\": 3000,\n    \"host\": \"localhost\"\n  }\n}\n",
+			values:  map[string]string{"server.port": "4000"},
+			want:    "{\n  \"name\": \"app\",\n  \"server\": {\n    \"port\": 4000,\n    \"host\": \"localhost\"\n  }\n}\n",
+		},
+		{
+			name:    "json keeps string type of existing value",
+			format:  formatJSON,
+			content: "{\"port\": \"3000\"}",
+			values:  map[string]string{"port": "4000"},
+			want:    "{\"port\": \"4000\"}",
+		},
+		{
+			name:    "json inserts missing nested keys",
+			format:  formatJSON,
+			content: "{\n    \"name\": \"app\"\n}\n",
+			values:  map[string]string{"Kestrel.Endpoints.Http.Url": "http://localhost:4000"},
+			want:    "{\n    \"name\": \"app\",\n    \"Kestrel\": {\n        \"Endpoints\": {\n            \"Http\": {\n                \"Url\": \"http://localhost:4000\"\n            }\n        }\n    }\n}\n",
+		},
+		{
+			name:    "json inserts into empty object",
+			format:  formatJSON,
+			content: "{\n  \"server\": {}\n}\n",
+			values:  map[string]string{"server.port": "4000"},
+			want:    "{\n  \"server\": {\n    \"port\": 4000\n  }\n}\n",
+		},
+		{
+			name:    "json compact document",
+			format:  formatJSON,
+			content: `{"a":1}`,
+			values:  map[string]string{"b": "true"},
+			want:    `{"a":1, "b": true}`,
+		},
+		{
+			name:    "json array index",
+			format:  formatJSON,
+			content: `{"hosts": ["a", "b"]}`,
+			values:  map[string]string{"hosts.1": "c"},
+			want:    `{"hosts": ["a", "c"]}`,
+		},
+		{
+			name:    "yaml replaces value and keeps comments",
+			format:  formatYAML,
+			content: "# Local settings\nserver:\n  port: 8080 # http port\n  host: localhost\nspring:\n  profiles: dev\n",
+			values:  map[string]string{"server.port": "4000", "spring.datasource.url": "jdbc:postgresql://localhost/app"},
+			want:    "# Local settings\nserver:\n  port: 4000 # http port\n  host: localhost\nspring:\n  profiles: dev\n  datasource:\n    url: jdbc:postgresql://localhost/app\n",
+		},
+		{
+			name:    "yaml keeps string type of existing value",
+			format:  formatYAML,
+			content: "version: \"1\"\n",
+			values:  map[string]string{"version": "2"},
+			want:    "version: \"2\"\n",
+		},
+		{
+			name:    "toml replaces value in table and keeps comment",
+			format:  formatTOML,
+			content: "title = \"app\"\n\n[server]\nport = 3000 # http\nhost = \"localhost\"\n",
+			values:  map[string]string{"server.port": "4000", "server.host": "0.0.0.0"},
+			want:    "title = \"app\"\n\n[server]\nport = 4000 # http\nhost = \"0.0.0.0\"\n",
+		},
+		{
+			name:    "toml appends to existing table and creates new tables",
+			format:  formatTOML,
+			content: "[server]\nport = 3000\n\n[logging]\nlevel = \"info\"\n",
+			values:  map[string]string{"server.debug": "true", "database.url": "postgres://localhost"},
+			want:    "[server]\nport = 3000\ndebug = true\n\n[logging]\nlevel = \"info\"\n\n[database]\nurl = \"postgres://localhost\"\n",
+		},
+		{
+			name:    "toml root key goes before first table",
+			format:  formatTOML,
+			content: "name = \"app\"\n\n[server]\nport = 3000\n",
+			values:  map[string]string{"debug": "false"},
+			want:    "name = \"app\"\ndebug = false\n\n[server]\nport = 3000\n",
+		},
+		{
+			name:    "toml dotted key in root table",
+			format:  formatTOML,
+			content: "server.port = 3000\n",
+			values:  map[string]string{"server.port": "4000"},
+			want:    "server.port = 4000\n",
+		},
+		{
+			name:    "properties replaces value and keeps separator",
+			format:  formatProperties,
+			content: "# Spring\nserver.port = 8080\nspring.profiles.active: dev\n",
+			values:  map[string]string{"server.port": "4000", "spring.profiles.active": "local"},
+			want:    "# Spring\nserver.port = 4000\nspring.profiles.active: local\n",
+		},
+		{
+			name:    "properties replaces continued value and appends missing keys",
+			format:  formatProperties,
+			content: "app.hosts=a,\\\n  b\nname=app\n",
+			values:  map[string]string{"app.hosts": "c", "app.url": "http://localhost:4000"},
+			want:    "app.hosts=c\nname=app\napp.url=http://localhost:4000\n",
+		},
+		{
+			name:    "dotenv sets keys",
+			format:  formatDotenv,
+			content: "PORT=3000\n",
+			values:  map[string]string{"PORT": "4000"},
+			want:    "PORT=4000\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := applySet(tt.format, tt.content, tt.values)
+			if err != nil {
+				t.Fatalf("applySet() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("applySet() mismatch\ngot:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestApplySetErrors tests invalid documents and paths
+func TestApplySetErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  string
+		content string
+		values  map[string]string
+	}{
+		{name: "invalid json", format: formatJSON, content: `{"a": }`, values: map[string]string{"a": "1"}},
+		{name: "json path through scalar", format: formatJSON, content: `{"a": 1}`, values: map[string]string{"a.b": "1"}},
+		{name: "json index out of range", format: formatJSON, content: `{"a": [1]}`, values: map[string]string{"a.3": "1"}},
+		{name: "yaml path through scalar", format: formatYAML, content: "a: 1\n", values: map[string]string{"a.b": "1"}},
+		{name: "yaml mapping replaced with scalar", format: formatYAML, content: "a:\n  b: 1\n", values: map[string]string{"a": "1"}},
+		{name: "toml multi-line array", format: formatTOML, content: "hosts = [\n  \"a\",\n]\n", values: map[string]string{"hosts": "b"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := applySet(tt.format, tt.content, tt.values); err == nil {
+				t.Error("applySet() expected error, got nil")
+			}
+		})
+	}
+}
+
+// TestProcessEnvFilesSet tests set: through ProcessEnvFiles
+func TestProcessEnvFilesSet(t *testing.T) {
+	tempDir := t.TempDir()
+	sourceRepoDir := filepath.Join(tempDir, "source")
+	worktreeDir := filepath.Join(tempDir, "worktree")
+
+	os.MkdirAll(sourceRepoDir, 0755)
+	os.MkdirAll(worktreeDir, 0755)
+
+	source := "{\n  \"Logging\": {\n    \"LogLevel\": \"Information\"\n  },\n  \"Urls\": \"http://localhost:5000\"\n}\n"
+	os.WriteFile(filepath.Join(sourceRepoDir, "appsettings.json"), []byte(source), 0644)
+
+	envFiles := []config.EnvFile{
+		{
+			Source: "appsettings.json",
+			Dest:   "appsettings.Development.json",
+			Set: map[string]string{
+				"Urls":          "http://localhost:${RAMP_PORT}",
+				"Feature.Name":  "${RAMP_WORKTREE_NAME}",
+				"Feature.Port":  "${RAMP_PORT}",
+				"Logging.Debug": "true",
+			},
+		},
+	}
+	envVars := map[string]string{
+		"RAMP_PORT":          "4000",
+		"RAMP_WORKTREE_NAME": "my-feature",
+	}
+
+	if err := ProcessEnvFiles("api", envFiles, sourceRepoDir, worktreeDir, envVars, false); err != nil {
+		t.Fatalf("ProcessEnvFiles() error = %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(worktreeDir, "appsettings.Development.json"))
+	if err != nil {
+		t.Fatalf("failed to read destination file: %v", err)
+	}
+
+	expected := "{\n  \"Logging\": {\n    \"LogLevel\": \"Information\",\n    \"Debug\": true\n  },\n  \"Urls\": \"http://localhost:4000\",\n  \"Feature\": {\n    \"Name\": \"my-feature\",\n    \"Port\": 4000\n  }\n}\n"
+	if string(content) != expected {
+		t.Errorf("destination file content mismatch\ngot:\n%s\nwant:\n%s", string(content), expected)
+	}
+}
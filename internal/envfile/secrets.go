@@ -0,0 +1,344 @@
+package envfile
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"ramp/internal/config"
+	"ramp/internal/ui"
+)
+
+// Secret provider types
+const (
+	SecretProviderFile     = "file"
+	SecretProviderPass     = "pass"
+	SecretProviderKeychain = "keychain"
+	SecretProviderExec     = "exec"
+)
+
+const (
+	// DefaultSecretsFile is the encrypted file used by file providers without a path
+	DefaultSecretsFile = ".ramp/secrets.enc"
+	// DefaultSecretsKeyEnv holds the passphrase for encrypted secrets files and caches
+	DefaultSecretsKeyEnv = "RAMP_SECRETS_KEY"
+	defaultKeychainName  = "ramp"
+)
+
+// secretRefPattern matches ${secret:provider/path}
+var secretRefPattern = regexp.MustCompile(`\$\{secret:([^}/]+)/([^}]+)\}`)
+
+// secretProvider looks up a single secret by path
+type secretProvider interface {
+	lookup(path string) (string, error)
+}
+
+// SecretResolver resolves ${secret:provider/path} references using the providers
+// configured under secrets: in ramp.yaml. Resolved values are kept in memory for
+// the life of the resolver and registered for masking in progress output.
+type SecretResolver struct {
+	projectDir string
+	configs    map[string]*config.SecretProvider
+	providers  map[string]secretProvider
+	values     map[string]string // Keyed by "provider/path"
+	mu         sync.Mutex        // Guards providers and values; repos render concurrently
+}
+
+// NewSecretResolver creates a resolver for the given provider configuration.
+func NewSecretResolver(projectDir string, configs map[string]*config.SecretProvider) *SecretResolver {
+	return &SecretResolver{
+		projectDir: projectDir,
+		configs:    configs,
+		providers:  make(map[string]secretProvider),
+		values:     make(map[string]string),
+	}
+}
+
+// HasSecretRefs reports whether content contains any ${secret:...} references.
+func HasSecretRefs(content string) bool {
+	return secretRefPattern.MatchString(content)
+}
+
+// Expand replaces every ${secret:provider/path} reference in content with its value.
+// A nil resolver fails if content contains any references.
+func (r *SecretResolver) Expand(content string) (string, error) {
+	if !HasSecretRefs(content) {
+		return content, nil
+	}
+	if r == nil {
+		return "", fmt.Errorf("secret references require a secrets: section in ramp.yaml")
+	}
+
+	var resolveErr error
+	result := secretRefPattern.ReplaceAllStringFunc(content, func(match string) string {
+		if resolveErr != nil {
+			return match
+		}
+		parts := secretRefPattern.FindStringSubmatch(match)
+		value, err := r.Resolve(parts[1], parts[2])
+		if err != nil {
+			resolveErr = err
+			return match
+		}
+		return value
+	})
+	if resolveErr != nil {
+		return "", resolveErr
+	}
+
+	return result, nil
+}
+
+// Resolve returns the value of one secret.
+func (r *SecretResolver) Resolve(providerName string, path string) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ref := providerName + "/" + path
+	if value, ok := r.values[ref]; ok {
+		return value, nil
+	}
+
+	provider, err := r.provider(providerName)
+	if err != nil {
+		return "", err
+	}
+
+	value, err := provider.lookup(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve secret %s: %w", ref, err)
+	}
+
+	ui.RegisterSecret(value)
+	r.values[ref] = value
+	return value, nil
+}
+
+// provider returns the named provider, creating it on first use
+func (r *SecretResolver) provider(name string) (secretProvider, error) {
+	if provider, ok := r.providers[name]; ok {
+		return provider, nil
+	}
+
+	cfg, ok := r.configs[name]
+	if !ok || cfg == nil {
+		return nil, fmt.Errorf("unknown secret provider %q", name)
+	}
+
+	var provider secretProvider
+	switch cfg.Type {
+	case SecretProviderFile:
+		provider = &fileSecretProvider{
+			path:   SecretsFilePath(r.projectDir, cfg),
+			keyEnv: secretsKeyEnv(cfg),
+		}
+	case SecretProviderPass:
+		provider = &commandSecretProvider{args: func(path string) []string { return []string{"pass", "show", path} }, firstLine: true}
+	case SecretProviderKeychain:
+		service := cfg.Service
+		if service == "" {
+			service = defaultKeychainName
+		}
+		provider = &commandSecretProvider{args: func(path string) []string {
+			return []string{"security", "find-generic-password", "-w", "-s", service, "-a", path}
+		}}
+	case SecretProviderExec:
+		if cfg.Command == "" {
+			return nil, fmt.Errorf("secret provider %q requires a command", name)
+		}
+		provider = &commandSecretProvider{args: func(string) []string { return []string{"/bin/bash", "-l", "-c", cfg.Command} }}
+	default:
+		return nil, fmt.Errorf("secret provider %q has unknown type %q (expected file, pass, keychain or exec)", name, cfg.Type)
+	}
+
+	if cfg.Cache != "" && cfg.Type != SecretProviderFile {
+		ttl, err := time.ParseDuration(cfg.Cache)
+		if err != nil {
+			return nil, fmt.Errorf("secret provider %q has invalid cache duration %q: %w", name, cfg.Cache, err)
+		}
+		provider = &cachedSecretProvider{
+			provider: provider,
+			path:     SecretCachePath(r.projectDir, name),
+			keyEnv:   secretsKeyEnv(cfg),
+			ttl:      ttl,
+		}
+	}
+
+	r.providers[name] = provider
+	return provider, nil
+}
+
+// secretsKeyEnv returns the env var holding the passphrase for a provider
+func secretsKeyEnv(cfg *config.SecretProvider) string {
+	if cfg.KeyEnv != "" {
+		return cfg.KeyEnv
+	}
+	return DefaultSecretsKeyEnv
+}
+
+// resolveProjectPath resolves a path relative to the project directory
+func resolveProjectPath(projectDir string, path string, defaultPath string) string {
+	if path == "" {
+		path = defaultPath
+	}
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(projectDir, path)
+}
+
+// SecretsFilePath returns the encrypted file used by a file provider.
+func SecretsFilePath(projectDir string, cfg *config.SecretProvider) string {
+	return resolveProjectPath(projectDir, cfg.Path, DefaultSecretsFile)
+}
+
+// SecretsPassphrase reads the passphrase for a provider from its key_env variable.
+func SecretsPassphrase(cfg *config.SecretProvider) (string, error) {
+	keyEnv := secretsKeyEnv(cfg)
+	passphrase := os.Getenv(keyEnv)
+	if passphrase == "" {
+		return "", fmt.Errorf("set %s to the passphrase for encrypted secrets", keyEnv)
+	}
+	return passphrase, nil
+}
+
+// SecretCachePath returns the encrypted cache file for a provider.
+func SecretCachePath(projectDir string, providerName string) string {
+	return filepath.Join(projectDir, ".ramp", "cache", "secrets", providerName+".enc")
+}
+
+// fileSecretProvider reads secrets from an encrypted file
+type fileSecretProvider struct {
+	path    string
+	keyEnv  string
+	secrets map[string]string
+}
+
+func (p *fileSecretProvider) lookup(path string) (string, error) {
+	if p.secrets == nil {
+		passphrase, err := SecretsPassphrase(&config.SecretProvider{KeyEnv: p.keyEnv})
+		if err != nil {
+			return "", err
+		}
+		if _, err := os.Stat(p.path); os.IsNotExist(err) {
+			return "", fmt.Errorf("secrets file %s does not exist", p.path)
+		}
+		secrets, err := LoadSecretsFile(p.path, passphrase)
+		if err != nil {
+			return "", err
+		}
+		p.secrets = secrets
+	}
+
+	value, ok := p.secrets[path]
+	if !ok {
+		return "", fmt.Errorf("not found in %s", p.path)
+	}
+	return value, nil
+}
+
+// commandSecretProvider runs a command that prints the secret on stdout.
+// The secret path is passed in $RAMP_SECRET_PATH.
+type commandSecretProvider struct {
+	args      func(path string) []string
+	firstLine bool // Only use the first line of output (pass stores metadata below it)
+}
+
+func (p *commandSecretProvider) lookup(path string) (string, error) {
+	args := p.args(path)
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Env = append(os.Environ(), "RAMP_SECRET_PATH="+path)
+
+	output, err := cmd.Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return "", fmt.Errorf("%s exited with code %d: %s", args[0], exitErr.ExitCode(), strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", fmt.Errorf("failed to run %s: %w", args[0], err)
+	}
+
+	value := string(output)
+	if p.firstLine {
+		value, _, _ = strings.Cut(value, "\n")
+	}
+	return strings.TrimRight(value, "\r\n"), nil
+}
+
+// cachedSecret is a secret value stored in an encrypted provider cache
+type cachedSecret struct {
+	Value   string    `json:"value"`
+	Fetched time.Time `json:"fetched"`
+}
+
+// cachedSecretProvider keeps looked-up values in an encrypted cache file.
+// Without a passphrase nothing is cached, so values never reach disk in plaintext.
+type cachedSecretProvider struct {
+	provider secretProvider
+	path     string
+	keyEnv   string
+	ttl      time.Duration
+	warned   bool
+}
+
+func (p *cachedSecretProvider) lookup(path string) (string, error) {
+	passphrase := os.Getenv(p.keyEnv)
+	if passphrase == "" {
+		if !p.warned {
+			ui.Warning(fmt.Sprintf("Not caching secrets: %s is not set", p.keyEnv))
+			p.warned = true
+		}
+		return p.provider.lookup(path)
+	}
+
+	cache := p.load(passphrase)
+	if entry, ok := cache[path]; ok && time.Since(entry.Fetched) < p.ttl {
+		return entry.Value, nil
+	}
+
+	value, err := p.provider.lookup(path)
+	if err != nil {
+		return "", err
+	}
+
+	cache[path] = cachedSecret{Value: value, Fetched: time.Now()}
+	if err := p.save(passphrase, cache); err != nil {
+		ui.Warning(fmt.Sprintf("Failed to cache secret: %v", err))
+	}
+
+	return value, nil
+}
+
+// load reads the cache, treating unreadable caches as empty
+func (p *cachedSecretProvider) load(passphrase string) map[string]cachedSecret {
+	cache := make(map[string]cachedSecret)
+
+	data, err := readEncrypted(p.path, passphrase)
+	if err != nil {
+		return cache
+	}
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return make(map[string]cachedSecret)
+	}
+
+	// Drop expired entries so they are not rewritten
+	for path, entry := range cache {
+		if time.Since(entry.Fetched) >= p.ttl {
+			delete(cache, path)
+		}
+	}
+	return cache
+}
+
+func (p *cachedSecretProvider) save(passphrase string, cache map[string]cachedSecret) error {
+	data, err := json.Marshal(cache)
+	if err != nil {
+		return err
+	}
+	return writeEncrypted(p.path, passphrase, data)
+}
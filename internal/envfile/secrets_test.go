@@ -0,0 +1,211 @@
+package envfile
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ramp/internal/config"
+)
+
+// TestSecretsFileRoundTrip tests encrypting and decrypting a secrets file
+func TestSecretsFileRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secrets.enc")
+
+	if err := SaveSecretsFile(path, "correct horse", map[string]string{"stripe/key": "sk_test_123"}); err != nil {
+		t.Fatalf("SaveSecretsFile() error = %v", err)
+	}
+
+	data, _ := os.ReadFile(path)
+	if strings.Contains(string(data), "sk_test_123") {
+		t.Error("secrets file contains plaintext value")
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0600 {
+		t.Errorf("secrets file mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	secrets, err := LoadSecretsFile(path, "correct horse")
+	if err != nil {
+		t.Fatalf("LoadSecretsFile() error = %v", err)
+	}
+	if secrets["stripe/key"] != "sk_test_123" {
+		t.Errorf("LoadSecretsFile() = %v", secrets)
+	}
+
+	if _, err := LoadSecretsFile(path, "wrong"); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("LoadSecretsFile() with wrong passphrase error = %v, want ErrWrongPassphrase", err)
+	}
+
+	missing, err := LoadSecretsFile(filepath.Join(t.TempDir(), "missing.enc"), "x")
+	if err != nil || len(missing) != 0 {
+		t.Errorf("LoadSecretsFile() for missing file = %v, %v; want empty map", missing, err)
+	}
+}
+
+// TestSecretResolverExpand tests resolving references through each provider type
+func TestSecretResolverExpand(t *testing.T) {
+	projectDir := t.TempDir()
+	t.Setenv("TEST_SECRETS_KEY", "passphrase")
+
+	secretsPath := filepath.Join(projectDir, ".ramp", "secrets.enc")
+	if err := SaveSecretsFile(secretsPath, "passphrase", map[string]string{"db/password": "s3cret-db"}); err != nil {
+		t.Fatalf("SaveSecretsFile() error = %v", err)
+	}
+
+	// A fake pass binary that prints the secret followed by metadata lines
+	binDir := t.TempDir()
+	passScript := "#!/bin/sh\necho \"pass-$2\"\necho \"login: someone\"\n"
+	os.WriteFile(filepath.Join(binDir, "pass"), []byte(passScript), 0755)
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	resolver := NewSecretResolver(projectDir, map[string]*config.SecretProvider{
+		"vault": {Type: SecretProviderFile, KeyEnv: "TEST_SECRETS_KEY"},
+		"pw":    {Type: SecretProviderPass},
+		"cmd":   {Type: SecretProviderExec, Command: `echo "exec-$RAMP_SECRET_PATH"`},
+	})
+
+	content := "DB=${secret:vault/db/password}\nAPI=${secret:pw/api/key}\nTOKEN=${secret:cmd/token}\nPORT=${RAMP_PORT}\n"
+	got, err := resolver.Expand(content)
+	if err != nil {
+		t.Fatalf("Expand() error = %v", err)
+	}
+
+	want := "DB=s3cret-db\nAPI=pass-api/key\nTOKEN=exec-token\nPORT=${RAMP_PORT}\n"
+	if got != want {
+		t.Errorf("Expand() = %q, want %q", got, want)
+	}
+}
+
+// TestSecretResolverErrors tests unresolvable references
+func TestSecretResolverErrors(t *testing.T) {
+	projectDir := t.TempDir()
+	t.Setenv("TEST_SECRETS_KEY", "")
+
+	tests := []struct {
+		name      string
+		providers map[string]*config.SecretProvider
+		content   string
+		wantErr   string
+	}{
+		{name: "no providers", content: "A=${secret:vault/a}", wantErr: "unknown secret provider"},
+		{name: "unknown type", providers: map[string]*config.SecretProvider{"v": {Type: "ldap"}}, content: "A=${secret:v/a}", wantErr: "unknown type"},
+		{name: "missing passphrase", providers: map[string]*config.SecretProvider{"v": {Type: SecretProviderFile, KeyEnv: "TEST_SECRETS_KEY"}}, content: "A=${secret:v/a}", wantErr: "set TEST_SECRETS_KEY"},
+		{name: "failing command", providers: map[string]*config.SecretProvider{"v": {Type: SecretProviderExec, Command: "echo nope >&2; exit 3"}}, content: "A=${secret:v/a}", wantErr: "exited with code 3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewSecretResolver(projectDir, tt.providers).Expand(tt.content)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expand() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("nil resolver", func(t *testing.T) {
+		var resolver *SecretResolver
+		if got, err := resolver.Expand("A=1"); err != nil || got != "A=1" {
+			t.Errorf("Expand() without references = %q, %v", got, err)
+		}
+		if _, err := resolver.Expand("A=${secret:vault/a}"); err == nil {
+			t.Error("Expand() with nil resolver should fail on references")
+		}
+	})
+}
+
+// TestSecretProviderCache tests that cached secrets are encrypted and reused
+func TestSecretProviderCache(t *testing.T) {
+	projectDir := t.TempDir()
+	counter := filepath.Join(t.TempDir(), "calls")
+	providers := map[string]*config.SecretProvider{
+		"cmd": {
+			Type:    SecretProviderExec,
+			Command: "echo x >> " + counter + "; echo cached-value-123",
+			KeyEnv:  "TEST_SECRETS_KEY",
+			Cache:   "1h",
+		},
+	}
+
+	t.Run("without passphrase nothing is cached", func(t *testing.T) {
+		t.Setenv("TEST_SECRETS_KEY", "")
+		if _, err := NewSecretResolver(projectDir, providers).Resolve("cmd", "token"); err != nil {
+			t.Fatalf("Resolve() error = %v", err)
+		}
+		if _, err := os.Stat(SecretCachePath(projectDir, "cmd")); !os.IsNotExist(err) {
+			t.Error("cache file should not be written without a passphrase")
+		}
+	})
+
+	t.Run("with passphrase", func(t *testing.T) {
+		t.Setenv("TEST_SECRETS_KEY", "passphrase")
+		os.Remove(counter)
+
+		for i := 0; i < 2; i++ {
+			value, err := NewSecretResolver(projectDir, providers).Resolve("cmd", "token")
+			if err != nil {
+				t.Fatalf("Resolve() error = %v", err)
+			}
+			if value != "cached-value-123" {
+				t.Errorf("Resolve() = %q", value)
+			}
+		}
+
+		calls, _ := os.ReadFile(counter)
+		if strings.Count(string(calls), "x") != 1 {
+			t.Errorf("command ran %d times, want 1", strings.Count(string(calls), "x"))
+		}
+
+		data, err := os.ReadFile(SecretCachePath(projectDir, "cmd"))
+		if err != nil {
+			t.Fatalf("cache file not written: %v", err)
+		}
+		if strings.Contains(string(data), "cached-value-123") {
+			t.Error("cache file contains plaintext value")
+		}
+	})
+}
+
+// TestRenderEnvFilesSecrets tests that secrets are resolved after the script cache
+func TestRenderEnvFilesSecrets(t *testing.T) {
+	projectDir := t.TempDir()
+	sourceRepoDir := filepath.Join(projectDir, "repos", "app")
+	os.MkdirAll(sourceRepoDir, 0755)
+
+	script := "#!/bin/bash\necho 'API_KEY=${secret:cmd/api}'\necho \"PORT=$RAMP_PORT\"\n"
+	os.WriteFile(filepath.Join(sourceRepoDir, "env.sh"), []byte(script), 0755)
+
+	envFiles := []config.EnvFile{{Source: "env.sh", Dest: ".env", Cache: "1h"}}
+	secrets := NewSecretResolver(projectDir, map[string]*config.SecretProvider{
+		"cmd": {Type: SecretProviderExec, Command: "echo live-api-key"},
+	})
+
+	rendered, err := RenderEnvFiles("app", envFiles, sourceRepoDir, map[string]string{"RAMP_PORT": "3000"}, false, projectDir, secrets)
+	if err != nil {
+		t.Fatalf("RenderEnvFiles() error = %v", err)
+	}
+
+	if len(rendered) != 1 {
+		t.Fatalf("expected 1 rendered file, got %d", len(rendered))
+	}
+	if rendered[0].Content != "API_KEY=live-api-key\nPORT=3000\n" {
+		t.Errorf("Content = %q", rendered[0].Content)
+	}
+	if rendered[0].Redacted != "API_KEY=${secret:cmd/api}\nPORT=3000\n" {
+		t.Errorf("Redacted = %q", rendered[0].Redacted)
+	}
+
+	entries, err := ListCache(projectDir)
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("ListCache() = %v, %v; want 1 entry", entries, err)
+	}
+	outputPath, _ := cachePaths(projectDir, entries[0].Key)
+	cached, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("script output not cached: %v", err)
+	}
+	if strings.Contains(string(cached), "live-api-key") {
+		t.Error("script cache contains secret value")
+	}
+}
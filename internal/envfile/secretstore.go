@@ -0,0 +1,140 @@
+package envfile
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Encrypted files start with a magic header, then a random salt and nonce,
+// followed by the AES-256-GCM sealed payload.
+const (
+	encryptedMagic   = "RAMPSEC1"
+	encryptedSaltLen = 16
+	pbkdf2Iterations = 600_000
+)
+
+// ErrWrongPassphrase is returned when an encrypted file cannot be decrypted
+var ErrWrongPassphrase = errors.New("wrong passphrase or corrupted file")
+
+// LoadSecretsFile decrypts a secrets file written by SaveSecretsFile.
+// A missing file yields an empty map.
+func LoadSecretsFile(path string, passphrase string) (map[string]string, error) {
+	secrets := make(map[string]string)
+
+	data, err := readEncrypted(path, passphrase)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return secrets, nil
+		}
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, &secrets); err != nil {
+		return nil, fmt.Errorf("failed to parse secrets file %s: %w", path, err)
+	}
+
+	return secrets, nil
+}
+
+// SaveSecretsFile encrypts secrets with a key derived from passphrase and writes them to path.
+func SaveSecretsFile(path string, passphrase string, secrets map[string]string) error {
+	data, err := json.Marshal(secrets)
+	if err != nil {
+		return fmt.Errorf("failed to marshal secrets: %w", err)
+	}
+
+	return writeEncrypted(path, passphrase, data)
+}
+
+// readEncrypted reads and decrypts a file written by writeEncrypted
+func readEncrypted(path string, passphrase string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	header := len(encryptedMagic) + encryptedSaltLen
+	if len(data) < header || !bytes.Equal(data[:len(encryptedMagic)], []byte(encryptedMagic)) {
+		return nil, fmt.Errorf("%s is not a ramp encrypted file", path)
+	}
+	salt := data[len(encryptedMagic):header]
+
+	gcm, err := newGCM(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	sealed := data[header:]
+	if len(sealed) < gcm.NonceSize() {
+		return nil, fmt.Errorf("%s: %w", path, ErrWrongPassphrase)
+	}
+
+	plaintext, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], []byte(encryptedMagic))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, ErrWrongPassphrase)
+	}
+
+	return plaintext, nil
+}
+
+// writeEncrypted encrypts plaintext with a fresh salt and nonce and writes it with 0600 permissions
+func writeEncrypted(path string, passphrase string, plaintext []byte) error {
+	salt := make([]byte, encryptedSaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	gcm, err := newGCM(passphrase, salt)
+	if err != nil {
+		return err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(encryptedMagic)
+	buf.Write(salt)
+	buf.Write(nonce)
+	buf.Write(gcm.Seal(nil, nonce, plaintext, []byte(encryptedMagic)))
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", path, err)
+	}
+
+	if err := os.WriteFile(path, buf.Bytes(), 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// newGCM derives an AES-256 key from the passphrase and returns a GCM cipher
+func newGCM(passphrase string, salt []byte) (cipher.AEAD, error) {
+	if passphrase == "" {
+		return nil, fmt.Errorf("passphrase is empty")
+	}
+
+	key, err := pbkdf2.Key(sha256.New, passphrase, salt, pbkdf2Iterations, 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+
+	return cipher.NewGCM(block)
+}
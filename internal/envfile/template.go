@@ -0,0 +1,136 @@
+package envfile
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"ramp/internal/config"
+)
+
+// templateData is the data available to env files rendered with template: true
+type templateData struct {
+	Feature     string            // Feature (worktree) name
+	DisplayName string            // Display name, empty if none was set
+	ProjectDir  string            // Absolute project directory
+	TreesDir    string            // Absolute trees directory for this feature
+	Port        int               // First allocated port, 0 if ports are not configured
+	Ports       []int             // All allocated ports in order
+	Env         map[string]string // Every variable available to env files (RAMP_*, prompt preferences)
+}
+
+var slugPattern = regexp.MustCompile(`[^a-z0-9]+`)
+
+// renderTemplate renders content as a Go text/template.
+// References to missing map keys (e.g. {{ .Env.UNKNOWN }}) are errors rather than
+// being rendered as "<no value>".
+func renderTemplate(name string, content string, envVars map[string]string) (string, error) {
+	tmpl, err := template.New(name).
+		Option("missingkey=error").
+		Funcs(templateFuncs(envVars)).
+		Parse(content)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse template %s: %w", name, err)
+	}
+
+	var b strings.Builder
+	if err := tmpl.Execute(&b, newTemplateData(envVars)); err != nil {
+		return "", fmt.Errorf("failed to render template %s: %w", name, err)
+	}
+
+	return b.String(), nil
+}
+
+// newTemplateData builds the template data from the env file variables
+func newTemplateData(envVars map[string]string) templateData {
+	data := templateData{
+		Feature:     envVars["RAMP_WORKTREE_NAME"],
+		DisplayName: envVars["RAMP_DISPLAY_NAME"],
+		ProjectDir:  envVars["RAMP_PROJECT_DIR"],
+		TreesDir:    envVars["RAMP_TREES_DIR"],
+		Env:         envVars,
+	}
+
+	if port, err := strconv.Atoi(envVars["RAMP_PORT"]); err == nil {
+		data.Port = port
+	}
+
+	// Collect RAMP_PORT_1..N in order, stopping at the first gap
+	for i := 1; ; i++ {
+		port, err := strconv.Atoi(envVars[fmt.Sprintf("RAMP_PORT_%d", i)])
+		if err != nil {
+			break
+		}
+		data.Ports = append(data.Ports, port)
+	}
+
+	return data
+}
+
+// templateFuncs returns the helper functions available to templates
+func templateFuncs(envVars map[string]string) template.FuncMap {
+	return template.FuncMap{
+		// default returns fallback when value is empty: {{ .DisplayName | default .Feature }}
+		"default": func(fallback string, value interface{}) string {
+			if value == nil {
+				return fallback
+			}
+			if s := fmt.Sprint(value); s != "" {
+				return s
+			}
+			return fallback
+		},
+		"lower": strings.ToLower,
+		"upper": strings.ToUpper,
+		"slug":  slugify,
+		// env returns a variable by name, failing if it is not defined
+		"env": func(name string) (string, error) {
+			value, ok := envVars[name]
+			if !ok {
+				return "", fmt.Errorf("environment variable %s is not defined", name)
+			}
+			return value, nil
+		},
+		// portFor returns a named port from port_names, or an indexed port ("1", "2", ...)
+		"portFor": func(name string) (int, error) {
+			key := config.GeneratePortEnvVarName(name)
+			value, ok := envVars[key]
+			if !ok {
+				return 0, fmt.Errorf("no port named %q (available: %s)", name, strings.Join(portNames(envVars), ", "))
+			}
+			return strconv.Atoi(value)
+		},
+		// repoPath returns the source checkout path of a repository by name
+		"repoPath": func(name string) (string, error) {
+			value, ok := envVars[config.GenerateEnvVarName(name)]
+			if !ok {
+				return "", fmt.Errorf("unknown repository %q", name)
+			}
+			return value, nil
+		},
+	}
+}
+
+// slugify lowercases a string and collapses anything that is not a letter or
+// digit into single dashes, e.g. "My Feature_2" -> "my-feature-2"
+func slugify(s string) string {
+	return strings.Trim(slugPattern.ReplaceAllString(strings.ToLower(s), "-"), "-")
+}
+
+// portNames lists the port suffixes present in envVars for error messages
+func portNames(envVars map[string]string) []string {
+	var names []string
+	for key := range envVars {
+		if strings.HasPrefix(key, "RAMP_PORT_") {
+			names = append(names, strings.ToLower(strings.TrimPrefix(key, "RAMP_PORT_")))
+		}
+	}
+	sort.Strings(names)
+	if len(names) == 0 {
+		return []string{"none"}
+	}
+	return names
+}
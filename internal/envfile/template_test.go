@@ -0,0 +1,176 @@
+package envfile
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ramp/internal/config"
+)
+
+// TestRenderTemplate tests template data and helper functions
+func TestRenderTemplate(t *testing.T) {
+	envVars := map[string]string{
+		"RAMP_WORKTREE_NAME": "My Feature_2",
+		"RAMP_DISPLAY_NAME":  "",
+		"RAMP_PROJECT_DIR":   "/projects/demo",
+		"RAMP_TREES_DIR":     "/projects/demo/trees/my-feature",
+		"RAMP_PORT":          "3000",
+		"RAMP_PORT_1":        "3000",
+		"RAMP_PORT_2":        "3001",
+		"RAMP_PORT_WEB":      "3000",
+		"RAMP_PORT_API":      "3001",
+		"RAMP_REPO_PATH_APP": "/projects/demo/repos/app",
+		"RAMP_DATABASE":      "postgres",
+	}
+
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			name:    "feature and ports",
+			content: "{{ .Feature }} {{ .Port }} {{ index .Ports 1 }}",
+			want:    "My Feature_2 3000 3001",
+		},
+		{
+			name:    "named ports",
+			content: "listen {{ portFor \"web\" }}; proxy_pass http://localhost:{{ portFor \"api\" }};",
+			want:    "listen 3000; proxy_pass http://localhost:3001;",
+		},
+		{
+			name:    "indexed port via portFor",
+			content: "{{ portFor \"2\" }}",
+			want:    "3001",
+		},
+		{
+			name:    "default falls back on empty values",
+			content: "{{ .DisplayName | default .Feature }}",
+			want:    "My Feature_2",
+		},
+		{
+			name:    "string helpers",
+			content: "{{ slug .Feature }} {{ upper \"db\" }} {{ lower .Env.RAMP_DATABASE }}",
+			want:    "my-feature-2 DB postgres",
+		},
+		{
+			name:    "repo paths and env lookups",
+			content: "{{ repoPath \"app\" }} {{ env \"RAMP_DATABASE\" }}",
+			want:    "/projects/demo/repos/app postgres",
+		},
+		{
+			name:    "range over ports",
+			content: "{{ range $i, $p := .Ports }}{{ if $i }},{{ end }}{{ $p }}{{ end }}",
+			want:    "3000,3001",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := renderTemplate("test", tt.content, envVars)
+			if err != nil {
+				t.Fatalf("renderTemplate() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("renderTemplate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestRenderTemplateErrors tests that undefined references fail instead of passing through
+func TestRenderTemplateErrors(t *testing.T) {
+	envVars := map[string]string{
+		"RAMP_WORKTREE_NAME": "feature",
+		"RAMP_PORT_WEB":      "3000",
+	}
+
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{name: "missing env key", content: "{{ .Env.UNDEFINED }}", wantErr: "UNDEFINED"},
+		{name: "unknown field", content: "{{ .Unknown }}", wantErr: "Unknown"},
+		{name: "unknown port", content: "{{ portFor \"db\" }}", wantErr: "no port named \"db\""},
+		{name: "unknown repo", content: "{{ repoPath \"nope\" }}", wantErr: "unknown repository"},
+		{name: "undefined env function lookup", content: "{{ env \"NOPE\" }}", wantErr: "NOPE is not defined"},
+		{name: "parse error", content: "{{ .Feature ", wantErr: "failed to parse template"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := renderTemplate("test", tt.content, envVars)
+			if err == nil {
+				t.Fatal("renderTemplate() expected error, got nil")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("renderTemplate() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestProcessEnvFilesTemplate tests template: true through ProcessEnvFiles
+func TestProcessEnvFilesTemplate(t *testing.T) {
+	t.Run("renders template and skips ${} substitution", func(t *testing.T) {
+		tempDir := t.TempDir()
+		sourceRepoDir := filepath.Join(tempDir, "source")
+		worktreeDir := filepath.Join(tempDir, "worktree")
+
+		os.MkdirAll(sourceRepoDir, 0755)
+		os.MkdirAll(worktreeDir, 0755)
+
+		tmpl := "services:\n  web:\n    ports: [\"{{ portFor \"web\" }}:80\"]\n    environment:\n      NAME: ${RAMP_WORKTREE_NAME}\n"
+		os.WriteFile(filepath.Join(sourceRepoDir, "compose.tmpl"), []byte(tmpl), 0644)
+
+		envFiles := []config.EnvFile{
+			{Source: "compose.tmpl", Dest: "docker-compose.override.yml", Template: true},
+		}
+		envVars := map[string]string{
+			"RAMP_WORKTREE_NAME": "feature",
+			"RAMP_PORT_WEB":      "3000",
+		}
+
+		if err := ProcessEnvFiles("app", envFiles, sourceRepoDir, worktreeDir, envVars, false); err != nil {
+			t.Fatalf("ProcessEnvFiles() error = %v", err)
+		}
+
+		content, err := os.ReadFile(filepath.Join(worktreeDir, "docker-compose.override.yml"))
+		if err != nil {
+			t.Fatalf("failed to read destination file: %v", err)
+		}
+
+		// ${...} is left for docker compose to interpolate
+		expected := "services:\n  web:\n    ports: [\"3000:80\"]\n    environment:\n      NAME: ${RAMP_WORKTREE_NAME}\n"
+		if string(content) != expected {
+			t.Errorf("destination file content mismatch\ngot:\n%s\nwant:\n%s", string(content), expected)
+		}
+	})
+
+	t.Run("undefined reference fails and writes nothing", func(t *testing.T) {
+		tempDir := t.TempDir()
+		sourceRepoDir := filepath.Join(tempDir, "source")
+		worktreeDir := filepath.Join(tempDir, "worktree")
+
+		os.MkdirAll(sourceRepoDir, 0755)
+		os.MkdirAll(worktreeDir, 0755)
+
+		os.WriteFile(filepath.Join(sourceRepoDir, "settings.json.tmpl"), []byte(`{"db": "{{ .Env.DATABASE_URL }}"}`), 0644)
+
+		envFiles := []config.EnvFile{
+			{Source: "settings.json.tmpl", Dest: "settings.json", Template: true},
+		}
+
+		err := ProcessEnvFiles("app", envFiles, sourceRepoDir, worktreeDir, map[string]string{}, false)
+		if err == nil {
+			t.Fatal("ProcessEnvFiles() expected error for undefined reference")
+		}
+
+		if _, statErr := os.Stat(filepath.Join(worktreeDir, "settings.json")); !os.IsNotExist(statErr) {
+			t.Error("destination file should not be written when rendering fails")
+		}
+	})
+}
@@ -1,17 +1,99 @@
 package features
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+	"time"
 )
 
 const MetadataFile = "feature_metadata.json"
 
 // FeatureMetadata holds metadata for a single feature.
 type FeatureMetadata struct {
-	DisplayName string `json:"displayName,omitempty"`
+	DisplayName string                      `json:"displayName,omitempty"`
+	Created     *CreationInfo               `json:"created,omitempty"` // Nil for features created before it was recorded
+	Notes       []Note                      `json:"notes,omitempty"`
+	Tags        []string                    `json:"tags,omitempty"`     // Sorted, see NormalizeTag
+	Links       map[string]string           `json:"links,omitempty"`    // Label such as "ticket" or "pr" to URL
+	EnvFiles    map[string]GeneratedEnvFile `json:"envFiles,omitempty"` // Keyed by EnvFileKey
+	Sync        *SyncState                  `json:"sync,omitempty"`     // Set while ramp sync is stopped on conflicts
+}
+
+// CreationInfo records when, by whom and how ramp up created a feature.
+type CreationInfo struct {
+	At          time.Time         `json:"at"`
+	By          string            `json:"by,omitempty"`          // git user.name, or the OS user
+	Target      string            `json:"target,omitempty"`      // --target the branches were created from
+	Prefix      string            `json:"prefix"`                // Branch prefix the feature's branches got
+	BaseCommits map[string]string `json:"baseCommits,omitempty"` // Repo to the commit its worktree started at
+}
+
+// Note is a free-form note added to a feature.
+type Note struct {
+	Text  string    `json:"text"`
+	Added time.Time `json:"added"`
+}
+
+// SyncState records a ramp sync that stopped on conflicts, so it can be
+// continued or aborted later.
+type SyncState struct {
+	Mode    string            `json:"mode"`              // "rebase" or "merge"
+	Repos   []string          `json:"repos"`             // Repos with a rebase or merge still in progress
+	Stashes map[string]string `json:"stashes,omitempty"` // Repo to the stash commit of its uncommitted changes, stashed before syncing
+}
+
+// GeneratedEnvFile records an env file as ramp last generated it, so local edits
+// can be detected and merged when the file is regenerated.
+type GeneratedEnvFile struct {
+	Checksum string `json:"checksum"` // sha256 of the content written to the worktree
+	Content  string `json:"content"`  // Generated content with secrets unresolved, used as the merge base
+}
+
+// NewGeneratedEnvFile creates a record for a freshly written env file. written is
+// the file content; base is the same content with secret references unresolved.
+func NewGeneratedEnvFile(written, base string) GeneratedEnvFile {
+	return GeneratedEnvFile{Checksum: Checksum(written), Content: base}
+}
+
+// Checksum returns the hex-encoded sha256 of content.
+func Checksum(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// EnvFileKey returns the metadata key for a repo's env file destination.
+func EnvFileKey(repoName, dest string) string {
+	return filepath.ToSlash(filepath.Join(repoName, dest))
+}
+
+// NormalizeTag lowercases a tag and checks that it is a single word.
+func NormalizeTag(tag string) (string, error) {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if tag == "" || strings.ContainsAny(tag, " \t,") {
+		return "", fmt.Errorf("invalid tag '%s': tags are single words without commas", tag)
+	}
+	return tag, nil
+}
+
+// HasTag reports whether the feature is tagged with tag.
+func (m FeatureMetadata) HasTag(tag string) bool {
+	for _, t := range m.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+func (m FeatureMetadata) isEmpty() bool {
+	return m.DisplayName == "" && m.Created == nil && len(m.Notes) == 0 && len(m.Tags) == 0 &&
+		len(m.Links) == 0 && len(m.EnvFiles) == 0 && m.Sync == nil
 }
 
 // MetadataStore manages feature metadata persistence.
@@ -88,22 +170,196 @@ func (ms *MetadataStore) GetDisplayName(featureName string) string {
 // SetDisplayName sets the display name for a feature.
 // Pass empty string to clear the display name.
 func (ms *MetadataStore) SetDisplayName(featureName, displayName string) error {
-	if displayName == "" {
-		// Remove the entry if display name is cleared
-		delete(ms.metadata, featureName)
+	meta := ms.metadata[featureName]
+	meta.DisplayName = displayName
+	ms.put(featureName, meta)
+
+	if err := ms.save(); err != nil {
+		return fmt.Errorf("failed to save display name: %w", err)
+	}
+
+	return nil
+}
+
+// GetEnvFiles returns the generated env file records for a feature.
+func (ms *MetadataStore) GetEnvFiles(featureName string) map[string]GeneratedEnvFile {
+	result := make(map[string]GeneratedEnvFile)
+	for key, file := range ms.metadata[featureName].EnvFiles {
+		result[key] = file
+	}
+	return result
+}
+
+// SetEnvFiles records generated env files for a feature, replacing existing
+// records with the same keys and keeping the rest.
+func (ms *MetadataStore) SetEnvFiles(featureName string, files map[string]GeneratedEnvFile) error {
+	if len(files) == 0 {
+		return nil
+	}
+
+	meta := ms.metadata[featureName]
+	envFiles := make(map[string]GeneratedEnvFile, len(meta.EnvFiles)+len(files))
+	for key, file := range meta.EnvFiles {
+		envFiles[key] = file
+	}
+	for key, file := range files {
+		envFiles[key] = file
+	}
+	meta.EnvFiles = envFiles
+	ms.put(featureName, meta)
+
+	if err := ms.save(); err != nil {
+		return fmt.Errorf("failed to save env file records: %w", err)
+	}
+
+	return nil
+}
+
+// GetSync returns the stopped sync of a feature, or nil if none is in progress.
+func (ms *MetadataStore) GetSync(featureName string) *SyncState {
+	return ms.metadata[featureName].Sync
+}
+
+// SetSync records a stopped sync for a feature. Pass nil once it has finished.
+func (ms *MetadataStore) SetSync(featureName string, state *SyncState) error {
+	meta := ms.metadata[featureName]
+	meta.Sync = state
+	ms.put(featureName, meta)
+
+	if err := ms.save(); err != nil {
+		return fmt.Errorf("failed to save sync state: %w", err)
+	}
+
+	return nil
+}
+
+// Get returns all metadata of a feature; the zero value if it has none.
+func (ms *MetadataStore) Get(featureName string) FeatureMetadata {
+	return ms.metadata[featureName]
+}
+
+// SetCreated records how a feature was created.
+func (ms *MetadataStore) SetCreated(featureName string, info *CreationInfo) error {
+	meta := ms.metadata[featureName]
+	meta.Created = info
+	ms.put(featureName, meta)
+
+	if err := ms.save(); err != nil {
+		return fmt.Errorf("failed to save creation info: %w", err)
+	}
+
+	return nil
+}
+
+// AddNote appends a note to a feature.
+func (ms *MetadataStore) AddNote(featureName, text string) error {
+	meta := ms.metadata[featureName]
+	meta.Notes = append(append([]Note{}, meta.Notes...), Note{Text: text, Added: time.Now()})
+	ms.put(featureName, meta)
+
+	if err := ms.save(); err != nil {
+		return fmt.Errorf("failed to save note: %w", err)
+	}
+
+	return nil
+}
+
+// ClearNotes removes all notes of a feature.
+func (ms *MetadataStore) ClearNotes(featureName string) error {
+	meta := ms.metadata[featureName]
+	meta.Notes = nil
+	ms.put(featureName, meta)
+
+	if err := ms.save(); err != nil {
+		return fmt.Errorf("failed to save notes: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateTags adds and removes tags of a feature. Tags must already be
+// normalized with NormalizeTag.
+func (ms *MetadataStore) UpdateTags(featureName string, add, remove []string) error {
+	meta := ms.metadata[featureName]
+	tags := make(map[string]bool)
+	for _, tag := range meta.Tags {
+		tags[tag] = true
+	}
+	for _, tag := range add {
+		tags[tag] = true
+	}
+	for _, tag := range remove {
+		delete(tags, tag)
+	}
+
+	meta.Tags = nil
+	for tag := range tags {
+		meta.Tags = append(meta.Tags, tag)
+	}
+	sort.Strings(meta.Tags)
+	ms.put(featureName, meta)
+
+	if err := ms.save(); err != nil {
+		return fmt.Errorf("failed to save tags: %w", err)
+	}
+
+	return nil
+}
+
+// SetLink sets the URL of a feature's link, such as its ticket or PR.
+// Pass an empty url to remove the link.
+func (ms *MetadataStore) SetLink(featureName, label, url string) error {
+	meta := ms.metadata[featureName]
+	links := make(map[string]string, len(meta.Links)+1)
+	for l, u := range meta.Links {
+		links[l] = u
+	}
+	if url == "" {
+		delete(links, label)
 	} else {
-		ms.metadata[featureName] = FeatureMetadata{
-			DisplayName: displayName,
-		}
+		links[label] = url
+	}
+	meta.Links = nil
+	if len(links) > 0 {
+		meta.Links = links
 	}
+	ms.put(featureName, meta)
 
 	if err := ms.save(); err != nil {
-		return fmt.Errorf("failed to save display name: %w", err)
+		return fmt.Errorf("failed to save link: %w", err)
 	}
 
 	return nil
 }
 
+// RestoreFrom gives a feature the display name, creation info, notes, tags
+// and links of metadata saved elsewhere, such as in an archive. Env file
+// records and sync state are left as they are.
+func (ms *MetadataStore) RestoreFrom(featureName string, saved FeatureMetadata) error {
+	meta := ms.metadata[featureName]
+	meta.DisplayName = saved.DisplayName
+	meta.Created = saved.Created
+	meta.Notes = saved.Notes
+	meta.Tags = saved.Tags
+	meta.Links = saved.Links
+	ms.put(featureName, meta)
+
+	if err := ms.save(); err != nil {
+		return fmt.Errorf("failed to save restored metadata: %w", err)
+	}
+
+	return nil
+}
+
+// put stores metadata for a feature, removing the entry once it is empty
+func (ms *MetadataStore) put(featureName string, meta FeatureMetadata) {
+	if meta.isEmpty() {
+		delete(ms.metadata, featureName)
+	} else {
+		ms.metadata[featureName] = meta
+	}
+}
+
 // RemoveFeature removes all metadata for a feature.
 func (ms *MetadataStore) RemoveFeature(featureName string) error {
 	if _, exists := ms.metadata[featureName]; !exists {
@@ -119,6 +375,23 @@ func (ms *MetadataStore) RemoveFeature(featureName string) error {
 	return nil
 }
 
+// RenameFeature moves the metadata of a feature to a new name, replacing any
+// metadata stored under it.
+func (ms *MetadataStore) RenameFeature(featureName, newName string) error {
+	meta, exists := ms.metadata[featureName]
+	if !exists {
+		return nil
+	}
+
+	delete(ms.metadata, featureName)
+	ms.metadata[newName] = meta
+	if err := ms.save(); err != nil {
+		return fmt.Errorf("failed to save after renaming feature metadata: %w", err)
+	}
+
+	return nil
+}
+
 // ListMetadata returns a copy of all feature metadata.
 func (ms *MetadataStore) ListMetadata() map[string]FeatureMetadata {
 	result := make(map[string]FeatureMetadata)
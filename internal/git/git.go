@@ -1,11 +1,13 @@
 package git
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"ramp/internal/ui"
 )
@@ -34,7 +36,41 @@ func Clone(repoURL, destDir string, shallow bool) error {
 	return nil
 }
 
-func CreateWorktreeFromSource(repoDir, worktreeDir, branchName, sourceBranch, repoName string) error {
+// CloneOptions configures CloneQuiet.
+type CloneOptions struct {
+	Shallow   bool   // Clone with --depth 1
+	Filter    string // Partial clone filter, e.g. blob:none (full history, blobs fetched on demand)
+	Reference string // Local repository to borrow objects from via alternates, if it exists
+}
+
+// CloneQuiet clones repoURL into destDir without a spinner, so several clones
+// can run at once. Git's output is included in the error on failure.
+func CloneQuiet(repoURL, destDir string, opts CloneOptions) error {
+	if err := os.MkdirAll(filepath.Dir(destDir), 0755); err != nil {
+		return fmt.Errorf("failed to create directory %s: %w", filepath.Dir(destDir), err)
+	}
+
+	args := []string{"clone", "--quiet"}
+	if opts.Shallow {
+		args = append(args, "--depth", "1")
+	}
+	if opts.Filter != "" {
+		args = append(args, "--filter="+opts.Filter)
+	}
+	if opts.Reference != "" {
+		args = append(args, "--reference-if-able", opts.Reference)
+	}
+	args = append(args, repoURL, destDir)
+
+	cmd := exec.Command("git", args...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to clone %s to %s: %w\n%s", repoURL, destDir, err, strings.TrimSpace(string(output)))
+	}
+
+	return nil
+}
+
+func CreateWorktreeFromSource(repoDir, worktreeDir, branchName, sourceBranch, repoName string, sparse ...string) error {
 	if err := os.MkdirAll(filepath.Dir(worktreeDir), 0755); err != nil {
 		return fmt.Errorf("failed to create directory %s: %w", filepath.Dir(worktreeDir), err)
 	}
@@ -60,7 +96,7 @@ func CreateWorktreeFromSource(repoDir, worktreeDir, branchName, sourceBranch, re
 	}
 
 	// Create new branch from source
-	cmd := exec.Command("git", "worktree", "add", "-b", branchName, worktreeDir, sourceBranch)
+	cmd := worktreeAddCommand(sparse, "-b", branchName, worktreeDir, sourceBranch)
 	cmd.Dir = repoDir
 	message := fmt.Sprintf("%s: creating worktree with new branch %s from %s", repoName, branchName, sourceBranch)
 
@@ -68,10 +104,10 @@ func CreateWorktreeFromSource(repoDir, worktreeDir, branchName, sourceBranch, re
 		return fmt.Errorf("failed to create worktree %s with branch %s from %s: %w", worktreeDir, branchName, sourceBranch, err)
 	}
 
-	return nil
+	return checkoutSparse(worktreeDir, branchName, sparse)
 }
 
-func CreateWorktreeFromSourceQuiet(repoDir, worktreeDir, branchName, sourceBranch, repoName string) error {
+func CreateWorktreeFromSourceQuiet(repoDir, worktreeDir, branchName, sourceBranch, repoName string, sparse ...string) error {
 	if err := os.MkdirAll(filepath.Dir(worktreeDir), 0755); err != nil {
 		return fmt.Errorf("failed to create directory %s: %w", filepath.Dir(worktreeDir), err)
 	}
@@ -97,17 +133,17 @@ func CreateWorktreeFromSourceQuiet(repoDir, worktreeDir, branchName, sourceBranc
 	}
 
 	// Create new branch from source
-	cmd := exec.Command("git", "worktree", "add", "-b", branchName, worktreeDir, sourceBranch)
+	cmd := worktreeAddCommand(sparse, "-b", branchName, worktreeDir, sourceBranch)
 	cmd.Dir = repoDir
 
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("failed to create worktree %s with branch %s from %s: %w", worktreeDir, branchName, sourceBranch, err)
 	}
 
-	return nil
+	return checkoutSparse(worktreeDir, branchName, sparse)
 }
 
-func CreateWorktree(repoDir, worktreeDir, branchName, repoName string) error {
+func CreateWorktree(repoDir, worktreeDir, branchName, repoName string, sparse ...string) error {
 	if err := os.MkdirAll(filepath.Dir(worktreeDir), 0755); err != nil {
 		return fmt.Errorf("failed to create directory %s: %w", filepath.Dir(worktreeDir), err)
 	}
@@ -133,7 +169,7 @@ func CreateWorktree(repoDir, worktreeDir, branchName, repoName string) error {
 
 	if localExists {
 		// Use existing local branch
-		cmd = exec.Command("git", "worktree", "add", worktreeDir, branchName)
+		cmd = worktreeAddCommand(sparse, worktreeDir, branchName)
 		message = fmt.Sprintf("%s: creating worktree with existing local branch %s", repoName, branchName)
 	} else if remoteExists {
 		// Create local branch tracking the remote
@@ -141,11 +177,11 @@ func CreateWorktree(repoDir, worktreeDir, branchName, repoName string) error {
 		if err != nil {
 			return fmt.Errorf("failed to get remote branch name: %w", err)
 		}
-		cmd = exec.Command("git", "worktree", "add", "-b", branchName, worktreeDir, remoteBranch)
+		cmd = worktreeAddCommand(sparse, "-b", branchName, worktreeDir, remoteBranch)
 		message = fmt.Sprintf("%s: creating worktree with existing remote branch %s", repoName, branchName)
 	} else {
 		// Create new branch
-		cmd = exec.Command("git", "worktree", "add", "-b", branchName, worktreeDir)
+		cmd = worktreeAddCommand(sparse, "-b", branchName, worktreeDir)
 		message = fmt.Sprintf("%s: creating worktree with new branch %s", repoName, branchName)
 	}
 
@@ -155,10 +191,10 @@ func CreateWorktree(repoDir, worktreeDir, branchName, repoName string) error {
 		return fmt.Errorf("failed to create worktree %s with branch %s: %w", worktreeDir, branchName, err)
 	}
 
-	return nil
+	return checkoutSparse(worktreeDir, branchName, sparse)
 }
 
-func CreateWorktreeQuiet(repoDir, worktreeDir, branchName, repoName string) error {
+func CreateWorktreeQuiet(repoDir, worktreeDir, branchName, repoName string, sparse ...string) error {
 	if err := os.MkdirAll(filepath.Dir(worktreeDir), 0755); err != nil {
 		return fmt.Errorf("failed to create directory %s: %w", filepath.Dir(worktreeDir), err)
 	}
@@ -183,17 +219,17 @@ func CreateWorktreeQuiet(repoDir, worktreeDir, branchName, repoName string) erro
 
 	if localExists {
 		// Use existing local branch
-		cmd = exec.Command("git", "worktree", "add", worktreeDir, branchName)
+		cmd = worktreeAddCommand(sparse, worktreeDir, branchName)
 	} else if remoteExists {
 		// Create local branch tracking the remote
 		remoteBranch, err := getRemoteBranchName(repoDir, branchName)
 		if err != nil {
 			return fmt.Errorf("failed to get remote branch name: %w", err)
 		}
-		cmd = exec.Command("git", "worktree", "add", "-b", branchName, worktreeDir, remoteBranch)
+		cmd = worktreeAddCommand(sparse, "-b", branchName, worktreeDir, remoteBranch)
 	} else {
 		// Create new branch
-		cmd = exec.Command("git", "worktree", "add", "-b", branchName, worktreeDir)
+		cmd = worktreeAddCommand(sparse, "-b", branchName, worktreeDir)
 	}
 
 	cmd.Dir = repoDir
@@ -202,6 +238,121 @@ func CreateWorktreeQuiet(repoDir, worktreeDir, branchName, repoName string) erro
 		return fmt.Errorf("failed to create worktree %s with branch %s: %w", worktreeDir, branchName, err)
 	}
 
+	return checkoutSparse(worktreeDir, branchName, sparse)
+}
+
+// worktreeAddCommand builds "git worktree add" with args, skipping the checkout
+// when sparse patterns will be applied first
+func worktreeAddCommand(sparse []string, args ...string) *exec.Cmd {
+	addArgs := []string{"worktree", "add"}
+	if len(sparse) > 0 {
+		addArgs = append(addArgs, "--no-checkout")
+	}
+	return exec.Command("git", append(addArgs, args...)...)
+}
+
+// checkoutSparse limits a worktree created with --no-checkout to the given cone
+// patterns and then checks out branchName. Does nothing without patterns.
+func checkoutSparse(worktreeDir, branchName string, sparse []string) error {
+	if len(sparse) == 0 {
+		return nil
+	}
+
+	if err := SetSparseCheckout(worktreeDir, sparse); err != nil {
+		return err
+	}
+
+	cmd := exec.Command("git", "checkout", "--quiet", branchName)
+	cmd.Dir = worktreeDir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to check out sparse worktree %s: %w\n%s", worktreeDir, err, strings.TrimSpace(string(output)))
+	}
+
+	return nil
+}
+
+// UpdateSubmodulesQuiet initializes and checks out the submodules of dir,
+// including nested ones when recursive is set.
+func UpdateSubmodulesQuiet(dir string, recursive bool) error {
+	args := []string{"submodule", "update", "--init", "--quiet"}
+	if recursive {
+		args = append(args, "--recursive")
+	}
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to update submodules: %w\n%s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// PullLFSQuiet downloads and checks out Git LFS objects for the current branch of dir.
+func PullLFSQuiet(dir string) error {
+	if err := exec.Command("git", "lfs", "version").Run(); err != nil {
+		return fmt.Errorf("git-lfs is not installed")
+	}
+
+	cmd := exec.Command("git", "lfs", "pull")
+	cmd.Dir = dir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to pull LFS objects: %w\n%s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// IsSparseCheckout reports whether sparse checkout is enabled for the worktree.
+func IsSparseCheckout(worktreeDir string) bool {
+	cmd := exec.Command("git", "config", "--get", "--bool", "core.sparseCheckout")
+	cmd.Dir = worktreeDir
+
+	output, err := cmd.Output()
+	if err != nil {
+		return false
+	}
+	return strings.TrimSpace(string(output)) == "true"
+}
+
+// SparseCheckoutPatterns returns the cone directories checked out in a sparse worktree.
+func SparseCheckoutPatterns(worktreeDir string) ([]string, error) {
+	cmd := exec.Command("git", "sparse-checkout", "list")
+	cmd.Dir = worktreeDir
+
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sparse-checkout patterns: %w", err)
+	}
+
+	var patterns []string
+	for _, line := range strings.Split(string(output), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			patterns = append(patterns, line)
+		}
+	}
+	return patterns, nil
+}
+
+// SetSparseCheckout replaces the worktree's sparse-checkout cone with patterns.
+// Git keeps the setting in the worktree's own config, so the source repository
+// and other worktrees are unaffected.
+func SetSparseCheckout(worktreeDir string, patterns []string) error {
+	cmd := exec.Command("git", append([]string{"sparse-checkout", "set", "--cone", "--"}, patterns...)...)
+	cmd.Dir = worktreeDir
+
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to set sparse-checkout patterns: %w\n%s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// AddSparseCheckout adds directories to a sparse worktree's cone.
+func AddSparseCheckout(worktreeDir string, paths []string) error {
+	cmd := exec.Command("git", append([]string{"sparse-checkout", "add", "--"}, paths...)...)
+	cmd.Dir = worktreeDir
+
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to add sparse-checkout patterns: %w\n%s", err, strings.TrimSpace(string(output)))
+	}
 	return nil
 }
 
@@ -293,6 +444,94 @@ func HasUncommittedChanges(repoDir string) (bool, error) {
 	return strings.TrimSpace(string(output)) != "", nil
 }
 
+// hasTrackedChanges reports whether tracked files of repoDir have staged or
+// unstaged changes, which is what a stash without --include-untracked saves.
+func hasTrackedChanges(repoDir string) (bool, error) {
+	cmd := exec.Command("git", "--no-optional-locks", "status", "--porcelain", "--untracked-files=no")
+	cmd.Dir = repoDir
+
+	output, err := cmd.Output()
+	if err != nil {
+		return false, err
+	}
+
+	return strings.TrimSpace(string(output)) != "", nil
+}
+
+// StageChanges stages changes to tracked files in repoDir, plus untracked files
+// when includeUntracked is set.
+func StageChanges(repoDir string, includeUntracked bool) error {
+	args := []string{"add", "--update"}
+	if includeUntracked {
+		args = []string{"add", "--all"}
+	}
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = repoDir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to stage changes: %w\n%s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// HasStagedChanges reports whether the index of repoDir differs from HEAD.
+func HasStagedChanges(repoDir string) (bool, error) {
+	cmd := exec.Command("git", "--no-optional-locks", "diff", "--cached", "--quiet")
+	cmd.Dir = repoDir
+
+	err := cmd.Run()
+	if err == nil {
+		return false, nil
+	}
+	if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
+		return true, nil
+	}
+	return false, fmt.Errorf("failed to check staged changes: %w", err)
+}
+
+// CommitOptions configures CommitQuiet.
+type CommitOptions struct {
+	Message  string   // Commit message; with Amend, empty keeps the previous message
+	Amend    bool     // Replace the last commit instead of adding one
+	Trailers []string // Trailers such as "Ramp-Feature: my-feature"
+}
+
+// CommitQuiet commits what is staged in repoDir.
+func CommitQuiet(repoDir string, opts CommitOptions) error {
+	args := []string{"commit", "--quiet"}
+	if opts.Amend {
+		args = append(args, "--amend")
+		if opts.Message == "" {
+			args = append(args, "--no-edit")
+		}
+	}
+	if opts.Message != "" {
+		args = append(args, "--message", opts.Message)
+	}
+	for _, trailer := range opts.Trailers {
+		args = append(args, "--trailer", trailer)
+	}
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = repoDir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to commit: %w\n%s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// GetShortCommit returns the abbreviated hash of HEAD in repoDir.
+func GetShortCommit(repoDir string) (string, error) {
+	cmd := exec.Command("git", "--no-optional-locks", "rev-parse", "--short", "HEAD")
+	cmd.Dir = repoDir
+
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to get HEAD commit: %w", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
 func RemoveWorktree(repoDir, worktreeDir string) error {
 	cmd := exec.Command("git", "worktree", "remove", worktreeDir, "--force")
 	cmd.Dir = repoDir
@@ -327,6 +566,74 @@ func PruneWorktrees(repoDir string) error {
 	return nil
 }
 
+// RepairWorktree reconnects a linked worktree and its repository after either
+// was moved by hand, so git finds the worktree at worktreeDir again.
+func RepairWorktree(repoDir, worktreeDir string) error {
+	cmd := exec.Command("git", "worktree", "repair", worktreeDir)
+	cmd.Dir = repoDir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to repair worktree %s: %w\n%s", worktreeDir, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// LinkedWorktreeName returns the name of the entry under a repository's
+// .git/worktrees/ that the .git file of dir points at, or "" if dir isn't a
+// linked worktree. The entry itself may be gone, or have moved with its repo.
+func LinkedWorktreeName(dir string) string {
+	if info, err := os.Stat(filepath.Join(dir, ".git")); err != nil || info.IsDir() {
+		return ""
+	}
+	gitDir := worktreeGitDir(dir)
+	if gitDir == "" || filepath.Base(filepath.Dir(gitDir)) != "worktrees" {
+		return ""
+	}
+	return filepath.Base(gitDir)
+}
+
+// HasWorktreeEntry reports whether a repository has an entry for a linked
+// worktree under .git/worktrees/, as named by LinkedWorktreeName.
+func HasWorktreeEntry(repoDir, name string) bool {
+	gitDir := worktreeGitDir(repoDir)
+	if gitDir == "" {
+		return false
+	}
+	info, err := os.Stat(filepath.Join(gitDir, "worktrees", name))
+	return err == nil && info.IsDir()
+}
+
+// MoveWorktreeQuiet moves a worktree to a new directory, keeping its
+// registration and uncommitted changes.
+func MoveWorktreeQuiet(repoDir, worktreeDir, newDir string) error {
+	cmd := exec.Command("git", "worktree", "move", worktreeDir, newDir)
+	cmd.Dir = repoDir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to move worktree %s: %w\n%s", worktreeDir, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// RenameBranchQuiet renames a local branch, including when it is checked out
+// in a worktree.
+func RenameBranchQuiet(repoDir, branchName, newName string) error {
+	cmd := exec.Command("git", "branch", "-m", branchName, newName)
+	cmd.Dir = repoDir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to rename branch %s: %w\n%s", branchName, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// DeleteRemoteBranchQuiet deletes a branch from origin.
+func DeleteRemoteBranchQuiet(repoDir, branchName string) error {
+	cmd := exec.Command("git", "push", "--quiet", "origin", "--delete", branchName)
+	cmd.Dir = repoDir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to delete remote branch %s: %w\n%s", branchName, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
 func DeleteBranch(repoDir, branchName string) error {
 	cmd := exec.Command("git", "branch", "-D", branchName)
 	cmd.Dir = repoDir
@@ -339,6 +646,17 @@ func DeleteBranch(repoDir, branchName string) error {
 	return nil
 }
 
+// DeleteMergedBranchQuiet deletes a branch with `git branch -d`, which refuses
+// a branch that isn't fully merged.
+func DeleteMergedBranchQuiet(repoDir, branchName string) error {
+	cmd := exec.Command("git", "branch", "-d", branchName)
+	cmd.Dir = repoDir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to delete branch %s: %w\n%s", branchName, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
 func DeleteBranchQuiet(repoDir, branchName string) error {
 	cmd := exec.Command("git", "branch", "-D", branchName)
 	cmd.Dir = repoDir
@@ -426,6 +744,24 @@ func PullQuiet(repoDir string) error {
 	return nil
 }
 
+// PushBranchQuiet pushes branchName to origin and sets it as the branch's upstream.
+// With forceWithLease, a rewritten branch replaces the remote one unless the
+// remote has commits that were never fetched.
+func PushBranchQuiet(repoDir, branchName string, forceWithLease bool) error {
+	args := []string{"push", "--quiet", "--set-upstream"}
+	if forceWithLease {
+		args = append(args, "--force-with-lease")
+	}
+	args = append(args, "origin", branchName)
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = repoDir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to push %s: %w\n%s", branchName, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
 func HasRemoteTrackingBranch(repoDir string) (bool, error) {
 	cmd := exec.Command("git", "--no-optional-locks", "rev-parse", "--abbrev-ref", "--symbolic-full-name", "@{u}")
 	cmd.Dir = repoDir
@@ -516,6 +852,106 @@ func FetchPruneQuiet(repoDir string) error {
 	return nil
 }
 
+// Operations that can be left in progress in a worktree, as reported by InProgressOperation.
+const (
+	OperationNone   = ""
+	OperationRebase = "rebase"
+	OperationMerge  = "merge"
+)
+
+// InProgressOperation reports whether a rebase or merge is stopped in worktreeDir,
+// e.g. waiting for conflicts to be resolved.
+func InProgressOperation(worktreeDir string) string {
+	for _, check := range []struct{ path, operation string }{
+		{"rebase-merge", OperationRebase},
+		{"rebase-apply", OperationRebase},
+		{"MERGE_HEAD", OperationMerge},
+	} {
+		cmd := exec.Command("git", "--no-optional-locks", "rev-parse", "--git-path", check.path)
+		cmd.Dir = worktreeDir
+		output, err := cmd.Output()
+		if err != nil {
+			return OperationNone
+		}
+
+		path := strings.TrimSpace(string(output))
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(worktreeDir, path)
+		}
+		if _, err := os.Stat(path); err == nil {
+			return check.operation
+		}
+	}
+	return OperationNone
+}
+
+// ConflictedFiles returns the files with unresolved conflicts in worktreeDir.
+func ConflictedFiles(worktreeDir string) ([]string, error) {
+	cmd := exec.Command("git", "--no-optional-locks", "diff", "--name-only", "--diff-filter=U")
+	cmd.Dir = worktreeDir
+
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list conflicted files: %w", err)
+	}
+	return strings.Fields(string(output)), nil
+}
+
+// RebaseQuiet rebases the current branch of worktreeDir onto onto. On conflicts
+// the rebase is left in progress; see InProgressOperation.
+func RebaseQuiet(worktreeDir, onto string) error {
+	cmd := exec.Command("git", "rebase", "--quiet", onto)
+	cmd.Dir = worktreeDir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to rebase onto %s: %w\n%s", onto, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// MergeQuiet merges branch into the current branch of worktreeDir. On conflicts
+// the merge is left in progress; see InProgressOperation.
+func MergeQuiet(worktreeDir, branch string) error {
+	cmd := exec.Command("git", "merge", "--quiet", "--no-edit", branch)
+	cmd.Dir = worktreeDir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to merge %s: %w\n%s", branch, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// ContinueOperationQuiet continues the rebase or merge in progress in worktreeDir
+// after its conflicts were resolved, keeping the default commit messages.
+func ContinueOperationQuiet(worktreeDir string) error {
+	operation := InProgressOperation(worktreeDir)
+	if operation == OperationNone {
+		return nil
+	}
+
+	// core.editor=true accepts the prepared commit message without opening an editor
+	cmd := exec.Command("git", "-c", "core.editor=true", operation, "--continue")
+	cmd.Dir = worktreeDir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to continue %s: %w\n%s", operation, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// AbortOperationQuiet aborts the rebase or merge in progress in worktreeDir,
+// restoring the branch to where it was before.
+func AbortOperationQuiet(worktreeDir string) error {
+	operation := InProgressOperation(worktreeDir)
+	if operation == OperationNone {
+		return nil
+	}
+
+	cmd := exec.Command("git", operation, "--abort")
+	cmd.Dir = worktreeDir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to abort %s: %w\n%s", operation, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
 func StashChanges(repoDir string) (bool, error) {
 	// First check if there are changes to stash
 	hasChanges, err := HasUncommittedChanges(repoDir)
@@ -582,6 +1018,92 @@ func PopStashQuiet(repoDir string) error {
 	return nil
 }
 
+// StashPushQuiet stashes the changes to tracked files of a worktree and
+// returns the stash commit, or "" if there was nothing to stash; untracked
+// files stay in place. Every worktree of a repository shares one stash, so
+// callers keep the commit to restore exactly this entry with
+// PopStashCommitQuiet, wherever it ends up in the stash list.
+func StashPushQuiet(worktreeDir, message string) (string, error) {
+	hasChanges, err := hasTrackedChanges(worktreeDir)
+	if err != nil {
+		return "", err
+	}
+	if !hasChanges {
+		return "", nil
+	}
+
+	// A unique message finds the entry even if another worktree stashes meanwhile
+	message = fmt.Sprintf("%s %d-%d", message, os.Getpid(), time.Now().UnixNano())
+	cmd := exec.Command("git", "stash", "push", "-m", message, "--quiet")
+	cmd.Dir = worktreeDir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return "", fmt.Errorf("failed to stash changes: %w\n%s", err, strings.TrimSpace(string(output)))
+	}
+
+	entries, err := stashEntries(worktreeDir)
+	if err != nil {
+		return "", err
+	}
+	for _, entry := range entries {
+		if strings.HasSuffix(entry.subject, message) {
+			return entry.commit, nil
+		}
+	}
+	return "", fmt.Errorf("failed to find the stash entry just created")
+}
+
+// PopStashCommitQuiet applies a stash commit from StashPushQuiet to the
+// worktree and drops its entry from the stash. If the changes don't apply
+// cleanly the entry is kept.
+func PopStashCommitQuiet(worktreeDir, stash string) error {
+	cmd := exec.Command("git", "stash", "apply", "--quiet", stash)
+	cmd.Dir = worktreeDir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to apply stash %.7s: %w\n%s", stash, err, strings.TrimSpace(string(output)))
+	}
+
+	entries, err := stashEntries(worktreeDir)
+	if err != nil {
+		return err
+	}
+	for i, entry := range entries {
+		if entry.commit != stash {
+			continue
+		}
+		cmd := exec.Command("git", "stash", "drop", "--quiet", fmt.Sprintf("stash@{%d}", i))
+		cmd.Dir = worktreeDir
+		if output, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("applied stash %.7s but failed to drop it: %w\n%s", stash, err, strings.TrimSpace(string(output)))
+		}
+		return nil
+	}
+	// Already dropped by hand; the changes are applied all the same
+	return nil
+}
+
+type stashEntry struct {
+	commit  string
+	subject string
+}
+
+// stashEntries lists the repository's stash, newest first, as stash@{n} numbers them.
+func stashEntries(worktreeDir string) ([]stashEntry, error) {
+	cmd := exec.Command("git", "--no-optional-locks", "stash", "list", "--format=%H%x00%gs")
+	cmd.Dir = worktreeDir
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stash: %w", err)
+	}
+
+	var entries []stashEntry
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if commit, subject, ok := strings.Cut(line, "\x00"); ok {
+			entries = append(entries, stashEntry{commit: commit, subject: subject})
+		}
+	}
+	return entries, nil
+}
+
 func CheckoutRemoteBranch(repoDir, branchName string) error {
 	// First try to fetch the branch
 	if err := FetchBranch(repoDir, branchName); err != nil {
@@ -664,9 +1186,14 @@ func validateRemoteBranch(repoDir, remoteBranch string) error {
 
 func ResolveSourceBranch(repoDir, target, effectivePrefix string) (string, error) {
 	// If target starts with a remote prefix, validate as remote branch
-	if strings.HasPrefix(target, "origin/") {
+	if strings.HasPrefix(target, "origin/") || strings.HasPrefix(target, UpstreamRemote+"/") {
 		// Validate that the remote branch actually exists
 		if err := validateRemoteBranch(repoDir, target); err != nil {
+			// Forks: a branch that isn't on origin may be on upstream
+			upstreamBranch := UpstreamRemote + "/" + strings.TrimPrefix(target, "origin/")
+			if strings.HasPrefix(target, "origin/") && HasRemote(repoDir, UpstreamRemote) && validateRemoteBranch(repoDir, upstreamBranch) == nil {
+				return upstreamBranch, nil
+			}
 			return "", err
 		}
 		return target, nil
@@ -719,8 +1246,17 @@ func ResolveSourceBranch(repoDir, target, effectivePrefix string) (string, error
 		return "origin/" + featureBranchName, nil
 	}
 
-	return "", fmt.Errorf("target '%s' not found as feature name, branch name, or remote branch", target)
-}
+	// Forks: fall back to branches that only exist on upstream
+	if HasRemote(repoDir, UpstreamRemote) {
+		for _, branch := range []string{target, featureBranchName} {
+			if validateRemoteBranch(repoDir, UpstreamRemote+"/"+branch) == nil {
+				return UpstreamRemote + "/" + branch, nil
+			}
+		}
+	}
+
+	return "", fmt.Errorf("target '%s' not found as feature name, branch name, or remote branch", target)
+}
 
 func GetRemoteTrackingStatus(repoDir string) (string, error) {
 	// Check if current branch has a remote tracking branch
@@ -798,6 +1334,79 @@ func GetDefaultBranch(repoDir string) (string, error) {
 	return "main", nil
 }
 
+// UpstreamRemote is the name of the remote pointing at the repository a fork was made from.
+const UpstreamRemote = "upstream"
+
+// HasRemote reports whether repoDir has a remote with the given name.
+func HasRemote(repoDir, name string) bool {
+	cmd := exec.Command("git", "--no-optional-locks", "remote", "get-url", name)
+	cmd.Dir = repoDir
+	return cmd.Run() == nil
+}
+
+// EnsureRemote adds the named remote, or points it at url if it already exists.
+func EnsureRemote(repoDir, name, url string) error {
+	args := []string{"remote", "add", name, url}
+	if HasRemote(repoDir, name) {
+		args = []string{"remote", "set-url", name, url}
+	}
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = repoDir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to configure remote %s: %w\n%s", name, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// FetchRemoteQuiet fetches the named remote and records its default branch
+// (refs/remotes/<name>/HEAD) so GetRemoteDefaultBranch can find it.
+func FetchRemoteQuiet(repoDir, name string) error {
+	cmd := exec.Command("git", "fetch", "--quiet", name)
+	cmd.Dir = repoDir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to fetch %s: %w\n%s", name, err, strings.TrimSpace(string(output)))
+	}
+
+	// Not fatal: GetRemoteDefaultBranch falls back to main/master
+	setHead := exec.Command("git", "remote", "set-head", name, "--auto")
+	setHead.Dir = repoDir
+	_ = setHead.Run()
+
+	return nil
+}
+
+// GetRemoteDefaultBranch returns the default branch of a remote as a
+// remote-tracking ref, e.g. "upstream/main".
+func GetRemoteDefaultBranch(repoDir, remote string) (string, error) {
+	cmd := exec.Command("git", "--no-optional-locks", "symbolic-ref", "refs/remotes/"+remote+"/HEAD")
+	cmd.Dir = repoDir
+
+	if output, err := cmd.Output(); err == nil {
+		return strings.TrimPrefix(strings.TrimSpace(string(output)), "refs/remotes/"), nil
+	}
+
+	// HEAD is only recorded on clone or set-head; fall back to common names
+	for _, branch := range []string{"main", "master"} {
+		if validateRemoteBranch(repoDir, remote+"/"+branch) == nil {
+			return remote + "/" + branch, nil
+		}
+	}
+
+	return "", fmt.Errorf("no default branch found for remote %s", remote)
+}
+
+// UnsetBranchUpstream removes the remote-tracking configuration of branchName,
+// e.g. after branching from upstream/main so pushes don't default to upstream.
+func UnsetBranchUpstream(repoDir, branchName string) error {
+	cmd := exec.Command("git", "branch", "--unset-upstream", branchName)
+	cmd.Dir = repoDir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to unset upstream of %s: %w\n%s", branchName, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
 func GetAheadBehindCount(worktreeDir, baseBranch string) (ahead int, behind int, err error) {
 	// Get ahead/behind status compared to base branch
 	cmd := exec.Command("git", "--no-optional-locks", "rev-list", "--count", "--left-right", fmt.Sprintf("HEAD...%s", baseBranch))
@@ -829,10 +1438,16 @@ func GetAheadBehindCount(worktreeDir, baseBranch string) (ahead int, behind int,
 }
 
 func IsMergedInto(worktreeDir, targetBranch string) (bool, error) {
-	// Use git merge-base to check if HEAD is an ancestor of targetBranch
-	// This means all commits from current branch are in targetBranch
-	cmd := exec.Command("git", "--no-optional-locks", "merge-base", "--is-ancestor", "HEAD", targetBranch)
-	cmd.Dir = worktreeDir
+	return IsAncestor(worktreeDir, "HEAD", targetBranch)
+}
+
+// IsAncestor reports whether every commit of rev is in targetBranch, e.g.
+// whether a branch that isn't checked out was merged.
+func IsAncestor(repoDir, rev, targetBranch string) (bool, error) {
+	// Use git merge-base to check if rev is an ancestor of targetBranch
+	// This means all commits from rev are in targetBranch
+	cmd := exec.Command("git", "--no-optional-locks", "merge-base", "--is-ancestor", rev, targetBranch)
+	cmd.Dir = repoDir
 
 	err := cmd.Run()
 	if err != nil {
@@ -844,10 +1459,146 @@ func IsMergedInto(worktreeDir, targetBranch string) (bool, error) {
 		return false, fmt.Errorf("failed to check merge status: %w", err)
 	}
 
-	// Exit code 0 means HEAD is an ancestor of targetBranch (merged)
+	// Exit code 0 means rev is an ancestor of targetBranch (merged)
+	return true, nil
+}
+
+// MergeMethod identifies the check that found a branch merged.
+type MergeMethod string
+
+const (
+	MergeMethodNone          MergeMethod = ""
+	MergeMethodAncestor      MergeMethod = "ancestor"       // HEAD is an ancestor of the target (merge commit or fast-forward)
+	MergeMethodPatchID       MergeMethod = "patch-id"       // Every commit has an equivalent patch in the target (rebase and merge)
+	MergeMethodTree          MergeMethod = "tree"           // Merging HEAD into the target would not change its tree (squash and merge)
+	MergeMethodRemoteDeleted MergeMethod = "remote-deleted" // The pushed upstream branch was deleted, as hosts do after merging (or closing)
+)
+
+// DetectMerge reports whether the branch checked out in worktreeDir has been merged
+// into targetBranch, and which check decided it. Checks run from cheapest to most
+// permissive: ancestry, patch-id equivalence of every commit (git cherry), tree
+// equality after a trial merge, and finally an upstream branch that no longer exists.
+// That last one is only a hint: closing a pull request deletes its branch too, and
+// once pruned, the commits the branch was last pushed with are no longer known.
+func DetectMerge(worktreeDir, targetBranch string) (MergeMethod, error) {
+	merged, err := IsMergedInto(worktreeDir, targetBranch)
+	if err != nil {
+		return MergeMethodNone, err
+	}
+	if merged {
+		return MergeMethodAncestor, nil
+	}
+
+	if method := mergedByContent(worktreeDir, targetBranch); method != MergeMethodNone {
+		return method, nil
+	}
+
+	if gone, err := upstreamGone(worktreeDir); err == nil && gone {
+		return MergeMethodRemoteDeleted, nil
+	}
+
+	return MergeMethodNone, nil
+}
+
+// DetectDivergedMerge runs the rest of DetectMerge's checks for a HEAD already
+// known not to be an ancestor of targetBranch, given whether its upstream is gone.
+// Batched status reads use it to skip the subprocesses for what they already know.
+func DetectDivergedMerge(worktreeDir, targetBranch string, upstreamGone bool) MergeMethod {
+	if method := mergedByContent(worktreeDir, targetBranch); method != MergeMethodNone {
+		return method
+	}
+	if upstreamGone {
+		return MergeMethodRemoteDeleted
+	}
+	return MergeMethodNone
+}
+
+// mergedByContent reports whether HEAD's changes reached targetBranch through a
+// rebase or squash merge
+func mergedByContent(worktreeDir, targetBranch string) MergeMethod {
+	if applied, err := commitsApplied(worktreeDir, targetBranch); err == nil && applied {
+		return MergeMethodPatchID
+	}
+
+	if unchanged, err := mergeLeavesTreeUnchanged(worktreeDir, targetBranch); err == nil && unchanged {
+		return MergeMethodTree
+	}
+
+	return MergeMethodNone
+}
+
+// commitsApplied reports whether every commit on HEAD that is not in targetBranch
+// has a patch-equivalent commit in targetBranch
+func commitsApplied(worktreeDir, targetBranch string) (bool, error) {
+	cmd := exec.Command("git", "--no-optional-locks", "cherry", targetBranch, "HEAD")
+	cmd.Dir = worktreeDir
+
+	output, err := cmd.Output()
+	if err != nil {
+		return false, fmt.Errorf("failed to compare patches: %w", err)
+	}
+
+	lines := strings.Fields(strings.TrimSpace(string(output)))
+	if len(lines) == 0 {
+		return false, nil
+	}
+
+	// Output alternates "+"/"-" markers and commit hashes; "-" means an equivalent exists
+	for i := 0; i < len(lines); i += 2 {
+		if lines[i] != "-" {
+			return false, nil
+		}
+	}
 	return true, nil
 }
 
+// mergeLeavesTreeUnchanged reports whether merging HEAD into targetBranch would
+// produce targetBranch's own tree, i.e. all of HEAD's changes are already there
+func mergeLeavesTreeUnchanged(worktreeDir, targetBranch string) (bool, error) {
+	cmd := exec.Command("git", "--no-optional-locks", "merge-tree", "--write-tree", targetBranch, "HEAD")
+	cmd.Dir = worktreeDir
+
+	output, err := cmd.Output()
+	if err != nil {
+		// Exit code 1 means the merge conflicts, so the changes are not in the target
+		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to run trial merge: %w", err)
+	}
+
+	mergedTree, _, _ := strings.Cut(strings.TrimSpace(string(output)), "\n")
+
+	cmd = exec.Command("git", "--no-optional-locks", "rev-parse", targetBranch+"^{tree}")
+	cmd.Dir = worktreeDir
+
+	output, err = cmd.Output()
+	if err != nil {
+		return false, fmt.Errorf("failed to resolve tree of %s: %w", targetBranch, err)
+	}
+
+	return mergedTree == strings.TrimSpace(string(output)), nil
+}
+
+// upstreamGone reports whether HEAD's branch tracks a remote branch that no longer
+// exists. Requires a fetch with --prune to notice deletions.
+func upstreamGone(worktreeDir string) (bool, error) {
+	branch, err := GetWorktreeBranch(worktreeDir)
+	if err != nil {
+		return false, err
+	}
+
+	cmd := exec.Command("git", "--no-optional-locks", "for-each-ref", "--format=%(upstream:track)", "refs/heads/"+branch)
+	cmd.Dir = worktreeDir
+
+	output, err := cmd.Output()
+	if err != nil {
+		return false, fmt.Errorf("failed to read upstream of %s: %w", branch, err)
+	}
+
+	return strings.TrimSpace(string(output)) == "[gone]", nil
+}
+
 type DiffStats struct {
 	FilesChanged int
 	Insertions   int
@@ -935,6 +1686,227 @@ func GetStatusStats(repoDir string) (*StatusStats, error) {
 	return stats, nil
 }
 
+// WorktreeStatus is a snapshot of a worktree read from a single
+// `git status --porcelain=v2 --branch` call.
+type WorktreeStatus struct {
+	Branch   string // Empty when HEAD is detached
+	Head     string // Commit HEAD points at; empty before the first commit
+	Upstream string // Tracking branch, e.g. "origin/my-feature"
+	Stats    StatusStats
+	Unstaged bool // Tracked files have unstaged changes, i.e. `git diff` is not empty
+	Sparse   bool // Sparse checkout is enabled, as read from the worktree's config files
+}
+
+// HasUncommittedChanges reports whether the worktree has staged, unstaged or untracked files.
+func (s *WorktreeStatus) HasUncommittedChanges() bool {
+	return s.Stats.UntrackedFiles+s.Stats.ModifiedFiles+s.Stats.StagedFiles > 0
+}
+
+// GetWorktreeStatus reads the branch, HEAD commit and file counts of a worktree
+// in one subprocess, and its sparse state from its config files without one.
+// File counts follow GetStatusStats.
+func GetWorktreeStatus(worktreeDir string) (*WorktreeStatus, error) {
+	cmd := exec.Command("git", "--no-optional-locks", "status", "--porcelain=v2", "--branch")
+	cmd.Dir = worktreeDir
+
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get status: %w", err)
+	}
+
+	status := parseWorktreeStatus(string(output))
+	status.Sparse = sparseCheckoutConfigured(worktreeDir)
+	return status, nil
+}
+
+// sparseCheckoutConfigured reads core.sparseCheckout from the config files of a
+// worktree: its repository's config, then the worktree's own config.worktree,
+// which `git sparse-checkout` writes to. Included config files are not followed.
+func sparseCheckoutConfigured(worktreeDir string) bool {
+	gitDir := worktreeGitDir(worktreeDir)
+	if gitDir == "" {
+		return false
+	}
+
+	commonDir := gitDir
+	if data, err := os.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+		commonDir = strings.TrimSpace(string(data))
+		if !filepath.IsAbs(commonDir) {
+			commonDir = filepath.Join(gitDir, commonDir)
+		}
+	}
+
+	sparse := false
+	for _, path := range []string{filepath.Join(commonDir, "config"), filepath.Join(gitDir, "config.worktree")} {
+		if value, ok := readCoreBool(path, "sparsecheckout"); ok {
+			sparse = value
+		}
+	}
+	return sparse
+}
+
+// worktreeGitDir returns the git directory of a worktree without running git:
+// .git itself, or for a linked worktree the directory its .git file points at.
+// Empty if dir isn't a worktree.
+func worktreeGitDir(dir string) string {
+	gitDir := filepath.Join(dir, ".git")
+	info, err := os.Stat(gitDir)
+	if err != nil {
+		return ""
+	}
+	if info.IsDir() {
+		return gitDir
+	}
+
+	data, err := os.ReadFile(gitDir)
+	if err != nil {
+		return ""
+	}
+	linked, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir: ")
+	if !ok {
+		return ""
+	}
+	if !filepath.IsAbs(linked) {
+		linked = filepath.Join(dir, linked)
+	}
+	return linked
+}
+
+// HasInitializedSubmodules reports whether submodules were initialized in a
+// worktree, which `git worktree move` and `git worktree remove` refuse.
+func HasInitializedSubmodules(worktreeDir string) bool {
+	gitDir := worktreeGitDir(worktreeDir)
+	if gitDir == "" {
+		return false
+	}
+	info, err := os.Stat(filepath.Join(gitDir, "modules"))
+	return err == nil && info.IsDir()
+}
+
+// readCoreBool reads a boolean from the [core] section of a git config file,
+// returning the last value set and whether it was set at all.
+func readCoreBool(path, key string) (bool, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false, false
+	}
+
+	inCore, value, found := false, false, false
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "[") {
+			section := strings.ToLower(strings.Trim(line, "[] \t"))
+			inCore = section == "core"
+			continue
+		}
+		if !inCore || line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+
+		name, val, hasValue := strings.Cut(line, "=")
+		if !strings.EqualFold(strings.TrimSpace(name), key) {
+			continue
+		}
+		found = true
+		// A key without a value means true
+		value = !hasValue
+		if hasValue {
+			switch strings.ToLower(strings.Trim(strings.TrimSpace(val), `"`)) {
+			case "true", "yes", "on", "1":
+				value = true
+			}
+		}
+	}
+	return value, found
+}
+
+func parseWorktreeStatus(output string) *WorktreeStatus {
+	status := &WorktreeStatus{}
+	for _, line := range strings.Split(output, "\n") {
+		switch {
+		case strings.HasPrefix(line, "# branch.oid "):
+			if oid := strings.TrimPrefix(line, "# branch.oid "); oid != "(initial)" {
+				status.Head = oid
+			}
+		case strings.HasPrefix(line, "# branch.head "):
+			if head := strings.TrimPrefix(line, "# branch.head "); head != "(detached)" {
+				status.Branch = head
+			}
+		case strings.HasPrefix(line, "# branch.upstream "):
+			status.Upstream = strings.TrimPrefix(line, "# branch.upstream ")
+		case strings.HasPrefix(line, "? "):
+			status.Stats.UntrackedFiles++
+		case strings.HasPrefix(line, "1 "), strings.HasPrefix(line, "2 "), strings.HasPrefix(line, "u "):
+			// Format: <type> XY ..., where "." means unchanged
+			// X = index status, Y = working tree status
+			if len(line) < 4 {
+				continue
+			}
+			x, y := line[2], line[3]
+			if y != '.' {
+				status.Unstaged = true
+			}
+			if x != '.' {
+				status.Stats.StagedFiles++
+			} else if y != '.' {
+				status.Stats.ModifiedFiles++
+			}
+		}
+	}
+	return status
+}
+
+// Ref is a local or remote-tracking branch as read by ListRefs.
+type Ref struct {
+	Name         string // Full refname, e.g. "refs/heads/main"
+	Commit       string
+	Upstream     string // Full refname of the branch's upstream, if it has one
+	UpstreamGone bool   // The upstream is configured but no longer exists on the remote
+}
+
+// Refs maps full refnames to branches.
+type Refs map[string]Ref
+
+// Resolve looks up a branch by full refname or by the short names git accepts,
+// such as "main" or "upstream/main".
+func (r Refs) Resolve(name string) (Ref, bool) {
+	for _, candidate := range []string{name, "refs/heads/" + name, "refs/remotes/" + name} {
+		if ref, ok := r[candidate]; ok {
+			return ref, true
+		}
+	}
+	return Ref{}, false
+}
+
+// ListRefs reads every local and remote-tracking branch of a repository with a
+// single for-each-ref call. Worktrees share their repository's refs.
+func ListRefs(repoDir string) (Refs, error) {
+	cmd := exec.Command("git", "--no-optional-locks", "for-each-ref",
+		"--format=%(refname)%00%(objectname)%00%(upstream)%00%(upstream:track)",
+		"refs/heads", "refs/remotes")
+	cmd.Dir = repoDir
+
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list refs: %w", err)
+	}
+
+	refs := Refs{}
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		fields := strings.Split(line, "\x00")
+		if len(fields) != 4 {
+			continue
+		}
+		refs[fields[0]] = Ref{
+			Name:         fields[0],
+			Commit:       fields[1],
+			Upstream:     fields[2],
+			UpstreamGone: fields[3] == "[gone]",
+		}
+	}
+	return refs, nil
+}
+
 // WorktreeRegistered checks if a worktree path is registered with git, even if the directory doesn't exist
 func WorktreeRegistered(repoDir, worktreeDir string) bool {
 	cmd := exec.Command("git", "--no-optional-locks", "worktree", "list", "--porcelain")
@@ -958,3 +1930,286 @@ func WorktreeRegistered(repoDir, worktreeDir string) bool {
 
 	return false
 }
+
+// DiffPatch returns a binary-safe patch of a worktree's uncommitted changes
+// to tracked files: the staged ones, or the unstaged ones.
+func DiffPatch(worktreeDir string, staged bool) (string, error) {
+	args := []string{"--no-optional-locks", "diff", "--binary", "--no-color", "--no-ext-diff"}
+	if staged {
+		args = append(args, "--cached")
+	}
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = worktreeDir
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to diff %s: %w", worktreeDir, err)
+	}
+	return string(output), nil
+}
+
+// ApplyPatchQuiet applies a patch from DiffPatch to a worktree, also staging
+// it when index is set.
+func ApplyPatchQuiet(worktreeDir, patch string, index bool) error {
+	args := []string{"apply", "--binary"}
+	if index {
+		args = append(args, "--index")
+	}
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = worktreeDir
+	cmd.Stdin = strings.NewReader(patch)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to apply changes: %w\n%s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// UntrackedFiles returns the untracked files of a worktree that aren't
+// ignored, relative to it.
+func UntrackedFiles(worktreeDir string) ([]string, error) {
+	cmd := exec.Command("git", "--no-optional-locks", "ls-files", "--others", "--exclude-standard", "-z")
+	cmd.Dir = worktreeDir
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list untracked files: %w", err)
+	}
+
+	files := []string{}
+	for _, file := range strings.Split(string(output), "\x00") {
+		if file != "" {
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
+
+// snapshotIdentity lets snapshot commits be written where no user is configured.
+var snapshotIdentity = []string{
+	"GIT_AUTHOR_NAME=ramp", "GIT_AUTHOR_EMAIL=ramp@localhost",
+	"GIT_COMMITTER_NAME=ramp", "GIT_COMMITTER_EMAIL=ramp@localhost",
+}
+
+// gitOutput runs git in dir with extra environment variables and returns its
+// trimmed output.
+func gitOutput(dir string, env []string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), env...)
+	output, err := cmd.Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return "", fmt.Errorf("git %s: %w\n%s", args[0], err, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// SnapshotChanges records the uncommitted state of a worktree without
+// changing its branch, index or files. It writes a commit of the index on top
+// of HEAD and, on top of that, a commit of every file including untracked
+// ones, and returns the latter's hash. Ignored files are left out. A clean
+// worktree returns "".
+func SnapshotChanges(worktreeDir string) (string, error) {
+	hasChanges, err := HasUncommittedChanges(worktreeDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to check uncommitted changes: %w", err)
+	}
+	if !hasChanges {
+		return "", nil
+	}
+
+	head, err := gitOutput(worktreeDir, nil, "rev-parse", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	indexTree, err := gitOutput(worktreeDir, nil, "write-tree")
+	if err != nil {
+		return "", err
+	}
+	indexCommit, err := gitOutput(worktreeDir, snapshotIdentity, "commit-tree", indexTree, "-p", head, "-m", "ramp: staged changes")
+	if err != nil {
+		return "", err
+	}
+
+	// Add everything to a copy of the index so the real one keeps what's staged
+	indexPath, err := gitOutput(worktreeDir, nil, "rev-parse", "--path-format=absolute", "--git-path", "index")
+	if err != nil {
+		return "", err
+	}
+	tempIndex, err := os.CreateTemp("", "ramp-index-")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp index: %w", err)
+	}
+	tempIndex.Close()
+	defer os.Remove(tempIndex.Name())
+
+	data, err := os.ReadFile(indexPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read index: %w", err)
+	}
+	if err := os.WriteFile(tempIndex.Name(), data, 0600); err != nil {
+		return "", fmt.Errorf("failed to write temp index: %w", err)
+	}
+
+	indexEnv := []string{"GIT_INDEX_FILE=" + tempIndex.Name()}
+	if _, err := gitOutput(worktreeDir, indexEnv, "add", "--all"); err != nil {
+		return "", err
+	}
+	tree, err := gitOutput(worktreeDir, indexEnv, "write-tree")
+	if err != nil {
+		return "", err
+	}
+	return gitOutput(worktreeDir, snapshotIdentity, "commit-tree", tree, "-p", indexCommit, "-m", "ramp: uncommitted changes")
+}
+
+// RestoreSnapshotQuiet reapplies a snapshot from SnapshotChanges to a worktree
+// on the commit it was taken from: staged changes are staged again, and the
+// rest, including untracked files, is left unstaged.
+func RestoreSnapshotQuiet(worktreeDir, snapshot string) error {
+	for _, step := range []struct {
+		from, to string
+		index    bool
+	}{
+		{"HEAD", snapshot + "^", true},
+		{snapshot + "^", snapshot, false},
+	} {
+		cmd := exec.Command("git", "diff", "--binary", "--no-color", "--no-ext-diff", step.from, step.to)
+		cmd.Dir = worktreeDir
+		patch, err := cmd.Output()
+		if err != nil {
+			return fmt.Errorf("failed to diff snapshot %s: %w", snapshot, err)
+		}
+		if len(patch) == 0 {
+			continue
+		}
+		if err := ApplyPatchQuiet(worktreeDir, string(patch), step.index); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ConfigValue returns a git config value as seen from dir, or "" if it isn't set.
+func ConfigValue(dir, key string) string {
+	value, err := gitOutput(dir, nil, "config", "--get", key)
+	if err != nil {
+		return ""
+	}
+	return value
+}
+
+// ResolveCommit returns the full hash of the commit rev points to.
+func ResolveCommit(repoDir, rev string) (string, error) {
+	return gitOutput(repoDir, nil, "rev-parse", "--verify", "--quiet", rev+"^{commit}")
+}
+
+// UpdateRefQuiet points ref, such as refs/ramp/archive/x, at a commit.
+func UpdateRefQuiet(repoDir, ref, commit string) error {
+	_, err := gitOutput(repoDir, nil, "update-ref", ref, commit)
+	return err
+}
+
+// DeleteRefQuiet deletes ref; a ref that doesn't exist is not an error.
+func DeleteRefQuiet(repoDir, ref string) error {
+	if _, err := ResolveCommit(repoDir, ref); err != nil {
+		return nil
+	}
+	_, err := gitOutput(repoDir, nil, "update-ref", "-d", ref)
+	return err
+}
+
+// CreateBranchQuiet creates a local branch at startPoint without checking it out.
+func CreateBranchQuiet(repoDir, branchName, startPoint string) error {
+	_, err := gitOutput(repoDir, nil, "branch", branchName, startPoint)
+	return err
+}
+
+// Worktree is a worktree of a repository as listed by ListWorktrees.
+type Worktree struct {
+	Path   string
+	Branch string // Short branch name, empty for a detached HEAD
+	Main   bool   // The repository's own checkout
+}
+
+// ListWorktrees returns every worktree registered with a repository,
+// including its main checkout and worktrees whose directory is gone.
+func ListWorktrees(repoDir string) ([]Worktree, error) {
+	cmd := exec.Command("git", "--no-optional-locks", "worktree", "list", "--porcelain")
+	cmd.Dir = repoDir
+
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list worktrees: %w", err)
+	}
+
+	worktrees := []Worktree{}
+	for _, block := range strings.Split(strings.TrimSpace(string(output)), "\n\n") {
+		var worktree Worktree
+		for _, line := range strings.Split(block, "\n") {
+			switch {
+			case strings.HasPrefix(line, "worktree "):
+				worktree.Path = strings.TrimPrefix(line, "worktree ")
+			case strings.HasPrefix(line, "branch "):
+				worktree.Branch = strings.TrimPrefix(line, "branch refs/heads/")
+			}
+		}
+		if worktree.Path != "" {
+			// git lists the main checkout first
+			worktree.Main = len(worktrees) == 0
+			worktrees = append(worktrees, worktree)
+		}
+	}
+	return worktrees, nil
+}
+
+// RemoteReachable checks that a remote answers within timeout, without
+// prompting for credentials.
+func RemoteReachable(repoDir, remote string, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "git", "ls-remote", "--quiet", remote, "HEAD")
+	cmd.Dir = repoDir
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0", "GIT_SSH_COMMAND=ssh -o BatchMode=yes")
+
+	output, err := cmd.CombinedOutput()
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("%s did not answer within %s", remote, timeout)
+	}
+	if err != nil {
+		return fmt.Errorf("%s is unreachable: %w\n%s", remote, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// MergeFile performs a three-way merge of file contents using git merge-file.
+// It returns the merged content and whether it contains conflict markers.
+func MergeFile(current, base, other, currentLabel, otherLabel string) (string, bool, error) {
+	tempDir, err := os.MkdirTemp("", "ramp-merge-")
+	if err != nil {
+		return "", false, fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	paths := make([]string, 3)
+	for i, content := range []string{current, base, other} {
+		paths[i] = filepath.Join(tempDir, fmt.Sprintf("%d", i))
+		if err := os.WriteFile(paths[i], []byte(content), 0600); err != nil {
+			return "", false, fmt.Errorf("failed to write merge input: %w", err)
+		}
+	}
+
+	cmd := exec.Command("git", "merge-file", "-p", "-L", currentLabel, "-L", "base", "-L", otherLabel, paths[0], paths[1], paths[2])
+	output, err := cmd.Output()
+	if err != nil {
+		// A positive exit code is the number of conflicts; other failures are errors
+		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() > 0 && exitErr.ExitCode() < 128 {
+			return string(output), true, nil
+		}
+		return "", false, fmt.Errorf("failed to merge file: %w", err)
+	}
+
+	return string(output), false, nil
+}
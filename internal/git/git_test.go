@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"ramp/internal/ui"
 )
@@ -489,6 +490,69 @@ func TestResolveSourceBranch(t *testing.T) {
 	})
 }
 
+// TestUpstreamRemote tests fork setups with an upstream remote
+func TestUpstreamRemote(t *testing.T) {
+	tempDir := t.TempDir()
+	initTestRepo(t, tempDir)
+
+	upstreamDir := t.TempDir()
+	initTestRepo(t, upstreamDir)
+	runGitCmd(t, upstreamDir, "checkout", "-b", "main")
+	runGitCmd(t, upstreamDir, "checkout", "-b", "upstream-only")
+	runGitCmd(t, upstreamDir, "checkout", "main")
+
+	if HasRemote(tempDir, UpstreamRemote) {
+		t.Fatal("HasRemote() = true before adding upstream")
+	}
+
+	if err := EnsureRemote(tempDir, UpstreamRemote, "/nonexistent"); err != nil {
+		t.Fatalf("EnsureRemote() error = %v", err)
+	}
+	// A second call repoints the existing remote
+	if err := EnsureRemote(tempDir, UpstreamRemote, upstreamDir); err != nil {
+		t.Fatalf("EnsureRemote() on existing remote error = %v", err)
+	}
+	if url := runGitCmdOutput(t, tempDir, "remote", "get-url", UpstreamRemote); url != upstreamDir {
+		t.Errorf("upstream url = %q, want %q", url, upstreamDir)
+	}
+
+	if err := FetchRemoteQuiet(tempDir, UpstreamRemote); err != nil {
+		t.Fatalf("FetchRemoteQuiet() error = %v", err)
+	}
+
+	t.Run("default branch", func(t *testing.T) {
+		branch, err := GetRemoteDefaultBranch(tempDir, UpstreamRemote)
+		if err != nil {
+			t.Fatalf("GetRemoteDefaultBranch() error = %v", err)
+		}
+		if branch != "upstream/main" {
+			t.Errorf("GetRemoteDefaultBranch() = %q, want %q", branch, "upstream/main")
+		}
+	})
+
+	t.Run("resolve branch only on upstream", func(t *testing.T) {
+		for _, target := range []string{"upstream-only", "origin/upstream-only", "upstream/upstream-only"} {
+			resolved, err := ResolveSourceBranch(tempDir, target, "feature/")
+			if err != nil {
+				t.Fatalf("ResolveSourceBranch(%q) error = %v", target, err)
+			}
+			if resolved != "upstream/upstream-only" {
+				t.Errorf("ResolveSourceBranch(%q) = %q, want %q", target, resolved, "upstream/upstream-only")
+			}
+		}
+	})
+
+	t.Run("unset branch upstream", func(t *testing.T) {
+		runGitCmd(t, tempDir, "branch", "--track", "from-upstream", "upstream/main")
+		if err := UnsetBranchUpstream(tempDir, "from-upstream"); err != nil {
+			t.Fatalf("UnsetBranchUpstream() error = %v", err)
+		}
+		if exec.Command("git", "-C", tempDir, "config", "branch.from-upstream.remote").Run() == nil {
+			t.Error("branch should no longer track upstream")
+		}
+	})
+}
+
 // TestGetStatusStats tests git status parsing
 func TestGetStatusStats(t *testing.T) {
 	t.Run("clean repo", func(t *testing.T) {
@@ -640,6 +704,96 @@ func TestIsMergedInto(t *testing.T) {
 	})
 }
 
+// TestDetectMerge tests detecting merge commits, rebase merges, squash merges and deleted upstreams
+func TestDetectMerge(t *testing.T) {
+	// setup creates a main branch and a feature branch with two commits, leaving the feature checked out
+	setup := func(t *testing.T) string {
+		t.Helper()
+		dir := t.TempDir()
+		initTestRepo(t, dir)
+		runGitCmd(t, dir, "checkout", "-b", "main")
+		runGitCmd(t, dir, "checkout", "-b", "feature/test")
+		for _, name := range []string{"a.txt", "b.txt"} {
+			os.WriteFile(filepath.Join(dir, name), []byte(name+"\n"), 0644)
+			runGitCmd(t, dir, "add", name)
+			runGitCmd(t, dir, "commit", "-m", "add "+name)
+		}
+		return dir
+	}
+
+	// advanceMain adds an unrelated commit to main so the feature is not an ancestor
+	advanceMain := func(t *testing.T, dir string) {
+		t.Helper()
+		runGitCmd(t, dir, "checkout", "main")
+		os.WriteFile(filepath.Join(dir, "other.txt"), []byte("other\n"), 0644)
+		runGitCmd(t, dir, "add", "other.txt")
+		runGitCmd(t, dir, "commit", "-m", "unrelated work")
+	}
+
+	assertMethod := func(t *testing.T, dir string, want MergeMethod) {
+		t.Helper()
+		method, err := DetectMerge(dir, "main")
+		if err != nil {
+			t.Fatalf("DetectMerge() error = %v", err)
+		}
+		if method != want {
+			t.Errorf("DetectMerge() = %q, want %q", method, want)
+		}
+	}
+
+	t.Run("not merged", func(t *testing.T) {
+		dir := setup(t)
+		advanceMain(t, dir)
+		runGitCmd(t, dir, "checkout", "feature/test")
+		assertMethod(t, dir, MergeMethodNone)
+	})
+
+	t.Run("merge commit", func(t *testing.T) {
+		dir := setup(t)
+		advanceMain(t, dir)
+		runGitCmd(t, dir, "merge", "--no-ff", "feature/test", "-m", "merge feature")
+		runGitCmd(t, dir, "checkout", "feature/test")
+		assertMethod(t, dir, MergeMethodAncestor)
+	})
+
+	t.Run("rebase and merge", func(t *testing.T) {
+		dir := setup(t)
+		advanceMain(t, dir)
+		runGitCmd(t, dir, "cherry-pick", "main..feature/test")
+		runGitCmd(t, dir, "checkout", "feature/test")
+		assertMethod(t, dir, MergeMethodPatchID)
+	})
+
+	t.Run("squash and merge", func(t *testing.T) {
+		dir := setup(t)
+		advanceMain(t, dir)
+		runGitCmd(t, dir, "merge", "--squash", "feature/test")
+		runGitCmd(t, dir, "commit", "-m", "feature (#1)")
+		// Later work on main does not hide the squash merge
+		os.WriteFile(filepath.Join(dir, "later.txt"), []byte("later\n"), 0644)
+		runGitCmd(t, dir, "add", "later.txt")
+		runGitCmd(t, dir, "commit", "-m", "later work")
+		runGitCmd(t, dir, "checkout", "feature/test")
+		assertMethod(t, dir, MergeMethodTree)
+	})
+
+	t.Run("upstream deleted", func(t *testing.T) {
+		dir := setup(t)
+		advanceMain(t, dir)
+		runGitCmd(t, dir, "checkout", "feature/test")
+
+		remoteDir := t.TempDir()
+		runGitCmd(t, remoteDir, "init", "--bare")
+		runGitCmd(t, dir, "remote", "add", "origin", remoteDir)
+		runGitCmd(t, dir, "push", "-u", "origin", "feature/test")
+		assertMethod(t, dir, MergeMethodNone)
+
+		runGitCmd(t, remoteDir, "branch", "-D", "feature/test")
+		runGitCmd(t, dir, "fetch", "--prune", "origin")
+		assertMethod(t, dir, MergeMethodRemoteDeleted)
+	})
+}
+
 // TestGetAheadBehindCount tests ahead/behind commit counting
 func TestGetAheadBehindCount(t *testing.T) {
 	tempDir := t.TempDir()
@@ -812,6 +966,107 @@ func TestClone(t *testing.T) {
 	})
 }
 
+// TestCloneQuiet tests cloning with partial clone and reference options
+func TestCloneQuiet(t *testing.T) {
+	sourceDir := t.TempDir()
+	initTestRepo(t, sourceDir)
+	// Partial clone over file:// requires the server to allow filters
+	runGitCmd(t, sourceDir, "config", "uploadpack.allowFilter", "true")
+
+	t.Run("partial clone keeps history", func(t *testing.T) {
+		destDir := filepath.Join(t.TempDir(), "partial")
+		if err := CloneQuiet("file://"+sourceDir, destDir, CloneOptions{Filter: "blob:none"}); err != nil {
+			t.Fatalf("CloneQuiet() error = %v", err)
+		}
+
+		filter := strings.TrimSpace(runGitCmdOutput(t, destDir, "config", "remote.origin.partialclonefilter"))
+		if filter != "blob:none" {
+			t.Errorf("partialclonefilter = %q, want blob:none", filter)
+		}
+		if _, err := os.Stat(filepath.Join(destDir, ".git", "shallow")); !os.IsNotExist(err) {
+			t.Error("partial clone should not be shallow")
+		}
+	})
+
+	t.Run("reference shares objects", func(t *testing.T) {
+		destDir := filepath.Join(t.TempDir(), "referenced")
+		if err := CloneQuiet(sourceDir, destDir, CloneOptions{Reference: sourceDir}); err != nil {
+			t.Fatalf("CloneQuiet() error = %v", err)
+		}
+
+		if _, err := os.Stat(filepath.Join(destDir, ".git", "objects", "info", "alternates")); err != nil {
+			t.Errorf("reference clone should have alternates: %v", err)
+		}
+	})
+
+	t.Run("missing reference is ignored", func(t *testing.T) {
+		destDir := filepath.Join(t.TempDir(), "no-reference")
+		if err := CloneQuiet(sourceDir, destDir, CloneOptions{Reference: filepath.Join(t.TempDir(), "missing")}); err != nil {
+			t.Fatalf("CloneQuiet() error = %v", err)
+		}
+		if !IsGitRepo(destDir) {
+			t.Error("CloneQuiet() did not create a git repository")
+		}
+	})
+
+	t.Run("failure includes git output", func(t *testing.T) {
+		err := CloneQuiet(filepath.Join(t.TempDir(), "nope"), filepath.Join(t.TempDir(), "dest"), CloneOptions{})
+		if err == nil || !strings.Contains(err.Error(), "does not exist") {
+			t.Errorf("CloneQuiet() error = %v, want git's message", err)
+		}
+	})
+}
+
+// TestCreateWorktreeSparse tests that sparse patterns limit the checkout
+func TestCreateWorktreeSparse(t *testing.T) {
+	repoDir := t.TempDir()
+	initTestRepo(t, repoDir)
+	for _, dir := range []string{"app", "docs"} {
+		os.MkdirAll(filepath.Join(repoDir, dir), 0755)
+		os.WriteFile(filepath.Join(repoDir, dir, "file.txt"), []byte(dir), 0644)
+	}
+	runGitCmd(t, repoDir, "add", ".")
+	runGitCmd(t, repoDir, "commit", "-m", "add dirs")
+	runGitCmd(t, repoDir, "branch", "existing")
+
+	for _, branch := range []string{"new-branch", "existing"} {
+		t.Run(branch, func(t *testing.T) {
+			worktreeDir := filepath.Join(t.TempDir(), branch)
+			if err := CreateWorktreeQuiet(repoDir, worktreeDir, branch, "repo", "app"); err != nil {
+				t.Fatalf("CreateWorktreeQuiet() error = %v", err)
+			}
+
+			if _, err := os.Stat(filepath.Join(worktreeDir, "app", "file.txt")); err != nil {
+				t.Errorf("app should be checked out: %v", err)
+			}
+			if _, err := os.Stat(filepath.Join(worktreeDir, "docs")); !os.IsNotExist(err) {
+				t.Error("docs should not be checked out")
+			}
+			if !IsSparseCheckout(worktreeDir) {
+				t.Error("IsSparseCheckout() = false, want true")
+			}
+			if status, err := GetWorktreeStatus(worktreeDir); err != nil || !status.Sparse {
+				t.Errorf("GetWorktreeStatus() = %+v, %v, want Sparse read from config", status, err)
+			}
+
+			runGitCmd(t, worktreeDir, "sparse-checkout", "disable")
+			if status, _ := GetWorktreeStatus(worktreeDir); status.Sparse {
+				t.Error("GetWorktreeStatus().Sparse = true after disabling sparse checkout")
+			}
+			if got, _ := GetCurrentBranch(worktreeDir); got != branch {
+				t.Errorf("branch = %q, want %q", got, branch)
+			}
+		})
+	}
+
+	if IsSparseCheckout(repoDir) {
+		t.Error("source repository should not become sparse")
+	}
+	if status, _ := GetWorktreeStatus(repoDir); status.Sparse {
+		t.Error("GetWorktreeStatus().Sparse = true for the source repository")
+	}
+}
+
 // TestCreateWorktree tests worktree creation with different branch scenarios
 func TestCreateWorktree(t *testing.T) {
 	oldVerbose := ui.Verbose
@@ -1452,6 +1707,51 @@ func TestStashChangesQuiet(t *testing.T) {
 	})
 }
 
+// TestStashPushQuiet tests stashing by commit, leaving untracked files alone
+func TestStashPushQuiet(t *testing.T) {
+	t.Run("tracked changes", func(t *testing.T) {
+		repoDir := t.TempDir()
+		initTestRepo(t, repoDir)
+
+		testFile := filepath.Join(repoDir, "test.txt")
+		os.WriteFile(testFile, []byte("original"), 0644)
+		runGitCmd(t, repoDir, "add", "test.txt")
+		runGitCmd(t, repoDir, "commit", "-m", "initial")
+		os.WriteFile(testFile, []byte("modified"), 0644)
+
+		stash, err := StashPushQuiet(repoDir, "test stash")
+		if err != nil || stash == "" {
+			t.Fatalf("StashPushQuiet() = %q, %v; want a stash commit", stash, err)
+		}
+		if content, _ := os.ReadFile(testFile); string(content) != "original" {
+			t.Errorf("test.txt = %q after stashing, want original", content)
+		}
+
+		if err := PopStashCommitQuiet(repoDir, stash); err != nil {
+			t.Fatalf("PopStashCommitQuiet() error = %v", err)
+		}
+		if content, _ := os.ReadFile(testFile); string(content) != "modified" {
+			t.Errorf("test.txt = %q after popping, want modified", content)
+		}
+	})
+
+	t.Run("only untracked files", func(t *testing.T) {
+		repoDir := t.TempDir()
+		initTestRepo(t, repoDir)
+
+		untracked := filepath.Join(repoDir, "new.txt")
+		os.WriteFile(untracked, []byte("new"), 0644)
+
+		stash, err := StashPushQuiet(repoDir, "test stash")
+		if err != nil || stash != "" {
+			t.Fatalf("StashPushQuiet() = %q, %v; want nothing stashed", stash, err)
+		}
+		if _, err := os.Stat(untracked); err != nil {
+			t.Error("untracked files should stay in place")
+		}
+	})
+}
+
 // TestPopStashQuiet tests quiet stash pop without spinner
 func TestPopStashQuiet(t *testing.T) {
 	oldVerbose := ui.Verbose
@@ -1578,3 +1878,321 @@ func TestFetchBranchQuiet(t *testing.T) {
 		}
 	})
 }
+
+func TestMergeFile(t *testing.T) {
+	base := "A=1\nB=2\nC=3\n"
+
+	t.Run("non-overlapping changes merge cleanly", func(t *testing.T) {
+		merged, conflicted, err := MergeFile("A=10\nB=2\nC=3\n", base, "A=1\nB=2\nC=30\n", "local", "generated")
+		if err != nil {
+			t.Fatalf("MergeFile() error = %v", err)
+		}
+		if conflicted {
+			t.Error("MergeFile() reported conflict for non-overlapping changes")
+		}
+		if merged != "A=10\nB=2\nC=30\n" {
+			t.Errorf("MergeFile() = %q", merged)
+		}
+	})
+
+	t.Run("overlapping changes conflict", func(t *testing.T) {
+		merged, conflicted, err := MergeFile("A=1\nB=20\nC=3\n", base, "A=1\nB=200\nC=3\n", "local", "generated")
+		if err != nil {
+			t.Fatalf("MergeFile() error = %v", err)
+		}
+		if !conflicted {
+			t.Error("MergeFile() expected conflict")
+		}
+		if !strings.Contains(merged, "<<<<<<< local") || !strings.Contains(merged, ">>>>>>> generated") {
+			t.Errorf("MergeFile() missing conflict markers: %q", merged)
+		}
+	})
+}
+
+// TestInProgressOperation tests detecting, continuing and aborting stopped merges and rebases
+func TestInProgressOperation(t *testing.T) {
+	for _, operation := range []string{OperationMerge, OperationRebase} {
+		t.Run(operation, func(t *testing.T) {
+			tempDir := t.TempDir()
+			initTestRepo(t, tempDir)
+			os.WriteFile(filepath.Join(tempDir, "file.txt"), []byte("base\n"), 0644)
+			runGitCmd(t, tempDir, "add", "file.txt")
+			runGitCmd(t, tempDir, "commit", "-m", "base")
+
+			runGitCmd(t, tempDir, "checkout", "-b", "feature")
+			os.WriteFile(filepath.Join(tempDir, "file.txt"), []byte("feature\n"), 0644)
+			runGitCmd(t, tempDir, "commit", "-am", "feature")
+			runGitCmd(t, tempDir, "checkout", "master")
+			os.WriteFile(filepath.Join(tempDir, "file.txt"), []byte("master\n"), 0644)
+			runGitCmd(t, tempDir, "commit", "-am", "master")
+			runGitCmd(t, tempDir, "checkout", "feature")
+
+			if got := InProgressOperation(tempDir); got != OperationNone {
+				t.Fatalf("InProgressOperation() = %q before starting, want none", got)
+			}
+
+			var err error
+			if operation == OperationMerge {
+				err = MergeQuiet(tempDir, "master")
+			} else {
+				err = RebaseQuiet(tempDir, "master")
+			}
+			if err == nil {
+				t.Fatalf("%s should stop on conflicts", operation)
+			}
+
+			if got := InProgressOperation(tempDir); got != operation {
+				t.Errorf("InProgressOperation() = %q, want %q", got, operation)
+			}
+			files, err := ConflictedFiles(tempDir)
+			if err != nil || len(files) != 1 || files[0] != "file.txt" {
+				t.Errorf("ConflictedFiles() = %v, %v, want [file.txt]", files, err)
+			}
+
+			if err := ContinueOperationQuiet(tempDir); err == nil {
+				t.Error("ContinueOperationQuiet() should fail with unresolved conflicts")
+			}
+
+			os.WriteFile(filepath.Join(tempDir, "file.txt"), []byte("resolved\n"), 0644)
+			runGitCmd(t, tempDir, "add", "file.txt")
+			if err := ContinueOperationQuiet(tempDir); err != nil {
+				t.Fatalf("ContinueOperationQuiet() error = %v", err)
+			}
+			if got := InProgressOperation(tempDir); got != OperationNone {
+				t.Errorf("InProgressOperation() = %q after continuing, want none", got)
+			}
+		})
+	}
+
+	t.Run("abort", func(t *testing.T) {
+		tempDir := t.TempDir()
+		initTestRepo(t, tempDir)
+		os.WriteFile(filepath.Join(tempDir, "file.txt"), []byte("base\n"), 0644)
+		runGitCmd(t, tempDir, "add", "file.txt")
+		runGitCmd(t, tempDir, "commit", "-m", "base")
+		runGitCmd(t, tempDir, "checkout", "-b", "feature")
+		os.WriteFile(filepath.Join(tempDir, "file.txt"), []byte("feature\n"), 0644)
+		runGitCmd(t, tempDir, "commit", "-am", "feature")
+		before := runGitCmdOutput(t, tempDir, "rev-parse", "HEAD")
+		runGitCmd(t, tempDir, "checkout", "master")
+		os.WriteFile(filepath.Join(tempDir, "file.txt"), []byte("master\n"), 0644)
+		runGitCmd(t, tempDir, "commit", "-am", "master")
+		runGitCmd(t, tempDir, "checkout", "feature")
+
+		if err := RebaseQuiet(tempDir, "master"); err == nil {
+			t.Fatal("rebase should stop on conflicts")
+		}
+		if err := AbortOperationQuiet(tempDir); err != nil {
+			t.Fatalf("AbortOperationQuiet() error = %v", err)
+		}
+		if got := runGitCmdOutput(t, tempDir, "rev-parse", "HEAD"); got != before {
+			t.Errorf("HEAD = %s after abort, want %s", got, before)
+		}
+	})
+}
+
+// TestCommitQuiet tests staging and committing with trailers
+func TestCommitQuiet(t *testing.T) {
+	tempDir := t.TempDir()
+	initTestRepo(t, tempDir)
+
+	os.WriteFile(filepath.Join(tempDir, "new.txt"), []byte("new"), 0644)
+
+	if err := StageChanges(tempDir, false); err != nil {
+		t.Fatalf("StageChanges() error = %v", err)
+	}
+	if staged, _ := HasStagedChanges(tempDir); staged {
+		t.Error("untracked files should not be staged without includeUntracked")
+	}
+
+	if err := StageChanges(tempDir, true); err != nil {
+		t.Fatalf("StageChanges() error = %v", err)
+	}
+	if staged, err := HasStagedChanges(tempDir); err != nil || !staged {
+		t.Fatalf("HasStagedChanges() = %v, %v, want true", staged, err)
+	}
+
+	err := CommitQuiet(tempDir, CommitOptions{Message: "Add new file", Trailers: []string{"Ramp-Feature: demo"}})
+	if err != nil {
+		t.Fatalf("CommitQuiet() error = %v", err)
+	}
+	if got := runGitCmdOutput(t, tempDir, "log", "-1", "--format=%B"); got != "Add new file\n\nRamp-Feature: demo" {
+		t.Errorf("commit message = %q", got)
+	}
+
+	short, err := GetShortCommit(tempDir)
+	if err != nil || !strings.HasPrefix(runGitCmdOutput(t, tempDir, "rev-parse", "HEAD"), short) {
+		t.Errorf("GetShortCommit() = %q, %v", short, err)
+	}
+
+	if err := CommitQuiet(tempDir, CommitOptions{Amend: true}); err != nil {
+		t.Fatalf("CommitQuiet() amend error = %v", err)
+	}
+	if got := runGitCmdOutput(t, tempDir, "log", "-1", "--format=%s"); got != "Add new file" {
+		t.Errorf("amend without a message should keep it, got %q", got)
+	}
+}
+
+func TestGetWorktreeStatus(t *testing.T) {
+	tempDir := t.TempDir()
+	initTestRepo(t, tempDir)
+
+	for _, name := range []string{"modified.txt", "staged.txt"} {
+		os.WriteFile(filepath.Join(tempDir, name), []byte("original"), 0644)
+	}
+	runGitCmd(t, tempDir, "add", ".")
+	runGitCmd(t, tempDir, "commit", "-m", "add files")
+
+	status, err := GetWorktreeStatus(tempDir)
+	if err != nil {
+		t.Fatalf("GetWorktreeStatus() error = %v", err)
+	}
+	if status.Branch != "master" || status.Head != runGitCmdOutput(t, tempDir, "rev-parse", "HEAD") {
+		t.Errorf("GetWorktreeStatus() branch/head = %q/%q", status.Branch, status.Head)
+	}
+	if status.HasUncommittedChanges() || status.Unstaged {
+		t.Errorf("clean worktree reported changes: %+v", status)
+	}
+
+	os.WriteFile(filepath.Join(tempDir, "modified.txt"), []byte("modified"), 0644)
+	os.WriteFile(filepath.Join(tempDir, "staged.txt"), []byte("staged"), 0644)
+	runGitCmd(t, tempDir, "add", "staged.txt")
+	os.WriteFile(filepath.Join(tempDir, "untracked.txt"), []byte("new"), 0644)
+
+	status, err = GetWorktreeStatus(tempDir)
+	if err != nil {
+		t.Fatalf("GetWorktreeStatus() error = %v", err)
+	}
+	want := StatusStats{UntrackedFiles: 1, ModifiedFiles: 1, StagedFiles: 1}
+	if status.Stats != want || !status.Unstaged || !status.HasUncommittedChanges() {
+		t.Errorf("GetWorktreeStatus() = %+v, want stats %+v with unstaged changes", status, want)
+	}
+
+	runGitCmd(t, tempDir, "checkout", "--detach")
+	if status, _ := GetWorktreeStatus(tempDir); status.Branch != "" {
+		t.Errorf("detached HEAD reported branch %q", status.Branch)
+	}
+}
+
+func TestListRefs(t *testing.T) {
+	remoteDir := t.TempDir()
+	initTestRepo(t, remoteDir)
+	runGitCmd(t, remoteDir, "checkout", "-b", "feature/gone")
+	runGitCmd(t, remoteDir, "checkout", "master")
+
+	localDir := filepath.Join(t.TempDir(), "local")
+	runGitCmd(t, filepath.Dir(localDir), "clone", remoteDir, localDir)
+	runGitCmd(t, localDir, "checkout", "feature/gone")
+	runGitCmd(t, remoteDir, "branch", "-D", "feature/gone")
+	runGitCmd(t, localDir, "fetch", "--prune")
+
+	refs, err := ListRefs(localDir)
+	if err != nil {
+		t.Fatalf("ListRefs() error = %v", err)
+	}
+
+	master, ok := refs.Resolve("master")
+	if !ok || master.Commit != runGitCmdOutput(t, localDir, "rev-parse", "master") {
+		t.Errorf("Resolve(master) = %+v, %v", master, ok)
+	}
+	if master.Upstream != "refs/remotes/origin/master" || master.UpstreamGone {
+		t.Errorf("master upstream = %q, gone = %v", master.Upstream, master.UpstreamGone)
+	}
+	if _, ok := refs.Resolve("origin/master"); !ok {
+		t.Error("Resolve(origin/master) should find the remote-tracking branch")
+	}
+	if gone, _ := refs.Resolve("feature/gone"); !gone.UpstreamGone {
+		t.Errorf("feature/gone should have a gone upstream, got %+v", gone)
+	}
+}
+
+func TestListWorktrees(t *testing.T) {
+	repoDir := t.TempDir()
+	initTestRepo(t, repoDir)
+
+	worktreesDir := t.TempDir()
+	kept := filepath.Join(worktreesDir, "kept")
+	gone := filepath.Join(worktreesDir, "gone")
+	runGitCmd(t, repoDir, "worktree", "add", "-b", "feature/kept", kept)
+	runGitCmd(t, repoDir, "worktree", "add", "--detach", gone)
+	os.RemoveAll(gone)
+
+	worktrees, err := ListWorktrees(repoDir)
+	if err != nil {
+		t.Fatalf("ListWorktrees() error = %v", err)
+	}
+	if len(worktrees) != 3 {
+		t.Fatalf("ListWorktrees() = %+v, want 3 worktrees", worktrees)
+	}
+
+	if !worktrees[0].Main || worktrees[0].Branch != "master" {
+		t.Errorf("first worktree = %+v, want the main checkout on master", worktrees[0])
+	}
+	byPath := map[string]Worktree{}
+	for _, worktree := range worktrees[1:] {
+		byPath[worktree.Path] = worktree
+	}
+	if worktree := byPath[kept]; worktree.Branch != "feature/kept" || worktree.Main {
+		t.Errorf("worktree %s = %+v, want it on feature/kept", kept, worktree)
+	}
+	if worktree, ok := byPath[gone]; !ok || worktree.Branch != "" {
+		t.Errorf("worktree %s = %+v, want it listed as detached", gone, worktree)
+	}
+}
+
+func TestRemoteReachable(t *testing.T) {
+	remoteDir := t.TempDir()
+	initTestRepo(t, remoteDir)
+
+	localDir := filepath.Join(t.TempDir(), "local")
+	runGitCmd(t, filepath.Dir(localDir), "clone", remoteDir, localDir)
+
+	if err := RemoteReachable(localDir, "origin", 10*time.Second); err != nil {
+		t.Errorf("RemoteReachable(origin) error = %v", err)
+	}
+
+	runGitCmd(t, localDir, "remote", "add", "missing", filepath.Join(t.TempDir(), "missing"))
+	if err := RemoteReachable(localDir, "missing", 10*time.Second); err == nil {
+		t.Error("RemoteReachable(missing) should fail for a remote that doesn't exist")
+	}
+}
+
+func TestSnapshotChanges(t *testing.T) {
+	repoDir := t.TempDir()
+	initTestRepo(t, repoDir)
+	os.WriteFile(filepath.Join(repoDir, "tracked.txt"), []byte("one\n"), 0644)
+	os.WriteFile(filepath.Join(repoDir, "staged.txt"), []byte("one\n"), 0644)
+	runGitCmd(t, repoDir, "add", ".")
+	runGitCmd(t, repoDir, "commit", "-m", "add files")
+
+	if snapshot, err := SnapshotChanges(repoDir); err != nil || snapshot != "" {
+		t.Fatalf("SnapshotChanges() of a clean repo = %q, %v; want no snapshot", snapshot, err)
+	}
+
+	os.WriteFile(filepath.Join(repoDir, "staged.txt"), []byte("two\n"), 0644)
+	runGitCmd(t, repoDir, "add", "staged.txt")
+	os.WriteFile(filepath.Join(repoDir, "tracked.txt"), []byte("two\n"), 0644)
+	os.WriteFile(filepath.Join(repoDir, "untracked.txt"), []byte("new\n"), 0644)
+	statusBefore := runGitCmdOutput(t, repoDir, "status", "--porcelain")
+
+	snapshot, err := SnapshotChanges(repoDir)
+	if err != nil || snapshot == "" {
+		t.Fatalf("SnapshotChanges() = %q, %v; want a snapshot", snapshot, err)
+	}
+	if status := runGitCmdOutput(t, repoDir, "status", "--porcelain"); status != statusBefore {
+		t.Errorf("SnapshotChanges() changed the worktree status to %q, want %q", status, statusBefore)
+	}
+
+	// Restoring into a clean checkout of the same commit brings everything back
+	runGitCmd(t, repoDir, "reset", "--hard")
+	os.Remove(filepath.Join(repoDir, "untracked.txt"))
+	if err := RestoreSnapshotQuiet(repoDir, snapshot); err != nil {
+		t.Fatalf("RestoreSnapshotQuiet() error = %v", err)
+	}
+	if status := runGitCmdOutput(t, repoDir, "status", "--porcelain"); status != statusBefore {
+		t.Errorf("status after restore = %q, want %q", status, statusBefore)
+	}
+	if content, _ := os.ReadFile(filepath.Join(repoDir, "untracked.txt")); string(content) != "new\n" {
+		t.Errorf("untracked.txt = %q, want %q", content, "new\n")
+	}
+}
@@ -80,6 +80,11 @@ func ExecuteHooksForCommand(
 	}
 }
 
+// ForEvent returns the hooks that ExecuteHooks would run for event, in order.
+func ForEvent(hooks []*config.Hook, event HookEvent) []*config.Hook {
+	return filterHooksByEvent(hooks, event)
+}
+
 // filterHooksByEvent returns hooks matching the given event.
 func filterHooksByEvent(hooks []*config.Hook, event HookEvent) []*config.Hook {
 	result := make([]*config.Hook, 0)
@@ -0,0 +1,462 @@
+package operations
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"ramp/internal/config"
+	"ramp/internal/features"
+	"ramp/internal/git"
+	"ramp/internal/ports"
+)
+
+// ArchiveDir holds the records of archived features, relative to the
+// project's .ramp directory.
+const ArchiveDir = "archive"
+
+// Archive is what ArchiveFeature saved of a feature so RestoreFeature can
+// bring it back. The commits themselves are kept alive by refs under
+// refs/ramp/archive/<feature>/ in each source repo.
+type Archive struct {
+	Feature     string         `json:"feature"`
+	DisplayName string         `json:"displayName,omitempty"`
+	Prefix      string         `json:"prefix"`          // Branch prefix; every branch is Prefix + Feature
+	Ports       []int          `json:"ports,omitempty"` // Ports the feature had, reclaimed on restore when free
+	Command     string         `json:"command"`         // ramp up command that creates a feature with the same branches
+	Archived    time.Time      `json:"archived"`
+	Repos       []ArchivedRepo `json:"repos"`
+
+	// Creation info, notes, tags and links, given back to the restored feature
+	Metadata *features.FeatureMetadata `json:"metadata,omitempty"`
+}
+
+// ArchivedRepo is the branch and uncommitted changes saved from one repo.
+type ArchivedRepo struct {
+	Repo        string `json:"repo"`
+	Branch      string `json:"branch"`
+	Commit      string `json:"commit"`                // Branch head when archived
+	Ref         string `json:"ref"`                   // Keeps Commit in the source repo
+	Snapshot    string `json:"snapshot,omitempty"`    // Uncommitted changes, see git.SnapshotChanges
+	SnapshotRef string `json:"snapshotRef,omitempty"` // Keeps Snapshot in the source repo
+}
+
+// ArchiveOptions configures archiving a feature.
+type ArchiveOptions struct {
+	// Required
+	FeatureName string
+	ProjectDir  string
+	Config      *config.Config
+	Progress    ProgressReporter
+}
+
+// ArchiveResult contains the saved archive and how the feature was removed.
+type ArchiveResult struct {
+	Archive *Archive
+	Down    *DownResult
+}
+
+// RestoreOptions configures restoring an archived feature.
+type RestoreOptions struct {
+	// Required
+	FeatureName string
+	ProjectDir  string
+	Config      *config.Config
+	Progress    ProgressReporter
+
+	// Optional
+	Output OutputStreamer // For streaming setup script stdout/stderr
+}
+
+// RestoreResult contains the recreated feature and what was brought back.
+type RestoreResult struct {
+	*UpResult
+	Restored     []string // Repos whose uncommitted changes were reapplied
+	PortsChanged bool     // The archived ports were taken, so new ones were allocated
+}
+
+func archivePath(projectDir, featureName string) string {
+	return filepath.Join(projectDir, ".ramp", ArchiveDir, featureName+".json")
+}
+
+func archiveRef(featureName, kind string) string {
+	return fmt.Sprintf("refs/ramp/archive/%s/%s", featureName, kind)
+}
+
+// LoadArchive returns the archive of a feature, or nil if it has none.
+func LoadArchive(projectDir, featureName string) (*Archive, error) {
+	data, err := os.ReadFile(archivePath(projectDir, featureName))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive of %s: %w", featureName, err)
+	}
+
+	var a Archive
+	if err := json.Unmarshal(data, &a); err != nil {
+		return nil, fmt.Errorf("failed to parse archive of %s: %w", featureName, err)
+	}
+	return &a, nil
+}
+
+// ListArchives returns the archived features of a project, most recent first.
+func ListArchives(projectDir string) ([]*Archive, error) {
+	entries, err := os.ReadDir(filepath.Join(projectDir, ".ramp", ArchiveDir))
+	if os.IsNotExist(err) {
+		return []*Archive{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archives: %w", err)
+	}
+
+	archives := []*Archive{}
+	for _, entry := range entries {
+		featureName, ok := strings.CutSuffix(entry.Name(), ".json")
+		if !ok || entry.IsDir() {
+			continue
+		}
+		a, err := LoadArchive(projectDir, featureName)
+		if err != nil {
+			return nil, err
+		}
+		archives = append(archives, a)
+	}
+
+	sort.Slice(archives, func(i, j int) bool {
+		return archives[i].Archived.After(archives[j].Archived)
+	})
+	return archives, nil
+}
+
+// DeleteArchive permanently discards the archive of a feature and the refs
+// that kept its commits.
+func DeleteArchive(projectDir string, cfg *config.Config, featureName string) error {
+	a, err := LoadArchive(projectDir, featureName)
+	if err != nil {
+		return err
+	}
+	if a == nil {
+		return fmt.Errorf("no archive of feature '%s'", featureName)
+	}
+
+	repos := cfg.GetRepos()
+	for _, archived := range a.Repos {
+		repo, ok := repos[archived.Repo]
+		if !ok {
+			continue
+		}
+		deleteArchiveRefs(repo.GetRepoPath(projectDir), archived, true)
+	}
+
+	if err := os.Remove(archivePath(projectDir, featureName)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove archive of %s: %w", featureName, err)
+	}
+	return nil
+}
+
+// deleteArchiveRefs deletes the refs that keep an archived repo's commits,
+// leaving the snapshot's when snapshot is false.
+func deleteArchiveRefs(repoDir string, archived ArchivedRepo, snapshot bool) {
+	git.DeleteRefQuiet(repoDir, archived.Ref)
+	if snapshot && archived.SnapshotRef != "" {
+		git.DeleteRefQuiet(repoDir, archived.SnapshotRef)
+	}
+}
+
+// ArchiveFeature saves a feature and then removes it the way a forced Down
+// does. Each repo's branch head and uncommitted changes, untracked files
+// included, are kept as commits under refs/ramp/archive/<feature>/ in the
+// source repo, and the feature's ports, branch prefix and metadata (display
+// name, creation info, notes, tags and links) are recorded in
+// .ramp/archive/<feature>.json. Nothing is pushed. Every worktree must be on
+// the branch ramp up created, the recorded or configured prefix followed by
+// the feature's name, so that restoring recreates the same branches.
+// This is the core business logic used by both CLI and UI.
+func ArchiveFeature(opts ArchiveOptions) (*ArchiveResult, error) {
+	projectDir := opts.ProjectDir
+	featureName := opts.FeatureName
+	progress := opts.Progress
+	treesDir := filepath.Join(projectDir, "trees", featureName)
+
+	if _, err := os.Stat(treesDir); os.IsNotExist(err) {
+		return nil, fmt.Errorf("feature '%s' not found", featureName)
+	}
+	if existing, err := LoadArchive(projectDir, featureName); err != nil {
+		return nil, err
+	} else if existing != nil {
+		return nil, fmt.Errorf("feature '%s' already has an archive from %s; restore or delete it first", featureName, existing.Archived.Format("2006-01-02 15:04"))
+	}
+	if j, err := LoadJournal(projectDir, featureName); err != nil {
+		return nil, err
+	} else if j != nil {
+		return nil, fmt.Errorf("feature '%s' has an unfinished %s; resume or roll it back first", featureName, j.Operation)
+	}
+
+	downOpts := DownOptions{
+		FeatureName: featureName,
+		ProjectDir:  projectDir,
+		Config:      opts.Config,
+		Progress:    progress,
+		Force:       true, // Uncommitted changes are archived
+	}
+	downPlan, err := PlanDown(downOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	a := &Archive{
+		Feature:     featureName,
+		DisplayName: LoadDisplayName(projectDir, featureName),
+		Ports:       featurePorts(projectDir, opts.Config, featureName),
+		Archived:    time.Now(),
+		Repos:       []ArchivedRepo{},
+	}
+	if metadataStore, err := features.NewMetadataStore(projectDir); err == nil {
+		meta := metadataStore.Get(featureName)
+		meta.EnvFiles, meta.Sync = nil, nil
+		a.Metadata = &meta
+	}
+
+	// Work out the branches first so nothing is saved for a feature that can't be archived
+	repos := opts.Config.GetRepos()
+	prefixes := featureBranchPrefixes(projectDir, opts.Config, featureName)
+	for _, name := range sortedRepoNames(repos) {
+		worktreeDir := filepath.Join(treesDir, name)
+		if _, err := os.Stat(worktreeDir); os.IsNotExist(err) || !git.IsGitRepo(repos[name].GetRepoPath(projectDir)) {
+			continue
+		}
+		if op := git.InProgressOperation(worktreeDir); op != "" {
+			return nil, fmt.Errorf("%s has a %s in progress; finish or abort it first", name, op)
+		}
+		branch, err := git.GetWorktreeBranch(worktreeDir)
+		if err != nil {
+			return nil, fmt.Errorf("%s: the worktree is not on a branch", name)
+		}
+		// Restore recreates the branches through ramp up, so each must be exactly the one it creates
+		prefix, ok := featureBranchPrefix(branch, featureName, prefixes)
+		if !ok {
+			return nil, fmt.Errorf("%s is on branch %s, not the feature's branch %s; check it out before archiving", name, branch, prefixes[0]+featureName)
+		}
+		a.Prefix, prefixes = prefix, []string{prefix} // The other repos must use the same prefix
+		a.Repos = append(a.Repos, ArchivedRepo{Repo: name, Branch: branch})
+	}
+	if len(a.Repos) == 0 {
+		return nil, fmt.Errorf("feature '%s' has no worktrees to archive", featureName)
+	}
+	a.Command = archiveCommand(a, opts.Config)
+
+	progress.Start(fmt.Sprintf("Archiving feature '%s'", featureName))
+	fail := func(err error) (*ArchiveResult, error) {
+		for _, archived := range a.Repos {
+			deleteArchiveRefs(repos[archived.Repo].GetRepoPath(projectDir), archived, true)
+		}
+		progress.Error(fmt.Sprintf("Failed to archive feature '%s'", featureName))
+		return nil, err
+	}
+
+	for i := range a.Repos {
+		archived := &a.Repos[i]
+		repoDir := repos[archived.Repo].GetRepoPath(projectDir)
+		worktreeDir := filepath.Join(treesDir, archived.Repo)
+		progress.Update(fmt.Sprintf("Saving %s", archived.Repo))
+
+		archived.Commit, err = git.ResolveCommit(worktreeDir, "HEAD")
+		if err != nil {
+			return fail(fmt.Errorf("%s: %w", archived.Repo, err))
+		}
+		archived.Ref = archiveRef(featureName, "head")
+		if err := git.UpdateRefQuiet(repoDir, archived.Ref, archived.Commit); err != nil {
+			return fail(fmt.Errorf("%s: %w", archived.Repo, err))
+		}
+
+		archived.Snapshot, err = git.SnapshotChanges(worktreeDir)
+		if err != nil {
+			return fail(fmt.Errorf("%s: failed to save uncommitted changes: %w", archived.Repo, err))
+		}
+		if archived.Snapshot != "" {
+			archived.SnapshotRef = archiveRef(featureName, "changes")
+			if err := git.UpdateRefQuiet(repoDir, archived.SnapshotRef, archived.Snapshot); err != nil {
+				return fail(fmt.Errorf("%s: %w", archived.Repo, err))
+			}
+			progress.Info(fmt.Sprintf("%s: saved uncommitted changes", archived.Repo))
+		}
+	}
+
+	data, err := json.MarshalIndent(a, "", "  ")
+	if err != nil {
+		return fail(fmt.Errorf("failed to marshal archive: %w", err))
+	}
+	path := archivePath(projectDir, featureName)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fail(fmt.Errorf("failed to create archive directory: %w", err))
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fail(fmt.Errorf("failed to write archive: %w", err))
+	}
+	progress.Success(fmt.Sprintf("Saved %d branches of feature '%s'", len(a.Repos), featureName))
+
+	// The archive stays if the removal fails; resuming it finishes the job
+	downResult, err := journaledDown(downOpts, downPlan, "down")
+	if err != nil {
+		return nil, err
+	}
+
+	return &ArchiveResult{Archive: a, Down: downResult}, nil
+}
+
+// archiveCommand returns the ramp up command that creates a feature with the
+// same name, branches and display name as an archived one.
+func archiveCommand(a *Archive, cfg *config.Config) string {
+	command := "ramp up " + a.Feature
+	switch {
+	case a.Prefix == cfg.GetBranchPrefix():
+	case a.Prefix == "":
+		command += " --no-prefix"
+	default:
+		command += " --prefix " + a.Prefix
+	}
+	if a.DisplayName != "" {
+		command += fmt.Sprintf(" --name %q", a.DisplayName)
+	}
+	return command
+}
+
+// RestoreFeature brings an archived feature back: its branches are recreated
+// at their archived commits, the feature is set up again through Up, which
+// writes env files and runs the setup script and hooks, and uncommitted
+// changes are reapplied on top. The archived ports are reclaimed when no
+// other feature took them, and the feature's notes, tags, links and original
+// creation info come back with it. The archive is removed once the feature is back.
+// This is the core business logic used by both CLI and UI.
+func RestoreFeature(opts RestoreOptions) (*RestoreResult, error) {
+	projectDir := opts.ProjectDir
+	featureName := opts.FeatureName
+	progress := opts.Progress
+	cfg := opts.Config
+
+	a, err := LoadArchive(projectDir, featureName)
+	if err != nil {
+		return nil, err
+	}
+	if a == nil {
+		return nil, fmt.Errorf("no archive of feature '%s'", featureName)
+	}
+	if _, err := os.Stat(filepath.Join(projectDir, "trees", featureName)); err == nil {
+		return nil, fmt.Errorf("feature '%s' already exists", featureName)
+	}
+
+	repos := cfg.GetRepos()
+	for _, archived := range a.Repos {
+		repo, ok := repos[archived.Repo]
+		if !ok || !git.IsGitRepo(repo.GetRepoPath(projectDir)) {
+			return nil, fmt.Errorf("%s is no longer installed; it is needed to restore feature '%s'", archived.Repo, featureName)
+		}
+		repoDir := repo.GetRepoPath(projectDir)
+		if _, err := git.ResolveCommit(repoDir, archived.Ref); err != nil {
+			return nil, fmt.Errorf("%s: archived commits of feature '%s' are missing (%s)", archived.Repo, featureName, archived.Ref)
+		}
+		if archived.Branch != a.Prefix+featureName {
+			return nil, fmt.Errorf("%s: archived branch %s is not %s, the branch ramp up would create", archived.Repo, archived.Branch, a.Prefix+featureName)
+		}
+		if exists, _ := git.LocalBranchExists(repoDir, archived.Branch); exists {
+			if commit, _ := git.ResolveCommit(repoDir, "refs/heads/"+archived.Branch); commit != archived.Commit {
+				return nil, fmt.Errorf("branch %s already exists in %s at a different commit", archived.Branch, archived.Repo)
+			}
+		}
+	}
+
+	progress.Start(fmt.Sprintf("Restoring feature '%s'", featureName))
+
+	// Undone if Up fails
+	var createdBranches []ArchivedRepo
+	reservedPorts := false
+	fail := func(err error) (*RestoreResult, error) {
+		for _, archived := range createdBranches {
+			git.DeleteBranchQuiet(repos[archived.Repo].GetRepoPath(projectDir), archived.Branch)
+		}
+		if reservedPorts {
+			if portAllocations, err := ports.NewPortAllocations(projectDir, cfg.GetBasePort(), cfg.GetMaxPorts()); err == nil {
+				portAllocations.ReleasePort(featureName)
+			}
+		}
+		return nil, err
+	}
+
+	for _, archived := range a.Repos {
+		repoDir := repos[archived.Repo].GetRepoPath(projectDir)
+		if exists, _ := git.LocalBranchExists(repoDir, archived.Branch); exists {
+			continue
+		}
+		if err := git.CreateBranchQuiet(repoDir, archived.Branch, archived.Commit); err != nil {
+			return fail(fmt.Errorf("%s: %w", archived.Repo, err))
+		}
+		createdBranches = append(createdBranches, archived)
+	}
+
+	result := &RestoreResult{Restored: []string{}}
+	if len(a.Ports) > 0 && cfg.HasPortConfig() {
+		portAllocations, err := ports.NewPortAllocations(projectDir, cfg.GetBasePort(), cfg.GetMaxPorts())
+		if err != nil {
+			return fail(err)
+		}
+		if err := portAllocations.ReservePorts(featureName, a.Ports); err != nil {
+			progress.Warning(fmt.Sprintf("Can't reclaim %s (%v); allocating new ones", describePorts(a.Ports), err))
+			result.PortsChanged = true
+		} else {
+			reservedPorts = true
+		}
+	}
+
+	upResult, err := Up(UpOptions{
+		FeatureName: featureName,
+		ProjectDir:  projectDir,
+		Config:      cfg,
+		Progress:    progress,
+		Output:      opts.Output,
+		Prefix:      a.Prefix,
+		NoPrefix:    a.Prefix == "",
+		DisplayName: a.DisplayName,
+	})
+	if err != nil {
+		return fail(err)
+	}
+	result.UpResult = upResult
+
+	if a.Metadata != nil {
+		metadataStore, err := features.NewMetadataStore(projectDir)
+		if err == nil {
+			err = metadataStore.RestoreFrom(featureName, *a.Metadata)
+		}
+		if err != nil {
+			progress.Warning(fmt.Sprintf("Failed to restore notes, tags and links: %v", err))
+		}
+	}
+
+	progress.Start("Reapplying uncommitted changes")
+	for _, archived := range a.Repos {
+		repoDir := repos[archived.Repo].GetRepoPath(projectDir)
+		keepSnapshot := false
+		if archived.Snapshot != "" {
+			worktreeDir := filepath.Join(upResult.TreesDir, archived.Repo)
+			if err := git.RestoreSnapshotQuiet(worktreeDir, archived.Snapshot); err != nil {
+				keepSnapshot = true
+				progress.Warning(fmt.Sprintf("%s: couldn't reapply uncommitted changes: %v; they are kept at %s (git checkout %s -- .)", archived.Repo, err, archived.SnapshotRef, archived.SnapshotRef))
+			} else {
+				result.Restored = append(result.Restored, archived.Repo)
+			}
+		}
+		deleteArchiveRefs(repoDir, archived, !keepSnapshot)
+	}
+	progress.Success(fmt.Sprintf("Reapplied uncommitted changes in %d repositories", len(result.Restored)))
+
+	if err := os.Remove(archivePath(projectDir, featureName)); err != nil && !os.IsNotExist(err) {
+		progress.Warning(fmt.Sprintf("Failed to remove archive of %s: %v", featureName, err))
+	}
+
+	return result, nil
+}
@@ -0,0 +1,201 @@
+package operations
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"ramp/internal/config"
+	"ramp/internal/git"
+)
+
+// CloneFeatureOptions configures duplicating a feature into a new one.
+type CloneFeatureOptions struct {
+	// Required
+	SourceFeature string
+	FeatureName   string // The new feature
+	ProjectDir    string
+	Config        *config.Config
+	Progress      ProgressReporter
+
+	// Optional
+	Output      OutputStreamer // For streaming setup script stdout/stderr
+	DisplayName string
+	Uncommitted bool // Carry over uncommitted changes and untracked files
+}
+
+// CloneFeatureResult contains the new feature and what was carried over.
+type CloneFeatureResult struct {
+	*UpResult
+	Sources     map[string]string // Branch each repo's new branch started from
+	CarriedOver []string          // Repos whose uncommitted changes were copied
+	Skipped     []string          // repo/path of untracked files the new worktree already had
+}
+
+// CloneFeature creates a feature whose branches start at the HEADs of another
+// feature's branches, going through the same worktree, port, env file, setup
+// and hook pipeline as Up. Repos without a worktree in the source feature
+// branch off their default branch as usual.
+//
+// With Uncommitted, staged and unstaged changes and untracked files are also
+// copied from the source worktrees, which are left untouched. They are copied
+// after the env files are written and before the setup script and hooks run,
+// so those see the carried-over work; untracked files the new worktree
+// already has, such as its generated env files, are kept and reported as
+// skipped with a warning.
+// This is the core business logic used by both CLI and UI.
+func CloneFeature(opts CloneFeatureOptions) (*CloneFeatureResult, error) {
+	projectDir := opts.ProjectDir
+	progress := opts.Progress
+	sourceTreesDir := filepath.Join(projectDir, "trees", opts.SourceFeature)
+
+	if _, err := os.Stat(sourceTreesDir); os.IsNotExist(err) {
+		return nil, fmt.Errorf("feature '%s' not found", opts.SourceFeature)
+	}
+	if opts.FeatureName == opts.SourceFeature {
+		return nil, fmt.Errorf("feature '%s' can't be cloned onto itself", opts.SourceFeature)
+	}
+
+	repos := opts.Config.GetRepos()
+	sources := make(map[string]string)
+	for _, name := range sortedRepoNames(repos) {
+		worktreeDir := filepath.Join(sourceTreesDir, name)
+		if _, err := os.Stat(worktreeDir); os.IsNotExist(err) {
+			continue
+		}
+		branch, err := git.GetWorktreeBranch(worktreeDir)
+		if err != nil {
+			return nil, fmt.Errorf("%s: the worktree of feature '%s' is not on a branch", name, opts.SourceFeature)
+		}
+		sources[name] = branch
+	}
+	if len(sources) == 0 {
+		return nil, fmt.Errorf("feature '%s' has no worktrees to clone", opts.SourceFeature)
+	}
+
+	result := &CloneFeatureResult{Sources: sources, CarriedOver: []string{}, Skipped: []string{}}
+	var copyChanges func(treesDir string) error
+	if opts.Uncommitted {
+		copyChanges = func(treesDir string) error {
+			progress.Start(fmt.Sprintf("Copying uncommitted changes from '%s'", opts.SourceFeature))
+			for _, name := range sortedRepoNames(repos) {
+				if sources[name] == "" {
+					continue
+				}
+				fromDir := filepath.Join(sourceTreesDir, name)
+				toDir := filepath.Join(treesDir, name)
+
+				copied, skipped, err := copyUncommittedChanges(fromDir, toDir)
+				for _, file := range skipped {
+					result.Skipped = append(result.Skipped, filepath.ToSlash(filepath.Join(name, file)))
+				}
+				if err != nil {
+					progress.Warning(fmt.Sprintf("%s: %v", name, err))
+					continue
+				}
+				if copied {
+					result.CarriedOver = append(result.CarriedOver, name)
+					progress.Info(fmt.Sprintf("%s: copied uncommitted changes", name))
+				}
+			}
+			for _, file := range result.Skipped {
+				progress.Warning(fmt.Sprintf("%s wasn't copied; the new feature already has it", file))
+			}
+			progress.Success(fmt.Sprintf("Copied uncommitted changes in %d repositories", len(result.CarriedOver)))
+			return nil
+		}
+	}
+
+	upResult, err := Up(UpOptions{
+		FeatureName: opts.FeatureName,
+		ProjectDir:  projectDir,
+		Config:      opts.Config,
+		Progress:    progress,
+		Output:      opts.Output,
+		Sources:     sources,
+		DisplayName: opts.DisplayName,
+		SkipRefresh: true, // The sources are local branches; pulling wouldn't move them
+		BeforeSetup: copyChanges,
+	})
+	if err != nil {
+		return nil, err
+	}
+	result.UpResult = upResult
+
+	return result, nil
+}
+
+// copyUncommittedChanges applies the staged and unstaged changes of one
+// worktree to another on the same commit, and copies its untracked files.
+// Untracked files the destination already has are skipped and returned.
+func copyUncommittedChanges(fromDir, toDir string) (bool, []string, error) {
+	copied := false
+	for _, staged := range []bool{true, false} {
+		patch, err := git.DiffPatch(fromDir, staged)
+		if err != nil {
+			return copied, nil, err
+		}
+		if patch == "" {
+			continue
+		}
+		if err := git.ApplyPatchQuiet(toDir, patch, staged); err != nil {
+			return copied, nil, err
+		}
+		copied = true
+	}
+
+	untracked, err := git.UntrackedFiles(fromDir)
+	if err != nil {
+		return copied, nil, err
+	}
+	skipped := []string{}
+	for _, file := range untracked {
+		dest := filepath.Join(toDir, file)
+		if _, err := os.Lstat(dest); err == nil {
+			skipped = append(skipped, file)
+			continue
+		}
+		if err := copyFile(filepath.Join(fromDir, file), dest); err != nil {
+			return copied, skipped, err
+		}
+		copied = true
+	}
+
+	return copied, skipped, nil
+}
+
+// copyFile copies a regular file or symlink, keeping its mode.
+func copyFile(from, to string) error {
+	info, err := os.Lstat(from)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(to), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", to, err)
+	}
+
+	if info.Mode()&os.ModeSymlink != 0 {
+		target, err := os.Readlink(from)
+		if err != nil {
+			return err
+		}
+		return os.Symlink(target, to)
+	}
+
+	in, err := os.Open(from)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(to, os.O_WRONLY|os.O_CREATE|os.O_EXCL, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return fmt.Errorf("failed to copy %s: %w", from, err)
+	}
+	return out.Close()
+}
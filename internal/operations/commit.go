@@ -0,0 +1,199 @@
+package operations
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"ramp/internal/config"
+	"ramp/internal/git"
+)
+
+// FeatureTrailer is the commit trailer that links commits made by ramp commit
+// to their feature, e.g. "Ramp-Feature: my-feature".
+const FeatureTrailer = "Ramp-Feature"
+
+// Commit statuses for a single repository.
+const (
+	CommitStatusCommitted = "committed"
+	CommitStatusUnchanged = "unchanged"
+	CommitStatusSkipped   = "skipped"
+	CommitStatusFailed    = "failed"
+)
+
+// CommitOptions configures committing across a feature's worktrees.
+type CommitOptions struct {
+	// Required
+	FeatureName string
+	ProjectDir  string
+	Config      *config.Config
+	Progress    ProgressReporter
+	Message     string // Required unless Amend is set
+
+	// Optional
+	All     bool     // Also stage untracked files (tracked changes are always staged)
+	Amend   bool     // Amend the last commit in each repo with changes; it must be ahead of the base branch
+	Trailer bool     // Append a Ramp-Feature trailer to the message
+	Include []string // Only commit in these repos (empty = all)
+	Exclude []string // Never commit in these repos
+}
+
+// CommitRepoResult is the outcome of committing in one repository.
+type CommitRepoResult struct {
+	Repo    string
+	Status  string // One of the CommitStatus constants
+	Commit  string // Short hash of the new commit
+	Message string
+}
+
+// CommitResult contains the per-repo outcome of a commit, sorted by repo name.
+type CommitResult struct {
+	FeatureName string
+	Repos       []CommitRepoResult
+}
+
+// Failed returns the repos where staging or committing failed.
+func (r *CommitResult) Failed() []string {
+	var failed []string
+	for _, repo := range r.Repos {
+		if repo.Status == CommitStatusFailed {
+			failed = append(failed, repo.Repo)
+		}
+	}
+	return failed
+}
+
+// Commit stages and commits the changes in every worktree of a feature with the
+// same message. Worktrees without changes are left alone.
+// This is the core business logic used by both CLI and UI.
+func Commit(opts CommitOptions) (*CommitResult, error) {
+	if opts.Message == "" && !opts.Amend {
+		return nil, fmt.Errorf("a commit message is required")
+	}
+
+	treesDir := filepath.Join(opts.ProjectDir, "trees", opts.FeatureName)
+	if _, err := os.Stat(treesDir); os.IsNotExist(err) {
+		return nil, fmt.Errorf("feature '%s' not found", opts.FeatureName)
+	}
+
+	repos := opts.Config.GetRepos()
+	for _, name := range append(slices.Clone(opts.Include), opts.Exclude...) {
+		if _, ok := repos[name]; !ok {
+			return nil, fmt.Errorf("repository '%s' not found in configuration", name)
+		}
+	}
+
+	progress := opts.Progress
+	progress.Start(fmt.Sprintf("Committing in feature '%s'", opts.FeatureName))
+
+	commitOpts := git.CommitOptions{Message: opts.Message, Amend: opts.Amend}
+	if opts.Trailer {
+		commitOpts.Trailers = []string{fmt.Sprintf("%s: %s", FeatureTrailer, opts.FeatureName)}
+	}
+
+	result := &CommitResult{FeatureName: opts.FeatureName}
+	for _, name := range sortedRepoNames(repos) {
+		repoResult := CommitRepoResult{Repo: name, Status: CommitStatusSkipped}
+		worktreeDir := filepath.Join(treesDir, name)
+
+		switch {
+		case len(opts.Include) > 0 && !slices.Contains(opts.Include, name), slices.Contains(opts.Exclude, name):
+			repoResult.Message = "excluded"
+		case !dirExists(worktreeDir):
+			repoResult.Message = "no worktree, skipped"
+		default:
+			base := ""
+			if opts.Amend {
+				base, _ = syncBase(repos[name], repos[name].GetRepoPath(opts.ProjectDir))
+			}
+			repoResult = commitRepo(name, worktreeDir, base, opts.All, commitOpts)
+		}
+
+		switch repoResult.Status {
+		case CommitStatusCommitted:
+			progress.Info(fmt.Sprintf("%s: ✅ %s", name, repoResult.Message))
+		case CommitStatusFailed:
+			progress.Warning(fmt.Sprintf("%s: %s", name, repoResult.Message))
+		default:
+			progress.Info(fmt.Sprintf("%s: %s", name, repoResult.Message))
+		}
+		result.Repos = append(result.Repos, repoResult)
+	}
+
+	if failed := result.Failed(); len(failed) > 0 {
+		progress.Error(fmt.Sprintf("Failed to commit in %s", strings.Join(failed, ", ")))
+	} else {
+		progress.Success(fmt.Sprintf("Committed in feature '%s'", opts.FeatureName))
+	}
+	return result, nil
+}
+
+// commitRepo stages and commits one worktree's changes. An amend needs a
+// commit of the feature's own, one ahead of base, so the base branch's
+// history is never rewritten onto the feature branch.
+func commitRepo(name, worktreeDir, base string, includeUntracked bool, opts git.CommitOptions) CommitRepoResult {
+	result := CommitRepoResult{Repo: name, Status: CommitStatusFailed}
+
+	if opts.Amend {
+		if base == "" {
+			result.Message = "can't find the base branch to check there is a commit to amend"
+			return result
+		}
+		ahead, _, err := git.GetAheadBehindCount(worktreeDir, base)
+		if err != nil {
+			result.Message = err.Error()
+			return result
+		}
+		if ahead == 0 {
+			if changed, _ := git.HasUncommittedChanges(worktreeDir); !changed {
+				result.Status = CommitStatusUnchanged
+				result.Message = "no changes"
+				return result
+			}
+			result.Message = fmt.Sprintf("no commits ahead of %s to amend; commit without --amend", base)
+			return result
+		}
+	}
+
+	if err := git.StageChanges(worktreeDir, includeUntracked); err != nil {
+		result.Message = err.Error()
+		return result
+	}
+
+	staged, err := git.HasStagedChanges(worktreeDir)
+	if err != nil {
+		result.Message = err.Error()
+		return result
+	}
+	if !staged {
+		result.Status = CommitStatusUnchanged
+		result.Message = "no changes"
+		return result
+	}
+
+	if err := git.CommitQuiet(worktreeDir, opts); err != nil {
+		result.Message = err.Error()
+		return result
+	}
+
+	commit, err := git.GetShortCommit(worktreeDir)
+	if err != nil {
+		result.Message = err.Error()
+		return result
+	}
+
+	result.Status = CommitStatusCommitted
+	result.Commit = commit
+	result.Message = "committed " + commit
+	if opts.Amend {
+		result.Message = "amended " + commit
+	}
+	return result
+}
+
+func dirExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
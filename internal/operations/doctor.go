@@ -0,0 +1,530 @@
+package operations
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"ramp/internal/config"
+	"ramp/internal/envfile"
+	"ramp/internal/features"
+	"ramp/internal/git"
+	"ramp/internal/ports"
+)
+
+// Kinds of problems Doctor looks for, in the order they are reported.
+const (
+	IssueMissingTool       = "missing-tool"
+	IssueUnreachableRemote = "unreachable-remote"
+	IssueInterrupted       = "interrupted-operation"
+	IssueMovedWorktree     = "moved-worktree"   // Worktree whose link to its repo broke, e.g. after moving the project
+	IssueMissingWorktree   = "missing-worktree" // Registered with git, directory gone
+	IssueUnregisteredDir   = "unregistered-dir" // Directory under trees/ git doesn't know about
+	IssueLeftoverBranch    = "leftover-branch"  // Feature branch of a feature that no longer exists
+	IssueStalePorts        = "stale-ports"      // Port allocation of a feature that no longer exists
+	IssueStaleMetadata     = "stale-metadata"   // Metadata of a feature that no longer exists
+)
+
+// remoteReachableTimeout bounds how long Doctor waits for each remote.
+const remoteReachableTimeout = 15 * time.Second
+
+// DoctorOptions configures checking a project for inconsistencies.
+type DoctorOptions struct {
+	// Required
+	ProjectDir string
+	Config     *config.Config
+	Progress   ProgressReporter
+
+	// Optional
+	Fix     bool // Repair the issues that can be repaired automatically
+	Offline bool // Skip checking that remotes are reachable
+}
+
+// DoctorIssue is one problem found in a project.
+type DoctorIssue struct {
+	Kind     string `json:"kind"`
+	Repo     string `json:"repo,omitempty"`
+	Feature  string `json:"feature,omitempty"`
+	Path     string `json:"path,omitempty"`
+	Detail   string `json:"detail"`
+	Fix      string `json:"fix"`     // What --fix does, or what to do by hand when it can't
+	Fixable  bool   `json:"fixable"` // Whether --fix repairs it
+	Fixed    bool   `json:"fixed"`
+	FixError string `json:"fixError,omitempty"`
+
+	repair func() error
+}
+
+// DoctorResult contains the issues Doctor found, in the order of the issue
+// kinds and then by repo, feature and path.
+type DoctorResult struct {
+	Issues []DoctorIssue `json:"issues"`
+}
+
+// Unresolved returns the number of issues that are still present.
+func (r *DoctorResult) Unresolved() int {
+	count := 0
+	for _, issue := range r.Issues {
+		if !issue.Fixed {
+			count++
+		}
+	}
+	return count
+}
+
+// Doctor checks a project for state that drifted out of sync: missing tools,
+// unreachable remotes, interrupted operations, worktrees and directories git
+// and ramp disagree about, branches of removed features, and port allocations
+// and metadata left behind by them. With Fix set it repairs what it can, in
+// an order where each repair can rely on the previous ones. Nothing that may
+// hold unmerged or uncommitted work is deleted: such issues are only reported.
+// This is the core business logic used by both CLI and UI.
+func Doctor(opts DoctorOptions) (*DoctorResult, error) {
+	progress := opts.Progress
+	progress.Start(fmt.Sprintf("Checking project '%s'", opts.Config.Name))
+
+	issues := checkTools(opts.Config)
+
+	if !opts.Offline {
+		progress.Update("Checking remotes")
+		issues = append(issues, checkRemotes(opts.ProjectDir, opts.Config)...)
+	}
+
+	journals, err := PendingJournals(opts.ProjectDir)
+	if err != nil {
+		return nil, err
+	}
+	for _, j := range journals {
+		issues = append(issues, DoctorIssue{
+			Kind:    IssueInterrupted,
+			Feature: j.Feature,
+			Detail:  fmt.Sprintf("%s of feature '%s' was interrupted", j.Operation, j.Feature),
+			Fix:     fmt.Sprintf("run 'ramp resume %s' or 'ramp rollback %s'", j.Feature, j.Feature),
+		})
+	}
+
+	progress.Update("Checking worktrees and branches")
+	worktreeIssues, existing, err := checkWorktrees(opts.ProjectDir, opts.Config)
+	if err != nil {
+		return nil, err
+	}
+	issues = append(issues, worktreeIssues...)
+
+	stateIssues, err := checkFeatureState(opts.ProjectDir, existing)
+	if err != nil {
+		return nil, err
+	}
+	issues = append(issues, stateIssues...)
+
+	result := &DoctorResult{Issues: issues}
+	if opts.Fix {
+		for i := range result.Issues {
+			issue := &result.Issues[i]
+			if !issue.Fixable {
+				continue
+			}
+			if err := issue.repair(); err != nil {
+				issue.FixError = err.Error()
+				progress.Warning(fmt.Sprintf("Failed to fix %s: %v", issue.Detail, err))
+				continue
+			}
+			issue.Fixed = true
+			progress.Info(fmt.Sprintf("Fixed: %s", issue.Detail))
+		}
+	}
+
+	if unresolved := result.Unresolved(); unresolved > 0 {
+		progress.Warning(fmt.Sprintf("%d issue(s) found", unresolved))
+	} else if len(result.Issues) > 0 {
+		progress.Success(fmt.Sprintf("Fixed %d issue(s)", len(result.Issues)))
+	} else {
+		progress.Success("No issues found")
+	}
+	return result, nil
+}
+
+// checkTools looks for the programs the project's configuration relies on.
+func checkTools(cfg *config.Config) []DoctorIssue {
+	issues := []DoctorIssue{}
+	missing := func(tool, reason, fix string) {
+		issues = append(issues, DoctorIssue{
+			Kind:   IssueMissingTool,
+			Detail: fmt.Sprintf("%s is not installed (%s)", tool, reason),
+			Fix:    fix,
+		})
+	}
+
+	if _, err := exec.LookPath("git"); err != nil {
+		missing("git", "required for every command", "install git")
+		return issues // The remaining checks run git
+	}
+
+	usesScripts := cfg.Setup != "" || cfg.Cleanup != "" || len(cfg.Hooks) > 0 || len(cfg.Commands) > 0
+	for _, provider := range cfg.Secrets {
+		if provider != nil && provider.Type == envfile.SecretProviderExec {
+			usesScripts = true
+		}
+	}
+	if usesScripts {
+		if _, err := os.Stat("/bin/bash"); err != nil {
+			missing("/bin/bash", "runs scripts, hooks and commands", "install bash")
+		}
+	}
+
+	for _, name := range sortedRepoNames(cfg.GetRepos()) {
+		if cfg.GetRepos()[name].LFS {
+			if err := exec.Command("git", "lfs", "version").Run(); err != nil {
+				missing("git-lfs", fmt.Sprintf("%s sets lfs", name), "install git-lfs and run 'git lfs install'")
+			}
+			break
+		}
+	}
+
+	secretTools := map[string]string{envfile.SecretProviderPass: "pass", envfile.SecretProviderKeychain: "security"}
+	providerNames := make([]string, 0, len(cfg.Secrets))
+	for name := range cfg.Secrets {
+		providerNames = append(providerNames, name)
+	}
+	sort.Strings(providerNames)
+	for _, name := range providerNames {
+		provider := cfg.Secrets[name]
+		if provider == nil {
+			continue
+		}
+		if tool, ok := secretTools[provider.Type]; ok {
+			if _, err := exec.LookPath(tool); err != nil {
+				missing(tool, fmt.Sprintf("secret provider %s", name), "install "+tool)
+			}
+		}
+	}
+
+	return issues
+}
+
+// checkRemotes checks that the origin of every installed repo, and the
+// upstream of forks, can be reached.
+func checkRemotes(projectDir string, cfg *config.Config) []DoctorIssue {
+	repos := cfg.GetRepos()
+	names := sortedRepoNames(repos)
+	found := make([][]DoctorIssue, len(names))
+
+	forEachParallel(len(names), cfg.GetMaxParallel(), func(i int) {
+		repo := repos[names[i]]
+		repoDir := repo.GetRepoPath(projectDir)
+		if !git.IsGitRepo(repoDir) {
+			return
+		}
+
+		remotes := []string{}
+		if git.HasRemote(repoDir, "origin") {
+			remotes = append(remotes, "origin")
+		}
+		if repo.Upstream != "" && git.HasRemote(repoDir, git.UpstreamRemote) {
+			remotes = append(remotes, git.UpstreamRemote)
+		}
+		for _, remote := range remotes {
+			if err := git.RemoteReachable(repoDir, remote, remoteReachableTimeout); err != nil {
+				found[i] = append(found[i], DoctorIssue{
+					Kind:   IssueUnreachableRemote,
+					Repo:   names[i],
+					Detail: fmt.Sprintf("%s: %v", names[i], err),
+					Fix:    "check the remote URL, network access and credentials",
+				})
+			}
+		}
+	})
+
+	issues := []DoctorIssue{}
+	for _, repoIssues := range found {
+		issues = append(issues, repoIssues...)
+	}
+	return issues
+}
+
+// checkWorktrees compares the worktrees each source repo has registered with
+// the directories under trees/. It returns the issues found and the features
+// that exist: those with at least one registered worktree or a journal.
+func checkWorktrees(projectDir string, cfg *config.Config) ([]DoctorIssue, map[string]bool, error) {
+	// git records real paths, so a project reached through a symlink is compared by its real path
+	treesRoot := filepath.Join(projectDir, "trees")
+	realTreesRoot := realPath(treesRoot)
+	featureNames, err := ListFeatures(projectDir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	existing := map[string]bool{}
+	journalEntries, err := os.ReadDir(filepath.Join(projectDir, ".ramp", JournalDir))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, nil, fmt.Errorf("failed to read journal directory: %w", err)
+	}
+	for _, entry := range journalEntries {
+		if name := entry.Name(); filepath.Ext(name) == ".json" {
+			existing[strings.TrimSuffix(name, ".json")] = true
+		}
+	}
+
+	var missing, moved, leftover []DoctorIssue
+	registered := map[string]bool{} // Real worktree paths under trees/ that still exist
+	checkedOut := map[string]map[string]bool{}
+
+	repos := cfg.GetRepos()
+	for _, name := range sortedRepoNames(repos) {
+		repoDir := repos[name].GetRepoPath(projectDir)
+		if !git.IsGitRepo(repoDir) {
+			continue
+		}
+		worktrees, err := git.ListWorktrees(repoDir)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", name, err)
+		}
+
+		checkedOut[name] = map[string]bool{}
+		pruned := false
+		for _, worktree := range worktrees {
+			if _, err := os.Stat(worktree.Path); err != nil {
+				if !worktree.Main && (isUnder(worktree.Path, treesRoot) || isUnder(worktree.Path, realTreesRoot)) {
+					missing = append(missing, DoctorIssue{
+						Kind:    IssueMissingWorktree,
+						Repo:    name,
+						Path:    worktree.Path,
+						Detail:  fmt.Sprintf("%s: worktree %s is registered but its directory is gone", name, worktree.Path),
+						Fix:     "prune the worktree registration",
+						Fixable: true,
+						repair: func() error {
+							// One prune clears every missing worktree of the repo
+							if pruned {
+								return nil
+							}
+							pruned = true
+							return git.PruneWorktrees(repoDir)
+						},
+					})
+				}
+				continue
+			}
+
+			if worktree.Branch != "" {
+				checkedOut[name][worktree.Branch] = true
+			}
+			path := realPath(worktree.Path)
+			if rel, err := filepath.Rel(realTreesRoot, path); err == nil && !strings.HasPrefix(rel, "..") {
+				registered[path] = true
+				existing[strings.Split(rel, string(filepath.Separator))[0]] = true
+			}
+		}
+	}
+
+	// Directories under trees/ no source repo knows about. Worktrees of repos
+	// that aren't installed can't be checked and keep their feature.
+	var unregistered []DoctorIssue
+	for _, featureName := range featureNames {
+		featureDir := filepath.Join(treesRoot, featureName)
+		entries, err := os.ReadDir(featureDir)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read %s: %w", featureDir, err)
+		}
+
+		var dirs []DoctorIssue
+		for _, entry := range entries {
+			dir := filepath.Join(featureDir, entry.Name())
+			if !entry.IsDir() || registered[realPath(dir)] {
+				continue
+			}
+			if _, configured := repos[entry.Name()]; configured && checkedOut[entry.Name()] == nil {
+				existing[featureName] = true
+				continue
+			}
+
+			// A worktree whose repo still has its entry lost the link, e.g. when the project moved
+			if name := git.LinkedWorktreeName(dir); name != "" && checkedOut[entry.Name()] != nil {
+				repoDir := repos[entry.Name()].GetRepoPath(projectDir)
+				if git.HasWorktreeEntry(repoDir, name) {
+					existing[featureName] = true
+					moved = append(moved, DoctorIssue{
+						Kind:    IssueMovedWorktree,
+						Repo:    entry.Name(),
+						Feature: featureName,
+						Path:    dir,
+						Detail:  fmt.Sprintf("trees/%s/%s is a worktree of %s that git lost track of, as after moving the project", featureName, entry.Name(), entry.Name()),
+						Fix:     "reconnect it with git worktree repair",
+						Fixable: true,
+						repair:  func() error { return git.RepairWorktree(repoDir, dir) },
+					})
+					continue
+				}
+			}
+
+			dirs = append(dirs, removeDirIssue(featureName, entry.Name(), dir,
+				fmt.Sprintf("trees/%s/%s is not a registered worktree", featureName, entry.Name())))
+		}
+
+		if existing[featureName] {
+			unregistered = append(unregistered, dirs...)
+		} else {
+			unregistered = append(unregistered, removeDirIssue(featureName, "", featureDir,
+				fmt.Sprintf("trees/%s has no registered worktrees", featureName)))
+		}
+	}
+
+	// Feature branches whose feature is gone. Without a prefix every branch
+	// would look like one, so those projects aren't checked. Only branches
+	// merged into the base branch are deleted; the rest may hold the only copy
+	// of their commits.
+	if prefix := cfg.GetBranchPrefix(); prefix != "" {
+		for _, name := range sortedRepoNames(repos) {
+			if checkedOut[name] == nil {
+				continue
+			}
+			repoDir := repos[name].GetRepoPath(projectDir)
+			refs, err := git.ListRefs(repoDir)
+			if err != nil {
+				return nil, nil, fmt.Errorf("%s: %w", name, err)
+			}
+			base, baseErr := syncBase(repos[name], repoDir)
+
+			branches := []string{}
+			for refname := range refs {
+				branch, ok := strings.CutPrefix(refname, "refs/heads/")
+				if ok && strings.HasPrefix(branch, prefix) && !checkedOut[name][branch] && !existing[strings.TrimPrefix(branch, prefix)] {
+					branches = append(branches, branch)
+				}
+			}
+			sort.Strings(branches)
+
+			for _, branch := range branches {
+				issue := DoctorIssue{
+					Kind:    IssueLeftoverBranch,
+					Repo:    name,
+					Feature: strings.TrimPrefix(branch, prefix),
+					Detail:  fmt.Sprintf("%s: branch %s belongs to no feature", name, branch),
+					Fix:     fmt.Sprintf("it isn't merged; delete it with 'git branch -D %s' once you're sure its commits aren't needed", branch),
+				}
+				if baseErr != nil {
+					issue.Fix = fmt.Sprintf("can't tell whether it is merged (%v); delete it by hand if it isn't needed", baseErr)
+				} else if merged, _ := git.IsAncestor(repoDir, "refs/heads/"+branch, base); merged {
+					issue.Fix = fmt.Sprintf("delete the branch, which is merged into %s", base)
+					issue.Fixable = true
+					issue.repair = func() error { return git.DeleteMergedBranchQuiet(repoDir, branch) }
+				}
+				leftover = append(leftover, issue)
+			}
+		}
+	}
+
+	// Repairs go first: pruning missing worktrees would drop the entries they reconnect to
+	issues := append(moved, missing...)
+	issues = append(issues, unregistered...)
+	issues = append(issues, leftover...)
+	return issues, existing, nil
+}
+
+// removeDirIssue reports a directory under trees/ that git doesn't know about.
+// Only a directory without any files is removed by the fix: anything else may
+// be a worktree or hold work that exists nowhere else.
+func removeDirIssue(featureName, repoName, dir, detail string) DoctorIssue {
+	issue := DoctorIssue{
+		Kind:    IssueUnregisteredDir,
+		Repo:    repoName,
+		Feature: featureName,
+		Path:    dir,
+		Detail:  detail,
+		Fix:     "it contains files; move anything you need and remove it by hand",
+	}
+	if !containsFiles(dir) {
+		issue.Fix = "remove the empty directory"
+		issue.Fixable = true
+		issue.repair = func() error { return os.RemoveAll(dir) }
+	}
+	return issue
+}
+
+// containsFiles reports whether anything but directories is under dir,
+// assuming it does when dir can't be read.
+func containsFiles(dir string) bool {
+	found := false
+	err := filepath.WalkDir(dir, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.IsDir() {
+			found = true
+			return filepath.SkipAll
+		}
+		return nil
+	})
+	return found || err != nil
+}
+
+// realPath resolves symlinks in path, or returns it unchanged when it can't.
+func realPath(path string) string {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		return resolved
+	}
+	return path
+}
+
+// isUnder reports whether path is inside dir.
+func isUnder(path, dir string) bool {
+	return strings.HasPrefix(path, dir+string(filepath.Separator))
+}
+
+// checkFeatureState looks for port allocations and metadata of features that
+// don't exist.
+func checkFeatureState(projectDir string, existing map[string]bool) ([]DoctorIssue, error) {
+	issues := []DoctorIssue{}
+
+	// Limits don't matter for reading and releasing allocations
+	portAllocations, err := ports.NewPortAllocations(projectDir, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	allocations := portAllocations.ListAllocations()
+	for _, featureName := range sortedKeys(allocations) {
+		if existing[featureName] {
+			continue
+		}
+		issues = append(issues, DoctorIssue{
+			Kind:    IssueStalePorts,
+			Feature: featureName,
+			Detail:  fmt.Sprintf("%s are allocated to feature '%s', which doesn't exist", describePorts(allocations[featureName]), featureName),
+			Fix:     "release the ports",
+			Fixable: true,
+			repair:  func() error { return portAllocations.ReleasePort(featureName) },
+		})
+	}
+
+	metadataStore, err := features.NewMetadataStore(projectDir)
+	if err != nil {
+		return nil, err
+	}
+	metadata := metadataStore.ListMetadata()
+	for _, featureName := range sortedKeys(metadata) {
+		if existing[featureName] {
+			continue
+		}
+		issues = append(issues, DoctorIssue{
+			Kind:    IssueStaleMetadata,
+			Feature: featureName,
+			Detail:  fmt.Sprintf("metadata is stored for feature '%s', which doesn't exist", featureName),
+			Fix:     "remove the metadata",
+			Fixable: true,
+			repair:  func() error { return metadataStore.RemoveFeature(featureName) },
+		})
+	}
+
+	return issues, nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
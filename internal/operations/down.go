@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 
 	"ramp/internal/config"
 	"ramp/internal/features"
@@ -57,13 +58,30 @@ func CheckForUncommittedChanges(cfg *config.Config, treesDir string) ([]string,
 	return reposWithChanges, nil
 }
 
+// DownPlan is what Down will do to remove a feature.
+type DownPlan struct {
+	Plan
+	TreesDir       string         `json:"treesDir"`
+	TreesDirExists bool           `json:"treesDirExists"` // False for orphaned worktrees
+	DisplayName    string         `json:"displayName,omitempty"`
+	Repos          []DownRepoPlan `json:"repos"`
+	Ports          []int          `json:"ports,omitempty"`
+	Uncommitted    []string       `json:"uncommitted,omitempty"` // Repos whose uncommitted changes will be lost
+}
+
+// DownRepoPlan is the worktree and branch removed from one repo.
+type DownRepoPlan struct {
+	Repo        string `json:"repo"`
+	WorktreeDir string `json:"worktreeDir"`
+	Branch      string `json:"branch"`
+}
+
 // Down removes a feature with worktrees for all repositories.
 // This is the core business logic used by both CLI and UI.
 func Down(opts DownOptions) (*DownResult, error) {
 	projectDir := opts.ProjectDir
 	cfg := opts.Config
 	progress := opts.Progress
-	featureName := opts.FeatureName
 
 	// Auto-install if requested and needed
 	if opts.AutoInstall && !IsProjectInstalled(cfg, projectDir) {
@@ -78,16 +96,41 @@ func Down(opts DownOptions) (*DownResult, error) {
 		}
 	}
 
+	plan, err := PlanDown(opts)
+	if err != nil {
+		return nil, err
+	}
+	return ApplyDown(opts, plan)
+}
+
+// PlanDown works out which worktrees, branches, ports and files Down would
+// remove, and which scripts and hooks it would run, without changing anything.
+func PlanDown(opts DownOptions) (*DownPlan, error) {
+	projectDir := opts.ProjectDir
+	cfg := opts.Config
+	progress := opts.Progress
+	featureName := opts.FeatureName
+
 	configPrefix := cfg.GetBranchPrefix()
 	treesDir := filepath.Join(projectDir, "trees", featureName)
+	repos := cfg.GetRepos()
+
+	plan := &DownPlan{
+		Plan:           newPlan("down", featureName),
+		TreesDir:       treesDir,
+		TreesDirExists: true,
+		Repos:          []DownRepoPlan{},
+	}
+
+	if opts.AutoInstall {
+		plan.addCloneSteps(InstallOptions{ProjectDir: projectDir, Config: cfg})
+	}
 
 	// Check if trees directory exists
-	treesDirExists := true
 	if _, err := os.Stat(treesDir); os.IsNotExist(err) {
-		treesDirExists = false
+		plan.TreesDirExists = false
 
 		// Check if any worktrees or branches exist for this feature
-		repos := cfg.GetRepos()
 		featureExists := false
 		for name, repo := range repos {
 			repoDir := repo.GetRepoPath(projectDir)
@@ -114,52 +157,129 @@ func Down(opts DownOptions) (*DownResult, error) {
 
 	progress.Start(fmt.Sprintf("Cleaning up feature '%s' for project '%s'", featureName, cfg.Name))
 
-	if !treesDirExists {
-		progress.Warning(fmt.Sprintf("Trees directory for feature '%s' not found - cleaning up orphaned worktrees", featureName))
+	if !plan.TreesDirExists {
+		warning := fmt.Sprintf("Trees directory for feature '%s' not found - cleaning up orphaned worktrees", featureName)
+		progress.Warning(warning)
+		plan.warn(warning)
 	}
 
 	// Check for uncommitted changes if not forced
-	if treesDirExists && !opts.Force {
+	if plan.TreesDirExists && !opts.Force {
 		reposWithChanges, err := CheckForUncommittedChanges(cfg, treesDir)
 		if err != nil {
 			return nil, err
 		}
+		sort.Strings(reposWithChanges)
 		for _, name := range reposWithChanges {
-			progress.Warning(fmt.Sprintf("Uncommitted changes found in %s", name))
+			warning := fmt.Sprintf("Uncommitted changes found in %s", name)
+			progress.Warning(warning)
+			plan.warn(warning)
 		}
+		plan.Uncommitted = reposWithChanges
 	}
 
 	// Get allocated ports for hook environment
-	repos := cfg.GetRepos()
-	var allocatedPorts []int
-	if cfg.HasPortConfig() {
-		portAllocations, err := ports.NewPortAllocations(projectDir, cfg.GetBasePort(), cfg.GetMaxPorts())
-		if err == nil {
-			if p, exists := portAllocations.GetPorts(featureName); exists {
-				allocatedPorts = p
+	plan.Ports = featurePorts(projectDir, cfg, featureName)
+
+	// Load metadata store - used for display name and cleanup
+	metadataStore, metaErr := features.NewMetadataStore(projectDir)
+	if metaErr == nil {
+		plan.DisplayName = metadataStore.GetDisplayName(featureName)
+	}
+
+	// Down hooks and the cleanup script run first, while the worktrees still exist
+	if plan.TreesDirExists {
+		plan.addHookSteps(projectDir, hooks.Down)
+		if cfg.Cleanup != "" {
+			plan.add(StepRunScript, "", "run cleanup script "+cfg.Cleanup)
+		}
+	}
+
+	for _, name := range sortedRepoNames(repos) {
+		repoDir := repos[name].GetRepoPath(projectDir)
+		worktreeDir := filepath.Join(treesDir, name)
+		if !git.IsGitRepo(repoDir) {
+			continue
+		}
+
+		// Try to detect the actual branch name from the worktree
+		var branchName string
+		if _, err := os.Stat(worktreeDir); err == nil {
+			if detectedBranch, err := git.GetWorktreeBranch(worktreeDir); err == nil {
+				branchName = detectedBranch
+				progress.Info(fmt.Sprintf("%s: detected branch %s", name, branchName))
+			} else {
+				branchName = configPrefix + featureName
+				progress.Info(fmt.Sprintf("%s: could not detect branch, using fallback %s", name, branchName))
 			}
+		} else {
+			branchName = configPrefix + featureName
+			progress.Info(fmt.Sprintf("%s: worktree directory not found, using fallback branch %s", name, branchName))
 		}
+
+		plan.Repos = append(plan.Repos, DownRepoPlan{Repo: name, WorktreeDir: worktreeDir, Branch: branchName})
+		plan.add(StepRemoveWorktree, name, "remove worktree "+worktreeDir)
+		plan.add(StepDeleteBranch, name, "delete branch "+branchName)
 	}
 
-	// Load metadata store - used for display name and cleanup
-	metadataStore, metaErr := features.NewMetadataStore(projectDir)
-	displayName := ""
+	if len(plan.Ports) > 0 {
+		plan.add(StepReleasePorts, "", "release "+describePorts(plan.Ports))
+	}
 	if metaErr == nil {
-		displayName = metadataStore.GetDisplayName(featureName)
+		if _, ok := metadataStore.ListMetadata()[featureName]; ok {
+			plan.add(StepRemoveMetadata, "", "remove feature metadata")
+		}
 	}
+	if plan.TreesDirExists {
+		plan.add(StepRemoveDir, "", "remove "+treesDir)
+	}
+
+	return plan, nil
+}
+
+// ApplyDown removes the feature described by a plan from PlanDown. Each
+// completed step is journaled, so ResumeOperation can finish the removal if
+// the process is killed.
+func ApplyDown(opts DownOptions, plan *DownPlan) (*DownResult, error) {
+	return journaledDown(opts, plan, "down")
+}
+
+// journaledDown starts the journal of a down or prune of one feature and applies its plan.
+func journaledDown(opts DownOptions, plan *DownPlan, operation string) (*DownResult, error) {
+	j, err := beginJournal(opts.ProjectDir, operation, opts.FeatureName, func(j *Journal) {
+		j.Down = plan
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer j.release()
+	return applyDown(opts, plan, j)
+}
+
+// applyDown runs the steps of a down plan, skipping the ones a resumed journal
+// already completed. Steps that fail with a warning count as completed.
+func applyDown(opts DownOptions, plan *DownPlan, j *Journal) (*DownResult, error) {
+	projectDir := opts.ProjectDir
+	cfg := opts.Config
+	progress := opts.Progress
+	featureName := opts.FeatureName
+	treesDir := plan.TreesDir
+	repos := cfg.GetRepos()
 
 	// Execute down hooks (before cleanup script)
 	mergedCfg, err := config.LoadMergedConfig(projectDir)
-	if err == nil && len(mergedCfg.Hooks) > 0 && treesDirExists {
-		hookEnv := BuildEnvVars(projectDir, treesDir, featureName, displayName, allocatedPorts, cfg, repos)
+	if err == nil && len(mergedCfg.Hooks) > 0 && plan.TreesDirExists && !j.Completed(StepRunHook, "") {
+		hookEnv := BuildEnvVars(projectDir, treesDir, featureName, plan.DisplayName, plan.Ports, cfg, repos)
 		hooks.ExecuteHooks(hooks.Down, mergedCfg.Hooks, projectDir, treesDir, hookEnv, progress)
+		j.record(StepRunHook, "", string(hooks.Down))
 	}
 
 	// Run cleanup script if configured and directory exists
-	if cfg.Cleanup != "" && treesDirExists {
-		if err := RunCleanupScript(projectDir, treesDir, featureName, displayName, cfg, progress); err != nil {
+	if cfg.Cleanup != "" && plan.TreesDirExists && !j.Completed(StepRunScript, "") {
+		if err := RunCleanupScript(projectDir, treesDir, featureName, plan.DisplayName, cfg, progress); err != nil {
 			progress.Warning(fmt.Sprintf("Cleanup script failed: %v", err))
 		}
+		j.record(StepRunScript, "", cfg.Cleanup)
 	}
 
 	result := &DownResult{
@@ -169,55 +289,41 @@ func Down(opts DownOptions) (*DownResult, error) {
 	}
 
 	// Remove git worktrees and branches
-	total := len(repos)
-	i := 0
-
-	for name, repo := range repos {
-		repoDir := repo.GetRepoPath(projectDir)
-		worktreeDir := filepath.Join(treesDir, name)
+	total := len(plan.Repos)
+	for i, repoPlan := range plan.Repos {
+		name := repoPlan.Repo
+		repoDir := repos[name].GetRepoPath(projectDir)
+		branchName := repoPlan.Branch
 
 		progress.UpdateWithProgress(fmt.Sprintf("Removing worktree for %s...", name), (i+1)*70/total)
 
-		if git.IsGitRepo(repoDir) {
-			var branchName string
-
-			// Try to detect the actual branch name from the worktree
-			if _, err := os.Stat(worktreeDir); err == nil {
-				if detectedBranch, err := git.GetWorktreeBranch(worktreeDir); err == nil {
-					branchName = detectedBranch
-					progress.Info(fmt.Sprintf("%s: detected branch %s", name, branchName))
-				} else {
-					branchName = configPrefix + featureName
-					progress.Info(fmt.Sprintf("%s: could not detect branch, using fallback %s", name, branchName))
-				}
-			} else {
-				branchName = configPrefix + featureName
-				progress.Info(fmt.Sprintf("%s: worktree directory not found, using fallback branch %s", name, branchName))
-			}
-
-			// Remove worktree
+		// Remove worktree
+		if !j.Completed(StepRemoveWorktree, name) {
 			progress.Info(fmt.Sprintf("%s: removing worktree registration", name))
-			if err := git.RemoveWorktreeQuiet(repoDir, worktreeDir); err != nil {
+			if err := git.RemoveWorktreeQuiet(repoDir, repoPlan.WorktreeDir); err != nil {
 				progress.Warning(fmt.Sprintf("Failed to remove worktree for %s: %v", name, err))
 				_ = git.PruneWorktrees(repoDir)
 			} else {
 				result.RemovedWorktrees = append(result.RemovedWorktrees, name)
 			}
+			j.record(StepRemoveWorktree, name, repoPlan.WorktreeDir)
+		}
 
-			// Delete branch
+		// Delete branch
+		if !j.Completed(StepDeleteBranch, name) {
 			progress.Info(fmt.Sprintf("%s: deleting branch %s", name, branchName))
 			if err := git.DeleteBranchQuiet(repoDir, branchName); err != nil {
 				progress.Warning(fmt.Sprintf("Failed to delete branch for %s: %v", name, err))
 			} else {
 				result.DeletedBranches = append(result.DeletedBranches, branchName)
 			}
+			j.record(StepDeleteBranch, name, branchName)
+		}
 
-			// Prune stale remote tracking branches
-			if err := git.FetchPruneQuiet(repoDir); err != nil {
-				progress.Warning(fmt.Sprintf("Failed to prune remote tracking branches for %s: %v", name, err))
-			}
+		// Prune stale remote tracking branches
+		if err := git.FetchPruneQuiet(repoDir); err != nil {
+			progress.Warning(fmt.Sprintf("Failed to prune remote tracking branches for %s: %v", name, err))
 		}
-		i++
 	}
 
 	// Release allocated port
@@ -233,8 +339,10 @@ func Down(opts DownOptions) (*DownResult, error) {
 			result.ReleasedPort = true
 		}
 	}
+	j.record(StepReleasePorts, "", "")
 
 	// Remove feature metadata (display name, etc.)
+	metadataStore, metaErr := features.NewMetadataStore(projectDir)
 	if metaErr != nil {
 		progress.Warning(fmt.Sprintf("Failed to initialize metadata store for cleanup: %v", metaErr))
 	} else {
@@ -242,9 +350,10 @@ func Down(opts DownOptions) (*DownResult, error) {
 			progress.Warning(fmt.Sprintf("Failed to remove feature metadata: %v", err))
 		}
 	}
+	j.record(StepRemoveMetadata, "", "")
 
 	// Remove trees directory if it exists
-	if treesDirExists {
+	if plan.TreesDirExists {
 		progress.UpdateWithProgress("Removing trees directory...", 90)
 		if err := os.RemoveAll(treesDir); err != nil {
 			progress.Error(fmt.Sprintf("Failed to remove trees directory: %s", treesDir))
@@ -254,6 +363,8 @@ func Down(opts DownOptions) (*DownResult, error) {
 		progress.Info("Trees directory already removed (orphaned worktree)")
 	}
 
+	j.finish()
+
 	progress.Complete(fmt.Sprintf("Feature '%s' cleaned up successfully!", featureName))
 
 	return result, nil
@@ -34,6 +34,13 @@ func BuildEnvVars(projectDir, treesDir, featureName, displayName string, allocat
 		for i, port := range allocatedPorts {
 			envVars[fmt.Sprintf("RAMP_PORT_%d", i+1)] = fmt.Sprintf("%d", port)
 		}
+
+		// Named ports (RAMP_PORT_WEB, RAMP_PORT_API, etc.) map onto the allocation in order
+		for i, name := range cfg.PortNames {
+			if i < len(allocatedPorts) {
+				envVars[config.GeneratePortEnvVarName(name)] = fmt.Sprintf("%d", allocatedPorts[i])
+			}
+		}
 	}
 
 	// Add repo path variables
@@ -0,0 +1,147 @@
+package operations
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"ramp/internal/config"
+	"ramp/internal/envfile"
+)
+
+// EnvFileIssue describes an env file with values ramp could not fill in.
+type EnvFileIssue struct {
+	Repo         string
+	Dest         string   // Destination relative to the worktree
+	Missing      []string // Required keys with no value
+	Placeholders []string // Unresolved ${NAME} placeholders
+	NotFound     bool     // File does not exist in the worktree
+}
+
+// Path returns the repo-relative name of the file, e.g. "app/.env".
+func (i EnvFileIssue) Path() string {
+	return filepath.ToSlash(filepath.Join(i.Repo, i.Dest))
+}
+
+// Describe summarizes the issue in one line.
+func (i EnvFileIssue) Describe() string {
+	var parts []string
+	if i.NotFound {
+		parts = append(parts, "file not found")
+	}
+	if len(i.Missing) > 0 {
+		parts = append(parts, "missing required "+strings.Join(i.Missing, ", "))
+	}
+	if len(i.Placeholders) > 0 {
+		parts = append(parts, "unresolved "+strings.Join(i.Placeholders, ", "))
+	}
+	return fmt.Sprintf("%s: %s", i.Path(), strings.Join(parts, "; "))
+}
+
+// newEnvFileIssue returns the issue for a rendered or existing file, or nil if nothing is unresolved
+func newEnvFileIssue(repoName, dest string, unresolved envfile.Unresolved) *EnvFileIssue {
+	if unresolved.Empty() {
+		return nil
+	}
+	return &EnvFileIssue{
+		Repo:         repoName,
+		Dest:         dest,
+		Missing:      unresolved.Missing,
+		Placeholders: unresolved.Placeholders,
+	}
+}
+
+// MissingEnvError is returned when required env file keys have no value.
+type MissingEnvError struct {
+	FeatureName string
+	Issues      []EnvFileIssue // Only issues with missing required keys
+}
+
+func (e *MissingEnvError) Error() string {
+	files := make([]string, 0, len(e.Issues))
+	for _, issue := range e.Issues {
+		files = append(files, fmt.Sprintf("%s (%s)", issue.Path(), strings.Join(issue.Missing, ", ")))
+	}
+	return fmt.Sprintf("required env values for '%s' have no value: %s", e.FeatureName, strings.Join(files, ", "))
+}
+
+// missingEnvError returns a *MissingEnvError for the issues with missing required keys, or nil
+func missingEnvError(featureName string, issues []EnvFileIssue) error {
+	var missing []EnvFileIssue
+	for _, issue := range issues {
+		if len(issue.Missing) > 0 {
+			missing = append(missing, issue)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	return &MissingEnvError{FeatureName: featureName, Issues: missing}
+}
+
+// EnvCheckOptions configures checking the env files of an existing feature.
+type EnvCheckOptions struct {
+	// Required
+	FeatureName string
+	ProjectDir  string
+	Config      *config.Config
+}
+
+// EnvCheckResult lists the env files of a feature with unresolved values.
+type EnvCheckResult struct {
+	FeatureName string
+	Checked     int // Number of configured env files
+	Issues      []EnvFileIssue
+}
+
+// Err returns a *MissingEnvError if any required key has no value.
+func (r *EnvCheckResult) Err() error {
+	return missingEnvError(r.FeatureName, r.Issues)
+}
+
+// CheckEnv inspects the env files in an existing feature's worktrees, as they
+// are on disk, for required keys without a value and unresolved placeholders.
+// Nothing is rendered, so hand-filled values count as provided.
+func CheckEnv(opts EnvCheckOptions) (*EnvCheckResult, error) {
+	treesDir := filepath.Join(opts.ProjectDir, "trees", opts.FeatureName)
+	if _, err := os.Stat(treesDir); os.IsNotExist(err) {
+		return nil, fmt.Errorf("feature '%s' not found", opts.FeatureName)
+	}
+
+	result := &EnvCheckResult{FeatureName: opts.FeatureName}
+
+	repos := opts.Config.GetRepos()
+	repoNames := make([]string, 0, len(repos))
+	for name := range repos {
+		repoNames = append(repoNames, name)
+	}
+	sort.Strings(repoNames)
+
+	for _, name := range repoNames {
+		worktreeDir := filepath.Join(treesDir, name)
+		if _, err := os.Stat(worktreeDir); os.IsNotExist(err) {
+			continue
+		}
+
+		for _, envFile := range repos[name].EnvFiles {
+			result.Checked++
+
+			content, err := os.ReadFile(filepath.Join(worktreeDir, envFile.Dest))
+			if err != nil {
+				if os.IsNotExist(err) {
+					result.Issues = append(result.Issues, EnvFileIssue{Repo: name, Dest: envFile.Dest, NotFound: true})
+					continue
+				}
+				return nil, fmt.Errorf("failed to read %s/%s: %w", name, envFile.Dest, err)
+			}
+
+			if issue := newEnvFileIssue(name, envFile.Dest, envfile.CheckEnvFile(envFile, string(content))); issue != nil {
+				result.Issues = append(result.Issues, *issue)
+			}
+		}
+	}
+
+	return result, nil
+}
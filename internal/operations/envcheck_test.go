@@ -0,0 +1,103 @@
+package operations
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"ramp/internal/config"
+)
+
+func TestUpFailsOnMissingRequiredEnv(t *testing.T) {
+	tp := NewTestProject(t)
+	repo := tp.InitRepo("app")
+
+	tp.Config.Repos[0].EnvFiles = []config.EnvFile{
+		{Source: ".env.example", Dest: ".env", Required: []string{"STRIPE_KEY"}},
+	}
+	os.WriteFile(filepath.Join(repo.SourceDir, ".env.example"), []byte("PORT=${RAMP_PORT}\nSTRIPE_KEY=${STRIPE_KEY}\nSENTRY_DSN=${SENTRY_DSN}\n"), 0644)
+
+	marker := filepath.Join(tp.Dir, "setup-ran")
+	setupPath := filepath.Join(tp.Dir, ".ramp", "scripts", "setup.sh")
+	os.MkdirAll(filepath.Dir(setupPath), 0755)
+	os.WriteFile(setupPath, []byte("#!/bin/bash\ntouch "+marker+"\n"), 0755)
+	tp.Config.Setup = "scripts/setup.sh"
+
+	progress := &MockProgressReporter{}
+	_, err := Up(UpOptions{
+		FeatureName: "needs-stripe",
+		ProjectDir:  tp.Dir,
+		Config:      tp.Config,
+		Progress:    progress,
+		SkipRefresh: true,
+	})
+
+	var missingErr *MissingEnvError
+	if !errors.As(err, &missingErr) {
+		t.Fatalf("Up() error = %v, want *MissingEnvError", err)
+	}
+	if len(missingErr.Issues) != 1 || missingErr.Issues[0].Path() != "app/.env" || !slices.Equal(missingErr.Issues[0].Missing, []string{"STRIPE_KEY"}) {
+		t.Errorf("Issues = %+v, want STRIPE_KEY missing from app/.env", missingErr.Issues)
+	}
+
+	if !slices.Contains(progress.Messages, "warning: app/.env: missing required STRIPE_KEY; unresolved SENTRY_DSN") {
+		t.Errorf("messages %v should report the unresolved values", progress.Messages)
+	}
+	if _, err := os.Stat(marker); !os.IsNotExist(err) {
+		t.Error("setup script should not run when required env values are missing")
+	}
+	if _, err := os.Stat(filepath.Join(tp.TreesDir, "needs-stripe", "app")); !os.IsNotExist(err) {
+		t.Error("worktree should be rolled back")
+	}
+}
+
+func TestCheckEnv(t *testing.T) {
+	tp := NewTestProject(t)
+	repo := tp.InitRepo("app")
+
+	tp.Config.Repos[0].EnvFiles = []config.EnvFile{
+		{Source: ".env.example", Dest: ".env", Required: []string{"STRIPE_KEY"}},
+		{Source: ".env.extra", Dest: ".env.extra"},
+	}
+	os.WriteFile(filepath.Join(repo.SourceDir, ".env.example"), []byte("STRIPE_KEY=sk_test\n"), 0644)
+	os.WriteFile(filepath.Join(repo.SourceDir, ".env.extra"), []byte("A=1\n"), 0644)
+
+	_, err := Up(UpOptions{
+		FeatureName: "check-test",
+		ProjectDir:  tp.Dir,
+		Config:      tp.Config,
+		Progress:    &MockProgressReporter{},
+		SkipRefresh: true,
+	})
+	if err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+
+	worktreeDir := filepath.Join(tp.TreesDir, "check-test", "app")
+	os.WriteFile(filepath.Join(worktreeDir, ".env"), []byte("STRIPE_KEY=${STRIPE_KEY}\n"), 0644)
+	os.Remove(filepath.Join(worktreeDir, ".env.extra"))
+
+	result, err := CheckEnv(EnvCheckOptions{FeatureName: "check-test", ProjectDir: tp.Dir, Config: tp.Config})
+	if err != nil {
+		t.Fatalf("CheckEnv() error = %v", err)
+	}
+
+	if result.Checked != 2 || len(result.Issues) != 2 {
+		t.Fatalf("CheckEnv() = %+v, want 2 issues in 2 files", result)
+	}
+	if got := result.Issues[0].Describe(); got != "app/.env: missing required STRIPE_KEY" {
+		t.Errorf("Issues[0] = %q", got)
+	}
+	if !result.Issues[1].NotFound {
+		t.Errorf("Issues[1] = %+v, want NotFound", result.Issues[1])
+	}
+	if result.Err() == nil {
+		t.Error("Err() should report the missing required key")
+	}
+
+	if _, err := CheckEnv(EnvCheckOptions{FeatureName: "missing", ProjectDir: tp.Dir, Config: tp.Config}); err == nil {
+		t.Error("CheckEnv() should fail for a missing feature")
+	}
+}
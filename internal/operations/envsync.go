@@ -0,0 +1,313 @@
+package operations
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"ramp/internal/config"
+	"ramp/internal/envfile"
+	"ramp/internal/features"
+	"ramp/internal/git"
+	"ramp/internal/ports"
+	"ramp/internal/ui"
+)
+
+// EnvConflictPolicy controls how env files edited since they were generated are handled.
+type EnvConflictPolicy string
+
+const (
+	EnvConflictFail  EnvConflictPolicy = ""      // Refuse to overwrite edited files
+	EnvConflictKeep  EnvConflictPolicy = "keep"  // Leave edited files untouched
+	EnvConflictMerge EnvConflictPolicy = "merge" // Three-way merge local edits into the new render
+	EnvConflictForce EnvConflictPolicy = "force" // Overwrite local edits
+)
+
+// EnvDriftError is returned when env files were edited since they were generated
+// and no conflict policy was chosen.
+type EnvDriftError struct {
+	FeatureName string
+	Files       []string // repo/dest of each edited file
+}
+
+func (e *EnvDriftError) Error() string {
+	return fmt.Sprintf("env files for '%s' were edited since they were generated: %s (keep, merge or force the changes)", e.FeatureName, strings.Join(e.Files, ", "))
+}
+
+// EnvSyncOptions configures re-rendering env files for an existing feature.
+type EnvSyncOptions struct {
+	// Required
+	FeatureName string
+	ProjectDir  string
+	Config      *config.Config
+	Progress    ProgressReporter
+
+	// Optional
+	ForceRefresh bool              // Bypass the cache for script sources
+	Conflict     EnvConflictPolicy // How to handle env files edited since generation
+}
+
+// EnvFileChange describes an env file whose generated content differs from the worktree.
+type EnvFileChange struct {
+	Repo        string
+	Dest        string // Destination relative to the worktree
+	WorktreeDir string
+	OldContent  string
+	NewContent  string // Content to write (the render, or the merge result)
+	Created     bool   // File does not exist in the worktree yet
+	Drifted     bool   // File was edited since ramp last generated it
+
+	record features.GeneratedEnvFile // Stored once the change is applied
+}
+
+// Path returns the absolute path of the env file in the worktree.
+func (c EnvFileChange) Path() string {
+	return filepath.Join(c.WorktreeDir, c.Dest)
+}
+
+// Diff returns a unified diff from the current file to the regenerated one,
+// with secret values masked.
+func (c EnvFileChange) Diff() string {
+	return ui.MaskSecrets(envfile.UnifiedDiff(filepath.ToSlash(filepath.Join(c.Repo, c.Dest)), c.OldContent, c.NewContent))
+}
+
+// EnvSyncResult contains the planned env file changes for a feature.
+type EnvSyncResult struct {
+	FeatureName string
+	Changes     []EnvFileChange
+	Unchanged   int      // Number of env files already up to date
+	Kept        []string // repo/dest of edited files left untouched
+
+	projectDir string
+	records    map[string]features.GeneratedEnvFile // Records to store for unchanged files
+}
+
+// PlanEnvSync renders every configured env file for an existing feature, using the
+// feature's stored ports and display name, and compares the result with the files
+// in its worktrees. Nothing is written; pass the result to ApplyEnvSync.
+//
+// Files edited since ramp last generated them are handled according to
+// opts.Conflict. Without a policy, an *EnvDriftError is returned if any edited
+// file would change.
+func PlanEnvSync(opts EnvSyncOptions) (*EnvSyncResult, error) {
+	projectDir := opts.ProjectDir
+	cfg := opts.Config
+	progress := opts.Progress
+	featureName := opts.FeatureName
+
+	treesDir := filepath.Join(projectDir, "trees", featureName)
+	if _, err := os.Stat(treesDir); os.IsNotExist(err) {
+		return nil, fmt.Errorf("feature '%s' not found", featureName)
+	}
+
+	result := &EnvSyncResult{
+		FeatureName: featureName,
+		projectDir:  projectDir,
+		records:     make(map[string]features.GeneratedEnvFile),
+	}
+
+	repos := cfg.GetRepos()
+	if !HasEnvFiles(repos) {
+		return result, nil
+	}
+
+	// Reuse the ports allocated when the feature was created
+	var allocatedPorts []int
+	if cfg.HasPortConfig() {
+		portAllocations, err := ports.NewPortAllocations(projectDir, cfg.GetBasePort(), cfg.GetMaxPorts())
+		if err != nil {
+			return nil, fmt.Errorf("failed to load port allocations: %w", err)
+		}
+		allocatedPorts, _ = portAllocations.GetPorts(featureName)
+	}
+
+	metadataStore, err := features.NewMetadataStore(projectDir)
+	if err != nil {
+		return nil, err
+	}
+	generated := metadataStore.GetEnvFiles(featureName)
+
+	envVars := BuildEnvVars(projectDir, treesDir, featureName, metadataStore.GetDisplayName(featureName), allocatedPorts, cfg, repos)
+	secrets := envfile.NewSecretResolver(projectDir, cfg.Secrets)
+
+	repoNames := make([]string, 0, len(repos))
+	for name := range repos {
+		repoNames = append(repoNames, name)
+	}
+	sort.Strings(repoNames)
+
+	progress.Start(fmt.Sprintf("Rendering env files for %s...", featureName))
+
+	var drifted, conflicts []string
+
+	for _, name := range repoNames {
+		repo := repos[name]
+		if len(repo.EnvFiles) == 0 {
+			continue
+		}
+
+		worktreeDir := filepath.Join(treesDir, name)
+		if _, err := os.Stat(worktreeDir); os.IsNotExist(err) {
+			progress.Warning(fmt.Sprintf("Worktree for %s not found, skipping", name))
+			continue
+		}
+
+		rendered, err := envfile.RenderEnvFiles(name, repo.EnvFiles, repo.GetRepoPath(projectDir), envVars, opts.ForceRefresh, projectDir, secrets)
+		if err != nil {
+			progress.Error(fmt.Sprintf("Failed to render env files for %s", name))
+			return nil, fmt.Errorf("failed to render env files for %s: %w", name, err)
+		}
+
+		for _, file := range rendered {
+			reportCacheEvents(name, repo.GetRepoPath(projectDir), file, progress)
+			if issue := newEnvFileIssue(name, file.Dest, file.Unresolved); issue != nil {
+				progress.Warning(issue.Describe())
+			}
+			change := EnvFileChange{
+				Repo:        name,
+				Dest:        file.Dest,
+				WorktreeDir: worktreeDir,
+				NewContent:  file.Content,
+				record:      features.NewGeneratedEnvFile(file.Content, file.Redacted),
+			}
+			key := features.EnvFileKey(name, file.Dest)
+			record, hasRecord := generated[key]
+
+			existing, err := os.ReadFile(change.Path())
+			if err != nil {
+				if !os.IsNotExist(err) {
+					return nil, fmt.Errorf("failed to read %s: %w", change.Path(), err)
+				}
+				change.Created = true
+			} else if string(existing) == file.Content {
+				result.Unchanged++
+				if !hasRecord || record != change.record {
+					result.records[key] = change.record
+				}
+				continue
+			}
+			change.OldContent = string(existing)
+
+			// Files without a record predate drift tracking and are treated as generated
+			change.Drifted = !change.Created && hasRecord && features.Checksum(change.OldContent) != record.Checksum
+			if change.Drifted {
+				if record.Content == file.Redacted {
+					// Only the local copy changed; there is nothing new to bring in
+					result.Unchanged++
+					continue
+				}
+
+				switch opts.Conflict {
+				case EnvConflictKeep:
+					result.Kept = append(result.Kept, key)
+					continue
+				case EnvConflictMerge:
+					merged, conflicted, err := git.MergeFile(change.OldContent, record.Content, file.Content, "local", "generated")
+					if err != nil {
+						return nil, fmt.Errorf("failed to merge %s: %w", key, err)
+					}
+					if conflicted {
+						conflicts = append(conflicts, key)
+						continue
+					}
+					change.NewContent = merged
+					if merged == change.OldContent {
+						// Local edits already contain the new render; only the base moves
+						result.Unchanged++
+						result.records[key] = change.record
+						continue
+					}
+				case EnvConflictForce:
+				default:
+					drifted = append(drifted, key)
+				}
+			}
+
+			result.Changes = append(result.Changes, change)
+		}
+	}
+
+	if len(conflicts) > 0 {
+		progress.Error(fmt.Sprintf("Could not merge local edits for %s", featureName))
+		return nil, fmt.Errorf("local edits conflict with regenerated env files for '%s': %s (keep or force the changes instead)", featureName, strings.Join(conflicts, ", "))
+	}
+	if len(drifted) > 0 {
+		progress.Error(fmt.Sprintf("Env files for %s were edited since they were generated", featureName))
+		return nil, &EnvDriftError{FeatureName: featureName, Files: drifted}
+	}
+
+	progress.Success(fmt.Sprintf("Rendered env files for %s (%d changed, %d unchanged)", featureName, len(result.Changes), result.Unchanged))
+	for _, key := range result.Kept {
+		progress.Info(fmt.Sprintf("Keeping local edits to %s", key))
+	}
+
+	return result, nil
+}
+
+// ApplyEnvSync writes the changes planned by PlanEnvSync and records the
+// regenerated content so later edits can be detected.
+func ApplyEnvSync(result *EnvSyncResult, progress ProgressReporter) error {
+	records := make(map[string]features.GeneratedEnvFile, len(result.records)+len(result.Changes))
+	for key, record := range result.records {
+		records[key] = record
+	}
+
+	var writeErr error
+	for _, change := range result.Changes {
+		file := envfile.RenderedFile{Dest: change.Dest, Content: change.NewContent}
+		if err := envfile.WriteEnvFile(change.WorktreeDir, file); err != nil {
+			progress.Error(fmt.Sprintf("Failed to update %s/%s", change.Repo, change.Dest))
+			writeErr = err
+			break
+		}
+		records[features.EnvFileKey(change.Repo, change.Dest)] = change.record
+		progress.Success(fmt.Sprintf("Updated %s/%s", change.Repo, change.Dest))
+	}
+
+	// Record whatever was written, even if a later file failed
+	if err := recordEnvFiles(result.projectDir, result.FeatureName, records); err != nil {
+		progress.Warning(fmt.Sprintf("Failed to record generated env files: %v", err))
+	}
+
+	return writeErr
+}
+
+// recordEnvFiles stores generated env file records in feature metadata
+func recordEnvFiles(projectDir, featureName string, records map[string]features.GeneratedEnvFile) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	metadataStore, err := features.NewMetadataStore(projectDir)
+	if err != nil {
+		return err
+	}
+
+	return metadataStore.SetEnvFiles(featureName, records)
+}
+
+// DetectEnvFileDrift returns the generated env files (as repo/dest) of a feature
+// whose content was edited since ramp last generated them. Missing files are ignored.
+func DetectEnvFileDrift(projectDir, featureName string) ([]string, error) {
+	metadataStore, err := features.NewMetadataStore(projectDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var drifted []string
+	treesDir := filepath.Join(projectDir, "trees", featureName)
+	for key, record := range metadataStore.GetEnvFiles(featureName) {
+		content, err := os.ReadFile(filepath.Join(treesDir, filepath.FromSlash(key)))
+		if err != nil {
+			continue
+		}
+		if features.Checksum(string(content)) != record.Checksum {
+			drifted = append(drifted, key)
+		}
+	}
+	sort.Strings(drifted)
+
+	return drifted, nil
+}
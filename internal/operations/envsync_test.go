@@ -0,0 +1,369 @@
+package operations
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"ramp/internal/config"
+	"ramp/internal/features"
+)
+
+// setupEnvSyncProject creates a project with one repo whose .env is generated from .env.example
+func setupEnvSyncProject(t *testing.T) (*TestProject, *TestRepo) {
+	t.Helper()
+
+	tp := NewTestProject(t)
+	repo := tp.InitRepo("app")
+
+	tp.Config.Repos[0].EnvFiles = []config.EnvFile{
+		{
+			Source:  ".env.example",
+			Dest:    ".env",
+			Replace: map[string]string{"PORT": "${RAMP_PORT}"},
+		},
+	}
+
+	if err := os.WriteFile(filepath.Join(repo.SourceDir, ".env.example"), []byte("PORT=1\nDEBUG=false\n"), 0644); err != nil {
+		t.Fatalf("failed to write .env.example: %v", err)
+	}
+
+	_, err := Up(UpOptions{
+		FeatureName: "sync-test",
+		ProjectDir:  tp.Dir,
+		Config:      tp.Config,
+		Progress:    &MockProgressReporter{},
+		SkipRefresh: true,
+	})
+	if err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+
+	return tp, repo
+}
+
+func TestPlanEnvSyncUpToDate(t *testing.T) {
+	tp, _ := setupEnvSyncProject(t)
+
+	result, err := PlanEnvSync(EnvSyncOptions{
+		FeatureName: "sync-test",
+		ProjectDir:  tp.Dir,
+		Config:      tp.Config,
+		Progress:    &MockProgressReporter{},
+	})
+	if err != nil {
+		t.Fatalf("PlanEnvSync() error = %v", err)
+	}
+
+	if len(result.Changes) != 0 {
+		t.Errorf("expected no changes, got %d", len(result.Changes))
+	}
+	if result.Unchanged != 1 {
+		t.Errorf("Unchanged = %d, want 1", result.Unchanged)
+	}
+}
+
+func TestPlanAndApplyEnvSync(t *testing.T) {
+	tp, repo := setupEnvSyncProject(t)
+
+	// Edit the source file after the feature was created
+	if err := os.WriteFile(filepath.Join(repo.SourceDir, ".env.example"), []byte("PORT=1\nDEBUG=false\nLOG_LEVEL=info\n"), 0644); err != nil {
+		t.Fatalf("failed to update .env.example: %v", err)
+	}
+
+	opts := EnvSyncOptions{
+		FeatureName: "sync-test",
+		ProjectDir:  tp.Dir,
+		Config:      tp.Config,
+		Progress:    &MockProgressReporter{},
+	}
+
+	result, err := PlanEnvSync(opts)
+	if err != nil {
+		t.Fatalf("PlanEnvSync() error = %v", err)
+	}
+
+	if len(result.Changes) != 1 {
+		t.Fatalf("expected 1 change, got %d", len(result.Changes))
+	}
+
+	change := result.Changes[0]
+	if change.Repo != "app" || change.Dest != ".env" {
+		t.Errorf("change = %s/%s, want app/.env", change.Repo, change.Dest)
+	}
+	if !strings.Contains(change.Diff(), "+LOG_LEVEL=info") {
+		t.Errorf("diff should add LOG_LEVEL, got:\n%s", change.Diff())
+	}
+
+	// Planning must not write anything
+	envPath := filepath.Join(tp.TreesDir, "sync-test", "app", ".env")
+	content, _ := os.ReadFile(envPath)
+	if strings.Contains(string(content), "LOG_LEVEL") {
+		t.Error("PlanEnvSync() should not modify files")
+	}
+
+	if err := ApplyEnvSync(result, &MockProgressReporter{}); err != nil {
+		t.Fatalf("ApplyEnvSync() error = %v", err)
+	}
+
+	content, err = os.ReadFile(envPath)
+	if err != nil {
+		t.Fatalf("failed to read .env: %v", err)
+	}
+
+	// The stored port is reused
+	expected := "PORT=3000\nDEBUG=false\nLOG_LEVEL=info\n"
+	if string(content) != expected {
+		t.Errorf(".env content = %q, want %q", string(content), expected)
+	}
+}
+
+func TestPlanEnvSyncLocalEdits(t *testing.T) {
+	newOpts := func(tp *TestProject, conflict EnvConflictPolicy) EnvSyncOptions {
+		return EnvSyncOptions{
+			FeatureName: "sync-test",
+			ProjectDir:  tp.Dir,
+			Config:      tp.Config,
+			Progress:    &MockProgressReporter{},
+			Conflict:    conflict,
+		}
+	}
+
+	// setup edits the generated .env locally and adds a new line to the source
+	setup := func(t *testing.T) (*TestProject, string) {
+		tp, repo := setupEnvSyncProject(t)
+		envPath := filepath.Join(tp.TreesDir, "sync-test", "app", ".env")
+		os.WriteFile(envPath, []byte("PORT=4000\nDEBUG=false\n"), 0644)
+		os.WriteFile(filepath.Join(repo.SourceDir, ".env.example"), []byte("PORT=1\nDEBUG=false\nLOG_LEVEL=info\n"), 0644)
+		return tp, envPath
+	}
+
+	t.Run("drift is reported", func(t *testing.T) {
+		tp, _ := setup(t)
+
+		drifted, err := DetectEnvFileDrift(tp.Dir, "sync-test")
+		if err != nil {
+			t.Fatalf("DetectEnvFileDrift() error = %v", err)
+		}
+		if strings.Join(drifted, ",") != "app/.env" {
+			t.Errorf("DetectEnvFileDrift() = %v, want [app/.env]", drifted)
+		}
+
+		_, err = PlanEnvSync(newOpts(tp, EnvConflictFail))
+		driftErr, ok := err.(*EnvDriftError)
+		if !ok {
+			t.Fatalf("PlanEnvSync() error = %v, want *EnvDriftError", err)
+		}
+		if strings.Join(driftErr.Files, ",") != "app/.env" {
+			t.Errorf("EnvDriftError.Files = %v", driftErr.Files)
+		}
+	})
+
+	t.Run("local-only edits are left alone", func(t *testing.T) {
+		tp, _ := setupEnvSyncProject(t)
+		envPath := filepath.Join(tp.TreesDir, "sync-test", "app", ".env")
+		os.WriteFile(envPath, []byte("PORT=3000\nDEBUG=true\n"), 0644)
+
+		result, err := PlanEnvSync(newOpts(tp, EnvConflictFail))
+		if err != nil {
+			t.Fatalf("PlanEnvSync() error = %v", err)
+		}
+		if len(result.Changes) != 0 || result.Unchanged != 1 {
+			t.Errorf("Changes = %d, Unchanged = %d, want 0 and 1", len(result.Changes), result.Unchanged)
+		}
+	})
+
+	t.Run("keep", func(t *testing.T) {
+		tp, envPath := setup(t)
+
+		result, err := PlanEnvSync(newOpts(tp, EnvConflictKeep))
+		if err != nil {
+			t.Fatalf("PlanEnvSync() error = %v", err)
+		}
+		if len(result.Changes) != 0 || strings.Join(result.Kept, ",") != "app/.env" {
+			t.Errorf("Changes = %d, Kept = %v", len(result.Changes), result.Kept)
+		}
+		if err := ApplyEnvSync(result, &MockProgressReporter{}); err != nil {
+			t.Fatalf("ApplyEnvSync() error = %v", err)
+		}
+
+		content, _ := os.ReadFile(envPath)
+		if string(content) != "PORT=4000\nDEBUG=false\n" {
+			t.Errorf(".env content = %q, want local edits kept", string(content))
+		}
+	})
+
+	t.Run("merge", func(t *testing.T) {
+		tp, envPath := setup(t)
+
+		result, err := PlanEnvSync(newOpts(tp, EnvConflictMerge))
+		if err != nil {
+			t.Fatalf("PlanEnvSync() error = %v", err)
+		}
+		if err := ApplyEnvSync(result, &MockProgressReporter{}); err != nil {
+			t.Fatalf("ApplyEnvSync() error = %v", err)
+		}
+
+		content, _ := os.ReadFile(envPath)
+		if string(content) != "PORT=4000\nDEBUG=false\nLOG_LEVEL=info\n" {
+			t.Errorf(".env content = %q", string(content))
+		}
+
+		// The merged file still differs from the render, so it stays flagged
+		drifted, _ := DetectEnvFileDrift(tp.Dir, "sync-test")
+		if len(drifted) != 1 {
+			t.Errorf("DetectEnvFileDrift() = %v, want merged file flagged", drifted)
+		}
+	})
+
+	t.Run("merge conflict", func(t *testing.T) {
+		tp, repo := setupEnvSyncProject(t)
+		envPath := filepath.Join(tp.TreesDir, "sync-test", "app", ".env")
+		os.WriteFile(envPath, []byte("PORT=3000\nDEBUG=true\n"), 0644)
+		os.WriteFile(filepath.Join(repo.SourceDir, ".env.example"), []byte("PORT=1\nDEBUG=verbose\n"), 0644)
+
+		if _, err := PlanEnvSync(newOpts(tp, EnvConflictMerge)); err == nil {
+			t.Error("PlanEnvSync() expected error for conflicting edits")
+		}
+	})
+
+	t.Run("force", func(t *testing.T) {
+		tp, envPath := setup(t)
+
+		result, err := PlanEnvSync(newOpts(tp, EnvConflictForce))
+		if err != nil {
+			t.Fatalf("PlanEnvSync() error = %v", err)
+		}
+		if len(result.Changes) != 1 || !result.Changes[0].Drifted {
+			t.Fatalf("expected one drifted change, got %+v", result.Changes)
+		}
+		if err := ApplyEnvSync(result, &MockProgressReporter{}); err != nil {
+			t.Fatalf("ApplyEnvSync() error = %v", err)
+		}
+
+		content, _ := os.ReadFile(envPath)
+		if string(content) != "PORT=3000\nDEBUG=false\nLOG_LEVEL=info\n" {
+			t.Errorf(".env content = %q", string(content))
+		}
+
+		drifted, _ := DetectEnvFileDrift(tp.Dir, "sync-test")
+		if len(drifted) != 0 {
+			t.Errorf("DetectEnvFileDrift() = %v, want none after force", drifted)
+		}
+	})
+}
+
+func TestUpRecordsGeneratedEnvFiles(t *testing.T) {
+	tp, _ := setupEnvSyncProject(t)
+
+	store, err := features.NewMetadataStore(tp.Dir)
+	if err != nil {
+		t.Fatalf("NewMetadataStore() error = %v", err)
+	}
+
+	record, ok := store.GetEnvFiles("sync-test")["app/.env"]
+	if !ok {
+		t.Fatal("expected a record for app/.env")
+	}
+	if record.Content != "PORT=3000\nDEBUG=false\n" || record.Checksum != features.Checksum(record.Content) {
+		t.Errorf("unexpected record %+v", record)
+	}
+
+	// Setting a display name keeps the env file records
+	if err := store.SetDisplayName("sync-test", "Sync Test"); err != nil {
+		t.Fatalf("SetDisplayName() error = %v", err)
+	}
+	if err := store.SetDisplayName("sync-test", ""); err != nil {
+		t.Fatalf("SetDisplayName() error = %v", err)
+	}
+	if len(store.GetEnvFiles("sync-test")) != 1 {
+		t.Error("clearing the display name should keep env file records")
+	}
+}
+
+func TestPlanEnvSyncFeatureNotFound(t *testing.T) {
+	tp := NewTestProject(t)
+	tp.InitRepo("app")
+
+	_, err := PlanEnvSync(EnvSyncOptions{
+		FeatureName: "missing",
+		ProjectDir:  tp.Dir,
+		Config:      tp.Config,
+		Progress:    &MockProgressReporter{},
+	})
+	if err == nil {
+		t.Fatal("PlanEnvSync() expected error for missing feature")
+	}
+}
+
+func TestListFeatures(t *testing.T) {
+	tp := NewTestProject(t)
+
+	features, err := ListFeatures(tp.Dir)
+	if err != nil {
+		t.Fatalf("ListFeatures() error = %v", err)
+	}
+	if len(features) != 0 {
+		t.Errorf("expected no features, got %v", features)
+	}
+
+	for _, name := range []string{"zeta", "alpha"} {
+		os.MkdirAll(filepath.Join(tp.TreesDir, name), 0755)
+	}
+	os.WriteFile(filepath.Join(tp.TreesDir, "stray-file"), []byte(""), 0644)
+
+	features, err = ListFeatures(tp.Dir)
+	if err != nil {
+		t.Fatalf("ListFeatures() error = %v", err)
+	}
+	if strings.Join(features, ",") != "alpha,zeta" {
+		t.Errorf("ListFeatures() = %v, want [alpha zeta]", features)
+	}
+}
+
+func TestEnvSyncReportsScriptCache(t *testing.T) {
+	tp := NewTestProject(t)
+	repo := tp.InitRepo("app")
+
+	tp.Config.Repos[0].EnvFiles = []config.EnvFile{{Source: "env.sh", Dest: ".env", Cache: "1h"}}
+	script := "#!/bin/bash\necho \"PORT=$RAMP_PORT\"\n"
+	if err := os.WriteFile(filepath.Join(repo.SourceDir, "env.sh"), []byte(script), 0755); err != nil {
+		t.Fatalf("failed to write env.sh: %v", err)
+	}
+
+	upProgress := &MockProgressReporter{}
+	_, err := Up(UpOptions{
+		FeatureName: "cache-test",
+		ProjectDir:  tp.Dir,
+		Config:      tp.Config,
+		Progress:    upProgress,
+		SkipRefresh: true,
+	})
+	if err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+
+	syncProgress := &MockProgressReporter{}
+	if _, err := PlanEnvSync(EnvSyncOptions{
+		FeatureName: "cache-test",
+		ProjectDir:  tp.Dir,
+		Config:      tp.Config,
+		Progress:    syncProgress,
+	}); err != nil {
+		t.Fatalf("PlanEnvSync() error = %v", err)
+	}
+
+	for _, check := range []struct {
+		progress *MockProgressReporter
+		want     string
+	}{
+		{upProgress, "info: app: env script env.sh cache miss"},
+		{syncProgress, "info: app: env script env.sh cache hit"},
+	} {
+		if !slices.Contains(check.progress.Messages, check.want) {
+			t.Errorf("messages %v should contain %q", check.progress.Messages, check.want)
+		}
+	}
+}
@@ -0,0 +1,226 @@
+package operations
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"ramp/internal/config"
+	"ramp/internal/features"
+	"ramp/internal/git"
+)
+
+// ListFeatures returns the names of all features in the project's trees directory, sorted.
+// Returns an empty list if the trees directory does not exist.
+func ListFeatures(projectDir string) ([]string, error) {
+	treesDir := filepath.Join(projectDir, "trees")
+
+	entries, err := os.ReadDir(treesDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []string{}, nil
+		}
+		return nil, fmt.Errorf("failed to read trees directory: %w", err)
+	}
+
+	featureNames := []string{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			featureNames = append(featureNames, entry.Name())
+		}
+	}
+	sort.Strings(featureNames)
+
+	return featureNames, nil
+}
+
+// FeatureDetails is everything ramp knows about a feature, as shown by
+// ramp feature info.
+type FeatureDetails struct {
+	Name        string                 `json:"name"`
+	DisplayName string                 `json:"displayName,omitempty"`
+	TreesDir    string                 `json:"treesDir"`
+	Created     time.Time              `json:"created"`
+	Creation    *features.CreationInfo `json:"creation,omitempty"` // Nil for features created before it was recorded
+	Branches    map[string]string      `json:"branches"`           // Repo to the branch its worktree has checked out
+	Ports       []int                  `json:"ports,omitempty"`
+	Tags        []string               `json:"tags,omitempty"`
+	Links       map[string]string      `json:"links,omitempty"`
+	Notes       []features.Note        `json:"notes,omitempty"`
+}
+
+// FeatureCreated returns when a feature was created: the time ramp up
+// recorded, or for features created before that was recorded, the
+// modification time of its trees directory.
+func FeatureCreated(projectDir, featureName string, meta features.FeatureMetadata) time.Time {
+	if meta.Created != nil {
+		return meta.Created.At
+	}
+	if info, err := os.Stat(filepath.Join(projectDir, "trees", featureName)); err == nil {
+		return info.ModTime()
+	}
+	return time.Time{}
+}
+
+// featureBranchPrefixes returns the prefixes a feature's branches may have:
+// the one ramp up recorded for it, then the configured default.
+func featureBranchPrefixes(projectDir string, cfg *config.Config, featureName string) []string {
+	var prefixes []string
+	if store, err := features.NewMetadataStore(projectDir); err == nil {
+		if created := store.Get(featureName).Created; created != nil {
+			prefixes = append(prefixes, created.Prefix)
+		}
+	}
+	if configured := cfg.GetBranchPrefix(); !slices.Contains(prefixes, configured) {
+		prefixes = append(prefixes, configured)
+	}
+	return prefixes
+}
+
+// featureBranchPrefix returns the prefix of branch if it is exactly one of
+// prefixes followed by the feature's name, i.e. the branch ramp up created.
+func featureBranchPrefix(branch, featureName string, prefixes []string) (string, bool) {
+	for _, prefix := range prefixes {
+		if branch == prefix+featureName {
+			return prefix, true
+		}
+	}
+	return "", false
+}
+
+// GetFeatureDetails collects a feature's metadata, branches and ports.
+func GetFeatureDetails(projectDir string, cfg *config.Config, featureName string) (*FeatureDetails, error) {
+	treesDir := filepath.Join(projectDir, "trees", featureName)
+	if _, err := os.Stat(treesDir); os.IsNotExist(err) {
+		return nil, fmt.Errorf("feature '%s' not found", featureName)
+	}
+
+	metadataStore, err := features.NewMetadataStore(projectDir)
+	if err != nil {
+		return nil, err
+	}
+	meta := metadataStore.Get(featureName)
+
+	details := &FeatureDetails{
+		Name:        featureName,
+		DisplayName: meta.DisplayName,
+		TreesDir:    treesDir,
+		Created:     FeatureCreated(projectDir, featureName, meta),
+		Creation:    meta.Created,
+		Branches:    make(map[string]string),
+		Ports:       featurePorts(projectDir, cfg, featureName),
+		Tags:        meta.Tags,
+		Links:       meta.Links,
+		Notes:       meta.Notes,
+	}
+	for _, name := range sortedRepoNames(cfg.GetRepos()) {
+		if branch, err := git.GetWorktreeBranch(filepath.Join(treesDir, name)); err == nil {
+			details.Branches[name] = branch
+		}
+	}
+
+	return details, nil
+}
+
+// AnnotateOptions configures changing a feature's notes, tags and links.
+type AnnotateOptions struct {
+	// Required
+	FeatureName string
+	ProjectDir  string
+
+	// Optional
+	Note       string   // Note to add
+	ClearNotes bool     // Remove existing notes first
+	AddTags    []string // Normalized with features.NormalizeTag
+	RemoveTags []string
+	Links      map[string]string // Label to URL; an empty URL removes the link
+}
+
+// AnnotateFeature adds notes, tags and links to a feature, or removes them.
+// Everything is validated before anything is saved.
+// This is the core business logic used by both CLI and UI.
+func AnnotateFeature(opts AnnotateOptions) error {
+	if _, err := os.Stat(filepath.Join(opts.ProjectDir, "trees", opts.FeatureName)); os.IsNotExist(err) {
+		return fmt.Errorf("feature '%s' not found", opts.FeatureName)
+	}
+
+	normalize := func(tags []string) ([]string, error) {
+		normalized := make([]string, len(tags))
+		for i, tag := range tags {
+			var err error
+			if normalized[i], err = features.NormalizeTag(tag); err != nil {
+				return nil, err
+			}
+		}
+		return normalized, nil
+	}
+	addTags, err := normalize(opts.AddTags)
+	if err != nil {
+		return err
+	}
+	removeTags, err := normalize(opts.RemoveTags)
+	if err != nil {
+		return err
+	}
+
+	for label, link := range opts.Links {
+		if label == "" || strings.ContainsAny(label, " \t=") {
+			return fmt.Errorf("invalid link label '%s'", label)
+		}
+		if link == "" {
+			continue
+		}
+		if u, err := url.Parse(link); err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("invalid URL '%s' for link '%s'", link, label)
+		}
+	}
+
+	metadataStore, err := features.NewMetadataStore(opts.ProjectDir)
+	if err != nil {
+		return err
+	}
+	if opts.ClearNotes {
+		if err := metadataStore.ClearNotes(opts.FeatureName); err != nil {
+			return err
+		}
+	}
+	if note := strings.TrimSpace(opts.Note); note != "" {
+		if err := metadataStore.AddNote(opts.FeatureName, note); err != nil {
+			return err
+		}
+	}
+	if len(addTags) > 0 || len(removeTags) > 0 {
+		if err := metadataStore.UpdateTags(opts.FeatureName, addTags, removeTags); err != nil {
+			return err
+		}
+	}
+	for _, label := range sortedKeys(opts.Links) {
+		if err := metadataStore.SetLink(opts.FeatureName, label, opts.Links[label]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GroupFeaturesByTag groups features by their tags, each group in the order
+// given. A feature with several tags is in each of their groups; untagged
+// features are grouped under "".
+func GroupFeaturesByTag(featureNames []string, metadata map[string]features.FeatureMetadata) map[string][]string {
+	groups := make(map[string][]string)
+	for _, name := range featureNames {
+		tags := metadata[name].Tags
+		if len(tags) == 0 {
+			groups[""] = append(groups[""], name)
+		}
+		for _, tag := range tags {
+			groups[tag] = append(groups[tag], name)
+		}
+	}
+	return groups
+}
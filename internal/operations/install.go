@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 
 	"ramp/internal/config"
 	"ramp/internal/git"
@@ -28,6 +30,10 @@ type InstallOptions struct {
 	Config     *config.Config
 	Progress   ProgressReporter
 	Shallow    bool
+
+	// Optional
+	Filter  string // Partial clone filter for every repo, overriding each repo's filter (e.g. blob:none)
+	Workers int    // Max concurrent clones (0 = config max_parallel)
 }
 
 // InstallResult contains the results of installation.
@@ -36,9 +42,23 @@ type InstallResult struct {
 	SkippedRepos []string
 }
 
-// Install clones all configured repositories.
+// InstallPlan is what Install will do.
+type InstallPlan struct {
+	Plan
+	Clone       []string `json:"clone"`                 // Repos that will be cloned
+	Skip        []string `json:"skip"`                  // Repos that are already cloned
+	AddUpstream []string `json:"addUpstream,omitempty"` // Existing fork clones that get an upstream remote
+}
+
+// Install clones all configured repositories concurrently.
 // This is the core business logic used by both CLI and UI.
 func Install(opts InstallOptions) (*InstallResult, error) {
+	return ApplyInstall(opts, PlanInstall(opts))
+}
+
+// PlanInstall works out which repos Install would clone and which remotes it
+// would add, without changing anything.
+func PlanInstall(opts InstallOptions) *InstallPlan {
 	projectDir := opts.ProjectDir
 	cfg := opts.Config
 	progress := opts.Progress
@@ -48,35 +68,151 @@ func Install(opts InstallOptions) (*InstallResult, error) {
 	repos := cfg.GetRepos()
 	progress.Info(fmt.Sprintf("Found %d repositories to clone", len(repos)))
 
+	plan := &InstallPlan{Plan: newPlan("install", cfg.Name), Skip: []string{}}
+	plan.Clone = plan.addCloneSteps(opts)
+
+	for _, name := range sortedRepoNames(repos) {
+		repoDir := repos[name].GetRepoPath(projectDir)
+		if !git.IsGitRepo(repoDir) {
+			continue
+		}
+
+		progress.Info(fmt.Sprintf("%s: already exists at %s, skipping", name, repoDir))
+		plan.Skip = append(plan.Skip, name)
+		if repos[name].Upstream != "" && !git.HasRemote(repoDir, git.UpstreamRemote) {
+			plan.AddUpstream = append(plan.AddUpstream, name)
+			plan.add(StepAddRemote, name, fmt.Sprintf("add %s remote %s", git.UpstreamRemote, repos[name].Upstream))
+		}
+	}
+
+	return plan
+}
+
+// ApplyInstall clones the repos in a plan from PlanInstall and adds their
+// upstream remotes.
+func ApplyInstall(opts InstallOptions, plan *InstallPlan) (*InstallResult, error) {
+	projectDir := opts.ProjectDir
+	cfg := opts.Config
+	progress := opts.Progress
+	repos := cfg.GetRepos()
+
 	result := &InstallResult{
 		ClonedRepos:  []string{},
-		SkippedRepos: []string{},
+		SkippedRepos: plan.Skip,
 	}
 
-	for name, repo := range repos {
-		repoDir := repo.GetRepoPath(projectDir)
+	toClone, toAddUpstream := plan.Clone, plan.AddUpstream
+	for _, name := range toClone {
+		repoDir := repos[name].GetRepoPath(projectDir)
 
 		// Create parent directories if needed
 		if err := os.MkdirAll(filepath.Dir(repoDir), 0755); err != nil {
 			progress.Error(fmt.Sprintf("Failed to create directory %s", filepath.Dir(repoDir)))
 			return nil, fmt.Errorf("failed to create directory %s: %w", filepath.Dir(repoDir), err)
 		}
+	}
 
-		if git.IsGitRepo(repoDir) {
-			progress.Info(fmt.Sprintf("%s: already exists at %s, skipping", name, repoDir))
-			result.SkippedRepos = append(result.SkippedRepos, name)
-			continue
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = cfg.GetMaxParallel()
+	}
+	workerProgress := newLockedProgress(progress)
+	steps := &stepCounter{progress: workerProgress, from: 0, to: 100, total: len(toClone)}
+	cloneErrs := make([]error, len(toClone))
+	upstreamErrs := make(map[string]error) // Repos whose upstream remote was set up, and how that went
+	var upstreamMu sync.Mutex
+	addUpstream := func(name string) {
+		if repos[name].Upstream == "" {
+			return
 		}
+		err := AddUpstreamRemote(repos[name], repos[name].GetRepoPath(projectDir))
+		upstreamMu.Lock()
+		upstreamErrs[name] = err
+		upstreamMu.Unlock()
+	}
+
+	forEachParallel(len(toClone), workers, func(i int) {
+		name := toClone[i]
+		repo := repos[name]
+		repoDir := repo.GetRepoPath(projectDir)
+		cloneOpts := installCloneOptions(opts, repo)
 
 		gitURL := repo.GetGitURL()
-		progress.Info(fmt.Sprintf("%s: cloning from %s to %s", name, gitURL, repoDir))
-		if err := git.Clone(gitURL, repoDir, opts.Shallow); err != nil {
+		workerProgress.Info(fmt.Sprintf("%s: cloning from %s to %s%s", name, gitURL, repoDir, describeCloneOptions(cloneOpts)))
+		if err := git.CloneQuiet(gitURL, repoDir, cloneOpts); err != nil {
+			cloneErrs[i] = err
+			steps.step(fmt.Sprintf("Failed to clone %s", name))
+			return
+		}
+		addUpstream(name)
+		steps.step(fmt.Sprintf("Cloned %s", name))
+	})
+
+	// Existing clones of forks that predate their upstream setting get the upstream remote added
+	forEachParallel(len(toAddUpstream), workers, func(i int) {
+		addUpstream(toAddUpstream[i])
+	})
+
+	// Report every failure, return the first; repos that did clone are kept
+	var firstErr error
+	for i, name := range toClone {
+		if err := cloneErrs[i]; err != nil {
 			progress.Error(fmt.Sprintf("Failed to clone %s", name))
-			return nil, fmt.Errorf("failed to clone %s: %w", name, err)
+			if firstErr == nil {
+				firstErr = fmt.Errorf("failed to clone %s: %w", name, err)
+			}
+			continue
 		}
 		result.ClonedRepos = append(result.ClonedRepos, name)
 	}
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	// A missing upstream doesn't undo the clone; features fall back to origin
+	for _, name := range sortedRepoNames(repos) {
+		err, ok := upstreamErrs[name]
+		if !ok {
+			continue
+		}
+		if err != nil {
+			progress.Warning(fmt.Sprintf("%s: failed to add upstream remote: %v", name, err))
+		} else {
+			progress.Info(fmt.Sprintf("%s: upstream remote set to %s", name, repos[name].Upstream))
+		}
+	}
 
 	progress.Complete("Installation complete!")
 	return result, nil
 }
+
+// installCloneOptions returns how Install clones a repo; opts.Filter overrides the repo's own.
+func installCloneOptions(opts InstallOptions, repo *config.Repo) git.CloneOptions {
+	cloneOpts := git.CloneOptions{
+		Shallow:   opts.Shallow,
+		Filter:    repo.Filter,
+		Reference: repo.GetReferencePath(opts.ProjectDir),
+	}
+	if opts.Filter != "" {
+		cloneOpts.Filter = opts.Filter
+	}
+	return cloneOpts
+}
+
+// describeCloneOptions returns a suffix such as " (shallow, filter blob:none)" for progress messages
+func describeCloneOptions(opts git.CloneOptions) string {
+	var parts []string
+	if opts.Shallow {
+		parts = append(parts, "shallow")
+	}
+	if opts.Filter != "" {
+		parts = append(parts, "filter "+opts.Filter)
+	}
+	if opts.Reference != "" {
+		parts = append(parts, "reference "+opts.Reference)
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(parts, ", ") + ")"
+}
@@ -0,0 +1,220 @@
+package operations
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// JournalDir holds the journals of operations in progress, relative to the
+// project's .ramp directory.
+const JournalDir = "journal"
+
+// activeRuns holds the Run of every journal this process is applying, so a
+// long-lived process can tell its own interrupted operations from running ones.
+var activeRuns sync.Map
+
+// Journal records each completed step of an up, down or prune while it runs.
+// It is removed when the operation finishes or is rolled back, so a journal
+// that isn't Running belongs to an operation that was killed or failed
+// part-way, and can be resumed or rolled back from what it recorded. Each
+// feature has at most one journal.
+type Journal struct {
+	Operation string    `json:"operation"` // "up", "down" or "prune"
+	Feature   string    `json:"feature"`
+	PID       int       `json:"pid"` // Process applying the operation
+	Run       string    `json:"run"` // Identifies one application of the operation within that process
+	Started   time.Time `json:"started"`
+
+	// What resuming needs: the plan being applied and, for up, the options
+	// that aren't part of it
+	Up           *UpPlan   `json:"up,omitempty"`
+	Down         *DownPlan `json:"down,omitempty"`
+	DisplayName  string    `json:"displayName,omitempty"`
+	ForceRefresh bool      `json:"forceRefresh,omitempty"`
+	SkipRefresh  bool      `json:"skipRefresh,omitempty"`
+	Target       string    `json:"target,omitempty"`
+
+	Done []PlanStep `json:"done"` // Completed steps, in order
+
+	mu   sync.Mutex
+	path string
+}
+
+func journalPath(projectDir, featureName string) string {
+	return filepath.Join(projectDir, ".ramp", JournalDir, featureName+".json")
+}
+
+// beginJournal starts the journal of an operation on a feature, replacing any
+// journal the feature had.
+func beginJournal(projectDir, operation, featureName string, fill func(*Journal)) (*Journal, error) {
+	j := &Journal{
+		Operation: operation,
+		Feature:   featureName,
+		PID:       os.Getpid(),
+		Run:       uuid.New().String(),
+		Started:   time.Now(),
+		Done:      []PlanStep{},
+		path:      journalPath(projectDir, featureName),
+	}
+	fill(j)
+
+	if err := os.MkdirAll(filepath.Dir(j.path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create journal directory: %w", err)
+	}
+	if err := j.save(); err != nil {
+		return nil, err
+	}
+	activeRuns.Store(j.Run, true)
+	return j, nil
+}
+
+// LoadJournal returns the journal of a feature, or nil if it has none.
+func LoadJournal(projectDir, featureName string) (*Journal, error) {
+	path := journalPath(projectDir, featureName)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read journal: %w", err)
+	}
+
+	j := &Journal{path: path}
+	if err := json.Unmarshal(data, j); err != nil {
+		return nil, fmt.Errorf("failed to parse journal %s: %w", path, err)
+	}
+	return j, nil
+}
+
+// PendingJournals returns the journals of operations that were interrupted,
+// oldest first. Journals of operations still running are left out.
+func PendingJournals(projectDir string) ([]*Journal, error) {
+	entries, err := os.ReadDir(filepath.Join(projectDir, ".ramp", JournalDir))
+	if os.IsNotExist(err) {
+		return []*Journal{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read journal directory: %w", err)
+	}
+
+	pending := []*Journal{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || filepath.Ext(name) != ".json" {
+			continue
+		}
+		j, err := LoadJournal(projectDir, name[:len(name)-len(".json")])
+		if err != nil {
+			return nil, err
+		}
+		if j != nil && !j.Running() {
+			pending = append(pending, j)
+		}
+	}
+
+	sort.Slice(pending, func(i, k int) bool {
+		return pending[i].Started.Before(pending[k].Started)
+	})
+	return pending, nil
+}
+
+// Running reports whether the operation is still being applied: by another
+// process that is still alive, or by this process without having returned.
+func (j *Journal) Running() bool {
+	if j.PID == os.Getpid() {
+		_, active := activeRuns.Load(j.Run)
+		return active
+	}
+	process, err := os.FindProcess(j.PID)
+	if err != nil {
+		return false
+	}
+	return process.Signal(syscall.Signal(0)) == nil
+}
+
+// Completed reports whether a step was recorded, for a repo or project-wide.
+func (j *Journal) Completed(kind, repo string) bool {
+	if j == nil {
+		return false
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	for _, step := range j.Done {
+		if step.Kind == kind && step.Repo == repo {
+			return true
+		}
+	}
+	return false
+}
+
+// record adds a completed step. Steps of repos processed in parallel may be
+// recorded concurrently. A journal that can't be written doesn't fail the
+// operation; it only makes the step run again on resume.
+func (j *Journal) record(kind, repo, detail string) {
+	if j == nil {
+		return
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.Done = append(j.Done, PlanStep{Kind: kind, Repo: repo, Detail: detail})
+	_ = j.save()
+}
+
+// claim takes over a journal for the current process before resuming or
+// rolling it back.
+func (j *Journal) claim() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.PID = os.Getpid()
+	j.Run = uuid.New().String()
+	if err := j.save(); err != nil {
+		return err
+	}
+	activeRuns.Store(j.Run, true)
+	return nil
+}
+
+// release marks the journal as no longer being applied by this process. A
+// journal left behind by a failed operation is then pending, like one whose
+// process was killed.
+func (j *Journal) release() {
+	if j == nil {
+		return
+	}
+	activeRuns.Delete(j.Run)
+}
+
+// finish removes the journal once the operation completed or was undone.
+func (j *Journal) finish() {
+	if j == nil {
+		return
+	}
+	j.release()
+	_ = os.Remove(j.path)
+}
+
+// save writes the journal to a temporary file and renames it into place, so a
+// crash mid-write leaves the previous version. Callers hold j.mu.
+func (j *Journal) save() error {
+	data, err := json.MarshalIndent(j, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal journal: %w", err)
+	}
+
+	tmpPath := j.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write journal: %w", err)
+	}
+	if err := os.Rename(tmpPath, j.path); err != nil {
+		return fmt.Errorf("failed to write journal: %w", err)
+	}
+	return nil
+}
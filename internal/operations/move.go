@@ -0,0 +1,267 @@
+package operations
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"ramp/internal/config"
+	"ramp/internal/features"
+	"ramp/internal/git"
+	"ramp/internal/ports"
+)
+
+// MoveOptions configures renaming a feature.
+type MoveOptions struct {
+	// Required
+	FeatureName string
+	NewName     string
+	ProjectDir  string
+	Config      *config.Config
+	Progress    ProgressReporter
+
+	// Optional
+	RenameRemote bool // Also push the renamed branches to origin and delete the old ones there
+}
+
+// MoveRepoResult is how one repository's feature branch was renamed.
+type MoveRepoResult struct {
+	Repo          string
+	Branch        string // Branch after the move
+	OldBranch     string // Same as Branch when the branch name doesn't end in the feature name
+	RemoteRenamed bool
+}
+
+// MoveResult contains the outcome of renaming a feature.
+type MoveResult struct {
+	FeatureName string // The new name
+	OldName     string
+	TreesDir    string
+	Repos       []MoveRepoResult
+}
+
+// moveRepo is a worktree to move and the branch to rename along with it.
+type moveRepo struct {
+	name      string
+	repoDir   string
+	oldDir    string
+	newDir    string
+	oldBranch string
+	newBranch string
+	onRemote  bool // The old branch exists on origin
+}
+
+// MoveFeature renames a feature: its worktrees move from trees/<old> to
+// trees/<new> with `git worktree move`, the feature's branches (its branch
+// prefix followed by its name) are renamed in every repo, and its port
+// allocation and metadata move to the new name. Worktrees on any other branch
+// keep it, with a warning. Features whose worktrees have initialized
+// submodules can't be moved, since git refuses to move such worktrees. Env
+// files are re-rendered so values embedding the feature's name or path are
+// updated, merging any local edits.
+//
+// If a step fails, the steps already done are undone and the feature keeps
+// its old name. Renaming branches on origin happens last and is not undone;
+// a failure there is reported as a warning.
+// This is the core business logic used by both CLI and UI.
+func MoveFeature(opts MoveOptions) (*MoveResult, error) {
+	projectDir := opts.ProjectDir
+	progress := opts.Progress
+	oldName, newName := opts.FeatureName, opts.NewName
+
+	repos, err := planMove(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	oldTreesDir := filepath.Join(projectDir, "trees", oldName)
+	newTreesDir := filepath.Join(projectDir, "trees", newName)
+	progress.Start(fmt.Sprintf("Moving feature '%s' to '%s'", oldName, newName))
+
+	// Undo steps run in reverse order if a later step fails
+	var undo []func() error
+	fail := func(err error) (*MoveResult, error) {
+		progress.Warning("Move failed, restoring the feature")
+		for i := len(undo) - 1; i >= 0; i-- {
+			if undoErr := undo[i](); undoErr != nil {
+				progress.Warning(fmt.Sprintf("Failed to restore: %v", undoErr))
+			}
+		}
+		progress.Error(fmt.Sprintf("Failed to move feature '%s'", oldName))
+		return nil, err
+	}
+
+	if err := os.MkdirAll(newTreesDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create trees directory: %w", err)
+	}
+	undo = append(undo, func() error { return os.RemoveAll(newTreesDir) })
+
+	for _, repo := range repos {
+		progress.Update(fmt.Sprintf("Moving %s", repo.name))
+		if err := git.MoveWorktreeQuiet(repo.repoDir, repo.oldDir, repo.newDir); err != nil {
+			return fail(fmt.Errorf("%s: %w", repo.name, err))
+		}
+		undo = append(undo, func() error { return git.MoveWorktreeQuiet(repo.repoDir, repo.newDir, repo.oldDir) })
+
+		if repo.newBranch != repo.oldBranch {
+			if err := git.RenameBranchQuiet(repo.repoDir, repo.oldBranch, repo.newBranch); err != nil {
+				return fail(fmt.Errorf("%s: %w", repo.name, err))
+			}
+			undo = append(undo, func() error { return git.RenameBranchQuiet(repo.repoDir, repo.newBranch, repo.oldBranch) })
+		}
+	}
+
+	// Anything else in the feature's directory moves along with the worktrees
+	entries, err := os.ReadDir(oldTreesDir)
+	if err != nil {
+		return fail(fmt.Errorf("failed to read %s: %w", oldTreesDir, err))
+	}
+	for _, entry := range entries {
+		from := filepath.Join(oldTreesDir, entry.Name())
+		to := filepath.Join(newTreesDir, entry.Name())
+		if err := os.Rename(from, to); err != nil {
+			return fail(fmt.Errorf("failed to move %s: %w", from, err))
+		}
+		undo = append(undo, func() error { return os.Rename(to, from) })
+	}
+	if err := os.Remove(oldTreesDir); err != nil {
+		return fail(fmt.Errorf("failed to remove %s: %w", oldTreesDir, err))
+	}
+	undo = append(undo, func() error { return os.MkdirAll(oldTreesDir, 0755) })
+
+	portAllocations, err := ports.NewPortAllocations(projectDir, opts.Config.GetBasePort(), opts.Config.GetMaxPorts())
+	if err != nil {
+		return fail(err)
+	}
+	if err := portAllocations.RenameFeature(oldName, newName); err != nil {
+		return fail(err)
+	}
+	undo = append(undo, func() error { return portAllocations.RenameFeature(newName, oldName) })
+
+	metadataStore, err := features.NewMetadataStore(projectDir)
+	if err != nil {
+		return fail(err)
+	}
+	if err := metadataStore.RenameFeature(oldName, newName); err != nil {
+		return fail(err)
+	}
+
+	result := &MoveResult{FeatureName: newName, OldName: oldName, TreesDir: newTreesDir, Repos: []MoveRepoResult{}}
+	for _, repo := range repos {
+		repoResult := MoveRepoResult{Repo: repo.name, Branch: repo.newBranch, OldBranch: repo.oldBranch}
+		if opts.RenameRemote && repo.onRemote && repo.newBranch != repo.oldBranch {
+			progress.Update(fmt.Sprintf("Renaming %s on origin", repo.oldBranch))
+			if err := git.PushBranchQuiet(repo.repoDir, repo.newBranch, false); err != nil {
+				progress.Warning(fmt.Sprintf("%s: %v", repo.name, err))
+			} else if err := git.DeleteRemoteBranchQuiet(repo.repoDir, repo.oldBranch); err != nil {
+				progress.Warning(fmt.Sprintf("%s: pushed %s but %v", repo.name, repo.newBranch, err))
+			} else {
+				repoResult.RemoteRenamed = true
+			}
+		}
+		result.Repos = append(result.Repos, repoResult)
+	}
+
+	if HasEnvFiles(opts.Config.GetRepos()) {
+		envResult, err := PlanEnvSync(EnvSyncOptions{
+			FeatureName: newName,
+			ProjectDir:  projectDir,
+			Config:      opts.Config,
+			Progress:    progress,
+			Conflict:    EnvConflictMerge,
+		})
+		if err == nil {
+			err = ApplyEnvSync(envResult, progress)
+		}
+		if err != nil {
+			progress.Warning(fmt.Sprintf("Env files were not updated: %v (run 'ramp env sync %s')", err, newName))
+		}
+	}
+
+	progress.Success(fmt.Sprintf("Moved feature '%s' to '%s'", oldName, newName))
+	return result, nil
+}
+
+// planMove checks that a feature can be renamed and works out the worktree
+// and branch of each repo, without changing anything.
+func planMove(opts MoveOptions) ([]moveRepo, error) {
+	projectDir := opts.ProjectDir
+	oldName, newName := opts.FeatureName, opts.NewName
+
+	if newName == "" || newName == "." || newName == ".." || strings.ContainsAny(newName, `/\`) {
+		return nil, fmt.Errorf("invalid feature name '%s'", newName)
+	}
+	if newName == oldName {
+		return nil, fmt.Errorf("feature is already named '%s'", oldName)
+	}
+
+	oldTreesDir := filepath.Join(projectDir, "trees", oldName)
+	newTreesDir := filepath.Join(projectDir, "trees", newName)
+	if _, err := os.Stat(oldTreesDir); os.IsNotExist(err) {
+		return nil, fmt.Errorf("feature '%s' not found", oldName)
+	}
+	if _, err := os.Stat(newTreesDir); err == nil {
+		return nil, fmt.Errorf("feature '%s' already exists", newName)
+	}
+
+	for _, name := range []string{oldName, newName} {
+		if j, err := LoadJournal(projectDir, name); err != nil {
+			return nil, err
+		} else if j != nil {
+			return nil, fmt.Errorf("feature '%s' has an unfinished %s; resume or roll it back first", name, j.Operation)
+		}
+	}
+
+	repos := opts.Config.GetRepos()
+	prefixes := featureBranchPrefixes(projectDir, opts.Config, oldName)
+	planned := []moveRepo{}
+	for _, name := range sortedRepoNames(repos) {
+		repoDir := repos[name].GetRepoPath(projectDir)
+		oldDir := filepath.Join(oldTreesDir, name)
+		if _, err := os.Stat(oldDir); os.IsNotExist(err) || !git.IsGitRepo(repoDir) {
+			continue
+		}
+
+		if op := git.InProgressOperation(oldDir); op != "" {
+			return nil, fmt.Errorf("%s has a %s in progress; finish or abort it first", name, op)
+		}
+		if git.HasInitializedSubmodules(oldDir) {
+			return nil, fmt.Errorf("%s has initialized submodules, and git can't move worktrees with submodules; use 'ramp clone-feature %s %s --uncommitted' and 'ramp down %s' instead", name, oldName, newName, oldName)
+		}
+		branch, err := git.GetWorktreeBranch(oldDir)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+
+		repo := moveRepo{
+			name:      name,
+			repoDir:   repoDir,
+			oldDir:    oldDir,
+			newDir:    filepath.Join(newTreesDir, name),
+			oldBranch: branch,
+			newBranch: branch,
+		}
+
+		// Only the feature's own branch is renamed, not one checked out by hand
+		prefix, ok := featureBranchPrefix(branch, oldName, prefixes)
+		if !ok {
+			opts.Progress.Warning(fmt.Sprintf("%s: %s is not the feature's branch; it keeps its name", name, branch))
+		} else {
+			repo.newBranch = prefix + newName
+			if exists, _ := git.LocalBranchExists(repoDir, repo.newBranch); exists {
+				return nil, fmt.Errorf("branch %s already exists in %s", repo.newBranch, name)
+			}
+			repo.onRemote, _ = git.RemoteBranchExists(repoDir, branch)
+			if opts.RenameRemote && repo.onRemote {
+				if exists, _ := git.RemoteBranchExists(repoDir, repo.newBranch); exists {
+					return nil, fmt.Errorf("branch %s already exists on origin in %s", repo.newBranch, name)
+				}
+			}
+		}
+
+		planned = append(planned, repo)
+	}
+
+	return planned, nil
+}
@@ -4,9 +4,13 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 
 	"ramp/internal/config"
+	"ramp/internal/features"
+	"ramp/internal/git"
 	"ramp/internal/scaffold"
 )
 
@@ -244,6 +248,52 @@ func TestUpMultipleRepos(t *testing.T) {
 	}
 }
 
+// TestUpParallelRollback tests that worktrees created by other workers are
+// rolled back when one repo fails
+func TestUpParallelRollback(t *testing.T) {
+	tp := NewTestProject(t)
+	tp.InitRepo("repo1")
+	busy := tp.InitRepo("repo2")
+	tp.InitRepo("repo3")
+
+	// A branch checked out in the source repo can't get a worktree
+	runGitCmd(t, busy.SourceDir, "checkout", "-b", "feature/parallel")
+
+	progress := &MockProgressReporter{}
+	_, err := Up(UpOptions{
+		FeatureName: "parallel",
+		ProjectDir:  tp.Dir,
+		Config:      tp.Config,
+		Progress:    progress,
+		SkipRefresh: true,
+		Workers:     3,
+	})
+
+	if err == nil {
+		t.Fatal("Up() should fail when a worktree can't be created")
+	}
+	if !strings.Contains(err.Error(), "repo2") {
+		t.Errorf("error = %v, want failure for repo2", err)
+	}
+
+	for _, name := range []string{"repo1", "repo2", "repo3"} {
+		if tp.WorktreeExists("parallel", name) {
+			t.Errorf("worktree for %s should be rolled back", name)
+		}
+	}
+	if tp.FeatureExists("parallel") {
+		t.Error("feature directory should be rolled back")
+	}
+
+	output, err := exec.Command("git", "-C", tp.Repos["repo1"].SourceDir, "branch", "--list", "feature/parallel").Output()
+	if err != nil {
+		t.Fatalf("git branch failed: %v", err)
+	}
+	if strings.TrimSpace(string(output)) != "" {
+		t.Errorf("branch should be deleted from repo1, got %q", output)
+	}
+}
+
 func TestUpWithNoPrefix(t *testing.T) {
 	tp := NewTestProject(t)
 	tp.InitRepo("repo1")
@@ -319,6 +369,30 @@ func TestUpAllocatesPorts(t *testing.T) {
 	}
 }
 
+func TestBuildEnvVarsNamedPorts(t *testing.T) {
+	projectDir := t.TempDir()
+	cfg := &config.Config{
+		Name:            "test",
+		BasePort:        3000,
+		PortsPerFeature: 3,
+		PortNames:       []string{"web", "api-gateway"},
+	}
+
+	envVars := BuildEnvVars(projectDir, "/trees/feature", "feature", "", []int{3000, 3001, 3002}, cfg, nil)
+
+	expected := map[string]string{
+		"RAMP_PORT":             "3000",
+		"RAMP_PORT_3":           "3002",
+		"RAMP_PORT_WEB":         "3000",
+		"RAMP_PORT_API_GATEWAY": "3001",
+	}
+	for key, want := range expected {
+		if got := envVars[key]; got != want {
+			t.Errorf("%s = %q, want %q", key, got, want)
+		}
+	}
+}
+
 func TestUpDuplicateFeature(t *testing.T) {
 	tp := NewTestProject(t)
 	tp.InitRepo("repo1")
@@ -615,3 +689,1967 @@ func TestIsProjectInstalled(t *testing.T) {
 		t.Error("Should be installed after repo added")
 	}
 }
+
+// newCloneSource creates a repository with one commit that allows partial clones
+func newCloneSource(t *testing.T, name string) string {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), name)
+	runGitCmd(t, dir, "init")
+	runGitCmd(t, dir, "config", "user.email", "test@example.com")
+	runGitCmd(t, dir, "config", "user.name", "Test User")
+	runGitCmd(t, dir, "config", "commit.gpgsign", "false")
+	runGitCmd(t, dir, "config", "uploadpack.allowFilter", "true")
+	os.WriteFile(filepath.Join(dir, "README.md"), []byte("# "+name), 0644)
+	runGitCmd(t, dir, "add", "README.md")
+	runGitCmd(t, dir, "commit", "-m", "initial commit")
+	return dir
+}
+
+func TestInstallClonesConcurrently(t *testing.T) {
+	tp := NewTestProject(t)
+
+	for _, name := range []string{"api", "web", "docs"} {
+		tp.Config.Repos = append(tp.Config.Repos, &config.Repo{Path: "repos", Git: "file://" + newCloneSource(t, name)})
+	}
+	tp.Config.Repos[1].Filter = "blob:none"
+
+	progress := &MockProgressReporter{}
+	result, err := Install(InstallOptions{
+		ProjectDir: tp.Dir,
+		Config:     tp.Config,
+		Progress:   progress,
+		Workers:    3,
+	})
+	if err != nil {
+		t.Fatalf("Install() error = %v", err)
+	}
+
+	if strings.Join(result.ClonedRepos, ",") != "api,docs,web" {
+		t.Errorf("ClonedRepos = %v, want [api docs web]", result.ClonedRepos)
+	}
+	if !IsProjectInstalled(tp.Config, tp.Dir) {
+		t.Error("all repos should be cloned")
+	}
+
+	output, err := exec.Command("git", "-C", filepath.Join(tp.ReposDir, "web"), "config", "remote.origin.partialclonefilter").Output()
+	if err != nil || strings.TrimSpace(string(output)) != "blob:none" {
+		t.Errorf("web should be a blob:none partial clone, got %q (%v)", output, err)
+	}
+
+	var cloned int
+	for _, msg := range progress.Messages {
+		if strings.HasPrefix(msg, "progress: Cloned ") {
+			cloned++
+		}
+	}
+	if cloned != 3 {
+		t.Errorf("got %d per-repo progress updates, want 3: %v", cloned, progress.Messages)
+	}
+}
+
+func TestInstallReportsFailedClones(t *testing.T) {
+	tp := NewTestProject(t)
+
+	tp.Config.Repos = append(tp.Config.Repos,
+		&config.Repo{Path: "repos", Git: newCloneSource(t, "api")},
+		&config.Repo{Path: "repos", Git: filepath.Join(t.TempDir(), "missing")},
+	)
+
+	progress := &MockProgressReporter{}
+	_, err := Install(InstallOptions{
+		ProjectDir: tp.Dir,
+		Config:     tp.Config,
+		Progress:   progress,
+	})
+	if err == nil || !strings.Contains(err.Error(), "failed to clone missing") {
+		t.Fatalf("Install() error = %v, want failure for missing", err)
+	}
+
+	// Other clones run to completion and are kept
+	if _, err := os.Stat(filepath.Join(tp.ReposDir, "api", ".git")); err != nil {
+		t.Errorf("api should still be cloned: %v", err)
+	}
+	if !slices.Contains(progress.Messages, "error: Failed to clone missing") {
+		t.Errorf("messages %v should report the failed clone", progress.Messages)
+	}
+}
+
+// addSubmodule adds a submodule named "lib" to the repo and pushes it
+func addSubmodule(t *testing.T, repo *TestRepo) {
+	t.Helper()
+
+	// Local submodule URLs need the file protocol, which git blocks by default
+	t.Setenv("GIT_CONFIG_COUNT", "1")
+	t.Setenv("GIT_CONFIG_KEY_0", "protocol.file.allow")
+	t.Setenv("GIT_CONFIG_VALUE_0", "always")
+
+	lib := newCloneSource(t, "lib")
+	runGitCmd(t, repo.SourceDir, "submodule", "add", lib, "lib")
+	runGitCmd(t, repo.SourceDir, "commit", "-m", "add lib submodule")
+	runGitCmd(t, repo.SourceDir, "push", "origin", "main")
+}
+
+func TestUpInitializesSubmodules(t *testing.T) {
+	tp := NewTestProject(t)
+	repo := tp.InitRepo("app")
+	addSubmodule(t, repo)
+
+	tp.Config.Repos[0].Submodules = "recursive"
+
+	_, err := Up(UpOptions{
+		FeatureName: "with-lib",
+		ProjectDir:  tp.Dir,
+		Config:      tp.Config,
+		Progress:    &MockProgressReporter{},
+		SkipRefresh: true,
+	})
+	if err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(tp.TreesDir, "with-lib", "app", "lib", "README.md")); err != nil {
+		t.Errorf("submodule should be checked out in the worktree: %v", err)
+	}
+}
+
+func TestUpRollsBackWhenLFSPullFails(t *testing.T) {
+	if exec.Command("git", "lfs", "version").Run() == nil {
+		t.Skip("git-lfs is installed; this test relies on the pull failing")
+	}
+
+	tp := NewTestProject(t)
+	tp.InitRepo("app")
+	tp.InitRepo("assets")
+	tp.Config.Repos[1].LFS = true
+
+	_, err := Up(UpOptions{
+		FeatureName: "lfs",
+		ProjectDir:  tp.Dir,
+		Config:      tp.Config,
+		Progress:    &MockProgressReporter{},
+		SkipRefresh: true,
+	})
+	if err == nil || !strings.Contains(err.Error(), "LFS pull failed") {
+		t.Fatalf("Up() error = %v, want LFS pull failure", err)
+	}
+
+	if tp.FeatureExists("lfs") {
+		t.Error("feature should be rolled back")
+	}
+}
+
+func TestRefreshUpdatesSubmodules(t *testing.T) {
+	tp := NewTestProject(t)
+	repo := tp.InitRepo("app")
+	addSubmodule(t, repo)
+
+	// Leave the submodule registered but not checked out
+	runGitCmd(t, repo.SourceDir, "submodule", "deinit", "--all")
+	tp.Config.Repos[0].Submodules = "true"
+
+	results := RefreshRepositories(RefreshOptions{
+		ProjectDir: tp.Dir,
+		Config:     tp.Config,
+		Progress:   &MockProgressReporter{},
+	})
+	if len(results) != 1 || results[0].Status != "success" {
+		t.Fatalf("results = %+v, want success", results)
+	}
+
+	if _, err := os.Stat(filepath.Join(repo.SourceDir, "lib", "README.md")); err != nil {
+		t.Errorf("refresh should check out the submodule: %v", err)
+	}
+}
+
+func TestForkFeaturesBranchFromUpstream(t *testing.T) {
+	tp := NewTestProject(t)
+	repo := tp.InitRepo("app")
+
+	// The fork's upstream has moved on since the fork was made
+	upstreamDir := filepath.Join(t.TempDir(), "app-upstream")
+	runGitCmd(t, tp.Dir, "clone", repo.SourceDir, upstreamDir)
+	runGitCmd(t, upstreamDir, "config", "user.email", "test@example.com")
+	runGitCmd(t, upstreamDir, "config", "user.name", "Test User")
+	runGitCmd(t, upstreamDir, "commit", "--allow-empty", "-m", "upstream change")
+	tp.Config.Repos[0].Upstream = upstreamDir
+
+	// Install adds the upstream remote to the existing clone
+	progress := &MockProgressReporter{}
+	if _, err := Install(InstallOptions{ProjectDir: tp.Dir, Config: tp.Config, Progress: progress}); err != nil {
+		t.Fatalf("Install() error = %v", err)
+	}
+	if !slices.Contains(progress.Messages, "info: app: upstream remote set to "+upstreamDir) {
+		t.Errorf("missing upstream message, got %v", progress.Messages)
+	}
+
+	base, err := BaseBranch(tp.Config.Repos[0], repo.SourceDir)
+	if err != nil || base != "upstream/main" {
+		t.Fatalf("BaseBranch() = %q, %v, want upstream/main", base, err)
+	}
+
+	if _, err := Up(UpOptions{
+		FeatureName: "fork",
+		ProjectDir:  tp.Dir,
+		Config:      tp.Config,
+		Progress:    &MockProgressReporter{},
+		SkipRefresh: true,
+	}); err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+
+	worktreeDir := filepath.Join(tp.TreesDir, "fork", "app")
+	head, _ := exec.Command("git", "-C", worktreeDir, "log", "-1", "--format=%s").Output()
+	if got := strings.TrimSpace(string(head)); got != "upstream change" {
+		t.Errorf("feature branch starts at %q, want upstream's latest commit", got)
+	}
+
+	// Pushes must still go to the fork, so the branch doesn't track upstream
+	if exec.Command("git", "-C", worktreeDir, "config", "branch.feature/fork.remote").Run() == nil {
+		t.Error("feature branch should not track upstream")
+	}
+}
+
+func TestPush(t *testing.T) {
+	tp := NewTestProject(t)
+	api := tp.InitRepo("api")
+	tp.InitRepo("web")
+
+	if _, err := Up(UpOptions{
+		FeatureName: "publish",
+		ProjectDir:  tp.Dir,
+		Config:      tp.Config,
+		Progress:    &MockProgressReporter{},
+		SkipRefresh: true,
+	}); err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+
+	apiWorktree := filepath.Join(tp.TreesDir, "publish", "api")
+	runGitCmd(t, apiWorktree, "commit", "--allow-empty", "-m", "api change")
+
+	push := func(forceWithLease bool) *PushResult {
+		t.Helper()
+		result, err := Push(PushOptions{
+			FeatureName:    "publish",
+			ProjectDir:     tp.Dir,
+			Config:         tp.Config,
+			Progress:       &MockProgressReporter{},
+			ForceWithLease: forceWithLease,
+		})
+		if err != nil {
+			t.Fatalf("Push() error = %v", err)
+		}
+		return result
+	}
+
+	result := push(false)
+	if len(result.Repos) != 2 {
+		t.Fatalf("got %d repo results, want 2", len(result.Repos))
+	}
+	if got := result.Repos[0]; got.Repo != "api" || got.Status != PushStatusPushed || got.Ahead != 1 {
+		t.Errorf("api result = %+v, want pushed with 1 commit", got)
+	}
+	if got := result.Repos[1]; got.Repo != "web" || got.Status != PushStatusSkipped {
+		t.Errorf("web result = %+v, want skipped", got)
+	}
+
+	if exec.Command("git", "-C", api.RemoteDir, "rev-parse", "--verify", "feature/publish").Run() != nil {
+		t.Error("feature branch should exist on the remote")
+	}
+	if upstream, _ := exec.Command("git", "-C", apiWorktree, "rev-parse", "--abbrev-ref", "@{u}").Output(); strings.TrimSpace(string(upstream)) != "origin/feature/publish" {
+		t.Errorf("upstream = %q, want origin/feature/publish", strings.TrimSpace(string(upstream)))
+	}
+
+	// Nothing new to push
+	if result := push(false); result.Repos[0].Status != PushStatusSkipped {
+		t.Errorf("second push = %+v, want skipped", result.Repos[0])
+	}
+
+	// A rewritten branch needs --force-with-lease
+	runGitCmd(t, apiWorktree, "commit", "--amend", "--allow-empty", "-m", "api change, amended")
+	if result := push(false); result.Repos[0].Status != PushStatusFailed || len(result.Failed()) != 1 {
+		t.Errorf("diverged push = %+v, want failed", result.Repos[0])
+	}
+	if result := push(true); result.Repos[0].Status != PushStatusPushed {
+		t.Errorf("push with lease = %+v, want pushed", result.Repos[0])
+	}
+}
+
+func TestPushFeatureNotFound(t *testing.T) {
+	tp := NewTestProject(t)
+	tp.InitRepo("api")
+
+	_, err := Push(PushOptions{
+		FeatureName: "missing",
+		ProjectDir:  tp.Dir,
+		Config:      tp.Config,
+		Progress:    &MockProgressReporter{},
+	})
+	if err == nil {
+		t.Error("Push() should fail for a missing feature")
+	}
+}
+
+// newSyncFeature creates feature "sync" on repo api, commits file with content in
+// the worktree and pushes a change to README.md on main.
+func newSyncFeature(t *testing.T, file, content string) (*TestProject, string) {
+	t.Helper()
+	tp := NewTestProject(t)
+	api := tp.InitRepo("api")
+
+	if _, err := Up(UpOptions{
+		FeatureName: "sync",
+		ProjectDir:  tp.Dir,
+		Config:      tp.Config,
+		Progress:    &MockProgressReporter{},
+		SkipRefresh: true,
+	}); err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+
+	worktreeDir := filepath.Join(tp.TreesDir, "sync", "api")
+	os.WriteFile(filepath.Join(worktreeDir, file), []byte(content), 0644)
+	runGitCmd(t, worktreeDir, "add", file)
+	runGitCmd(t, worktreeDir, "commit", "-m", "feature change")
+
+	os.WriteFile(filepath.Join(api.SourceDir, "README.md"), []byte("# api\nfrom main\n"), 0644)
+	runGitCmd(t, api.SourceDir, "commit", "-am", "main change")
+	runGitCmd(t, api.SourceDir, "push", "origin", "main")
+
+	return tp, worktreeDir
+}
+
+func syncOptions(tp *TestProject, mode SyncMode) SyncOptions {
+	return SyncOptions{
+		FeatureName: "sync",
+		ProjectDir:  tp.Dir,
+		Config:      tp.Config,
+		Progress:    &MockProgressReporter{},
+		Mode:        mode,
+	}
+}
+
+func TestSyncRebase(t *testing.T) {
+	tp, worktreeDir := newSyncFeature(t, "feature.txt", "feature work")
+	os.WriteFile(filepath.Join(worktreeDir, "wip.txt"), []byte("work in progress"), 0644)
+	runGitCmd(t, worktreeDir, "add", "wip.txt")
+
+	for _, mode := range []SyncMode{SyncRebase, SyncMerge} {
+		result, err := Sync(syncOptions(tp, mode))
+		if err != nil {
+			t.Fatalf("Sync(%s) error = %v", mode, err)
+		}
+		if len(result.Repos) != 1 {
+			t.Fatalf("got %d repo results, want 1", len(result.Repos))
+		}
+		got := result.Repos[0]
+		// The merge run finds the branch already rebased
+		want := SyncStatusSynced
+		if mode == SyncMerge {
+			want = SyncStatusUpToDate
+		}
+		if got.Status != want || got.Onto != "origin/main" {
+			t.Errorf("Sync(%s) = %+v, want %s onto origin/main", mode, got, want)
+		}
+	}
+
+	log, _ := exec.Command("git", "-C", worktreeDir, "log", "--format=%s").Output()
+	if !strings.HasPrefix(string(log), "feature change\nmain change\n") {
+		t.Errorf("feature commits should be replayed on main, got log:\n%s", log)
+	}
+	if _, err := os.Stat(filepath.Join(worktreeDir, "wip.txt")); err != nil {
+		t.Errorf("stashed changes should be restored: %v", err)
+	}
+}
+
+func TestSyncConflictContinue(t *testing.T) {
+	tp, worktreeDir := newSyncFeature(t, "README.md", "# api\nfrom feature\n")
+
+	result, err := Sync(syncOptions(tp, SyncMerge))
+	if err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	got := result.Repos[0]
+	if got.Status != SyncStatusConflict || !slices.Equal(got.Conflicts, []string{"README.md"}) {
+		t.Fatalf("Sync() = %+v, want conflict in README.md", got)
+	}
+
+	if _, err := Sync(syncOptions(tp, SyncMerge)); err == nil {
+		t.Error("Sync() should refuse to start while a sync is stopped")
+	}
+
+	// Continuing with the conflict unresolved stops again
+	result, err = ContinueSync(syncOptions(tp, ""))
+	if err != nil || result.Repos[0].Status != SyncStatusConflict {
+		t.Fatalf("ContinueSync() unresolved = %+v, %v, want conflict", result, err)
+	}
+
+	os.WriteFile(filepath.Join(worktreeDir, "README.md"), []byte("# api\nresolved\n"), 0644)
+	runGitCmd(t, worktreeDir, "add", "README.md")
+
+	result, err = ContinueSync(syncOptions(tp, ""))
+	if err != nil {
+		t.Fatalf("ContinueSync() error = %v", err)
+	}
+	if result.Repos[0].Status != SyncStatusSynced {
+		t.Errorf("ContinueSync() = %+v, want synced", result.Repos[0])
+	}
+
+	if _, err := ContinueSync(syncOptions(tp, "")); err == nil {
+		t.Error("ContinueSync() should fail once the sync has finished")
+	}
+}
+
+func TestSyncConflictAbort(t *testing.T) {
+	tp, worktreeDir := newSyncFeature(t, "README.md", "# api\nfrom feature\n")
+	before, _ := exec.Command("git", "-C", worktreeDir, "rev-parse", "HEAD").Output()
+
+	result, err := Sync(syncOptions(tp, SyncRebase))
+	if err != nil || result.Repos[0].Status != SyncStatusConflict {
+		t.Fatalf("Sync() = %+v, %v, want conflict", result, err)
+	}
+
+	result, err = AbortSync(syncOptions(tp, ""))
+	if err != nil {
+		t.Fatalf("AbortSync() error = %v", err)
+	}
+	if result.Repos[0].Status != SyncStatusAborted {
+		t.Errorf("AbortSync() = %+v, want aborted", result.Repos[0])
+	}
+
+	after, _ := exec.Command("git", "-C", worktreeDir, "rev-parse", "HEAD").Output()
+	if string(after) != string(before) {
+		t.Error("abort should restore the branch to where it was")
+	}
+
+	// The sync can be started again
+	if result, err := Sync(syncOptions(tp, SyncRebase)); err != nil || result.Repos[0].Status != SyncStatusConflict {
+		t.Errorf("Sync() after abort = %+v, %v, want conflict", result, err)
+	}
+}
+
+// TestSyncRestoresItsOwnStash tests that continuing or aborting a stopped sync
+// restores the changes it stashed even after another worktree stashed too
+func TestSyncRestoresItsOwnStash(t *testing.T) {
+	tp, worktreeDir := newSyncFeature(t, "README.md", "# api\nfrom feature\n")
+	os.WriteFile(filepath.Join(worktreeDir, "wip.txt"), []byte("sync wip"), 0644)
+	runGitCmd(t, worktreeDir, "add", "wip.txt")
+
+	if _, err := Up(UpOptions{FeatureName: "sibling", ProjectDir: tp.Dir, Config: tp.Config, Progress: &MockProgressReporter{}, SkipRefresh: true}); err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+	siblingDir := filepath.Join(tp.TreesDir, "sibling", "api")
+
+	result, err := Sync(syncOptions(tp, SyncRebase))
+	if err != nil || result.Repos[0].Status != SyncStatusConflict {
+		t.Fatalf("Sync() = %+v, %v, want conflict", result, err)
+	}
+
+	// The sibling's stash lands on top of the sync's in the shared stash
+	os.WriteFile(filepath.Join(siblingDir, "sibling.txt"), []byte("sibling wip"), 0644)
+	runGitCmd(t, siblingDir, "add", "sibling.txt")
+	runGitCmd(t, siblingDir, "stash", "push", "-m", "sibling stash")
+
+	result, err = AbortSync(syncOptions(tp, ""))
+	if err != nil || result.Repos[0].Status != SyncStatusAborted {
+		t.Fatalf("AbortSync() = %+v, %v, want aborted", result, err)
+	}
+
+	if _, err := os.Stat(filepath.Join(worktreeDir, "wip.txt")); err != nil {
+		t.Errorf("the sync's stashed changes should be restored: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(worktreeDir, "sibling.txt")); !os.IsNotExist(err) {
+		t.Error("the sibling's stash should not be applied to the synced worktree")
+	}
+	list, _ := exec.Command("git", "-C", siblingDir, "stash", "list", "--format=%gs").Output()
+	if got := strings.TrimSpace(string(list)); !strings.HasSuffix(got, "sibling stash") || strings.Contains(got, "\n") {
+		t.Errorf("stash list = %q, want only the sibling's entry", got)
+	}
+}
+
+func TestCommit(t *testing.T) {
+	tp := NewTestProject(t)
+	for _, name := range []string{"api", "docs", "web"} {
+		tp.InitRepo(name)
+	}
+
+	if _, err := Up(UpOptions{
+		FeatureName: "billing",
+		ProjectDir:  tp.Dir,
+		Config:      tp.Config,
+		Progress:    &MockProgressReporter{},
+		SkipRefresh: true,
+	}); err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+
+	worktree := func(name string) string { return filepath.Join(tp.TreesDir, "billing", name) }
+	commit := func(opts CommitOptions) *CommitResult {
+		t.Helper()
+		opts.FeatureName = "billing"
+		opts.ProjectDir = tp.Dir
+		opts.Config = tp.Config
+		opts.Progress = &MockProgressReporter{}
+		result, err := Commit(opts)
+		if err != nil {
+			t.Fatalf("Commit() error = %v", err)
+		}
+		return result
+	}
+	statuses := func(result *CommitResult) []string {
+		var got []string
+		for _, repo := range result.Repos {
+			got = append(got, repo.Repo+"="+repo.Status)
+		}
+		return got
+	}
+
+	os.WriteFile(filepath.Join(worktree("api"), "README.md"), []byte("# api\nbilling\n"), 0644)
+	os.WriteFile(filepath.Join(worktree("web"), "billing.js"), []byte("// billing"), 0644)
+
+	// Untracked files need --all
+	result := commit(CommitOptions{Message: "Add billing", Trailer: true})
+	want := []string{"api=committed", "docs=unchanged", "web=unchanged"}
+	if got := statuses(result); !slices.Equal(got, want) {
+		t.Errorf("statuses = %v, want %v", got, want)
+	}
+	head, _ := exec.Command("git", "-C", worktree("api"), "rev-parse", "--short", "HEAD").Output()
+	if result.Repos[0].Commit != strings.TrimSpace(string(head)) {
+		t.Errorf("Commit = %q, want HEAD %q", result.Repos[0].Commit, strings.TrimSpace(string(head)))
+	}
+	message, _ := exec.Command("git", "-C", worktree("api"), "log", "-1", "--format=%B").Output()
+	if !strings.Contains(string(message), "Ramp-Feature: billing") {
+		t.Errorf("commit message should have the feature trailer, got:\n%s", message)
+	}
+
+	result = commit(CommitOptions{Message: "Add billing UI", All: true, Exclude: []string{"api"}})
+	want = []string{"api=skipped", "docs=unchanged", "web=committed"}
+	if got := statuses(result); !slices.Equal(got, want) {
+		t.Errorf("statuses with --all = %v, want %v", got, want)
+	}
+
+	// Amend keeps the message and only touches repos with changes
+	os.WriteFile(filepath.Join(worktree("web"), "billing.js"), []byte("// billing v2"), 0644)
+	result = commit(CommitOptions{Amend: true, Include: []string{"api", "web"}})
+	want = []string{"api=unchanged", "docs=skipped", "web=committed"}
+	if got := statuses(result); !slices.Equal(got, want) {
+		t.Errorf("statuses with --amend = %v, want %v", got, want)
+	}
+	subjects, _ := exec.Command("git", "-C", worktree("web"), "log", "--format=%s").Output()
+	if !strings.HasPrefix(string(subjects), "Add billing UI\ninitial commit") {
+		t.Errorf("amend should replace the last commit, got log:\n%s", subjects)
+	}
+
+	// A repo without commits of its own is not amended onto the base branch's tip
+	os.WriteFile(filepath.Join(worktree("docs"), "README.md"), []byte("# docs\nbilling\n"), 0644)
+	before, _ := exec.Command("git", "-C", worktree("docs"), "rev-parse", "HEAD").Output()
+	result = commit(CommitOptions{Amend: true, Include: []string{"docs"}})
+	want = []string{"api=skipped", "docs=failed", "web=skipped"}
+	if got := statuses(result); !slices.Equal(got, want) {
+		t.Errorf("statuses with --amend and no feature commits = %v, want %v", got, want)
+	}
+	if after, _ := exec.Command("git", "-C", worktree("docs"), "rev-parse", "HEAD").Output(); string(after) != string(before) {
+		t.Error("docs HEAD should be left alone")
+	}
+
+	if _, err := Commit(CommitOptions{FeatureName: "billing", ProjectDir: tp.Dir, Config: tp.Config, Progress: &MockProgressReporter{}}); err == nil {
+		t.Error("Commit() without a message should fail")
+	}
+	if _, err := Commit(CommitOptions{FeatureName: "billing", ProjectDir: tp.Dir, Config: tp.Config, Progress: &MockProgressReporter{}, Message: "x", Include: []string{"nope"}}); err == nil {
+		t.Error("Commit() with an unknown repo should fail")
+	}
+}
+
+func TestStatusCollector(t *testing.T) {
+	tp := NewTestProject(t)
+	api := tp.InitRepo("api")
+	tp.InitRepo("web")
+
+	for _, name := range []string{"clean", "wip", "squashed"} {
+		if _, err := Up(UpOptions{
+			FeatureName: name,
+			ProjectDir:  tp.Dir,
+			Config:      tp.Config,
+			Progress:    &MockProgressReporter{},
+			SkipRefresh: true,
+		}); err != nil {
+			t.Fatalf("Up(%s) error = %v", name, err)
+		}
+	}
+
+	worktree := func(feature, repo string) string { return filepath.Join(tp.TreesDir, feature, repo) }
+	commitFile := func(dir, file, content, message string) {
+		t.Helper()
+		os.WriteFile(filepath.Join(dir, file), []byte(content), 0644)
+		runGitCmd(t, dir, "add", file)
+		runGitCmd(t, dir, "commit", "-m", message)
+	}
+
+	// wip/api: one commit ahead, an unstaged edit and an untracked file
+	commitFile(worktree("wip", "api"), "wip.txt", "wip\n", "Add wip")
+	os.WriteFile(filepath.Join(worktree("wip", "api"), "README.md"), []byte("# api\nedited\n"), 0644)
+	os.WriteFile(filepath.Join(worktree("wip", "api"), "notes.txt"), []byte("notes"), 0644)
+
+	// squashed/api: the same change lands on main as a separate commit
+	commitFile(worktree("squashed", "api"), "feature.txt", "feature\n", "Add feature")
+	commitFile(api.SourceDir, "feature.txt", "feature\n", "Add feature (#12)")
+
+	collector := NewStatusCollector(tp.Dir, tp.Config.GetRepos(), 4)
+	all := collector.CollectFeatures([]string{"clean", "wip", "squashed", "missing"})
+
+	if _, ok := all["missing"]; ok {
+		t.Error("features without a directory should be left out")
+	}
+	for _, feature := range []string{"clean", "wip", "squashed"} {
+		statuses := all[feature]
+		if len(statuses) != 2 || statuses[0].Repo != "api" || statuses[1].Repo != "web" {
+			t.Fatalf("%s statuses = %+v, want api and web", feature, statuses)
+		}
+
+		// The collector must agree with the per-call git helpers
+		for _, status := range statuses {
+			dir := worktree(feature, status.Repo)
+			if status.Error != "" || status.Branch != "feature/"+feature || status.BaseBranch != "main" {
+				t.Errorf("%s/%s = %+v", feature, status.Repo, status)
+			}
+			ahead, behind, _ := git.GetAheadBehindCount(dir, "main")
+			method, _ := git.DetectMerge(dir, "main")
+			dirty, _ := git.HasUncommittedChanges(dir)
+			if status.Ahead != ahead || status.Behind != behind || status.MergeMethod != method || status.HasUncommitted != dirty {
+				t.Errorf("%s/%s = %+v, want ahead %d behind %d method %q dirty %v",
+					feature, status.Repo, status, ahead, behind, method, dirty)
+			}
+		}
+	}
+
+	wip := all["wip"][0]
+	if wip.Ahead != 1 || wip.IsMerged() {
+		t.Errorf("wip/api should be one commit ahead and unmerged, got %+v", wip)
+	}
+	if *wip.StatusStats != (git.StatusStats{UntrackedFiles: 1, ModifiedFiles: 1}) || wip.DiffStats.FilesChanged != 1 {
+		t.Errorf("wip/api stats = %+v / %+v", wip.StatusStats, wip.DiffStats)
+	}
+	if clean := all["clean"][1]; clean.MergeMethod != git.MergeMethodAncestor || clean.HasUncommitted {
+		t.Errorf("clean/web = %+v, want an unchanged ancestor of main", clean)
+	}
+	if squashed := all["squashed"][0]; !squashed.IsMerged() || squashed.Behind != 1 {
+		t.Errorf("squashed/api = %+v, want merged by content and one behind", squashed)
+	}
+
+	if got := collector.Collect("clean", "missing"); got.Error != "worktree not found" {
+		t.Errorf("Collect() for a missing worktree error = %q", got.Error)
+	}
+}
+
+// stepKinds returns the kinds of a plan's steps for one repo, or for the whole
+// plan when repo is empty.
+func stepKinds(plan *Plan, repo string) []string {
+	var kinds []string
+	for _, step := range plan.Steps {
+		if repo == "" || step.Repo == repo {
+			kinds = append(kinds, step.Kind)
+		}
+	}
+	return kinds
+}
+
+func TestPlanUp(t *testing.T) {
+	tp := NewTestProject(t)
+	tp.InitRepo("repo1")
+
+	opts := UpOptions{
+		FeatureName: "planned",
+		ProjectDir:  tp.Dir,
+		Config:      tp.Config,
+		Progress:    &MockProgressReporter{},
+		SkipRefresh: true,
+	}
+	plan, err := PlanUp(opts)
+	if err != nil {
+		t.Fatalf("PlanUp() error = %v", err)
+	}
+
+	if tp.FeatureExists("planned") {
+		t.Error("PlanUp() should not create the feature")
+	}
+	if plan.BranchName != "feature/planned" {
+		t.Errorf("BranchName = %q, want feature/planned", plan.BranchName)
+	}
+	if len(plan.Repos) != 1 || plan.Repos[0].BranchState != BranchStateNew {
+		t.Errorf("Repos = %+v, want repo1 with a new branch", plan.Repos)
+	}
+	if !slices.Contains(stepKinds(&plan.Plan, "repo1"), StepCreateWorktree) {
+		t.Errorf("steps = %+v, want a create-worktree step for repo1", plan.Steps)
+	}
+	if !slices.Contains(stepKinds(&plan.Plan, ""), StepAllocatePorts) || !slices.Equal(plan.Ports, []int{3000}) {
+		t.Errorf("Ports = %v, want [3000] with an allocate-ports step", plan.Ports)
+	}
+
+	// Planning doesn't reserve the ports it shows
+	result, err := Up(opts)
+	if err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+	if !slices.Equal(result.AllocatedPorts, plan.Ports) {
+		t.Errorf("AllocatedPorts = %v, want the planned %v", result.AllocatedPorts, plan.Ports)
+	}
+
+	if _, err := PlanUp(opts); err == nil {
+		t.Error("PlanUp() should fail like Up() for an existing feature")
+	}
+}
+
+func TestPlanDown(t *testing.T) {
+	tp := NewTestProject(t)
+	tp.InitRepo("repo1")
+
+	_, err := Up(UpOptions{
+		FeatureName: "planned",
+		ProjectDir:  tp.Dir,
+		Config:      tp.Config,
+		Progress:    &MockProgressReporter{},
+		SkipRefresh: true,
+	})
+	if err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+	os.WriteFile(filepath.Join(tp.TreesDir, "planned", "repo1", "wip.txt"), []byte("wip"), 0644)
+
+	opts := DownOptions{
+		FeatureName: "planned",
+		ProjectDir:  tp.Dir,
+		Config:      tp.Config,
+		Progress:    &MockProgressReporter{},
+	}
+	plan, err := PlanDown(opts)
+	if err != nil {
+		t.Fatalf("PlanDown() error = %v", err)
+	}
+
+	if !tp.FeatureExists("planned") {
+		t.Fatal("PlanDown() should not remove the feature")
+	}
+	if !slices.Equal(plan.Uncommitted, []string{"repo1"}) || len(plan.Warnings) == 0 {
+		t.Errorf("Uncommitted = %v, Warnings = %v, want repo1 to be warned about", plan.Uncommitted, plan.Warnings)
+	}
+	if kinds := stepKinds(&plan.Plan, "repo1"); !slices.Equal(kinds, []string{StepRemoveWorktree, StepDeleteBranch}) {
+		t.Errorf("repo1 steps = %v, want remove-worktree and delete-branch", kinds)
+	}
+	if len(plan.Repos) != 1 || plan.Repos[0].Branch != "feature/planned" {
+		t.Errorf("Repos = %+v, want repo1 on feature/planned", plan.Repos)
+	}
+	kinds := stepKinds(&plan.Plan, "")
+	for _, kind := range []string{StepReleasePorts, StepRemoveDir} {
+		if !slices.Contains(kinds, kind) {
+			t.Errorf("steps = %v, want a %s step", kinds, kind)
+		}
+	}
+
+	opts.Force = true
+	if _, err := ApplyDown(opts, plan); err != nil {
+		t.Fatalf("ApplyDown() error = %v", err)
+	}
+	if tp.FeatureExists("planned") {
+		t.Error("ApplyDown() should remove the feature")
+	}
+}
+
+func TestPlanInstall(t *testing.T) {
+	tp := NewTestProject(t)
+	tp.InitRepo("existing")
+	tp.Config.Repos = append(tp.Config.Repos, &config.Repo{Path: "repos", Git: "file://" + newCloneSource(t, "api")})
+
+	opts := InstallOptions{
+		ProjectDir: tp.Dir,
+		Config:     tp.Config,
+		Progress:   &MockProgressReporter{},
+	}
+	plan := PlanInstall(opts)
+
+	if !slices.Equal(plan.Clone, []string{"api"}) || !slices.Equal(plan.Skip, []string{"existing"}) {
+		t.Errorf("Clone = %v, Skip = %v, want [api] and [existing]", plan.Clone, plan.Skip)
+	}
+	if kinds := stepKinds(&plan.Plan, "api"); !slices.Equal(kinds, []string{StepClone}) {
+		t.Errorf("api steps = %v, want a single clone step", kinds)
+	}
+	if IsProjectInstalled(tp.Config, tp.Dir) {
+		t.Fatal("PlanInstall() should not clone anything")
+	}
+
+	result, err := ApplyInstall(opts, plan)
+	if err != nil {
+		t.Fatalf("ApplyInstall() error = %v", err)
+	}
+	if !slices.Equal(result.ClonedRepos, []string{"api"}) || !IsProjectInstalled(tp.Config, tp.Dir) {
+		t.Errorf("ClonedRepos = %v, want api to be cloned", result.ClonedRepos)
+	}
+}
+
+func TestPlanPrune(t *testing.T) {
+	tp := NewTestProject(t)
+	tp.InitRepo("repo1")
+
+	_, err := Up(UpOptions{
+		FeatureName: "merged",
+		ProjectDir:  tp.Dir,
+		Config:      tp.Config,
+		Progress:    &MockProgressReporter{},
+		SkipRefresh: true,
+	})
+	if err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+
+	opts := PruneOptions{
+		ProjectDir: tp.Dir,
+		Config:     tp.Config,
+		Progress:   &MockProgressReporter{},
+		Features:   []string{"merged", "missing"},
+	}
+	plan := PlanPrune(opts)
+
+	if len(plan.Features) != 1 || plan.Features[0].Target != "merged" {
+		t.Errorf("Features = %+v, want only merged", plan.Features)
+	}
+	if len(plan.Failed) != 1 || plan.Failed[0].Feature != "missing" {
+		t.Errorf("Failed = %+v, want missing", plan.Failed)
+	}
+	for _, step := range plan.Steps {
+		if step.Feature != "merged" {
+			t.Errorf("step %+v should belong to merged", step)
+		}
+	}
+	if !tp.FeatureExists("merged") {
+		t.Fatal("PlanPrune() should not remove anything")
+	}
+
+	result := ApplyPrune(opts, plan)
+	if !slices.Equal(result.Pruned, []string{"merged"}) || len(result.Failed) != 1 {
+		t.Errorf("Pruned = %v, Failed = %+v, want merged pruned and missing failed", result.Pruned, result.Failed)
+	}
+	if tp.FeatureExists("merged") {
+		t.Error("ApplyPrune() should remove the feature")
+	}
+}
+
+// interrupt makes a journal look like its process was killed.
+func interrupt(t *testing.T, j *Journal) {
+	t.Helper()
+	cmd := exec.Command("true")
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("failed to run true: %v", err)
+	}
+	j.PID = cmd.Process.Pid
+	if err := j.save(); err != nil {
+		t.Fatalf("failed to save journal: %v", err)
+	}
+}
+
+// interruptedUp leaves a feature as if ramp up, with an optional target, was
+// killed after creating the worktree of repo1 but before anything else.
+func interruptedUp(t *testing.T, tp *TestProject, featureName, target string) *UpPlan {
+	t.Helper()
+	opts := UpOptions{
+		FeatureName: featureName,
+		ProjectDir:  tp.Dir,
+		Config:      tp.Config,
+		Progress:    &MockProgressReporter{},
+		Target:      target,
+		SkipRefresh: true,
+	}
+	plan, err := PlanUp(opts)
+	if err != nil {
+		t.Fatalf("PlanUp() error = %v", err)
+	}
+	j, err := beginJournal(tp.Dir, "up", featureName, func(j *Journal) {
+		j.Up = plan
+		j.Target = target
+	})
+	if err != nil {
+		t.Fatalf("beginJournal() error = %v", err)
+	}
+
+	repoPlan := plan.Repos[0]
+	os.MkdirAll(plan.TreesDir, 0755)
+	runGitCmd(t, tp.Repos[repoPlan.Repo].SourceDir, "worktree", "add", "-b", plan.BranchName, repoPlan.WorktreeDir)
+	j.record(StepCreateWorktree, repoPlan.Repo, repoPlan.WorktreeDir)
+	interrupt(t, j)
+	return plan
+}
+
+func TestPendingJournals(t *testing.T) {
+	tp := NewTestProject(t)
+	tp.InitRepo("repo1")
+
+	running, err := beginJournal(tp.Dir, "up", "running", func(*Journal) {})
+	if err != nil {
+		t.Fatalf("beginJournal() error = %v", err)
+	}
+	defer running.finish()
+	interruptedUp(t, tp, "killed", "")
+
+	pending, err := PendingJournals(tp.Dir)
+	if err != nil {
+		t.Fatalf("PendingJournals() error = %v", err)
+	}
+	if len(pending) != 1 || pending[0].Feature != "killed" || pending[0].Operation != "up" {
+		t.Fatalf("PendingJournals() = %+v, want only the killed up", pending)
+	}
+	if !pending[0].Completed(StepCreateWorktree, "repo1") || pending[0].Completed(StepAllocatePorts, "") {
+		t.Errorf("Done = %+v, want only repo1's worktree", pending[0].Done)
+	}
+}
+
+// TestPendingJournalOfThisProcess tests that a long-lived process sees its own
+// failed operations as pending once it stopped applying them
+func TestPendingJournalOfThisProcess(t *testing.T) {
+	tp := NewTestProject(t)
+
+	j, err := beginJournal(tp.Dir, "up", "failed", func(*Journal) {})
+	if err != nil {
+		t.Fatalf("beginJournal() error = %v", err)
+	}
+	if !j.Running() {
+		t.Error("a journal being applied by this process should be running")
+	}
+
+	j.release()
+	loaded, _ := LoadJournal(tp.Dir, "failed")
+	if loaded == nil || loaded.Running() {
+		t.Error("a journal this process stopped applying should not be running")
+	}
+
+	if err := loaded.claim(); err != nil {
+		t.Fatalf("claim() error = %v", err)
+	}
+	if !loaded.Running() || j.Running() {
+		t.Error("only the claimed run should be running")
+	}
+	loaded.finish()
+}
+
+func TestResumeInterruptedUp(t *testing.T) {
+	tp := NewTestProject(t)
+	tp.InitRepo("repo1")
+	tp.InitRepo("repo2")
+	interruptedUp(t, tp, "resumed", "main")
+
+	err := ResumeOperation(RecoverOptions{
+		ProjectDir:  tp.Dir,
+		Config:      tp.Config,
+		Progress:    &MockProgressReporter{},
+		FeatureName: "resumed",
+	})
+	if err != nil {
+		t.Fatalf("ResumeOperation() error = %v", err)
+	}
+
+	for _, name := range []string{"repo1", "repo2"} {
+		if !worktreeOnBranch(filepath.Join(tp.TreesDir, "resumed", name), "feature/resumed") {
+			t.Errorf("%s should have a worktree on feature/resumed", name)
+		}
+	}
+	if ports := featurePorts(tp.Dir, tp.Config, "resumed"); len(ports) == 0 {
+		t.Error("resuming should allocate the feature's ports")
+	}
+	if j, _ := LoadJournal(tp.Dir, "resumed"); j != nil {
+		t.Error("journal should be removed once the up finishes")
+	}
+
+	store, err := features.NewMetadataStore(tp.Dir)
+	if err != nil {
+		t.Fatalf("NewMetadataStore() error = %v", err)
+	}
+	if created := store.Get("resumed").Created; created == nil || created.Target != "main" {
+		t.Errorf("Created = %+v, want the target of the interrupted up", created)
+	}
+}
+
+func TestRollbackInterruptedUp(t *testing.T) {
+	tp := NewTestProject(t)
+	repo1 := tp.InitRepo("repo1")
+	tp.InitRepo("repo2")
+	plan := interruptedUp(t, tp, "undone", "")
+
+	err := RollbackOperation(RecoverOptions{
+		ProjectDir:  tp.Dir,
+		Config:      tp.Config,
+		Progress:    &MockProgressReporter{},
+		FeatureName: "undone",
+	})
+	if err != nil {
+		t.Fatalf("RollbackOperation() error = %v", err)
+	}
+
+	if tp.FeatureExists("undone") {
+		t.Error("trees directory should be removed")
+	}
+	if exists, _ := git.LocalBranchExists(repo1.SourceDir, plan.BranchName); exists {
+		t.Error("branch created by the interrupted up should be deleted")
+	}
+	if j, _ := LoadJournal(tp.Dir, "undone"); j != nil {
+		t.Error("journal should be removed once rolled back")
+	}
+
+	if err := RollbackOperation(RecoverOptions{ProjectDir: tp.Dir, Config: tp.Config, Progress: &MockProgressReporter{}, FeatureName: "undone"}); err == nil {
+		t.Error("RollbackOperation() should fail without an interrupted operation")
+	}
+}
+
+func TestRecoverInterruptedDown(t *testing.T) {
+	tp := NewTestProject(t)
+	tp.InitRepo("repo1")
+	tp.InitRepo("repo2")
+
+	_, err := Up(UpOptions{
+		FeatureName: "half-removed",
+		ProjectDir:  tp.Dir,
+		Config:      tp.Config,
+		Progress:    &MockProgressReporter{},
+		SkipRefresh: true,
+	})
+	if err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+
+	downOpts := DownOptions{
+		FeatureName: "half-removed",
+		ProjectDir:  tp.Dir,
+		Config:      tp.Config,
+		Progress:    &MockProgressReporter{},
+		Force:       true,
+	}
+	plan, err := PlanDown(downOpts)
+	if err != nil {
+		t.Fatalf("PlanDown() error = %v", err)
+	}
+
+	// Killed after removing repo1's worktree
+	j, err := beginJournal(tp.Dir, "down", "half-removed", func(j *Journal) { j.Down = plan })
+	if err != nil {
+		t.Fatalf("beginJournal() error = %v", err)
+	}
+	runGitCmd(t, tp.Repos["repo1"].SourceDir, "worktree", "remove", "--force", plan.Repos[0].WorktreeDir)
+	j.record(StepRemoveWorktree, "repo1", plan.Repos[0].WorktreeDir)
+	interrupt(t, j)
+
+	recoverOpts := RecoverOptions{
+		ProjectDir:  tp.Dir,
+		Config:      tp.Config,
+		Progress:    &MockProgressReporter{},
+		FeatureName: "half-removed",
+	}
+	if err := RollbackOperation(recoverOpts); err == nil || !strings.Contains(err.Error(), "ramp resume") {
+		t.Errorf("RollbackOperation() error = %v, want a pointer to ramp resume", err)
+	}
+
+	if err := ResumeOperation(recoverOpts); err != nil {
+		t.Fatalf("ResumeOperation() error = %v", err)
+	}
+	if tp.FeatureExists("half-removed") {
+		t.Error("resuming should finish removing the feature")
+	}
+	for _, name := range []string{"repo1", "repo2"} {
+		if exists, _ := git.LocalBranchExists(tp.Repos[name].SourceDir, "feature/half-removed"); exists {
+			t.Errorf("%s branch should be deleted", name)
+		}
+	}
+	if j, _ := LoadJournal(tp.Dir, "half-removed"); j != nil {
+		t.Error("journal should be removed once the down finishes")
+	}
+}
+
+// doctorKinds returns the issue kinds of a doctor result, with the repo or
+// feature each is about
+func doctorKinds(result *DoctorResult) []string {
+	kinds := []string{}
+	for _, issue := range result.Issues {
+		kinds = append(kinds, issue.Kind+" "+issue.Repo+" "+issue.Feature)
+	}
+	return kinds
+}
+
+func TestDoctor(t *testing.T) {
+	tp := NewTestProject(t)
+	tp.InitRepo("repo1")
+	tp.InitRepo("repo2")
+
+	for _, name := range []string{"kept", "deleted"} {
+		_, err := Up(UpOptions{
+			FeatureName: name,
+			ProjectDir:  tp.Dir,
+			Config:      tp.Config,
+			Progress:    &MockProgressReporter{},
+			SkipRefresh: true,
+			DisplayName: "Feature " + name,
+		})
+		if err != nil {
+			t.Fatalf("Up(%s) error = %v", name, err)
+		}
+	}
+
+	// Drift: a feature removed by hand, a stray directory in a feature and an
+	// empty feature directory
+	os.RemoveAll(filepath.Join(tp.TreesDir, "deleted"))
+	os.MkdirAll(filepath.Join(tp.TreesDir, "kept", "notes"), 0755)
+	os.MkdirAll(filepath.Join(tp.TreesDir, "empty"), 0755)
+
+	opts := DoctorOptions{
+		ProjectDir: tp.Dir,
+		Config:     tp.Config,
+		Progress:   &MockProgressReporter{},
+	}
+	result, err := Doctor(opts)
+	if err != nil {
+		t.Fatalf("Doctor() error = %v", err)
+	}
+
+	want := []string{
+		IssueMissingWorktree + " repo1 ",
+		IssueMissingWorktree + " repo2 ",
+		IssueUnregisteredDir + "  empty",
+		IssueUnregisteredDir + " notes kept",
+		IssueLeftoverBranch + " repo1 deleted",
+		IssueLeftoverBranch + " repo2 deleted",
+		IssueStalePorts + "  deleted",
+		IssueStaleMetadata + "  deleted",
+	}
+	if got := doctorKinds(result); !slices.Equal(got, want) {
+		t.Fatalf("Doctor() issues =\n%v\nwant\n%v", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if !tp.FeatureExists("kept") {
+		t.Fatal("Doctor() without Fix should not change anything")
+	}
+
+	opts.Fix = true
+	result, err = Doctor(opts)
+	if err != nil {
+		t.Fatalf("Doctor(Fix) error = %v", err)
+	}
+	if result.Unresolved() != 0 {
+		t.Errorf("Doctor(Fix) left issues: %+v", result.Issues)
+	}
+
+	opts.Fix = false
+	result, err = Doctor(opts)
+	if err != nil {
+		t.Fatalf("Doctor() error = %v", err)
+	}
+	if len(result.Issues) != 0 {
+		t.Errorf("Doctor() after fixing = %v, want no issues", doctorKinds(result))
+	}
+
+	if !tp.FeatureExists("kept") {
+		t.Error("fixing should leave the existing feature alone")
+	}
+	if _, err := os.Stat(filepath.Join(tp.TreesDir, "kept", "notes")); !os.IsNotExist(err) {
+		t.Error("the unregistered directory should be removed")
+	}
+	for _, repo := range []string{"repo1", "repo2"} {
+		if exists, _ := git.LocalBranchExists(tp.Repos[repo].SourceDir, "feature/deleted"); exists {
+			t.Errorf("%s: leftover branch should be deleted", repo)
+		}
+		if exists, _ := git.LocalBranchExists(tp.Repos[repo].SourceDir, "feature/kept"); !exists {
+			t.Errorf("%s: the existing feature's branch should be kept", repo)
+		}
+	}
+}
+
+// TestDoctorMovedProject tests that worktrees of a moved or symlinked project
+// are reconnected rather than removed
+func TestDoctorMovedProject(t *testing.T) {
+	tp := NewTestProject(t)
+	tp.InitRepo("repo1")
+
+	_, err := Up(UpOptions{
+		FeatureName: "work",
+		ProjectDir:  tp.Dir,
+		Config:      tp.Config,
+		Progress:    &MockProgressReporter{},
+		SkipRefresh: true,
+	})
+	if err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+	os.WriteFile(filepath.Join(tp.TreesDir, "work", "repo1", "wip.txt"), []byte("wip\n"), 0644)
+
+	// Reached through a symlink, the project is fine as it is
+	parent, _ := filepath.EvalSymlinks(t.TempDir())
+	link := filepath.Join(parent, "link")
+	if err := os.Symlink(tp.Dir, link); err != nil {
+		t.Fatalf("failed to link the project: %v", err)
+	}
+	result, err := Doctor(DoctorOptions{ProjectDir: link, Config: tp.Config, Progress: &MockProgressReporter{}, Offline: true})
+	if err != nil || len(result.Issues) != 0 {
+		t.Fatalf("Doctor() through a symlink = %v, %v; want no issues", doctorKinds(result), err)
+	}
+
+	moved := filepath.Join(parent, "moved")
+	if err := os.Rename(tp.Dir, moved); err != nil {
+		t.Fatalf("failed to move the project: %v", err)
+	}
+	opts := DoctorOptions{ProjectDir: moved, Config: tp.Config, Progress: &MockProgressReporter{}, Offline: true}
+	result, err = Doctor(opts)
+	if err != nil {
+		t.Fatalf("Doctor() error = %v", err)
+	}
+	if got := doctorKinds(result); !slices.Equal(got, []string{IssueMovedWorktree + " repo1 work"}) {
+		t.Fatalf("Doctor() issues = %v, want only the moved worktree", got)
+	}
+
+	opts.Fix = true
+	if result, err = Doctor(opts); err != nil || result.Unresolved() != 0 {
+		t.Fatalf("Doctor(Fix) = %+v, %v", result, err)
+	}
+	worktreeDir := filepath.Join(moved, "trees", "work", "repo1")
+	if content, _ := os.ReadFile(filepath.Join(worktreeDir, "wip.txt")); string(content) != "wip\n" {
+		t.Error("the worktree's uncommitted files should be kept")
+	}
+	if branch, err := git.GetWorktreeBranch(worktreeDir); err != nil || branch != "feature/work" {
+		t.Errorf("repaired worktree branch = %q, %v; want feature/work", branch, err)
+	}
+
+	opts.Fix = false
+	if result, _ := Doctor(opts); len(result.Issues) != 0 {
+		t.Errorf("Doctor() after repairing = %v, want no issues", doctorKinds(result))
+	}
+}
+
+// TestDoctorKeepsUnmergedWork tests that branches with unmerged commits and
+// directories with files are reported but not removed
+func TestDoctorKeepsUnmergedWork(t *testing.T) {
+	tp := NewTestProject(t)
+	repo := tp.InitRepo("repo1")
+
+	_, err := Up(UpOptions{
+		FeatureName: "gone",
+		ProjectDir:  tp.Dir,
+		Config:      tp.Config,
+		Progress:    &MockProgressReporter{},
+		SkipRefresh: true,
+	})
+	if err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+	worktreeDir := filepath.Join(tp.TreesDir, "gone", "repo1")
+	os.WriteFile(filepath.Join(worktreeDir, "work.txt"), []byte("work\n"), 0644)
+	runGitCmd(t, worktreeDir, "add", "work.txt")
+	runGitCmd(t, worktreeDir, "commit", "-m", "unmerged work")
+	os.RemoveAll(filepath.Join(tp.TreesDir, "gone"))
+	os.MkdirAll(filepath.Join(tp.TreesDir, "stray", "notes"), 0755)
+	os.WriteFile(filepath.Join(tp.TreesDir, "stray", "notes", "todo.txt"), []byte("todo\n"), 0644)
+
+	result, err := Doctor(DoctorOptions{ProjectDir: tp.Dir, Config: tp.Config, Progress: &MockProgressReporter{}, Offline: true, Fix: true})
+	if err != nil {
+		t.Fatalf("Doctor(Fix) error = %v", err)
+	}
+
+	unresolved := []string{}
+	for _, issue := range result.Issues {
+		if !issue.Fixed {
+			unresolved = append(unresolved, issue.Kind+" "+issue.Feature)
+		}
+	}
+	if want := []string{IssueUnregisteredDir + " stray", IssueLeftoverBranch + " gone"}; !slices.Equal(unresolved, want) {
+		t.Errorf("unresolved issues = %v, want %v", unresolved, want)
+	}
+	if exists, _ := git.LocalBranchExists(repo.SourceDir, "feature/gone"); !exists {
+		t.Error("the unmerged branch should be kept")
+	}
+	if _, err := os.Stat(filepath.Join(tp.TreesDir, "stray", "notes", "todo.txt")); err != nil {
+		t.Error("the directory with files should be kept")
+	}
+}
+
+func TestDoctorUnreachableRemote(t *testing.T) {
+	tp := NewTestProject(t)
+	tp.InitRepo("repo1")
+	os.RemoveAll(tp.Repos["repo1"].RemoteDir)
+
+	opts := DoctorOptions{ProjectDir: tp.Dir, Config: tp.Config, Progress: &MockProgressReporter{}}
+	result, err := Doctor(opts)
+	if err != nil {
+		t.Fatalf("Doctor() error = %v", err)
+	}
+	if got := doctorKinds(result); !slices.Equal(got, []string{IssueUnreachableRemote + " repo1 "}) {
+		t.Errorf("Doctor() issues = %v, want repo1's unreachable remote", got)
+	}
+
+	opts.Offline = true
+	if result, _ := Doctor(opts); len(result.Issues) != 0 {
+		t.Errorf("Doctor(Offline) issues = %v, want none", doctorKinds(result))
+	}
+}
+
+func TestMoveFeature(t *testing.T) {
+	tp := NewTestProject(t)
+	app := tp.InitRepo("app")
+	api := tp.InitRepo("api")
+
+	tp.Config.Repos[0].EnvFiles = []config.EnvFile{
+		{Source: ".env.example", Dest: ".env", Replace: map[string]string{"NAME": "${RAMP_WORKTREE_NAME}"}},
+	}
+	if err := os.WriteFile(filepath.Join(app.SourceDir, ".env.example"), []byte("NAME=x\n"), 0644); err != nil {
+		t.Fatalf("failed to write .env.example: %v", err)
+	}
+
+	_, err := Up(UpOptions{
+		FeatureName: "old-name",
+		ProjectDir:  tp.Dir,
+		Config:      tp.Config,
+		Progress:    &MockProgressReporter{},
+		SkipRefresh: true,
+		DisplayName: "Old Name",
+	})
+	if err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+
+	// Uncommitted work and a published branch move along
+	oldAPIDir := filepath.Join(tp.TreesDir, "old-name", "api")
+	os.WriteFile(filepath.Join(oldAPIDir, "wip.txt"), []byte("work in progress"), 0644)
+	runGitCmd(t, oldAPIDir, "push", "-u", "origin", "feature/old-name")
+	ports := featurePorts(tp.Dir, tp.Config, "old-name")
+
+	result, err := MoveFeature(MoveOptions{
+		FeatureName:  "old-name",
+		NewName:      "new-name",
+		ProjectDir:   tp.Dir,
+		Config:       tp.Config,
+		Progress:     &MockProgressReporter{},
+		RenameRemote: true,
+	})
+	if err != nil {
+		t.Fatalf("MoveFeature() error = %v", err)
+	}
+
+	if tp.FeatureExists("old-name") || !tp.FeatureExists("new-name") {
+		t.Fatal("trees/old-name should have moved to trees/new-name")
+	}
+	if content, _ := os.ReadFile(filepath.Join(tp.TreesDir, "new-name", "api", "wip.txt")); string(content) != "work in progress" {
+		t.Error("uncommitted changes should move with the worktree")
+	}
+	for _, repo := range []*TestRepo{app, api} {
+		if exists, _ := git.LocalBranchExists(repo.SourceDir, "feature/old-name"); exists {
+			t.Errorf("%s: feature/old-name should be renamed", repo.Name)
+		}
+		if branch, _ := git.GetWorktreeBranch(filepath.Join(tp.TreesDir, "new-name", repo.Name)); branch != "feature/new-name" {
+			t.Errorf("%s: worktree is on %q, want feature/new-name", repo.Name, branch)
+		}
+	}
+	if len(result.Repos) != 2 || !result.Repos[0].RemoteRenamed || result.Repos[1].RemoteRenamed {
+		t.Errorf("Repos = %+v, want only api renamed on origin", result.Repos)
+	}
+	if exists, _ := git.RemoteBranchExists(api.SourceDir, "feature/old-name"); exists {
+		t.Error("feature/old-name should be deleted from origin")
+	}
+	runGitCmd(t, api.RemoteDir, "rev-parse", "--verify", "feature/new-name")
+
+	if moved := featurePorts(tp.Dir, tp.Config, "new-name"); !slices.Equal(moved, ports) || len(ports) == 0 {
+		t.Errorf("ports = %v, want %v moved to new-name", moved, ports)
+	}
+	if LoadDisplayName(tp.Dir, "new-name") != "Old Name" || LoadDisplayName(tp.Dir, "old-name") != "" {
+		t.Error("metadata should move to new-name")
+	}
+	if content, _ := os.ReadFile(filepath.Join(tp.TreesDir, "new-name", "app", ".env")); string(content) != "NAME=new-name\n" {
+		t.Errorf(".env = %q, want it re-rendered with the new name", content)
+	}
+
+	// The worktrees are still registered where they now are
+	for _, repo := range []*TestRepo{app, api} {
+		if !git.WorktreeRegistered(repo.SourceDir, filepath.Join(tp.TreesDir, "new-name", repo.Name)) {
+			t.Errorf("%s: moved worktree should be registered", repo.Name)
+		}
+	}
+}
+
+// TestMoveFeatureKeepsOtherBranches tests that only the feature's own
+// branches are renamed, not ones that merely end with its name
+func TestMoveFeatureKeepsOtherBranches(t *testing.T) {
+	tp := NewTestProject(t)
+	app := tp.InitRepo("app")
+	api := tp.InitRepo("api")
+
+	_, err := Up(UpOptions{
+		FeatureName: "api",
+		ProjectDir:  tp.Dir,
+		Config:      tp.Config,
+		Progress:    &MockProgressReporter{},
+		SkipRefresh: true,
+	})
+	if err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+	runGitCmd(t, filepath.Join(tp.TreesDir, "api", "app"), "checkout", "-b", "fix/old-api")
+
+	progress := &MockProgressReporter{}
+	result, err := MoveFeature(MoveOptions{
+		FeatureName: "api",
+		NewName:     "gateway",
+		ProjectDir:  tp.Dir,
+		Config:      tp.Config,
+		Progress:    progress,
+	})
+	if err != nil {
+		t.Fatalf("MoveFeature() error = %v", err)
+	}
+
+	if branch, _ := git.GetWorktreeBranch(filepath.Join(tp.TreesDir, "gateway", "app")); branch != "fix/old-api" {
+		t.Errorf("app: worktree is on %q, want fix/old-api left alone", branch)
+	}
+	if exists, _ := git.LocalBranchExists(app.SourceDir, "fix/old-gateway"); exists {
+		t.Error("app: fix/old-api should not be renamed")
+	}
+	if branch, _ := git.GetWorktreeBranch(filepath.Join(tp.TreesDir, "gateway", "api")); branch != "feature/gateway" {
+		t.Errorf("api: worktree is on %q, want feature/gateway", branch)
+	}
+	if exists, _ := git.LocalBranchExists(api.SourceDir, "feature/api"); exists {
+		t.Error("api: feature/api should be renamed")
+	}
+	for _, r := range result.Repos {
+		if r.Repo == "app" && r.OldBranch != r.Branch {
+			t.Errorf("app result = %+v, want the branch unchanged", r)
+		}
+	}
+	if !slices.ContainsFunc(progress.Messages, func(m string) bool { return strings.HasPrefix(m, "warning: app: fix/old-api") }) {
+		t.Errorf("messages = %v, want a warning about fix/old-api", progress.Messages)
+	}
+}
+
+// TestMoveFeatureRefusesSubmodules tests that a feature whose worktrees have
+// submodules is refused before anything is moved
+func TestMoveFeatureRefusesSubmodules(t *testing.T) {
+	tp := NewTestProject(t)
+	repo := tp.InitRepo("app")
+	addSubmodule(t, repo)
+	tp.Config.Repos[0].Submodules = "true"
+
+	_, err := Up(UpOptions{
+		FeatureName: "with-lib",
+		ProjectDir:  tp.Dir,
+		Config:      tp.Config,
+		Progress:    &MockProgressReporter{},
+		SkipRefresh: true,
+	})
+	if err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+
+	_, err = MoveFeature(MoveOptions{
+		FeatureName: "with-lib",
+		NewName:     "renamed",
+		ProjectDir:  tp.Dir,
+		Config:      tp.Config,
+		Progress:    &MockProgressReporter{},
+	})
+	if err == nil || !strings.Contains(err.Error(), "submodules") {
+		t.Fatalf("MoveFeature() error = %v, want a submodules error", err)
+	}
+
+	if !tp.FeatureExists("with-lib") || tp.FeatureExists("renamed") {
+		t.Error("the feature should be left where it was")
+	}
+	if branch, _ := git.GetWorktreeBranch(filepath.Join(tp.TreesDir, "with-lib", "app")); branch != "feature/with-lib" {
+		t.Errorf("worktree is on %q, want feature/with-lib", branch)
+	}
+}
+
+func TestMoveFeatureRollsBack(t *testing.T) {
+	tp := NewTestProject(t)
+	app := tp.InitRepo("app")
+	tp.InitRepo("api")
+
+	_, err := Up(UpOptions{
+		FeatureName: "old-name",
+		ProjectDir:  tp.Dir,
+		Config:      tp.Config,
+		Progress:    &MockProgressReporter{},
+		SkipRefresh: true,
+	})
+	if err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+	ports := featurePorts(tp.Dir, tp.Config, "old-name")
+
+	// A locked worktree can't be moved, so the second repo fails after the first moved
+	runGitCmd(t, app.SourceDir, "worktree", "lock", filepath.Join(tp.TreesDir, "old-name", "app"))
+
+	_, err = MoveFeature(MoveOptions{
+		FeatureName: "old-name",
+		NewName:     "new-name",
+		ProjectDir:  tp.Dir,
+		Config:      tp.Config,
+		Progress:    &MockProgressReporter{},
+	})
+	if err == nil {
+		t.Fatal("MoveFeature() should fail when a worktree can't be moved")
+	}
+
+	if !tp.FeatureExists("old-name") || tp.FeatureExists("new-name") {
+		t.Fatal("the feature should keep its old name")
+	}
+	for _, repo := range []string{"app", "api"} {
+		dir := filepath.Join(tp.TreesDir, "old-name", repo)
+		if branch, _ := git.GetWorktreeBranch(dir); branch != "feature/old-name" {
+			t.Errorf("%s: worktree is on %q, want feature/old-name", repo, branch)
+		}
+	}
+	if restored := featurePorts(tp.Dir, tp.Config, "old-name"); !slices.Equal(restored, ports) {
+		t.Errorf("ports = %v, want %v", restored, ports)
+	}
+
+	// Names already in use are refused before anything changes
+	_, err = MoveFeature(MoveOptions{
+		FeatureName: "old-name",
+		NewName:     "old-name",
+		ProjectDir:  tp.Dir,
+		Config:      tp.Config,
+		Progress:    &MockProgressReporter{},
+	})
+	if err == nil {
+		t.Error("MoveFeature() onto the same name should fail")
+	}
+}
+
+func TestCloneFeature(t *testing.T) {
+	tp := NewTestProject(t)
+	app := tp.InitRepo("app")
+	tp.InitRepo("api")
+
+	_, err := Up(UpOptions{
+		FeatureName: "original",
+		ProjectDir:  tp.Dir,
+		Config:      tp.Config,
+		Progress:    &MockProgressReporter{},
+		SkipRefresh: true,
+	})
+	if err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+
+	// A commit, a staged and an unstaged change, and an untracked file
+	originalDir := filepath.Join(tp.TreesDir, "original", "app")
+	os.WriteFile(filepath.Join(originalDir, "committed.txt"), []byte("committed\n"), 0644)
+	runGitCmd(t, originalDir, "add", "committed.txt")
+	runGitCmd(t, originalDir, "commit", "-m", "feature work")
+	os.WriteFile(filepath.Join(originalDir, "committed.txt"), []byte("committed\nstaged\n"), 0644)
+	runGitCmd(t, originalDir, "add", "committed.txt")
+	os.WriteFile(filepath.Join(originalDir, "committed.txt"), []byte("committed\nstaged\nunstaged\n"), 0644)
+	os.MkdirAll(filepath.Join(originalDir, "notes"), 0755)
+	os.WriteFile(filepath.Join(originalDir, "notes", "idea.md"), []byte("idea\n"), 0644)
+
+	result, err := CloneFeature(CloneFeatureOptions{
+		SourceFeature: "original",
+		FeatureName:   "copy",
+		ProjectDir:    tp.Dir,
+		Config:        tp.Config,
+		Progress:      &MockProgressReporter{},
+		Uncommitted:   true,
+	})
+	if err != nil {
+		t.Fatalf("CloneFeature() error = %v", err)
+	}
+
+	copyDir := filepath.Join(tp.TreesDir, "copy", "app")
+	if branch, _ := git.GetWorktreeBranch(copyDir); branch != "feature/copy" {
+		t.Errorf("branch = %q, want feature/copy", branch)
+	}
+	head, _ := git.GetShortCommit(copyDir)
+	if want, _ := git.GetShortCommit(originalDir); head != want {
+		t.Errorf("copy HEAD = %s, want the original's %s", head, want)
+	}
+	if content, _ := os.ReadFile(filepath.Join(copyDir, "committed.txt")); string(content) != "committed\nstaged\nunstaged\n" {
+		t.Errorf("committed.txt = %q, want the staged and unstaged changes", content)
+	}
+	if staged, _ := git.HasStagedChanges(copyDir); !staged {
+		t.Error("staged changes should stay staged")
+	}
+	if content, _ := os.ReadFile(filepath.Join(copyDir, "notes", "idea.md")); string(content) != "idea\n" {
+		t.Error("untracked files should be copied")
+	}
+	if !slices.Equal(result.CarriedOver, []string{"app"}) {
+		t.Errorf("CarriedOver = %v, want [app]", result.CarriedOver)
+	}
+
+	// The source is left as it was, and the clone gets its own ports
+	if content, _ := os.ReadFile(filepath.Join(originalDir, "committed.txt")); string(content) != "committed\nstaged\nunstaged\n" {
+		t.Error("the original worktree should be untouched")
+	}
+	if len(result.AllocatedPorts) == 0 || slices.Equal(result.AllocatedPorts, featurePorts(tp.Dir, tp.Config, "original")) {
+		t.Errorf("AllocatedPorts = %v, want fresh ports", result.AllocatedPorts)
+	}
+	if exists, _ := git.LocalBranchExists(app.SourceDir, "feature/original"); !exists {
+		t.Error("the original branch should be kept")
+	}
+}
+
+// TestCloneFeatureCopiesBeforeSetup tests that the setup script of a clone
+// already sees the uncommitted changes carried over from the source
+func TestCloneFeatureCopiesBeforeSetup(t *testing.T) {
+	tp := NewTestProject(t)
+	tp.InitRepo("app")
+
+	_, err := Up(UpOptions{
+		FeatureName: "original",
+		ProjectDir:  tp.Dir,
+		Config:      tp.Config,
+		Progress:    &MockProgressReporter{},
+		SkipRefresh: true,
+	})
+	if err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+	originalDir := filepath.Join(tp.TreesDir, "original", "app")
+	os.WriteFile(filepath.Join(originalDir, "idea.md"), []byte("idea\n"), 0644)
+
+	scriptPath := filepath.Join(tp.Dir, ".ramp", "scripts", "setup.sh")
+	os.MkdirAll(filepath.Dir(scriptPath), 0755)
+	script := "#!/bin/bash\ncp \"$RAMP_TREES_DIR/app/idea.md\" \"$RAMP_PROJECT_DIR/.ramp/setup-saw.txt\"\n"
+	if err := os.WriteFile(scriptPath, []byte(script), 0755); err != nil {
+		t.Fatalf("failed to write setup script: %v", err)
+	}
+	tp.Config.Setup = "scripts/setup.sh"
+
+	result, err := CloneFeature(CloneFeatureOptions{
+		SourceFeature: "original",
+		FeatureName:   "copy",
+		ProjectDir:    tp.Dir,
+		Config:        tp.Config,
+		Progress:      &MockProgressReporter{},
+		Uncommitted:   true,
+	})
+	if err != nil {
+		t.Fatalf("CloneFeature() error = %v", err)
+	}
+
+	if content, err := os.ReadFile(filepath.Join(tp.Dir, ".ramp", "setup-saw.txt")); err != nil || string(content) != "idea\n" {
+		t.Errorf("setup script saw %q, %v; want the copied idea.md", content, err)
+	}
+	if !slices.Equal(result.CarriedOver, []string{"app"}) {
+		t.Errorf("CarriedOver = %v, want [app]", result.CarriedOver)
+	}
+}
+
+func TestArchiveAndRestoreFeature(t *testing.T) {
+	tp := NewTestProject(t)
+	app := tp.InitRepo("app")
+	tp.InitRepo("api")
+
+	_, err := Up(UpOptions{
+		FeatureName: "shelved",
+		ProjectDir:  tp.Dir,
+		Config:      tp.Config,
+		Progress:    &MockProgressReporter{},
+		DisplayName: "Shelved Work",
+		SkipRefresh: true,
+	})
+	if err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+	archivedPorts := featurePorts(tp.Dir, tp.Config, "shelved")
+
+	// An unpushed commit, a staged and an unstaged change, and an untracked file
+	appDir := filepath.Join(tp.TreesDir, "shelved", "app")
+	os.WriteFile(filepath.Join(appDir, "work.txt"), []byte("committed\n"), 0644)
+	runGitCmd(t, appDir, "add", "work.txt")
+	runGitCmd(t, appDir, "commit", "-m", "unpushed work")
+	os.WriteFile(filepath.Join(appDir, "work.txt"), []byte("committed\nstaged\n"), 0644)
+	runGitCmd(t, appDir, "add", "work.txt")
+	os.WriteFile(filepath.Join(appDir, "work.txt"), []byte("committed\nstaged\nunstaged\n"), 0644)
+	os.WriteFile(filepath.Join(appDir, "untracked.txt"), []byte("new\n"), 0644)
+	commit, _ := git.GetShortCommit(appDir)
+
+	result, err := ArchiveFeature(ArchiveOptions{
+		FeatureName: "shelved",
+		ProjectDir:  tp.Dir,
+		Config:      tp.Config,
+		Progress:    &MockProgressReporter{},
+	})
+	if err != nil {
+		t.Fatalf("ArchiveFeature() error = %v", err)
+	}
+
+	if tp.FeatureExists("shelved") {
+		t.Error("the feature's worktrees should be removed")
+	}
+	if exists, _ := git.LocalBranchExists(app.SourceDir, "feature/shelved"); exists {
+		t.Error("the feature's branch should be deleted")
+	}
+	if len(featurePorts(tp.Dir, tp.Config, "shelved")) != 0 {
+		t.Error("the feature's ports should be released")
+	}
+	if result.Archive.DisplayName != "Shelved Work" || !slices.Equal(result.Archive.Ports, archivedPorts) {
+		t.Errorf("Archive = %+v, want the display name and ports %v", result.Archive, archivedPorts)
+	}
+	if result.Archive.Command != `ramp up shelved --name "Shelved Work"` {
+		t.Errorf("Command = %q", result.Archive.Command)
+	}
+	archives, err := ListArchives(tp.Dir)
+	if err != nil || len(archives) != 1 || archives[0].Feature != "shelved" {
+		t.Fatalf("ListArchives() = %v, %v; want the shelved feature", archives, err)
+	}
+
+	restored, err := RestoreFeature(RestoreOptions{
+		FeatureName: "shelved",
+		ProjectDir:  tp.Dir,
+		Config:      tp.Config,
+		Progress:    &MockProgressReporter{},
+	})
+	if err != nil {
+		t.Fatalf("RestoreFeature() error = %v", err)
+	}
+
+	if head, _ := git.GetShortCommit(appDir); head != commit {
+		t.Errorf("restored HEAD = %s, want the archived %s", head, commit)
+	}
+	if content, _ := os.ReadFile(filepath.Join(appDir, "work.txt")); string(content) != "committed\nstaged\nunstaged\n" {
+		t.Errorf("work.txt = %q, want the staged and unstaged changes", content)
+	}
+	if staged, _ := git.HasStagedChanges(appDir); !staged {
+		t.Error("staged changes should be staged again")
+	}
+	if content, _ := os.ReadFile(filepath.Join(appDir, "untracked.txt")); string(content) != "new\n" {
+		t.Error("untracked files should be restored")
+	}
+	if !slices.Equal(restored.Restored, []string{"app"}) || !slices.Equal(restored.AllocatedPorts, archivedPorts) || restored.PortsChanged {
+		t.Errorf("RestoreResult = %+v, want app's changes and the archived ports %v", restored, archivedPorts)
+	}
+	if name := LoadDisplayName(tp.Dir, "shelved"); name != "Shelved Work" {
+		t.Errorf("display name = %q, want it kept", name)
+	}
+
+	// The archive and its refs are gone
+	if a, _ := LoadArchive(tp.Dir, "shelved"); a != nil {
+		t.Error("the archive should be removed after restoring")
+	}
+	if _, err := git.ResolveCommit(app.SourceDir, "refs/ramp/archive/shelved/head"); err == nil {
+		t.Error("the archive refs should be deleted after restoring")
+	}
+}
+
+// TestArchiveFeatureUsesExactBranches tests that archiving takes the prefix
+// ramp up recorded and refuses branches that only end with the feature's name
+func TestArchiveFeatureUsesExactBranches(t *testing.T) {
+	tp := NewTestProject(t)
+	app := tp.InitRepo("app")
+	tp.InitRepo("api")
+
+	_, err := Up(UpOptions{
+		FeatureName: "api",
+		ProjectDir:  tp.Dir,
+		Config:      tp.Config,
+		Progress:    &MockProgressReporter{},
+		Prefix:      "team/",
+		SkipRefresh: true,
+	})
+	if err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+
+	appDir := filepath.Join(tp.TreesDir, "api", "app")
+	runGitCmd(t, appDir, "checkout", "-b", "fix/old-api")
+	_, err = ArchiveFeature(ArchiveOptions{
+		FeatureName: "api",
+		ProjectDir:  tp.Dir,
+		Config:      tp.Config,
+		Progress:    &MockProgressReporter{},
+	})
+	if err == nil || !strings.Contains(err.Error(), "fix/old-api") {
+		t.Fatalf("ArchiveFeature() error = %v, want fix/old-api refused", err)
+	}
+	if a, _ := LoadArchive(tp.Dir, "api"); a != nil || !tp.FeatureExists("api") {
+		t.Fatal("nothing should be archived or removed")
+	}
+
+	runGitCmd(t, appDir, "checkout", "team/api")
+	result, err := ArchiveFeature(ArchiveOptions{
+		FeatureName: "api",
+		ProjectDir:  tp.Dir,
+		Config:      tp.Config,
+		Progress:    &MockProgressReporter{},
+	})
+	if err != nil {
+		t.Fatalf("ArchiveFeature() error = %v", err)
+	}
+	if result.Archive.Prefix != "team/" || result.Archive.Command != "ramp up api --prefix team/" {
+		t.Errorf("Archive = %+v, want the recorded prefix team/", result.Archive)
+	}
+	for _, archived := range result.Archive.Repos {
+		if archived.Branch != "team/api" {
+			t.Errorf("%s: archived branch %q, want team/api", archived.Repo, archived.Branch)
+		}
+	}
+
+	_, err = RestoreFeature(RestoreOptions{
+		FeatureName: "api",
+		ProjectDir:  tp.Dir,
+		Config:      tp.Config,
+		Progress:    &MockProgressReporter{},
+	})
+	if err != nil {
+		t.Fatalf("RestoreFeature() error = %v", err)
+	}
+	if branch, _ := git.GetWorktreeBranch(appDir); branch != "team/api" {
+		t.Errorf("restored worktree is on %q, want team/api", branch)
+	}
+	if exists, _ := git.LocalBranchExists(app.SourceDir, "feature/api"); exists {
+		t.Error("restore should not create feature/api")
+	}
+}
+
+func TestPruneArchivesFeatures(t *testing.T) {
+	tp := NewTestProject(t)
+	tp.InitRepo("app")
+
+	_, err := Up(UpOptions{
+		FeatureName: "merged",
+		ProjectDir:  tp.Dir,
+		Config:      tp.Config,
+		Progress:    &MockProgressReporter{},
+		SkipRefresh: true,
+	})
+	if err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+
+	opts := PruneOptions{
+		ProjectDir: tp.Dir,
+		Config:     tp.Config,
+		Progress:   &MockProgressReporter{},
+		Features:   []string{"merged"},
+		Archive:    true,
+	}
+	plan := PlanPrune(opts)
+	if len(plan.Steps) == 0 || plan.Steps[0].Kind != StepArchive {
+		t.Fatalf("plan steps = %+v, want an archive step first", plan.Steps)
+	}
+
+	result := ApplyPrune(opts, plan)
+	if !slices.Equal(result.Pruned, []string{"merged"}) {
+		t.Fatalf("Pruned = %v, failed = %v", result.Pruned, result.Failed)
+	}
+	if tp.FeatureExists("merged") {
+		t.Error("the feature should be removed")
+	}
+	if a, _ := LoadArchive(tp.Dir, "merged"); a == nil {
+		t.Error("the feature should be archived")
+	}
+}
+
+// TestFeatureMetadata tests creation info, notes, tags and links
+func TestFeatureMetadata(t *testing.T) {
+	tp := NewTestProject(t)
+	app := tp.InitRepo("app")
+
+	_, err := Up(UpOptions{
+		FeatureName: "billing",
+		ProjectDir:  tp.Dir,
+		Config:      tp.Config,
+		Progress:    &MockProgressReporter{},
+		SkipRefresh: true,
+	})
+	if err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+
+	details, err := GetFeatureDetails(tp.Dir, tp.Config, "billing")
+	if err != nil {
+		t.Fatalf("GetFeatureDetails() error = %v", err)
+	}
+	head, _ := git.ResolveCommit(app.SourceDir, "HEAD")
+	if details.Creation == nil {
+		t.Fatal("creation info should be recorded")
+	}
+	if details.Creation.Prefix != "feature/" || details.Creation.BaseCommits["app"] != head || details.Creation.By == "" {
+		t.Errorf("Creation = %+v, want prefix feature/, base commit %s and a creator", details.Creation, head)
+	}
+	if !details.Created.Equal(details.Creation.At) || details.Branches["app"] != "feature/billing" {
+		t.Errorf("details = %+v, want the recorded creation time and branch feature/billing", details)
+	}
+
+	err = AnnotateFeature(AnnotateOptions{
+		FeatureName: "billing",
+		ProjectDir:  tp.Dir,
+		Note:        "waiting on review",
+		AddTags:     []string{"Urgent", "payments"},
+		Links:       map[string]string{"ticket": "https://tracker.example.com/PROJ-42"},
+	})
+	if err != nil {
+		t.Fatalf("AnnotateFeature() error = %v", err)
+	}
+
+	// Nothing is saved when any change is invalid
+	invalid := []AnnotateOptions{
+		{AddTags: []string{"two words"}},
+		{Links: map[string]string{"pr": "not a url"}},
+		{Note: "dropped", Links: map[string]string{"bad label": "https://example.com"}},
+	}
+	for _, opts := range invalid {
+		opts.FeatureName, opts.ProjectDir = "billing", tp.Dir
+		if err := AnnotateFeature(opts); err == nil {
+			t.Errorf("AnnotateFeature(%+v) should fail", opts)
+		}
+	}
+
+	err = AnnotateFeature(AnnotateOptions{
+		FeatureName: "billing",
+		ProjectDir:  tp.Dir,
+		RemoveTags:  []string{"urgent"},
+		Links:       map[string]string{"pr": "https://github.com/org/app/pull/7"},
+	})
+	if err != nil {
+		t.Fatalf("AnnotateFeature() error = %v", err)
+	}
+
+	details, _ = GetFeatureDetails(tp.Dir, tp.Config, "billing")
+	if len(details.Notes) != 1 || details.Notes[0].Text != "waiting on review" {
+		t.Errorf("Notes = %+v, want one note", details.Notes)
+	}
+	if !slices.Equal(details.Tags, []string{"payments"}) {
+		t.Errorf("Tags = %v, want [payments]", details.Tags)
+	}
+	if len(details.Links) != 2 || details.Links["pr"] != "https://github.com/org/app/pull/7" {
+		t.Errorf("Links = %v, want the ticket and pr links", details.Links)
+	}
+
+	if err := AnnotateFeature(AnnotateOptions{FeatureName: "missing", ProjectDir: tp.Dir, Note: "x"}); err == nil {
+		t.Error("AnnotateFeature() should fail for a missing feature")
+	}
+
+	// Notes, tags and links survive archiving and restoring
+	_, err = ArchiveFeature(ArchiveOptions{FeatureName: "billing", ProjectDir: tp.Dir, Config: tp.Config, Progress: &MockProgressReporter{}})
+	if err != nil {
+		t.Fatalf("ArchiveFeature() error = %v", err)
+	}
+	_, err = RestoreFeature(RestoreOptions{FeatureName: "billing", ProjectDir: tp.Dir, Config: tp.Config, Progress: &MockProgressReporter{}})
+	if err != nil {
+		t.Fatalf("RestoreFeature() error = %v", err)
+	}
+	restored, _ := GetFeatureDetails(tp.Dir, tp.Config, "billing")
+	if !slices.Equal(restored.Tags, details.Tags) || len(restored.Notes) != 1 || len(restored.Links) != 2 {
+		t.Errorf("restored = %+v, want the archived notes, tags and links", restored)
+	}
+	if !restored.Created.Equal(details.Created) {
+		t.Errorf("Created = %v, want the original creation time %v", restored.Created, details.Created)
+	}
+}
+
+// TestGroupFeaturesByTag tests grouping features by their tags
+func TestGroupFeaturesByTag(t *testing.T) {
+	metadata := map[string]features.FeatureMetadata{
+		"a": {Tags: []string{"billing", "urgent"}},
+		"b": {Tags: []string{"billing"}},
+	}
+
+	groups := GroupFeaturesByTag([]string{"a", "b", "c"}, metadata)
+
+	if !slices.Equal(groups["billing"], []string{"a", "b"}) {
+		t.Errorf("billing = %v, want [a b]", groups["billing"])
+	}
+	if !slices.Equal(groups["urgent"], []string{"a"}) {
+		t.Errorf("urgent = %v, want [a]", groups["urgent"])
+	}
+	if !slices.Equal(groups[""], []string{"c"}) {
+		t.Errorf("untagged = %v, want [c]", groups[""])
+	}
+}
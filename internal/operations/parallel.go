@@ -0,0 +1,116 @@
+package operations
+
+import (
+	"sync"
+)
+
+// forEachParallel calls fn for each index in [0, n) using at most workers
+// goroutines and waits for every call to return. Callers collect results by
+// index so no locking is needed for per-item output.
+func forEachParallel(n, workers int, fn func(i int)) {
+	if workers <= 0 || workers > n {
+		workers = n
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				fn(i)
+			}
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+}
+
+// lockedProgress serializes calls to a ProgressReporter shared by workers.
+// Spinners and the test mock are not safe for concurrent use.
+type lockedProgress struct {
+	mu       sync.Mutex
+	progress ProgressReporter
+}
+
+func newLockedProgress(progress ProgressReporter) *lockedProgress {
+	return &lockedProgress{progress: progress}
+}
+
+func (p *lockedProgress) Start(message string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.progress.Start(message)
+}
+
+func (p *lockedProgress) Update(message string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.progress.Update(message)
+}
+
+func (p *lockedProgress) UpdateWithProgress(message string, percentage int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.progress.UpdateWithProgress(message, percentage)
+}
+
+func (p *lockedProgress) Stop() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.progress.Stop()
+}
+
+func (p *lockedProgress) Success(message string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.progress.Success(message)
+}
+
+func (p *lockedProgress) Error(message string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.progress.Error(message)
+}
+
+func (p *lockedProgress) Warning(message string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.progress.Warning(message)
+}
+
+func (p *lockedProgress) Info(message string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.progress.Info(message)
+}
+
+func (p *lockedProgress) Complete(message string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.progress.Complete(message)
+}
+
+// stepCounter reports per-item progress across a percentage range as workers
+// finish, so the percentage only ever moves forward.
+type stepCounter struct {
+	progress ProgressReporter
+	from, to int
+	total    int
+
+	mu   sync.Mutex
+	done int
+}
+
+// step records one finished item and reports message at the new percentage.
+func (c *stepCounter) step(message string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.done++
+	c.progress.UpdateWithProgress(message, c.from+c.done*(c.to-c.from)/c.total)
+}
@@ -0,0 +1,73 @@
+package operations
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestForEachParallel(t *testing.T) {
+	const n = 10
+	const workers = 3
+
+	var running, maxRunning atomic.Int32
+	seen := make([]bool, n)
+
+	forEachParallel(n, workers, func(i int) {
+		current := running.Add(1)
+		for {
+			max := maxRunning.Load()
+			if current <= max || maxRunning.CompareAndSwap(max, current) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		seen[i] = true
+		running.Add(-1)
+	})
+
+	for i, ok := range seen {
+		if !ok {
+			t.Errorf("index %d was not processed", i)
+		}
+	}
+	if got := maxRunning.Load(); got > workers {
+		t.Errorf("max concurrent calls = %d, want at most %d", got, workers)
+	}
+
+	// No items and a zero worker limit must not block
+	forEachParallel(0, 0, func(i int) { t.Error("fn should not be called") })
+}
+
+func TestStepCounterIsMonotonic(t *testing.T) {
+	recorder := &percentRecorder{}
+	steps := &stepCounter{progress: recorder, from: 0, to: 50, total: 7}
+
+	forEachParallel(7, 4, func(i int) { steps.step("done") })
+
+	if len(recorder.percentages) != 7 {
+		t.Fatalf("got %d updates, want 7", len(recorder.percentages))
+	}
+	for i := 1; i < len(recorder.percentages); i++ {
+		if recorder.percentages[i] < recorder.percentages[i-1] {
+			t.Errorf("percentages went backwards: %v", recorder.percentages)
+		}
+	}
+	if last := recorder.percentages[6]; last != 50 {
+		t.Errorf("final percentage = %d, want 50", last)
+	}
+}
+
+// percentRecorder records UpdateWithProgress percentages
+type percentRecorder struct {
+	MockProgressReporter
+	mu          sync.Mutex
+	percentages []int
+}
+
+func (r *percentRecorder) UpdateWithProgress(message string, pct int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.percentages = append(r.percentages, pct)
+}
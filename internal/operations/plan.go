@@ -0,0 +1,122 @@
+package operations
+
+import (
+	"fmt"
+
+	"ramp/internal/config"
+	"ramp/internal/git"
+	"ramp/internal/hooks"
+	"ramp/internal/ports"
+)
+
+// Kinds of plan steps.
+const (
+	StepClone          = "clone"
+	StepAddRemote      = "add-remote"
+	StepRefresh        = "refresh"
+	StepCreateWorktree = "create-worktree"
+	StepAllocatePorts  = "allocate-ports"
+	StepWriteEnvFile   = "write-env-file"
+	StepRunScript      = "run-script"
+	StepSaveMetadata   = "save-metadata"
+	StepRunHook        = "run-hook"
+	StepRemoveWorktree = "remove-worktree"
+	StepDeleteBranch   = "delete-branch"
+	StepReleasePorts   = "release-ports"
+	StepRemoveMetadata = "remove-metadata"
+	StepRemoveDir      = "remove-directory"
+	StepArchive        = "archive"
+)
+
+// PlanStep is one change an operation will make.
+type PlanStep struct {
+	Kind    string `json:"kind"`              // One of the Step constants
+	Feature string `json:"feature,omitempty"` // Set when a plan covers several features
+	Repo    string `json:"repo,omitempty"`    // Empty for project-wide steps
+	Detail  string `json:"detail"`
+}
+
+// Plan lists what an operation will do, in order, without doing any of it.
+// Each operation's plan type embeds it next to the fields its apply step
+// uses; ramp --dry-run and the UI preview endpoint show it as is.
+type Plan struct {
+	Operation string     `json:"operation"`
+	Target    string     `json:"target"` // Feature or project the operation acts on
+	Steps     []PlanStep `json:"steps"`
+	Warnings  []string   `json:"warnings,omitempty"`
+}
+
+// Planned is implemented by every operation's plan type.
+type Planned interface {
+	Summary() *Plan
+}
+
+// Summary returns the operation-independent part of a plan.
+func (p *Plan) Summary() *Plan {
+	return p
+}
+
+func newPlan(operation, target string) Plan {
+	return Plan{Operation: operation, Target: target, Steps: []PlanStep{}}
+}
+
+func (p *Plan) add(kind, repo, detail string) {
+	p.Steps = append(p.Steps, PlanStep{Kind: kind, Repo: repo, Detail: detail})
+}
+
+func (p *Plan) warn(message string) {
+	p.Warnings = append(p.Warnings, message)
+}
+
+// addHookSteps adds a step for each hook that runs on event.
+func (p *Plan) addHookSteps(projectDir string, event hooks.HookEvent) {
+	mergedCfg, err := config.LoadMergedConfig(projectDir)
+	if err != nil {
+		return
+	}
+	for _, hook := range hooks.ForEvent(mergedCfg.Hooks, event) {
+		p.add(StepRunHook, "", fmt.Sprintf("run %s hook %s", event, hook.Command))
+	}
+}
+
+// addCloneSteps adds a step for each repo Install would clone, returning their names.
+func (p *Plan) addCloneSteps(opts InstallOptions) []string {
+	repos := opts.Config.GetRepos()
+	toClone := []string{}
+	for _, name := range sortedRepoNames(repos) {
+		repo := repos[name]
+		repoDir := repo.GetRepoPath(opts.ProjectDir)
+		if git.IsGitRepo(repoDir) {
+			continue
+		}
+
+		cloneOpts := installCloneOptions(opts, repo)
+		p.add(StepClone, name, fmt.Sprintf("clone %s to %s%s", repo.GetGitURL(), repoDir, describeCloneOptions(cloneOpts)))
+		if repo.Upstream != "" {
+			p.add(StepAddRemote, name, fmt.Sprintf("add %s remote %s", git.UpstreamRemote, repo.Upstream))
+		}
+		toClone = append(toClone, name)
+	}
+	return toClone
+}
+
+// describePorts returns "port 3000" or "ports 3000-3002".
+func describePorts(allocated []int) string {
+	if len(allocated) == 1 {
+		return fmt.Sprintf("port %d", allocated[0])
+	}
+	return fmt.Sprintf("ports %d-%d", allocated[0], allocated[len(allocated)-1])
+}
+
+// featurePorts returns the ports allocated to a feature, if any.
+func featurePorts(projectDir string, cfg *config.Config, featureName string) []int {
+	if !cfg.HasPortConfig() {
+		return nil
+	}
+	portAllocations, err := ports.NewPortAllocations(projectDir, cfg.GetBasePort(), cfg.GetMaxPorts())
+	if err != nil {
+		return nil
+	}
+	allocated, _ := portAllocations.GetPorts(featureName)
+	return allocated
+}
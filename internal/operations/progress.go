@@ -49,3 +49,17 @@ type ConfirmationHandler interface {
 	// Confirm asks user for yes/no confirmation. Returns true if confirmed.
 	Confirm(message string) bool
 }
+
+// DiscardProgress is a ProgressReporter that reports nothing, for callers whose
+// output must stay machine-readable such as --json.
+type DiscardProgress struct{}
+
+func (DiscardProgress) Start(string)                   {}
+func (DiscardProgress) Update(string)                  {}
+func (DiscardProgress) UpdateWithProgress(string, int) {}
+func (DiscardProgress) Stop()                          {}
+func (DiscardProgress) Success(string)                 {}
+func (DiscardProgress) Error(string)                   {}
+func (DiscardProgress) Warning(string)                 {}
+func (DiscardProgress) Info(string)                    {}
+func (DiscardProgress) Complete(string)                {}
@@ -1,5 +1,7 @@
 package operations
 
+import "ramp/internal/ui"
+
 // WSBroadcaster is a function that broadcasts a message to all WebSocket clients.
 type WSBroadcaster func(msg interface{})
 
@@ -43,15 +45,15 @@ func NewWSProgressReporterWithCommand(operation, target, command string, broadca
 }
 
 func (r *WSProgressReporter) Start(message string) {
-	r.broadcast(WSMessage{Type: "progress", Operation: r.operation, Message: message, Target: r.target, Command: r.command})
+	r.broadcast(WSMessage{Type: "progress", Operation: r.operation, Message: ui.MaskSecrets(message), Target: r.target, Command: r.command})
 }
 
 func (r *WSProgressReporter) Update(message string) {
-	r.broadcast(WSMessage{Type: "progress", Operation: r.operation, Message: message, Target: r.target, Command: r.command})
+	r.broadcast(WSMessage{Type: "progress", Operation: r.operation, Message: ui.MaskSecrets(message), Target: r.target, Command: r.command})
 }
 
 func (r *WSProgressReporter) UpdateWithProgress(message string, percentage int) {
-	r.broadcast(WSMessage{Type: "progress", Operation: r.operation, Message: message, Percentage: percentage, Target: r.target, Command: r.command})
+	r.broadcast(WSMessage{Type: "progress", Operation: r.operation, Message: ui.MaskSecrets(message), Percentage: percentage, Target: r.target, Command: r.command})
 }
 
 func (r *WSProgressReporter) Stop() {
@@ -59,23 +61,23 @@ func (r *WSProgressReporter) Stop() {
 }
 
 func (r *WSProgressReporter) Success(message string) {
-	r.broadcast(WSMessage{Type: "progress", Operation: r.operation, Message: message, Target: r.target, Command: r.command})
+	r.broadcast(WSMessage{Type: "progress", Operation: r.operation, Message: ui.MaskSecrets(message), Target: r.target, Command: r.command})
 }
 
 func (r *WSProgressReporter) Error(message string) {
-	r.broadcast(WSMessage{Type: "error", Operation: r.operation, Message: message, Target: r.target, Command: r.command})
+	r.broadcast(WSMessage{Type: "error", Operation: r.operation, Message: ui.MaskSecrets(message), Target: r.target, Command: r.command})
 }
 
 func (r *WSProgressReporter) Warning(message string) {
-	r.broadcast(WSMessage{Type: "warning", Operation: r.operation, Message: message, Target: r.target, Command: r.command})
+	r.broadcast(WSMessage{Type: "warning", Operation: r.operation, Message: ui.MaskSecrets(message), Target: r.target, Command: r.command})
 }
 
 func (r *WSProgressReporter) Info(message string) {
-	r.broadcast(WSMessage{Type: "info", Operation: r.operation, Message: message, Target: r.target, Command: r.command})
+	r.broadcast(WSMessage{Type: "info", Operation: r.operation, Message: ui.MaskSecrets(message), Target: r.target, Command: r.command})
 }
 
 func (r *WSProgressReporter) Complete(message string) {
-	r.broadcast(WSMessage{Type: "complete", Operation: r.operation, Message: message, Percentage: 100, Target: r.target, Command: r.command})
+	r.broadcast(WSMessage{Type: "complete", Operation: r.operation, Message: ui.MaskSecrets(message), Percentage: 100, Target: r.target, Command: r.command})
 }
 
 // WSOutputStreamer implements OutputStreamer for WebSocket broadcasting.
@@ -105,9 +107,9 @@ func NewWSOutputStreamerWithContext(operation, target, command string, broadcast
 }
 
 func (s *WSOutputStreamer) WriteLine(line string) {
-	s.broadcast(WSMessage{Type: "output", Operation: s.operation, Message: line, Target: s.target, Command: s.command})
+	s.broadcast(WSMessage{Type: "output", Operation: s.operation, Message: ui.MaskSecrets(line), Target: s.target, Command: s.command})
 }
 
 func (s *WSOutputStreamer) WriteErrorLine(line string) {
-	s.broadcast(WSMessage{Type: "output", Operation: s.operation, Message: "[stderr] " + line, Target: s.target, Command: s.command})
+	s.broadcast(WSMessage{Type: "output", Operation: s.operation, Message: "[stderr] " + ui.MaskSecrets(line), Target: s.target, Command: s.command})
 }
@@ -0,0 +1,111 @@
+package operations
+
+import (
+	"fmt"
+
+	"ramp/internal/config"
+)
+
+// PruneOptions configures removing merged features.
+type PruneOptions struct {
+	// Required
+	ProjectDir string
+	Config     *config.Config
+	Progress   ProgressReporter
+	Features   []string // Features to remove, already known to be merged
+
+	// Optional
+	Archive bool // Archive each feature before removing it, so it can be restored
+}
+
+// PruneFailure is a feature that could not be planned or removed.
+type PruneFailure struct {
+	Feature string `json:"feature"`
+	Error   string `json:"error"`
+}
+
+// PrunePlan is what removing a set of merged features will do.
+type PrunePlan struct {
+	Plan
+	Features []*DownPlan    `json:"features"`
+	Failed   []PruneFailure `json:"failed,omitempty"` // Features that cannot be removed
+}
+
+// PruneResult contains the outcome of removing merged features.
+type PruneResult struct {
+	Pruned []string
+	Failed []PruneFailure
+}
+
+// PlanPrune plans removing each feature the way a forced Down does, so
+// uncommitted changes don't stop the batch.
+func PlanPrune(opts PruneOptions) *PrunePlan {
+	plan := &PrunePlan{Plan: newPlan("prune", opts.Config.Name), Features: []*DownPlan{}}
+
+	for _, featureName := range opts.Features {
+		featurePlan, err := PlanDown(opts.downOptions(featureName))
+		if err != nil {
+			plan.Failed = append(plan.Failed, PruneFailure{Feature: featureName, Error: err.Error()})
+			plan.warn(fmt.Sprintf("%s: %v", featureName, err))
+			continue
+		}
+
+		plan.Features = append(plan.Features, featurePlan)
+		if opts.Archive {
+			plan.Steps = append(plan.Steps, PlanStep{Kind: StepArchive, Feature: featureName, Detail: "save branches and uncommitted changes under refs/ramp/archive/" + featureName})
+		}
+		for _, step := range featurePlan.Steps {
+			step.Feature = featureName
+			plan.Steps = append(plan.Steps, step)
+		}
+		for _, warning := range featurePlan.Warnings {
+			plan.warn(fmt.Sprintf("%s: %s", featureName, warning))
+		}
+	}
+
+	return plan
+}
+
+// ApplyPrune removes the features in a plan from PlanPrune, archiving each
+// first when opts.Archive is set. A feature that fails to be removed doesn't
+// stop the others. Each removal is journaled like a down; features the batch
+// hadn't reached when it was killed have no journal and are simply found again
+// by the next prune.
+func ApplyPrune(opts PruneOptions, plan *PrunePlan) *PruneResult {
+	result := &PruneResult{Pruned: []string{}, Failed: append([]PruneFailure{}, plan.Failed...)}
+
+	for _, featurePlan := range plan.Features {
+		featureName := featurePlan.Target
+
+		var err error
+		if opts.Archive {
+			opts.Progress.Update(fmt.Sprintf("Archiving %s...", featureName))
+			_, err = ArchiveFeature(ArchiveOptions{
+				FeatureName: featureName,
+				ProjectDir:  opts.ProjectDir,
+				Config:      opts.Config,
+				Progress:    opts.Progress,
+			})
+		} else {
+			opts.Progress.Update(fmt.Sprintf("Removing %s...", featureName))
+			_, err = journaledDown(opts.downOptions(featureName), featurePlan, "prune")
+		}
+		if err != nil {
+			result.Failed = append(result.Failed, PruneFailure{Feature: featureName, Error: err.Error()})
+			continue
+		}
+		result.Pruned = append(result.Pruned, featureName)
+	}
+
+	return result
+}
+
+func (opts PruneOptions) downOptions(featureName string) DownOptions {
+	return DownOptions{
+		FeatureName: featureName,
+		ProjectDir:  opts.ProjectDir,
+		Config:      opts.Config,
+		Progress:    opts.Progress,
+		Force:       true, // Merged features are safe to delete
+	}
+}
@@ -0,0 +1,143 @@
+package operations
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"ramp/internal/git"
+)
+
+// TestApplyPruneRunsCleanupScript tests that pruning, with and without
+// archiving, runs the cleanup script before removing each feature
+func TestApplyPruneRunsCleanupScript(t *testing.T) {
+	for _, archive := range []bool{false, true} {
+		name := "delete"
+		if archive {
+			name = "archive"
+		}
+		t.Run(name, func(t *testing.T) {
+			tp := NewTestProject(t)
+			repo := tp.InitRepo("repo1")
+
+			scriptPath := filepath.Join(tp.Dir, ".ramp", "scripts", "cleanup.sh")
+			os.MkdirAll(filepath.Dir(scriptPath), 0755)
+			script := "#!/bin/bash\necho \"cleanup executed for $RAMP_WORKTREE_NAME\" > \"$RAMP_PROJECT_DIR/.ramp/prune-cleanup-marker.txt\"\n"
+			if err := os.WriteFile(scriptPath, []byte(script), 0755); err != nil {
+				t.Fatalf("failed to write cleanup script: %v", err)
+			}
+			tp.Config.Cleanup = "scripts/cleanup.sh"
+
+			_, err := Up(UpOptions{
+				FeatureName: "scripted",
+				ProjectDir:  tp.Dir,
+				Config:      tp.Config,
+				Progress:    &MockProgressReporter{},
+				SkipRefresh: true,
+			})
+			if err != nil {
+				t.Fatalf("Up() error = %v", err)
+			}
+
+			opts := PruneOptions{
+				ProjectDir: tp.Dir,
+				Config:     tp.Config,
+				Progress:   &MockProgressReporter{},
+				Features:   []string{"scripted"},
+				Archive:    archive,
+			}
+			result := ApplyPrune(opts, PlanPrune(opts))
+			if !slices.Equal(result.Pruned, []string{"scripted"}) {
+				t.Fatalf("Pruned = %v, failed = %v", result.Pruned, result.Failed)
+			}
+
+			marker, err := os.ReadFile(filepath.Join(tp.Dir, ".ramp", "prune-cleanup-marker.txt"))
+			if err != nil {
+				t.Fatal("cleanup script was not executed")
+			}
+			if !strings.Contains(string(marker), "scripted") {
+				t.Errorf("marker = %q, want the feature name", marker)
+			}
+			if tp.FeatureExists("scripted") {
+				t.Error("feature directory should be removed")
+			}
+			if exists, _ := git.LocalBranchExists(repo.SourceDir, "feature/scripted"); exists {
+				t.Error("branch should be deleted")
+			}
+			if a, _ := LoadArchive(tp.Dir, "scripted"); (a != nil) != archive {
+				t.Errorf("archived = %v, want %v", a != nil, archive)
+			}
+		})
+	}
+}
+
+// TestApplyPruneMissingFeature tests that a feature that doesn't exist is
+// reported as failed without stopping the others
+func TestApplyPruneMissingFeature(t *testing.T) {
+	tp := NewTestProject(t)
+	tp.InitRepo("repo1")
+
+	_, err := Up(UpOptions{
+		FeatureName: "merged",
+		ProjectDir:  tp.Dir,
+		Config:      tp.Config,
+		Progress:    &MockProgressReporter{},
+		SkipRefresh: true,
+	})
+	if err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+
+	opts := PruneOptions{
+		ProjectDir: tp.Dir,
+		Config:     tp.Config,
+		Progress:   &MockProgressReporter{},
+		Features:   []string{"does-not-exist", "merged"},
+	}
+	result := ApplyPrune(opts, PlanPrune(opts))
+
+	if !slices.Equal(result.Pruned, []string{"merged"}) {
+		t.Errorf("Pruned = %v, want [merged]", result.Pruned)
+	}
+	if len(result.Failed) != 1 || result.Failed[0].Feature != "does-not-exist" || !strings.Contains(result.Failed[0].Error, "does not exist") {
+		t.Errorf("Failed = %+v, want does-not-exist reported as missing", result.Failed)
+	}
+}
+
+// TestApplyPruneOrphanedFeature tests pruning a feature whose trees directory
+// was deleted by hand
+func TestApplyPruneOrphanedFeature(t *testing.T) {
+	tp := NewTestProject(t)
+	repo := tp.InitRepo("repo1")
+
+	_, err := Up(UpOptions{
+		FeatureName: "orphaned",
+		ProjectDir:  tp.Dir,
+		Config:      tp.Config,
+		Progress:    &MockProgressReporter{},
+		SkipRefresh: true,
+	})
+	if err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+	if err := os.RemoveAll(filepath.Join(tp.TreesDir, "orphaned")); err != nil {
+		t.Fatalf("failed to remove trees directory: %v", err)
+	}
+
+	opts := PruneOptions{
+		ProjectDir: tp.Dir,
+		Config:     tp.Config,
+		Progress:   &MockProgressReporter{},
+		Features:   []string{"orphaned"},
+	}
+	result := ApplyPrune(opts, PlanPrune(opts))
+
+	if !slices.Equal(result.Pruned, []string{"orphaned"}) {
+		t.Fatalf("Pruned = %v, failed = %v", result.Pruned, result.Failed)
+	}
+	if exists, _ := git.LocalBranchExists(repo.SourceDir, "feature/orphaned"); exists {
+		t.Error("branch should be deleted even with the trees directory gone")
+	}
+}
@@ -0,0 +1,179 @@
+package operations
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"ramp/internal/config"
+	"ramp/internal/git"
+)
+
+// Push statuses for a single repository.
+const (
+	PushStatusPushed  = "pushed"
+	PushStatusSkipped = "skipped"
+	PushStatusFailed  = "failed"
+)
+
+// PushOptions configures pushing a feature's branches.
+type PushOptions struct {
+	// Required
+	FeatureName string
+	ProjectDir  string
+	Config      *config.Config
+	Progress    ProgressReporter
+
+	// Optional
+	ForceWithLease bool // Push rewritten branches with --force-with-lease
+	Workers        int  // Max concurrent pushes (0 = config max_parallel)
+}
+
+// PushRepoResult is the outcome of pushing one repository's feature branch.
+type PushRepoResult struct {
+	Repo    string
+	Branch  string
+	Status  string // PushStatusPushed, PushStatusSkipped or PushStatusFailed
+	Ahead   int    // Commits the branch had that the remote didn't
+	Message string
+}
+
+// PushResult contains the per-repo outcome of a push, sorted by repo name.
+type PushResult struct {
+	FeatureName string
+	Repos       []PushRepoResult
+}
+
+// Failed returns the repos whose push failed.
+func (r *PushResult) Failed() []string {
+	var failed []string
+	for _, repo := range r.Repos {
+		if repo.Status == PushStatusFailed {
+			failed = append(failed, repo.Repo)
+		}
+	}
+	return failed
+}
+
+// Push pushes the feature branch of every worktree with commits the remote
+// doesn't have, setting upstream tracking. Repos are pushed concurrently; a
+// failed push doesn't stop the others and is reported in the result.
+// This is the core business logic used by both CLI and UI.
+func Push(opts PushOptions) (*PushResult, error) {
+	treesDir := filepath.Join(opts.ProjectDir, "trees", opts.FeatureName)
+	if _, err := os.Stat(treesDir); os.IsNotExist(err) {
+		return nil, fmt.Errorf("feature '%s' not found", opts.FeatureName)
+	}
+
+	progress := opts.Progress
+	progress.Start(fmt.Sprintf("Pushing feature '%s'", opts.FeatureName))
+
+	repos := opts.Config.GetRepos()
+	repoNames := make([]string, 0, len(repos))
+	for name := range repos {
+		repoNames = append(repoNames, name)
+	}
+	sort.Strings(repoNames)
+
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = opts.Config.GetMaxParallel()
+	}
+	steps := &stepCounter{progress: newLockedProgress(progress), from: 0, to: 100, total: len(repoNames)}
+	results := make([]PushRepoResult, len(repoNames))
+
+	forEachParallel(len(repoNames), workers, func(i int) {
+		name := repoNames[i]
+		results[i] = pushRepo(name, repos[name], opts.ProjectDir, filepath.Join(treesDir, name), opts.ForceWithLease)
+		steps.step(fmt.Sprintf("Checked %s", name))
+	})
+
+	for _, result := range results {
+		switch result.Status {
+		case PushStatusPushed:
+			progress.Info(fmt.Sprintf("%s: ✅ %s", result.Repo, result.Message))
+		case PushStatusSkipped:
+			progress.Info(fmt.Sprintf("%s: %s", result.Repo, result.Message))
+		case PushStatusFailed:
+			progress.Warning(fmt.Sprintf("%s: %s", result.Repo, result.Message))
+		}
+	}
+
+	result := &PushResult{FeatureName: opts.FeatureName, Repos: results}
+	if failed := result.Failed(); len(failed) > 0 {
+		progress.Error(fmt.Sprintf("Failed to push %d of %d repositories", len(failed), len(results)))
+	} else {
+		progress.Success(fmt.Sprintf("Pushed feature '%s'", opts.FeatureName))
+	}
+	return result, nil
+}
+
+// pushRepo pushes one worktree's branch if it has commits to publish.
+func pushRepo(name string, repo *config.Repo, projectDir, worktreeDir string, forceWithLease bool) PushRepoResult {
+	result := PushRepoResult{Repo: name, Status: PushStatusSkipped}
+
+	if _, err := os.Stat(worktreeDir); os.IsNotExist(err) {
+		result.Message = "no worktree, skipped"
+		return result
+	}
+
+	branch, err := git.GetWorktreeBranch(worktreeDir)
+	if err != nil {
+		return failPush(result, fmt.Sprintf("failed to get branch: %v", err))
+	}
+	result.Branch = branch
+
+	// Compare with the tracked branch, else the branch on origin, else the base
+	// branch a new feature started from
+	tracking, err := git.HasRemoteTrackingBranch(worktreeDir)
+	if err != nil {
+		return failPush(result, err.Error())
+	}
+	compareTo := "@{u}"
+	if !tracking {
+		if onOrigin, _ := git.RemoteBranchExists(worktreeDir, branch); onOrigin {
+			compareTo = "origin/" + branch
+		} else if compareTo, err = BaseBranch(repo, repo.GetRepoPath(projectDir)); err != nil {
+			compareTo = ""
+		}
+	}
+
+	if compareTo != "" {
+		ahead, behind, err := git.GetAheadBehindCount(worktreeDir, compareTo)
+		if err != nil {
+			return failPush(result, err.Error())
+		}
+		result.Ahead = ahead
+
+		if ahead == 0 {
+			result.Message = "nothing to push"
+			return result
+		}
+		// Without a lease, a push to a branch that has moved on would be rejected
+		if tracking && behind > 0 && !forceWithLease {
+			return failPush(result, fmt.Sprintf("%s has diverged from its remote (%d ahead, %d behind); pull first or use --force-with-lease", branch, ahead, behind))
+		}
+	}
+
+	if err := git.PushBranchQuiet(worktreeDir, branch, forceWithLease); err != nil {
+		return failPush(result, err.Error())
+	}
+
+	result.Status = PushStatusPushed
+	switch result.Ahead {
+	case 0:
+		result.Message = fmt.Sprintf("pushed %s", branch)
+	case 1:
+		result.Message = fmt.Sprintf("pushed %s (1 commit)", branch)
+	default:
+		result.Message = fmt.Sprintf("pushed %s (%d commits)", branch, result.Ahead)
+	}
+	return result
+}
+
+func failPush(result PushRepoResult, message string) PushRepoResult {
+	result.Status = PushStatusFailed
+	result.Message = message
+	return result
+}
@@ -0,0 +1,154 @@
+package operations
+
+import (
+	"fmt"
+	"os"
+
+	"ramp/internal/config"
+	"ramp/internal/features"
+)
+
+// RecoverOptions configures resuming or rolling back an interrupted operation.
+type RecoverOptions struct {
+	// Required
+	ProjectDir  string
+	Config      *config.Config
+	Progress    ProgressReporter
+	FeatureName string
+
+	// Optional
+	Output OutputStreamer // Setup script output when resuming an up
+}
+
+// loadPendingJournal loads the journal of an interrupted operation on a feature.
+func loadPendingJournal(opts RecoverOptions) (*Journal, error) {
+	j, err := LoadJournal(opts.ProjectDir, opts.FeatureName)
+	if err != nil {
+		return nil, err
+	}
+	if j == nil {
+		return nil, fmt.Errorf("no interrupted operation found for feature '%s'", opts.FeatureName)
+	}
+	if j.Running() {
+		return nil, fmt.Errorf("the %s of feature '%s' is still running (pid %d)", j.Operation, j.Feature, j.PID)
+	}
+	return j, nil
+}
+
+// ResumeOperation finishes an interrupted up, down or prune of a feature,
+// skipping the steps its journal recorded as completed.
+func ResumeOperation(opts RecoverOptions) error {
+	j, err := loadPendingJournal(opts)
+	if err != nil {
+		return err
+	}
+	if err := j.claim(); err != nil {
+		return err
+	}
+	defer j.release()
+
+	switch {
+	case j.Operation == "up" && j.Up != nil:
+		opts.Progress.Start(fmt.Sprintf("Resuming creation of feature '%s'", j.Feature))
+		_, err = applyUp(UpOptions{
+			FeatureName:  j.Feature,
+			ProjectDir:   opts.ProjectDir,
+			Config:       opts.Config,
+			Progress:     opts.Progress,
+			Output:       opts.Output,
+			Target:       j.Target,
+			DisplayName:  j.DisplayName,
+			ForceRefresh: j.ForceRefresh,
+			SkipRefresh:  j.SkipRefresh,
+		}, j.Up, j)
+		return err
+
+	case (j.Operation == "down" || j.Operation == "prune") && j.Down != nil:
+		opts.Progress.Start(fmt.Sprintf("Resuming removal of feature '%s'", j.Feature))
+		_, err = applyDown(DownOptions{
+			FeatureName: j.Feature,
+			ProjectDir:  opts.ProjectDir,
+			Config:      opts.Config,
+			Progress:    opts.Progress,
+			Force:       true, // Uncommitted changes were dealt with before the removal started
+		}, j.Down, j)
+		return err
+	}
+
+	return fmt.Errorf("journal of feature '%s' has no plan to resume a %s from", j.Feature, j.Operation)
+}
+
+// CanRollback reports whether RollbackOperation can undo the journaled
+// operation: any up, or a down or prune that hasn't removed anything yet.
+func (j *Journal) CanRollback() bool {
+	if j.Operation == "up" {
+		return true
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	for _, step := range j.Done {
+		if step.Kind == StepRemoveWorktree || step.Kind == StepDeleteBranch {
+			return false
+		}
+	}
+	return true
+}
+
+// RollbackOperation undoes an interrupted up of a feature: worktrees, branches
+// it created, ports and metadata are removed as if the up had failed. A down
+// or prune can only be rolled back before it removed any worktree or branch,
+// in which case its journal is discarded; otherwise it has to be resumed.
+func RollbackOperation(opts RecoverOptions) error {
+	j, err := loadPendingJournal(opts)
+	if err != nil {
+		return err
+	}
+	progress := opts.Progress
+
+	if !j.CanRollback() {
+		return fmt.Errorf("the %s of feature '%s' already removed worktrees or branches and can't be rolled back; run 'ramp resume %s' to finish it", j.Operation, j.Feature, j.Feature)
+	}
+	if err := j.claim(); err != nil {
+		return err
+	}
+	defer j.release()
+
+	if j.Operation != "up" {
+		j.finish()
+		progress.Complete(fmt.Sprintf("Discarded the interrupted %s of feature '%s'; nothing had been removed", j.Operation, j.Feature))
+		return nil
+	}
+
+	if j.Up == nil {
+		return fmt.Errorf("journal of feature '%s' has no plan to roll back an up from", j.Feature)
+	}
+
+	progress.Start(fmt.Sprintf("Rolling back creation of feature '%s'", j.Feature))
+
+	// A kill right after `git worktree add` leaves a worktree the journal doesn't know about
+	states := make(map[string]*upState)
+	for _, repoPlan := range j.Up.Repos {
+		_, statErr := os.Stat(repoPlan.WorktreeDir)
+		states[repoPlan.Repo] = &upState{
+			repoName:        repoPlan.Repo,
+			worktreeCreated: j.Completed(StepCreateWorktree, repoPlan.Repo) || statErr == nil,
+			worktreeDir:     repoPlan.WorktreeDir,
+			branchName:      j.Up.BranchName,
+			branchExisted:   repoPlan.BranchState == BranchStateLocal,
+			treesDirCreated: true,
+			portAllocated:   true, // Releasing ports the feature never got is a no-op
+		}
+	}
+
+	if j.Completed(StepSaveMetadata, "") {
+		if metadataStore, err := features.NewMetadataStore(opts.ProjectDir); err == nil {
+			if err := metadataStore.RemoveFeature(j.Feature); err != nil {
+				progress.Warning(fmt.Sprintf("Failed to remove feature metadata: %v", err))
+			}
+		}
+	}
+
+	rollbackUp(opts.ProjectDir, j.Up.TreesDir, j.Feature, states, opts.Config, progress, j)
+	progress.Complete(fmt.Sprintf("Rolled back feature '%s'", j.Feature))
+	return nil
+}
@@ -73,6 +73,18 @@ func RefreshRepositories(opts RefreshOptions) []RefreshResult {
 				return
 			}
 
+			// Forks: make sure upstream is a remote so fetch --all picks it up
+			if r.Upstream != "" {
+				if err := git.EnsureRemote(repoDir, git.UpstreamRemote, r.Upstream); err != nil {
+					result.Status = "warning"
+					result.Message = err.Error()
+					mu.Lock()
+					results = append(results, result)
+					mu.Unlock()
+					return
+				}
+			}
+
 			// Fetch all remotes
 			if err := git.FetchAllQuiet(repoDir); err != nil {
 				result.Status = "warning"
@@ -111,6 +123,12 @@ func RefreshRepositories(opts RefreshOptions) []RefreshResult {
 				result.Message = fmt.Sprintf("branch %s has no remote tracking branch, skipped pull", currentBranch)
 			}
 
+			// Bring submodules and LFS objects in line with what was pulled
+			if err := UpdateSubmodulesAndLFS(r, repoDir); err != nil {
+				result.Status = "warning"
+				result.Message = err.Error()
+			}
+
 			mu.Lock()
 			results = append(results, result)
 			mu.Unlock()
@@ -133,3 +151,45 @@ func RefreshRepositories(opts RefreshOptions) []RefreshResult {
 
 	return results
 }
+
+// UpdateSubmodulesAndLFS initializes submodules and pulls LFS objects in dir,
+// a source repo or worktree, as configured for repo. Does nothing for repos
+// without submodules or lfs set.
+func UpdateSubmodulesAndLFS(repo *config.Repo, dir string) error {
+	if repo.SubmodulesEnabled() {
+		if err := git.UpdateSubmodulesQuiet(dir, repo.SubmodulesRecursive()); err != nil {
+			return fmt.Errorf("submodule update failed: %w", err)
+		}
+	}
+	if repo.LFS {
+		if err := git.PullLFSQuiet(dir); err != nil {
+			return fmt.Errorf("LFS pull failed: %w", err)
+		}
+	}
+	return nil
+}
+
+// FetchRepositories fetches all remotes of every source repository without
+// touching their checked-out branches, so feature statuses read afterwards
+// compare against fresh remote refs. Returns the repos that failed to fetch.
+func FetchRepositories(projectDir string, cfg *config.Config) []string {
+	repos := cfg.GetRepos()
+	names := sortedRepoNames(repos)
+	failed := make([]bool, len(names))
+
+	forEachParallel(len(names), cfg.GetMaxParallel(), func(i int) {
+		repoDir := repos[names[i]].GetRepoPath(projectDir)
+		if !git.IsGitRepo(repoDir) {
+			return
+		}
+		failed[i] = git.FetchAllQuiet(repoDir) != nil
+	})
+
+	var failedRepos []string
+	for i, name := range names {
+		if failed[i] {
+			failedRepos = append(failedRepos, name)
+		}
+	}
+	return failedRepos
+}
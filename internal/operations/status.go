@@ -0,0 +1,240 @@
+package operations
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"ramp/internal/config"
+	"ramp/internal/git"
+)
+
+// FeatureRepoStatus is the state of one repo's worktree in a feature.
+type FeatureRepoStatus struct {
+	Repo           string
+	Branch         string
+	BaseBranch     string // Branch the feature is compared against
+	HasUncommitted bool
+	DiffStats      *git.DiffStats   // Unstaged changes to tracked files; nil when clean
+	StatusStats    *git.StatusStats // Nil when clean
+	Ahead          int
+	Behind         int
+	MergeMethod    git.MergeMethod
+	Sparse         bool
+	Error          string
+}
+
+// IsMerged reports whether the worktree's branch was merged into its base branch.
+// A deleted upstream alone doesn't count, see RemoteDeleted.
+func (s FeatureRepoStatus) IsMerged() bool {
+	return s.MergeMethod != git.MergeMethodNone && s.MergeMethod != git.MergeMethodRemoteDeleted
+}
+
+// RemoteDeleted reports whether the branch's only sign of being merged is that
+// its pushed upstream was deleted. Closing a pull request does that too, so the
+// branch may have commits that are nowhere else.
+func (s FeatureRepoStatus) RemoteDeleted() bool {
+	return s.MergeMethod == git.MergeMethodRemoteDeleted
+}
+
+// StatusCollector gathers worktree statuses across many features. Each worktree
+// costs one `git status` call, plus a `git diff --shortstat` when it has
+// unstaged changes; its sparse state is read from its config files. The base
+// branch and refs of each source repo are read once. Ahead/behind counts take
+// one `git rev-list` per distinct pair of HEAD and base commits, shared by the
+// worktrees on them. The rebase and squash merge checks (`git cherry`,
+// `git merge-tree` and a `git rev-parse`) only run for branches that are both
+// ahead of and behind their base; a branch only ahead of it can't have been
+// merged that way, and whether its upstream is gone is known from the refs.
+// A collector never fetches, so its results are only as fresh as the last fetch.
+// It is safe for concurrent use and meant to live for a single listing.
+type StatusCollector struct {
+	projectDir string
+	repos      map[string]*config.Repo
+	workers    int
+
+	mu       sync.Mutex
+	bases    map[string]*repoBase
+	distance map[[2]string]aheadBehind
+}
+
+// repoBase is what a source repo contributes to the status of its worktrees.
+type repoBase struct {
+	once   sync.Once
+	branch string
+	commit string
+	refs   git.Refs
+	err    error
+}
+
+type aheadBehind struct {
+	ahead, behind int
+	err           error
+}
+
+// NewStatusCollector creates a collector for the given repos that runs at most
+// workers git processes at a time.
+func NewStatusCollector(projectDir string, repos map[string]*config.Repo, workers int) *StatusCollector {
+	return &StatusCollector{
+		projectDir: projectDir,
+		repos:      repos,
+		workers:    workers,
+		bases:      make(map[string]*repoBase),
+		distance:   make(map[[2]string]aheadBehind),
+	}
+}
+
+// CollectFeatures returns the status of every configured repo worktree in each
+// feature, in directory order. Features whose directory cannot be read are left
+// out of the result.
+func (c *StatusCollector) CollectFeatures(featureNames []string) map[string][]FeatureRepoStatus {
+	type worktree struct {
+		feature, repo string
+	}
+
+	var worktrees []worktree
+	for _, featureName := range featureNames {
+		entries, err := os.ReadDir(filepath.Join(c.projectDir, "trees", featureName))
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if _, ok := c.repos[entry.Name()]; ok && entry.IsDir() {
+				worktrees = append(worktrees, worktree{featureName, entry.Name()})
+			}
+		}
+	}
+
+	statuses := make([]FeatureRepoStatus, len(worktrees))
+	forEachParallel(len(worktrees), c.workers, func(i int) {
+		statuses[i] = c.Collect(worktrees[i].feature, worktrees[i].repo)
+	})
+
+	result := make(map[string][]FeatureRepoStatus)
+	for i, wt := range worktrees {
+		result[wt.feature] = append(result[wt.feature], statuses[i])
+	}
+	return result
+}
+
+// Collect returns the status of a single repo worktree in a feature.
+func (c *StatusCollector) Collect(featureName, repoName string) FeatureRepoStatus {
+	worktreePath := filepath.Join(c.projectDir, "trees", featureName, repoName)
+	status := FeatureRepoStatus{Repo: repoName}
+
+	if _, err := os.Stat(worktreePath); os.IsNotExist(err) {
+		status.Error = "worktree not found"
+		return status
+	}
+
+	wt, err := git.GetWorktreeStatus(worktreePath)
+	if err != nil {
+		status.Error = err.Error()
+		return status
+	}
+	if wt.HasUncommittedChanges() {
+		status.HasUncommitted = true
+		stats := wt.Stats
+		status.StatusStats = &stats
+		status.DiffStats = &git.DiffStats{}
+		if wt.Unstaged {
+			if diffStats, err := git.GetDiffStats(worktreePath); err == nil {
+				status.DiffStats = diffStats
+			}
+		}
+	}
+
+	if wt.Branch == "" {
+		status.Error = "failed to get branch: HEAD is detached"
+		return status
+	}
+	status.Branch = wt.Branch
+	status.Sparse = wt.Sparse
+
+	base := c.base(repoName)
+	if base.err != nil {
+		status.Error = fmt.Sprintf("failed to get default branch: %v", base.err)
+		return status
+	}
+	status.BaseBranch = base.branch
+
+	// Without a base commit or HEAD there is nothing to compare, as before the first fetch
+	if base.commit == "" || wt.Head == "" {
+		return status
+	}
+
+	distance := c.aheadBehind(worktreePath, wt.Head, base.commit)
+	if distance.err != nil {
+		return status
+	}
+	status.Ahead = distance.ahead
+	status.Behind = distance.behind
+
+	// Nothing ahead means HEAD is an ancestor of the base branch
+	if distance.ahead == 0 {
+		status.MergeMethod = git.MergeMethodAncestor
+		return status
+	}
+	branch, _ := base.refs.Resolve(wt.Branch)
+	if distance.behind == 0 {
+		// The base has nothing HEAD lacks, so HEAD's changes can't be in it
+		if branch.UpstreamGone {
+			status.MergeMethod = git.MergeMethodRemoteDeleted
+		}
+		return status
+	}
+	status.MergeMethod = git.DetectDivergedMerge(worktreePath, base.branch, branch.UpstreamGone)
+	return status
+}
+
+// base reads the base branch and refs of a source repo the first time one of its
+// worktrees is collected.
+func (c *StatusCollector) base(repoName string) *repoBase {
+	c.mu.Lock()
+	base, ok := c.bases[repoName]
+	if !ok {
+		base = &repoBase{}
+		c.bases[repoName] = base
+	}
+	c.mu.Unlock()
+
+	base.once.Do(func() {
+		repoDir := c.repos[repoName].GetRepoPath(c.projectDir)
+		base.branch, base.err = BaseBranch(c.repos[repoName], repoDir)
+		if base.err != nil {
+			return
+		}
+		base.refs, base.err = git.ListRefs(repoDir)
+		if base.err != nil {
+			return
+		}
+		if ref, ok := base.refs.Resolve(base.branch); ok {
+			base.commit = ref.Commit
+		}
+	})
+	return base
+}
+
+// aheadBehind counts the commits between head and base, reusing the count for
+// worktrees on the same commits.
+func (c *StatusCollector) aheadBehind(worktreePath, head, base string) aheadBehind {
+	if head == base {
+		return aheadBehind{}
+	}
+
+	key := [2]string{head, base}
+	c.mu.Lock()
+	distance, ok := c.distance[key]
+	c.mu.Unlock()
+	if ok {
+		return distance
+	}
+
+	distance.ahead, distance.behind, distance.err = git.GetAheadBehindCount(worktreePath, base)
+
+	c.mu.Lock()
+	c.distance[key] = distance
+	c.mu.Unlock()
+	return distance
+}
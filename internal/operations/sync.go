@@ -0,0 +1,377 @@
+package operations
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"ramp/internal/config"
+	"ramp/internal/features"
+	"ramp/internal/git"
+)
+
+// SyncMode selects how feature branches are brought up to date.
+type SyncMode string
+
+const (
+	SyncRebase SyncMode = "rebase" // Replay the feature's commits on top of the base branch
+	SyncMerge  SyncMode = "merge"  // Merge the base branch into the feature branch
+)
+
+// Sync statuses for a single repository.
+const (
+	SyncStatusSynced   = "synced"
+	SyncStatusUpToDate = "up-to-date"
+	SyncStatusConflict = "conflict"
+	SyncStatusAborted  = "aborted"
+	SyncStatusFailed   = "failed"
+	SyncStatusSkipped  = "skipped"
+)
+
+// SyncOptions configures syncing a feature's branches with their base branch.
+type SyncOptions struct {
+	// Required
+	FeatureName string
+	ProjectDir  string
+	Config      *config.Config
+	Progress    ProgressReporter
+
+	// Optional
+	Mode    SyncMode // Defaults to SyncRebase
+	Workers int      // Max concurrent repos (0 = config max_parallel)
+}
+
+// SyncRepoResult is the outcome of syncing one repository.
+type SyncRepoResult struct {
+	Repo      string
+	Onto      string   // Base branch the feature was rebased onto or merged with
+	Status    string   // One of the SyncStatus constants
+	Conflicts []string // Conflicted files when Status is SyncStatusConflict
+	Message   string
+}
+
+// SyncResult contains the per-repo outcome of a sync, sorted by repo name.
+type SyncResult struct {
+	FeatureName string
+	Mode        SyncMode
+	Repos       []SyncRepoResult
+}
+
+// Conflicted returns the repos stopped on conflicts.
+func (r *SyncResult) Conflicted() []string {
+	return r.withStatus(SyncStatusConflict)
+}
+
+// Failed returns the repos that could not be synced for reasons other than conflicts.
+func (r *SyncResult) Failed() []string {
+	return r.withStatus(SyncStatusFailed)
+}
+
+func (r *SyncResult) withStatus(status string) []string {
+	var repos []string
+	for _, repo := range r.Repos {
+		if repo.Status == status {
+			repos = append(repos, repo.Repo)
+		}
+	}
+	return repos
+}
+
+// Sync fetches each repository and rebases or merges the feature branch onto
+// its base branch (upstream's default branch for forks, origin's otherwise).
+// Uncommitted changes are stashed first and restored afterwards. Repos with
+// conflicts are left mid-rebase or mid-merge for ContinueSync or AbortSync;
+// the others are unaffected.
+// This is the core business logic used by both CLI and UI.
+func Sync(opts SyncOptions) (*SyncResult, error) {
+	mode := opts.Mode
+	if mode == "" {
+		mode = SyncRebase
+	}
+	if mode != SyncRebase && mode != SyncMerge {
+		return nil, fmt.Errorf("unknown sync mode '%s' (expected rebase or merge)", mode)
+	}
+
+	treesDir, store, err := loadSyncFeature(opts)
+	if err != nil {
+		return nil, err
+	}
+	if store.GetSync(opts.FeatureName) != nil {
+		return nil, fmt.Errorf("a sync of '%s' is already in progress; use --continue or --abort", opts.FeatureName)
+	}
+
+	progress := opts.Progress
+	progress.Start(fmt.Sprintf("Syncing feature '%s' (%s)", opts.FeatureName, mode))
+
+	repos := opts.Config.GetRepos()
+	repoNames := sortedRepoNames(repos)
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = opts.Config.GetMaxParallel()
+	}
+	steps := &stepCounter{progress: newLockedProgress(progress), from: 0, to: 100, total: len(repoNames)}
+	results := make([]SyncRepoResult, len(repoNames))
+	stashes := make([]string, len(repoNames))
+
+	forEachParallel(len(repoNames), workers, func(i int) {
+		name := repoNames[i]
+		results[i], stashes[i] = syncRepo(name, repos[name], opts.ProjectDir, filepath.Join(treesDir, name), mode)
+		steps.step(fmt.Sprintf("Synced %s", name))
+	})
+
+	result := &SyncResult{FeatureName: opts.FeatureName, Mode: mode, Repos: results}
+	if err := finishSync(store, result, stashes, progress); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ContinueSync continues a sync stopped on conflicts, once they are resolved
+// in each worktree, and restores stashed changes of repos that finish.
+func ContinueSync(opts SyncOptions) (*SyncResult, error) {
+	return resumeSync(opts, "Continuing", SyncStatusSynced, func(worktreeDir string) error {
+		return git.ContinueOperationQuiet(worktreeDir)
+	})
+}
+
+// AbortSync aborts a sync stopped on conflicts, returning those repos' branches
+// to where they were and restoring stashed changes. Repos that already synced
+// keep their new history.
+func AbortSync(opts SyncOptions) (*SyncResult, error) {
+	return resumeSync(opts, "Aborting", SyncStatusAborted, func(worktreeDir string) error {
+		return git.AbortOperationQuiet(worktreeDir)
+	})
+}
+
+// resumeSync applies step to every repo left in progress by a stopped sync;
+// repos where it succeeds end up with doneStatus.
+func resumeSync(opts SyncOptions, verb, doneStatus string, step func(worktreeDir string) error) (*SyncResult, error) {
+	treesDir, store, err := loadSyncFeature(opts)
+	if err != nil {
+		return nil, err
+	}
+	state := store.GetSync(opts.FeatureName)
+	if state == nil {
+		return nil, fmt.Errorf("no sync of '%s' is in progress", opts.FeatureName)
+	}
+
+	progress := opts.Progress
+	progress.Start(fmt.Sprintf("%s sync of feature '%s'", verb, opts.FeatureName))
+
+	result := &SyncResult{FeatureName: opts.FeatureName, Mode: SyncMode(state.Mode)}
+	stashes := make([]string, len(state.Repos))
+
+	for i, name := range state.Repos {
+		worktreeDir := filepath.Join(treesDir, name)
+		stashes[i] = state.Stashes[name]
+		repoResult := SyncRepoResult{Repo: name, Status: doneStatus}
+		if err := step(worktreeDir); err != nil {
+			repoResult = syncStopped(repoResult, worktreeDir, err)
+		}
+
+		if repoResult.Status != SyncStatusConflict && stashes[i] != "" {
+			repoResult = restoreStash(repoResult, worktreeDir, stashes[i])
+			stashes[i] = ""
+		}
+		result.Repos = append(result.Repos, repoResult)
+	}
+
+	if err := finishSync(store, result, stashes, progress); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// syncRepo fetches and syncs one worktree. It returns the stash commit of
+// uncommitted changes still stashed, which is the case when it stops on
+// conflicts, or "".
+func syncRepo(name string, repo *config.Repo, projectDir, worktreeDir string, mode SyncMode) (SyncRepoResult, string) {
+	result := SyncRepoResult{Repo: name}
+
+	if _, err := os.Stat(worktreeDir); os.IsNotExist(err) {
+		result.Status = SyncStatusSkipped
+		result.Message = "no worktree, skipped"
+		return result, ""
+	}
+	if operation := git.InProgressOperation(worktreeDir); operation != git.OperationNone {
+		return failSync(result, fmt.Sprintf("a %s is already in progress; finish or abort it first", operation)), ""
+	}
+
+	sourceDir := repo.GetRepoPath(projectDir)
+	if err := git.FetchAllQuiet(sourceDir); err != nil {
+		return failSync(result, err.Error()), ""
+	}
+
+	onto, err := syncBase(repo, sourceDir)
+	if err != nil {
+		return failSync(result, fmt.Sprintf("failed to get base branch: %v", err)), ""
+	}
+	result.Onto = onto
+
+	_, behind, err := git.GetAheadBehindCount(worktreeDir, onto)
+	if err != nil {
+		return failSync(result, err.Error()), ""
+	}
+	if behind == 0 {
+		result.Status = SyncStatusUpToDate
+		result.Message = fmt.Sprintf("up to date with %s", onto)
+		return result, ""
+	}
+
+	stash, err := git.StashPushQuiet(worktreeDir, "ramp sync stash")
+	if err != nil {
+		return failSync(result, err.Error()), ""
+	}
+
+	if mode == SyncMerge {
+		err = git.MergeQuiet(worktreeDir, onto)
+	} else {
+		err = git.RebaseQuiet(worktreeDir, onto)
+	}
+	if err != nil {
+		result = syncStopped(result, worktreeDir, err)
+		if result.Status == SyncStatusConflict {
+			return result, stash
+		}
+	} else {
+		result.Status = SyncStatusSynced
+		result.Message = fmt.Sprintf("rebased onto %s (%d new %s)", onto, behind, commitsWord(behind))
+		if mode == SyncMerge {
+			result.Message = fmt.Sprintf("merged %s (%d new %s)", onto, behind, commitsWord(behind))
+		}
+	}
+
+	if stash != "" {
+		result = restoreStash(result, worktreeDir, stash)
+	}
+	return result, ""
+}
+
+// syncBase returns the ref a feature branch syncs with: upstream's default
+// branch for forks, else origin's copy of the default branch when there is one.
+func syncBase(repo *config.Repo, sourceDir string) (string, error) {
+	base, err := BaseBranch(repo, sourceDir)
+	if err != nil {
+		return "", err
+	}
+	if strings.HasPrefix(base, git.UpstreamRemote+"/") {
+		return base, nil
+	}
+	if onOrigin, _ := git.RemoteBranchExists(sourceDir, base); onOrigin {
+		return "origin/" + base, nil
+	}
+	return base, nil
+}
+
+// syncStopped classifies a failed rebase, merge or continue: still in progress
+// means conflicts for the user to resolve, anything else is a failure.
+func syncStopped(result SyncRepoResult, worktreeDir string, err error) SyncRepoResult {
+	if git.InProgressOperation(worktreeDir) == git.OperationNone {
+		return failSync(result, err.Error())
+	}
+
+	result.Status = SyncStatusConflict
+	result.Conflicts, _ = git.ConflictedFiles(worktreeDir)
+	if len(result.Conflicts) == 0 {
+		result.Message = "stopped; resolve and stage the changes"
+	} else {
+		result.Message = fmt.Sprintf("conflicts in %s", strings.Join(result.Conflicts, ", "))
+	}
+	return result
+}
+
+// restoreStash pops the changes stashed before syncing. If they no longer
+// apply cleanly they stay in the stash and the repo is reported as failed.
+func restoreStash(result SyncRepoResult, worktreeDir, stash string) SyncRepoResult {
+	if err := git.PopStashCommitQuiet(worktreeDir, stash); err != nil {
+		return failSync(result, fmt.Sprintf("synced, but stashed changes could not be restored (still in git stash as %.7s): %v", stash, err))
+	}
+	return result
+}
+
+// finishSync reports per-repo results and records repos still stopped on
+// conflicts so the sync can be continued or aborted.
+func finishSync(store *features.MetadataStore, result *SyncResult, stashes []string, progress ProgressReporter) error {
+	var state *features.SyncState
+	for i, repo := range result.Repos {
+		switch repo.Status {
+		case SyncStatusSynced:
+			if repo.Message == "" {
+				repo.Message = "synced"
+			}
+			progress.Info(fmt.Sprintf("%s: ✅ %s", repo.Repo, repo.Message))
+		case SyncStatusUpToDate, SyncStatusSkipped, SyncStatusAborted:
+			if repo.Message == "" {
+				repo.Message = repo.Status
+			}
+			progress.Info(fmt.Sprintf("%s: %s", repo.Repo, repo.Message))
+		case SyncStatusConflict:
+			progress.Warning(fmt.Sprintf("%s: %s", repo.Repo, repo.Message))
+			if state == nil {
+				state = &features.SyncState{Mode: string(result.Mode)}
+			}
+			state.Repos = append(state.Repos, repo.Repo)
+			if stashes[i] != "" {
+				if state.Stashes == nil {
+					state.Stashes = make(map[string]string)
+				}
+				state.Stashes[repo.Repo] = stashes[i]
+			}
+		case SyncStatusFailed:
+			progress.Warning(fmt.Sprintf("%s: %s", repo.Repo, repo.Message))
+		}
+		result.Repos[i] = repo
+	}
+
+	if err := store.SetSync(result.FeatureName, state); err != nil {
+		return err
+	}
+
+	switch {
+	case state != nil:
+		progress.Warning(fmt.Sprintf("Sync stopped on conflicts in %s", strings.Join(state.Repos, ", ")))
+	case len(result.Failed()) > 0:
+		progress.Error(fmt.Sprintf("Failed to sync %s", strings.Join(result.Failed(), ", ")))
+	default:
+		progress.Success(fmt.Sprintf("Synced feature '%s'", result.FeatureName))
+	}
+	return nil
+}
+
+// loadSyncFeature checks the feature exists and opens its metadata.
+func loadSyncFeature(opts SyncOptions) (string, *features.MetadataStore, error) {
+	treesDir := filepath.Join(opts.ProjectDir, "trees", opts.FeatureName)
+	if _, err := os.Stat(treesDir); os.IsNotExist(err) {
+		return "", nil, fmt.Errorf("feature '%s' not found", opts.FeatureName)
+	}
+
+	store, err := features.NewMetadataStore(opts.ProjectDir)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to initialize metadata store: %w", err)
+	}
+	return treesDir, store, nil
+}
+
+func failSync(result SyncRepoResult, message string) SyncRepoResult {
+	result.Status = SyncStatusFailed
+	result.Message = message
+	return result
+}
+
+func sortedRepoNames(repos map[string]*config.Repo) []string {
+	names := make([]string, 0, len(repos))
+	for name := range repos {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func commitsWord(count int) string {
+	if count == 1 {
+		return "commit"
+	}
+	return "commits"
+}
@@ -3,7 +3,10 @@ package operations
 import (
 	"fmt"
 	"os"
+	"os/user"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"ramp/internal/config"
 	"ramp/internal/envfile"
@@ -25,9 +28,10 @@ type UpOptions struct {
 	Output OutputStreamer // For streaming setup script stdout/stderr
 
 	// Optional - branch configuration
-	Prefix   string // Branch prefix override (empty = use config default)
-	NoPrefix bool   // Explicitly disable prefix
-	Target   string // Source branch/feature to create from
+	Prefix   string            // Branch prefix override (empty = use config default)
+	NoPrefix bool              // Explicitly disable prefix
+	Target   string            // Source branch/feature to create from
+	Sources  map[string]string // Per-repo branch to create from, overriding Target (e.g. another feature's branches)
 
 	// Optional - pre-operation behavior
 	AutoInstall bool // Auto-install repos if not present (default: false)
@@ -40,6 +44,13 @@ type UpOptions struct {
 
 	// Optional - display name
 	DisplayName string // Human-readable display name (different from feature directory/branch name)
+
+	// Optional - concurrency
+	Workers int // Max repos processed at once (0 = config max_parallel)
+
+	// Optional - runs once the worktrees and env files exist, before the setup
+	// script; an error rolls the feature back. Not kept for resuming.
+	BeforeSetup func(treesDir string) error
 }
 
 // UpResult contains the results of feature creation.
@@ -52,12 +63,37 @@ type UpResult struct {
 	AllocatedPorts []int
 }
 
+// How a feature branch is checked out in a repo.
+const (
+	BranchStateNew    = "new"             // Created from the source branch
+	BranchStateLocal  = "existing-local"  // Reuses a local branch
+	BranchStateRemote = "existing-remote" // Tracks an existing remote branch
+)
+
+// UpPlan is what Up will do to create a feature.
+type UpPlan struct {
+	Plan
+	BranchName string       `json:"branchName"`
+	TreesDir   string       `json:"treesDir"`
+	Repos      []UpRepoPlan `json:"repos"`
+	Ports      []int        `json:"ports,omitempty"`
+}
+
+// UpRepoPlan is how one repo's worktree will be created.
+type UpRepoPlan struct {
+	Repo        string `json:"repo"`
+	WorktreeDir string `json:"worktreeDir"`
+	BranchState string `json:"branchState"`      // One of the BranchState constants
+	Source      string `json:"source,omitempty"` // Branch a new branch is created from; empty uses the default
+}
+
 // upState tracks state for rollback purposes.
 type upState struct {
 	repoName        string
 	worktreeCreated bool
 	worktreeDir     string
 	branchName      string
+	branchExisted   bool // The worktree checked out a local branch that rollback must keep
 	treesDirCreated bool
 	portAllocated   bool
 	setupRan        bool
@@ -69,7 +105,6 @@ func Up(opts UpOptions) (*UpResult, error) {
 	projectDir := opts.ProjectDir
 	cfg := opts.Config
 	progress := opts.Progress
-	featureName := opts.FeatureName
 
 	// Phase 0a: Auto-install if requested and needed
 	if opts.AutoInstall && !IsProjectInstalled(cfg, projectDir) {
@@ -85,47 +120,73 @@ func Up(opts UpOptions) (*UpResult, error) {
 	}
 
 	// Phase 0b: Auto-refresh based on per-repo config (unless SkipRefresh is set)
-	if !opts.SkipRefresh {
-		repos := cfg.GetRepos()
+	repoFilter := upRefreshFilter(opts)
 
-		// Build filter for repos that should be refreshed
-		repoFilter := make(map[string]bool)
-		for name, repo := range repos {
-			shouldRefresh := false
-			if opts.ForceRefresh {
-				// --refresh flag: refresh ALL repos
-				shouldRefresh = true
-			} else {
-				// Default: respect per-repo auto_refresh config
-				shouldRefresh = repo.ShouldAutoRefresh()
-			}
+	// Only show refresh UI if there are repos to refresh
+	if len(repoFilter) > 0 {
+		progress.Start("Auto-refreshing repositories before creating feature")
 
-			if shouldRefresh {
-				repoFilter[name] = true
+		// Log skipped repos
+		for name := range cfg.GetRepos() {
+			if !repoFilter[name] {
+				progress.Info(fmt.Sprintf("%s: auto-refresh disabled, skipping", name))
 			}
 		}
 
-		// Only show refresh UI if there are repos to refresh
-		if len(repoFilter) > 0 {
-			progress.Start("Auto-refreshing repositories before creating feature")
+		RefreshRepositories(RefreshOptions{
+			ProjectDir: projectDir,
+			Config:     cfg,
+			Progress:   progress,
+			RepoFilter: repoFilter,
+		})
 
-			// Log skipped repos
-			for name := range repos {
-				if !repoFilter[name] {
-					progress.Info(fmt.Sprintf("%s: auto-refresh disabled, skipping", name))
-				}
-			}
+		progress.Success("Auto-refresh completed")
+	}
 
-			RefreshRepositories(RefreshOptions{
-				ProjectDir: projectDir,
-				Config:     cfg,
-				Progress:   progress,
-				RepoFilter: repoFilter,
-			})
+	plan, err := planUp(opts, true)
+	if err != nil {
+		return nil, err
+	}
+	return ApplyUp(opts, plan)
+}
 
-			progress.Success("Auto-refresh completed")
+// upRefreshFilter returns the repos Up refreshes before creating worktrees:
+// all of them with ForceRefresh, none with SkipRefresh, and otherwise those
+// with auto_refresh enabled.
+func upRefreshFilter(opts UpOptions) map[string]bool {
+	repoFilter := make(map[string]bool)
+	if opts.SkipRefresh {
+		return repoFilter
+	}
+	for name, repo := range opts.Config.GetRepos() {
+		if opts.ForceRefresh || repo.ShouldAutoRefresh() {
+			repoFilter[name] = true
 		}
 	}
+	return repoFilter
+}
+
+func upWorkers(opts UpOptions) int {
+	if opts.Workers > 0 {
+		return opts.Workers
+	}
+	return opts.Config.GetMaxParallel()
+}
+
+// PlanUp works out what Up would do, including the install and refresh it
+// would run first, without changing anything. Branches are resolved against
+// refs as of the last fetch.
+func PlanUp(opts UpOptions) (*UpPlan, error) {
+	return planUp(opts, false)
+}
+
+// planUp resolves branches, validates every repo and lists the steps of
+// creating the feature. prepared is set once install and refresh have run.
+func planUp(opts UpOptions, prepared bool) (*UpPlan, error) {
+	projectDir := opts.ProjectDir
+	cfg := opts.Config
+	progress := opts.Progress
+	featureName := opts.FeatureName
 
 	progress.Start(fmt.Sprintf("Creating feature '%s' for project '%s'", featureName, cfg.Name))
 
@@ -143,20 +204,59 @@ func Up(opts UpOptions) (*UpResult, error) {
 	treesDir := filepath.Join(projectDir, "trees", featureName)
 	repos := cfg.GetRepos()
 
+	plan := &UpPlan{
+		Plan:       newPlan("up", featureName),
+		BranchName: branchName,
+		TreesDir:   treesDir,
+	}
+
+	// Repos that a dry run would clone or refresh first
+	willClone := make(map[string]bool)
+	if !prepared {
+		if opts.AutoInstall {
+			for _, name := range plan.addCloneSteps(InstallOptions{ProjectDir: projectDir, Config: cfg}) {
+				willClone[name] = true
+			}
+		}
+		repoFilter := upRefreshFilter(opts)
+		for _, name := range sortedRepoNames(repos) {
+			if repoFilter[name] && !willClone[name] {
+				plan.add(StepRefresh, name, "fetch all remotes and pull the current branch")
+			}
+		}
+	}
+
+	// Repos are processed concurrently; report results in name order so output is stable
+	repoNames := sortedRepoNames(repos)
+	workers := upWorkers(opts)
+
 	// Resolve target branch for each repository if target is specified
 	var sourceBranches map[string]string
-	if opts.Target != "" {
+	if len(opts.Sources) > 0 {
+		sourceBranches = opts.Sources
+	} else if opts.Target != "" {
 		progress.Update("Resolving target branch across repositories")
+		resolved := make([]string, len(repoNames))
+		resolveErrs := make([]error, len(repoNames))
+		forEachParallel(len(repoNames), workers, func(i int) {
+			if willClone[repoNames[i]] {
+				resolveErrs[i] = fmt.Errorf("not cloned yet")
+				return
+			}
+			repoDir := repos[repoNames[i]].GetRepoPath(projectDir)
+			resolved[i], resolveErrs[i] = git.ResolveSourceBranch(repoDir, opts.Target, effectivePrefix)
+		})
+
 		sourceBranches = make(map[string]string)
-		for name, repo := range repos {
-			repoDir := repo.GetRepoPath(projectDir)
-			sourceBranch, err := git.ResolveSourceBranch(repoDir, opts.Target, effectivePrefix)
-			if err != nil {
-				progress.Warning(fmt.Sprintf("%s: target '%s' not found, will use default branch", name, opts.Target))
+		for i, name := range repoNames {
+			if resolveErrs[i] != nil {
+				warning := fmt.Sprintf("%s: target '%s' not found, will use default branch", name, opts.Target)
+				progress.Warning(warning)
+				plan.warn(warning)
 				sourceBranches[name] = ""
 			} else {
-				sourceBranches[name] = sourceBranch
-				progress.Info(fmt.Sprintf("%s: resolved target '%s' to source branch '%s'", name, opts.Target, sourceBranch))
+				sourceBranches[name] = resolved[i]
+				progress.Info(fmt.Sprintf("%s: resolved target '%s' to source branch '%s'", name, opts.Target, resolved[i]))
 			}
 		}
 		progress.Success("Target branch resolution completed")
@@ -164,75 +264,121 @@ func Up(opts UpOptions) (*UpResult, error) {
 
 	// Phase 1: Validation
 	progress.Start("Validating repositories and checking for conflicts")
-	states := make(map[string]*upState)
-
-	for name, repo := range repos {
-		repoDir := repo.GetRepoPath(projectDir)
-		worktreeDir := filepath.Join(treesDir, name)
 
-		if !git.IsGitRepo(repoDir) {
-			progress.Error(fmt.Sprintf("Source repo not found at %s", repoDir))
-			return nil, fmt.Errorf("source repo not found at %s", repoDir)
+	validations := make([]upValidation, len(repoNames))
+	forEachParallel(len(repoNames), workers, func(i int) {
+		name := repoNames[i]
+		if willClone[name] {
+			validations[i] = upValidation{plan: fmt.Sprintf("create worktree with new branch %s", branchName), state: BranchStateNew}
+			return
 		}
+		repoDir := repos[name].GetRepoPath(projectDir)
 
-		// Prune stale worktree entries before checking for conflicts
-		// This cleans up orphaned worktrees where the directory was removed but git still has a reference
-		_ = git.PruneWorktrees(repoDir)
+		// Forks branch off upstream's default branch rather than their own HEAD
+		var upstreamBase string
+		if repos[name].Upstream != "" && sourceBranches[name] == "" {
+			upstreamBase, _ = git.GetRemoteDefaultBranch(repoDir, git.UpstreamRemote)
+		}
 
-		if _, err := os.Stat(worktreeDir); err == nil {
-			progress.Error(fmt.Sprintf("Worktree directory already exists: %s", worktreeDir))
-			return nil, fmt.Errorf("worktree directory already exists: %s", worktreeDir)
+		target := opts.Target
+		if target == "" {
+			target = sourceBranches[name]
+		}
+		validations[i] = validateUpRepo(name, repoDir, filepath.Join(treesDir, name), branchName, target, sourceBranches[name], upstreamBase)
+	})
+
+	for i, name := range repoNames {
+		validation := validations[i]
+		if validation.err != nil {
+			progress.Error(validation.errMessage)
+			return nil, validation.err
 		}
+		progress.Info(fmt.Sprintf("%s: will %s", name, validation.plan))
+
+		plan.add(StepCreateWorktree, name, validation.plan)
+		plan.Repos = append(plan.Repos, UpRepoPlan{
+			Repo:        name,
+			WorktreeDir: filepath.Join(treesDir, name),
+			BranchState: validation.state,
+			Source:      validation.source,
+		})
+	}
+
+	progress.Success("Validation completed successfully")
 
-		localExists, err := git.LocalBranchExists(repoDir, branchName)
+	if cfg.HasPortConfig() {
+		portAllocations, err := ports.NewPortAllocations(projectDir, cfg.GetBasePort(), cfg.GetMaxPorts())
 		if err != nil {
-			progress.Error(fmt.Sprintf("Failed to check local branch for %s", name))
-			return nil, fmt.Errorf("failed to check local branch for %s: %w", name, err)
+			return nil, fmt.Errorf("failed to initialize port allocations: %w", err)
 		}
-
-		remoteExists, err := git.RemoteBranchExists(repoDir, branchName)
+		plan.Ports, err = portAllocations.PreviewPorts(featureName, cfg.GetPortsPerFeature())
 		if err != nil {
-			progress.Error(fmt.Sprintf("Failed to check remote branch for %s", name))
-			return nil, fmt.Errorf("failed to check remote branch for %s: %w", name, err)
+			return nil, fmt.Errorf("failed to allocate ports for feature: %w", err)
 		}
+		plan.add(StepAllocatePorts, "", "allocate "+describePorts(plan.Ports))
+	}
 
-		// When using a target, existing branches are conflicts
-		if opts.Target != "" && sourceBranches[name] != "" {
-			if localExists {
-				progress.Error(fmt.Sprintf("Branch %s already exists locally in %s", branchName, name))
-				return nil, fmt.Errorf("branch %s already exists locally in repository %s", branchName, name)
-			}
-			progress.Info(fmt.Sprintf("%s: will create worktree with new branch %s from %s", name, branchName, sourceBranches[name]))
-		} else if opts.Target != "" && sourceBranches[name] == "" {
-			if localExists {
-				progress.Info(fmt.Sprintf("%s: will create worktree with existing local branch %s", name, branchName))
-			} else if remoteExists {
-				progress.Info(fmt.Sprintf("%s: will create worktree with existing remote branch %s", name, branchName))
-			} else {
-				progress.Info(fmt.Sprintf("%s: will create worktree with new branch %s from default branch", name, branchName))
-			}
-		} else {
-			if localExists {
-				progress.Info(fmt.Sprintf("%s: will create worktree with existing local branch %s", name, branchName))
-			} else if remoteExists {
-				progress.Info(fmt.Sprintf("%s: will create worktree with existing remote branch %s", name, branchName))
-			} else {
-				progress.Info(fmt.Sprintf("%s: will create worktree with new branch %s", name, branchName))
-			}
+	for _, name := range repoNames {
+		for _, envFile := range repos[name].EnvFiles {
+			plan.add(StepWriteEnvFile, name, fmt.Sprintf("write %s from %s", envFile.Dest, envFile.Source))
 		}
+	}
+
+	if cfg.Setup != "" {
+		plan.add(StepRunScript, "", "run setup script "+cfg.Setup)
+	}
+
+	plan.add(StepSaveMetadata, "", "save feature metadata")
+
+	plan.addHookSteps(projectDir, hooks.Up)
+
+	return plan, nil
+}
+
+// ApplyUp creates the feature described by a plan from PlanUp, rolling back
+// everything it did if a step fails. Each completed step is journaled, so
+// ResumeOperation or RollbackOperation can pick up if the process is killed.
+func ApplyUp(opts UpOptions, plan *UpPlan) (*UpResult, error) {
+	j, err := beginJournal(opts.ProjectDir, "up", opts.FeatureName, func(j *Journal) {
+		j.Up = plan
+		j.DisplayName = opts.DisplayName
+		j.ForceRefresh = opts.ForceRefresh
+		j.SkipRefresh = opts.SkipRefresh
+		j.Target = opts.Target
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer j.release()
+	return applyUp(opts, plan, j)
+}
+
+// applyUp runs the steps of an up plan, skipping the ones a resumed journal
+// already completed.
+func applyUp(opts UpOptions, plan *UpPlan, j *Journal) (*UpResult, error) {
+	projectDir := opts.ProjectDir
+	cfg := opts.Config
+	progress := opts.Progress
+	featureName := opts.FeatureName
 
-		states[name] = &upState{
-			repoName:        name,
-			worktreeCreated: false,
-			worktreeDir:     worktreeDir,
-			branchName:      branchName,
-			treesDirCreated: false,
-			portAllocated:   false,
-			setupRan:        false,
+	branchName := plan.BranchName
+	treesDir := plan.TreesDir
+	repos := cfg.GetRepos()
+
+	repoNames := make([]string, len(plan.Repos))
+	states := make(map[string]*upState)
+	for i, repoPlan := range plan.Repos {
+		repoNames[i] = repoPlan.Repo
+		states[repoPlan.Repo] = &upState{
+			repoName:      repoPlan.Repo,
+			worktreeDir:   repoPlan.WorktreeDir,
+			branchName:    branchName,
+			branchExisted: repoPlan.BranchState == BranchStateLocal,
 		}
 	}
 
-	progress.Success("Validation completed successfully")
+	workers := upWorkers(opts)
+	workerProgress := newLockedProgress(progress)
 
 	// Phase 2: Create trees directory
 	progress.Start("Creating trees directory")
@@ -247,32 +393,69 @@ func Up(opts UpOptions) (*UpResult, error) {
 	progress.Success("Trees directory created")
 
 	// Phase 3: Create worktrees
-	repoNames := []string{}
-	total := len(repos)
-	i := 0
+	worktreeSteps := &stepCounter{progress: workerProgress, from: 0, to: 50, total: len(repoNames)}
+	worktreeErrs := make([]error, len(repoNames))
 
-	for name, repo := range repos {
-		repoNames = append(repoNames, name)
+	forEachParallel(len(repoNames), workers, func(i int) {
+		name := repoNames[i]
 		state := states[name]
-		repoDir := repo.GetRepoPath(projectDir)
+		repoDir := repos[name].GetRepoPath(projectDir)
+
+		// A resumed up keeps the worktrees it already finished
+		if j.Completed(StepCreateWorktree, name) {
+			state.worktreeCreated = true
+			worktreeSteps.step(fmt.Sprintf("Created worktree for %s", name))
+			return
+		}
 
-		progress.UpdateWithProgress(fmt.Sprintf("Creating worktree for %s...", name), (i+1)*50/total)
+		// A kill right after `git worktree add` leaves a worktree the journal doesn't know about
+		if !worktreeOnBranch(state.worktreeDir, state.branchName) {
+			// Prune stale worktree entries so orphaned registrations don't block the new worktree
+			_ = git.PruneWorktrees(repoDir)
 
-		var err error
-		if opts.Target != "" && sourceBranches[name] != "" {
-			err = git.CreateWorktreeFromSourceQuiet(repoDir, state.worktreeDir, state.branchName, sourceBranches[name], name)
-		} else {
-			err = git.CreateWorktreeQuiet(repoDir, state.worktreeDir, state.branchName, name)
+			source := plan.Repos[i].Source
+			var err error
+			if source != "" {
+				err = git.CreateWorktreeFromSourceQuiet(repoDir, state.worktreeDir, state.branchName, source, name, repos[name].Sparse...)
+			} else {
+				err = git.CreateWorktreeQuiet(repoDir, state.worktreeDir, state.branchName, name, repos[name].Sparse...)
+			}
+
+			// Branching from upstream/<branch> would make upstream the push target; features push to origin
+			if err == nil && strings.HasPrefix(source, git.UpstreamRemote+"/") {
+				err = git.UnsetBranchUpstream(repoDir, state.branchName)
+			}
+
+			if err != nil {
+				// A sparse checkout can fail after the worktree was added; make sure rollback removes it
+				if _, statErr := os.Stat(state.worktreeDir); statErr == nil {
+					state.worktreeCreated = true
+				}
+				worktreeErrs[i] = err
+				worktreeSteps.step(fmt.Sprintf("Failed to create worktree for %s", name))
+				return
+			}
 		}
 
-		if err != nil {
+		// Each worker only touches its own repo's state
+		state.worktreeCreated = true
+
+		if err := UpdateSubmodulesAndLFS(repos[name], state.worktreeDir); err != nil {
+			worktreeErrs[i] = err
+			worktreeSteps.step(fmt.Sprintf("Failed to create worktree for %s", name))
+			return
+		}
+		j.record(StepCreateWorktree, name, state.worktreeDir)
+		worktreeSteps.step(fmt.Sprintf("Created worktree for %s", name))
+	})
+
+	// All workers have finished, so rollback sees every worktree that was created
+	for i, name := range repoNames {
+		if err := worktreeErrs[i]; err != nil {
 			progress.Error(fmt.Sprintf("Failed to create worktree for %s", name))
-			rollbackUp(projectDir, treesDir, featureName, states, cfg, progress)
+			rollbackUp(projectDir, treesDir, featureName, states, cfg, progress, j)
 			return nil, fmt.Errorf("failed to create worktree for %s: %w", name, err)
 		}
-
-		state.worktreeCreated = true
-		i++
 	}
 
 	var worktreesMessage string
@@ -293,20 +476,21 @@ func Up(opts UpOptions) (*UpResult, error) {
 		portAllocations, err := ports.NewPortAllocations(projectDir, cfg.GetBasePort(), cfg.GetMaxPorts())
 		if err != nil {
 			progress.Error("Failed to initialize port allocations")
-			rollbackUp(projectDir, treesDir, featureName, states, cfg, progress)
+			rollbackUp(projectDir, treesDir, featureName, states, cfg, progress, j)
 			return nil, fmt.Errorf("failed to initialize port allocations: %w", err)
 		}
 
 		allocatedPorts, err = portAllocations.AllocatePort(featureName, cfg.GetPortsPerFeature())
 		if err != nil {
 			progress.Error("Failed to allocate ports")
-			rollbackUp(projectDir, treesDir, featureName, states, cfg, progress)
+			rollbackUp(projectDir, treesDir, featureName, states, cfg, progress, j)
 			return nil, fmt.Errorf("failed to allocate ports for feature: %w", err)
 		}
 
 		for _, state := range states {
 			state.portAllocated = true
 		}
+		j.record(StepAllocatePorts, "", describePorts(allocatedPorts))
 
 		if len(allocatedPorts) == 1 {
 			progress.Success(fmt.Sprintf("Allocated port %d", allocatedPorts[0]))
@@ -316,36 +500,78 @@ func Up(opts UpOptions) (*UpResult, error) {
 	}
 
 	// Phase 5: Process env files
+	envFileRecords := make(map[string]features.GeneratedEnvFile)
 	if HasEnvFiles(repos) {
 		progress.UpdateWithProgress("Processing environment files...", 65)
 
 		envVars := BuildEnvVars(projectDir, treesDir, featureName, opts.DisplayName, allocatedPorts, cfg, repos)
+		secrets := envfile.NewSecretResolver(projectDir, cfg.Secrets)
+		var envIssues []EnvFileIssue
 
-		for name, repo := range repos {
-			if len(repo.EnvFiles) > 0 {
-				state := states[name]
-				sourceRepoDir := repo.GetRepoPath(projectDir)
-
-				// Determine refresh behavior for env scripts
-				shouldRefresh := false
-				if opts.ForceRefresh {
-					shouldRefresh = true
-				} else if !opts.SkipRefresh {
-					shouldRefresh = repo.ShouldAutoRefresh()
-				}
+		var envRepos []string
+		for _, name := range repoNames {
+			if len(repos[name].EnvFiles) > 0 {
+				envRepos = append(envRepos, name)
+			}
+		}
 
-				if err := envfile.ProcessEnvFiles(name, repo.EnvFiles, sourceRepoDir, state.worktreeDir, envVars, shouldRefresh); err != nil {
-					progress.Error(fmt.Sprintf("Failed to process env files for %s", name))
-					rollbackUp(projectDir, treesDir, featureName, states, cfg, progress)
-					return nil, fmt.Errorf("failed to process env files for %s: %w", name, err)
-				}
+		envSteps := &stepCounter{progress: workerProgress, from: 65, to: 75, total: len(envRepos)}
+		envRecords := make([]map[string]features.GeneratedEnvFile, len(envRepos))
+		envRepoIssues := make([][]EnvFileIssue, len(envRepos))
+		envErrs := make([]error, len(envRepos))
+
+		forEachParallel(len(envRepos), workers, func(i int) {
+			name := envRepos[i]
+			repo := repos[name]
+			sourceRepoDir := repo.GetRepoPath(projectDir)
+
+			// Determine refresh behavior for env scripts
+			shouldRefresh := false
+			if opts.ForceRefresh {
+				shouldRefresh = true
+			} else if !opts.SkipRefresh {
+				shouldRefresh = repo.ShouldAutoRefresh()
+			}
+
+			envRecords[i] = make(map[string]features.GeneratedEnvFile)
+			envRepoIssues[i], envErrs[i] = writeGeneratedEnvFiles(name, repo, sourceRepoDir, states[name].worktreeDir, envVars, shouldRefresh, projectDir, secrets, envRecords[i], workerProgress)
+			if envErrs[i] == nil {
+				j.record(StepWriteEnvFile, name, fmt.Sprintf("%d env file(s)", len(repo.EnvFiles)))
+			}
+			envSteps.step(fmt.Sprintf("Processed environment files for %s", name))
+		})
+
+		for i, name := range envRepos {
+			if err := envErrs[i]; err != nil {
+				progress.Error(fmt.Sprintf("Failed to process env files for %s", name))
+				rollbackUp(projectDir, treesDir, featureName, states, cfg, progress, j)
+				return nil, fmt.Errorf("failed to process env files for %s: %w", name, err)
+			}
+			for key, record := range envRecords[i] {
+				envFileRecords[key] = record
 			}
+			envIssues = append(envIssues, envRepoIssues[i]...)
+		}
+
+		// Fail before the setup script runs if required values are missing
+		if err := missingEnvError(featureName, envIssues); err != nil {
+			progress.Error("Required env values are missing")
+			rollbackUp(projectDir, treesDir, featureName, states, cfg, progress, j)
+			return nil, err
 		}
 		progress.Success("Environment files processed")
 	}
 
-	// Phase 6: Run setup script
-	if cfg.Setup != "" {
+	// Phase 5b: Let the caller prepare the worktrees for the setup script
+	if opts.BeforeSetup != nil && !j.Completed(StepRunScript, "") {
+		if err := opts.BeforeSetup(treesDir); err != nil {
+			rollbackUp(projectDir, treesDir, featureName, states, cfg, progress, j)
+			return nil, err
+		}
+	}
+
+	// Phase 6: Run setup script (unless a resumed up already ran it)
+	if cfg.Setup != "" && !j.Completed(StepRunScript, "") {
 		progress.UpdateWithProgress("Running setup script...", 80)
 
 		if err := RunSetupScript(projectDir, treesDir, featureName, opts.DisplayName, cfg, allocatedPorts, repos, progress, opts.Output); err != nil {
@@ -353,35 +579,45 @@ func Up(opts UpOptions) (*UpResult, error) {
 			for _, state := range states {
 				state.setupRan = true
 			}
-			rollbackUp(projectDir, treesDir, featureName, states, cfg, progress)
+			rollbackUp(projectDir, treesDir, featureName, states, cfg, progress, j)
 			return nil, fmt.Errorf("setup script failed: %w", err)
 		}
 
 		for _, state := range states {
 			state.setupRan = true
 		}
+		j.record(StepRunScript, "", cfg.Setup)
 		progress.Success("Ran setup script")
 	}
 
-	// Phase 7: Store display name metadata (if provided)
-	if opts.DisplayName != "" {
-		metadataStore, err := features.NewMetadataStore(projectDir)
-		if err != nil {
-			progress.Warning(fmt.Sprintf("Failed to initialize metadata store: %v", err))
-		} else {
+	// Phase 7: Store creation info, display name (if provided) and generated env file metadata
+	metadataStore, err := features.NewMetadataStore(projectDir)
+	if err != nil {
+		progress.Warning(fmt.Sprintf("Failed to initialize metadata store: %v", err))
+	} else {
+		if err := metadataStore.SetCreated(featureName, newCreationInfo(opts, plan)); err != nil {
+			progress.Warning(fmt.Sprintf("Failed to save creation info: %v", err))
+		}
+		if opts.DisplayName != "" {
 			if err := metadataStore.SetDisplayName(featureName, opts.DisplayName); err != nil {
 				progress.Warning(fmt.Sprintf("Failed to save display name: %v", err))
 			}
 		}
+		if err := metadataStore.SetEnvFiles(featureName, envFileRecords); err != nil {
+			progress.Warning(fmt.Sprintf("Failed to record generated env files: %v", err))
+		}
+		j.record(StepSaveMetadata, "", "creation info, display name and env file records")
 	}
 
 	// Phase 8: Execute up hooks (after setup script)
 	mergedCfg, err := config.LoadMergedConfig(projectDir)
-	if err == nil && len(mergedCfg.Hooks) > 0 {
+	if err == nil && len(mergedCfg.Hooks) > 0 && !j.Completed(StepRunHook, "") {
 		hookEnv := BuildEnvVars(projectDir, treesDir, featureName, opts.DisplayName, allocatedPorts, cfg, repos)
 		hooks.ExecuteHooks(hooks.Up, mergedCfg.Hooks, projectDir, treesDir, hookEnv, progress)
+		j.record(StepRunHook, "", string(hooks.Up))
 	}
 
+	j.finish()
 	progress.Complete(fmt.Sprintf("Feature '%s' created successfully", featureName))
 
 	return &UpResult{
@@ -394,8 +630,121 @@ func Up(opts UpOptions) (*UpResult, error) {
 	}, nil
 }
 
+// upValidation is the outcome of checking one repository before creating its worktree.
+type upValidation struct {
+	plan       string // How the worktree will be created
+	state      string // One of the BranchState constants
+	source     string // Branch to create the new branch from; empty uses CreateWorktree's defaults
+	errMessage string // Progress message when err is set
+	err        error
+}
+
+// validateUpRepo checks that a repository can get a worktree for branchName and
+// describes how it will be created. It only runs git in repoDir, so repos can
+// be validated concurrently. upstreamBase, when set, is the branch new branches
+// of a fork are created from.
+func validateUpRepo(name, repoDir, worktreeDir, branchName, target, sourceBranch, upstreamBase string) upValidation {
+	if !git.IsGitRepo(repoDir) {
+		return upValidation{
+			errMessage: fmt.Sprintf("Source repo not found at %s", repoDir),
+			err:        fmt.Errorf("source repo not found at %s", repoDir),
+		}
+	}
+
+	if _, err := os.Stat(worktreeDir); err == nil {
+		return upValidation{
+			errMessage: fmt.Sprintf("Worktree directory already exists: %s", worktreeDir),
+			err:        fmt.Errorf("worktree directory already exists: %s", worktreeDir),
+		}
+	}
+
+	localExists, err := git.LocalBranchExists(repoDir, branchName)
+	if err != nil {
+		return upValidation{
+			errMessage: fmt.Sprintf("Failed to check local branch for %s", name),
+			err:        fmt.Errorf("failed to check local branch for %s: %w", name, err),
+		}
+	}
+
+	remoteExists, err := git.RemoteBranchExists(repoDir, branchName)
+	if err != nil {
+		return upValidation{
+			errMessage: fmt.Sprintf("Failed to check remote branch for %s", name),
+			err:        fmt.Errorf("failed to check remote branch for %s: %w", name, err),
+		}
+	}
+
+	// When using a target, existing branches are conflicts
+	if target != "" && sourceBranch != "" {
+		if localExists {
+			return upValidation{
+				errMessage: fmt.Sprintf("Branch %s already exists locally in %s", branchName, name),
+				err:        fmt.Errorf("branch %s already exists locally in repository %s", branchName, name),
+			}
+		}
+		return upValidation{
+			plan:   fmt.Sprintf("create worktree with new branch %s from %s", branchName, sourceBranch),
+			state:  BranchStateNew,
+			source: sourceBranch,
+		}
+	}
+
+	switch {
+	case localExists:
+		return upValidation{plan: fmt.Sprintf("create worktree with existing local branch %s", branchName), state: BranchStateLocal}
+	case remoteExists:
+		return upValidation{plan: fmt.Sprintf("create worktree with existing remote branch %s", branchName), state: BranchStateRemote}
+	case upstreamBase != "":
+		return upValidation{
+			plan:   fmt.Sprintf("create worktree with new branch %s from %s", branchName, upstreamBase),
+			state:  BranchStateNew,
+			source: upstreamBase,
+		}
+	case target != "":
+		return upValidation{plan: fmt.Sprintf("create worktree with new branch %s from default branch", branchName), state: BranchStateNew}
+	default:
+		return upValidation{plan: fmt.Sprintf("create worktree with new branch %s", branchName), state: BranchStateNew}
+	}
+}
+
+// writeGeneratedEnvFiles renders a repo's env files into its worktree and adds a
+// record of each generated file to records. Files with unresolved values are
+// reported as warnings and returned.
+func writeGeneratedEnvFiles(repoName string, repo *config.Repo, sourceRepoDir, worktreeDir string, envVars map[string]string, shouldRefresh bool, projectDir string, secrets *envfile.SecretResolver, records map[string]features.GeneratedEnvFile, progress ProgressReporter) ([]EnvFileIssue, error) {
+	rendered, err := envfile.RenderEnvFiles(repoName, repo.EnvFiles, sourceRepoDir, envVars, shouldRefresh, projectDir, secrets)
+	if err != nil {
+		return nil, err
+	}
+
+	var issues []EnvFileIssue
+	for _, file := range rendered {
+		reportCacheEvents(repoName, sourceRepoDir, file, progress)
+		if issue := newEnvFileIssue(repoName, file.Dest, file.Unresolved); issue != nil {
+			progress.Warning(issue.Describe())
+			issues = append(issues, *issue)
+		}
+		if err := envfile.WriteEnvFile(worktreeDir, file); err != nil {
+			return nil, err
+		}
+		records[features.EnvFileKey(repoName, file.Dest)] = features.NewGeneratedEnvFile(file.Content, file.Redacted)
+	}
+
+	return issues, nil
+}
+
+// reportCacheEvents reports how each cached env script for a rendered file was resolved.
+func reportCacheEvents(repoName, sourceRepoDir string, file envfile.RenderedFile, progress ProgressReporter) {
+	for _, event := range file.CacheEvents {
+		script := event.Script
+		if rel, err := filepath.Rel(sourceRepoDir, script); err == nil {
+			script = rel
+		}
+		progress.Info(fmt.Sprintf("%s: env script %s cache %s", repoName, script, event.Status))
+	}
+}
+
 // rollbackUp cleans up on failure.
-func rollbackUp(projectDir, treesDir, featureName string, states map[string]*upState, cfg *config.Config, progress ProgressReporter) {
+func rollbackUp(projectDir, treesDir, featureName string, states map[string]*upState, cfg *config.Config, progress ProgressReporter, j *Journal) {
 	progress.Warning("Rolling back changes due to failure")
 
 	repos := cfg.GetRepos()
@@ -413,9 +762,9 @@ func rollbackUp(projectDir, treesDir, featureName string, states map[string]*upS
 				progress.Info(fmt.Sprintf("%s: worktree removed", name))
 			}
 
-			// Delete branch if it exists
+			// Delete branch if it exists and this up created it
 			localExists, _ := git.LocalBranchExists(repoDir, state.branchName)
-			if localExists {
+			if localExists && !state.branchExisted {
 				progress.Info(fmt.Sprintf("%s: deleting branch %s", name, state.branchName))
 				if err := git.DeleteBranchQuiet(repoDir, state.branchName); err != nil {
 					progress.Warning(fmt.Sprintf("Failed to delete branch %s for %s: %v", state.branchName, name, err))
@@ -467,5 +816,40 @@ func rollbackUp(projectDir, treesDir, featureName string, states map[string]*upS
 		}
 	}
 
+	j.finish()
 	progress.Info("Rollback completed")
 }
+
+// newCreationInfo records how an up created a feature: who ran it, with which
+// target and branch prefix, and the commit each worktree started at.
+func newCreationInfo(opts UpOptions, plan *UpPlan) *features.CreationInfo {
+	info := &features.CreationInfo{
+		At:          time.Now(),
+		Target:      opts.Target,
+		Prefix:      strings.TrimSuffix(plan.BranchName, opts.FeatureName),
+		BaseCommits: make(map[string]string),
+	}
+	for _, repoPlan := range plan.Repos {
+		if commit, err := git.ResolveCommit(repoPlan.WorktreeDir, "HEAD"); err == nil {
+			info.BaseCommits[repoPlan.Repo] = commit
+		}
+		if info.By == "" {
+			info.By = git.ConfigValue(repoPlan.WorktreeDir, "user.name")
+		}
+	}
+	if info.By == "" {
+		if u, err := user.Current(); err == nil {
+			info.By = u.Username
+		}
+	}
+	return info
+}
+
+// worktreeOnBranch reports whether dir is a worktree with branchName checked out.
+func worktreeOnBranch(dir, branchName string) bool {
+	if _, err := os.Stat(dir); err != nil {
+		return false
+	}
+	branch, err := git.GetWorktreeBranch(dir)
+	return err == nil && branch == branchName
+}
@@ -0,0 +1,30 @@
+package operations
+
+import (
+	"ramp/internal/config"
+	"ramp/internal/git"
+)
+
+// AddUpstreamRemote points the upstream remote of a fork at the repo's
+// configured upstream URL and fetches it. Repos without upstream are left alone.
+func AddUpstreamRemote(repo *config.Repo, repoDir string) error {
+	if repo.Upstream == "" {
+		return nil
+	}
+	if err := git.EnsureRemote(repoDir, git.UpstreamRemote, repo.Upstream); err != nil {
+		return err
+	}
+	return git.FetchRemoteQuiet(repoDir, git.UpstreamRemote)
+}
+
+// BaseBranch returns the branch features of a repo are created from and
+// compared against: upstream's default branch (e.g. upstream/main) for forks,
+// otherwise the repo's own default branch.
+func BaseBranch(repo *config.Repo, repoDir string) (string, error) {
+	if repo != nil && repo.Upstream != "" {
+		if branch, err := git.GetRemoteDefaultBranch(repoDir, git.UpstreamRemote); err == nil {
+			return branch, nil
+		}
+	}
+	return git.GetDefaultBranch(repoDir)
+}
@@ -108,11 +108,9 @@ func (pa *PortAllocations) AllocatePort(featureName string, count int) ([]int, e
 		return ports, nil
 	}
 
-	// Find N consecutive available ports
-	ports := pa.findNextAvailablePorts(count)
-	if len(ports) < count {
-		return nil, fmt.Errorf("insufficient available ports (need %d, found %d) in range %d-%d",
-			count, len(ports), pa.basePort, pa.basePort+pa.maxPorts-1)
+	ports, err := pa.PreviewPorts(featureName, count)
+	if err != nil {
+		return nil, err
 	}
 
 	pa.allocations[featureName] = ports
@@ -123,6 +121,22 @@ func (pa *PortAllocations) AllocatePort(featureName string, count int) ([]int, e
 	return ports, nil
 }
 
+// PreviewPorts returns the ports AllocatePort would give featureName without
+// recording the allocation.
+func (pa *PortAllocations) PreviewPorts(featureName string, count int) ([]int, error) {
+	if ports, exists := pa.allocations[featureName]; exists {
+		return ports, nil
+	}
+
+	// Find N consecutive available ports
+	ports := pa.findNextAvailablePorts(count)
+	if len(ports) < count {
+		return nil, fmt.Errorf("insufficient available ports (need %d, found %d) in range %d-%d",
+			count, len(ports), pa.basePort, pa.basePort+pa.maxPorts-1)
+	}
+	return ports, nil
+}
+
 func (pa *PortAllocations) ReleasePort(featureName string) error {
 	if _, exists := pa.allocations[featureName]; !exists {
 		// Already released or never allocated
@@ -137,6 +151,57 @@ func (pa *PortAllocations) ReleasePort(featureName string) error {
 	return nil
 }
 
+// RenameFeature moves the ports of a feature to a new name. Features without
+// ports are left alone.
+func (pa *PortAllocations) RenameFeature(featureName, newName string) error {
+	ports, exists := pa.allocations[featureName]
+	if !exists {
+		return nil
+	}
+	if _, taken := pa.allocations[newName]; taken {
+		return fmt.Errorf("ports are already allocated to %s", newName)
+	}
+
+	pa.allocations[newName] = ports
+	delete(pa.allocations, featureName)
+	if err := pa.save(); err != nil {
+		return fmt.Errorf("failed to save port allocation after rename: %w", err)
+	}
+
+	return nil
+}
+
+// ReservePorts allocates specific ports to a feature, such as the ones it had
+// before it was archived. It fails if the feature already has ports or any of
+// the ports is out of range or allocated to another feature.
+func (pa *PortAllocations) ReservePorts(featureName string, ports []int) error {
+	if _, exists := pa.allocations[featureName]; exists {
+		return fmt.Errorf("ports are already allocated to %s", featureName)
+	}
+
+	allocatedTo := make(map[int]string)
+	for feature, featurePorts := range pa.allocations {
+		for _, port := range featurePorts {
+			allocatedTo[port] = feature
+		}
+	}
+	for _, port := range ports {
+		if port < pa.basePort || port >= pa.basePort+pa.maxPorts {
+			return fmt.Errorf("port %d is outside the range %d-%d", port, pa.basePort, pa.basePort+pa.maxPorts-1)
+		}
+		if feature, taken := allocatedTo[port]; taken {
+			return fmt.Errorf("port %d is allocated to %s", port, feature)
+		}
+	}
+
+	pa.allocations[featureName] = append([]int{}, ports...)
+	if err := pa.save(); err != nil {
+		return fmt.Errorf("failed to save port allocation: %w", err)
+	}
+
+	return nil
+}
+
 func (pa *PortAllocations) GetPorts(featureName string) ([]int, bool) {
 	ports, exists := pa.allocations[featureName]
 	return ports, exists
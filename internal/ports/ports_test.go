@@ -315,4 +315,71 @@ func TestPortAllocationsMigration(t *testing.T) {
 	if len(newFormat["feature-a"]) != 1 || newFormat["feature-a"][0] != 3000 {
 		t.Errorf("Expected feature-a to be [3000] in new format, got %v", newFormat["feature-a"])
 	}
-}
\ No newline at end of file
+}
+
+func TestRenameFeature(t *testing.T) {
+	tempDir := t.TempDir()
+	os.MkdirAll(filepath.Join(tempDir, ".ramp"), 0755)
+
+	pa, err := NewPortAllocations(tempDir, 3000, 10)
+	if err != nil {
+		t.Fatalf("Failed to create PortAllocations: %v", err)
+	}
+	pa.AllocatePort("old", 2)
+	pa.AllocatePort("other", 1)
+
+	if err := pa.RenameFeature("old", "other"); err == nil {
+		t.Error("Expected renaming onto a feature with ports to fail")
+	}
+	if err := pa.RenameFeature("old", "new"); err != nil {
+		t.Fatalf("RenameFeature() error = %v", err)
+	}
+	if err := pa.RenameFeature("missing", "whatever"); err != nil {
+		t.Errorf("RenameFeature() of a feature without ports error = %v", err)
+	}
+
+	// The rename is persisted
+	reloaded, err := NewPortAllocations(tempDir, 3000, 10)
+	if err != nil {
+		t.Fatalf("Failed to reload PortAllocations: %v", err)
+	}
+	if _, exists := reloaded.GetPorts("old"); exists {
+		t.Error("Expected old to have no ports after rename")
+	}
+	if ports, _ := reloaded.GetPorts("new"); len(ports) != 2 || ports[0] != 3000 {
+		t.Errorf("Expected new to keep ports [3000 3001], got %v", ports)
+	}
+}
+
+func TestReservePorts(t *testing.T) {
+	tempDir := t.TempDir()
+	os.MkdirAll(filepath.Join(tempDir, ".ramp"), 0755)
+
+	pa, err := NewPortAllocations(tempDir, 3000, 10)
+	if err != nil {
+		t.Fatalf("Failed to create PortAllocations: %v", err)
+	}
+	pa.AllocatePort("other", 1) // 3000
+
+	if err := pa.ReservePorts("restored", []int{3000, 3001}); err == nil {
+		t.Error("Expected reserving a port of another feature to fail")
+	}
+	if err := pa.ReservePorts("restored", []int{3010}); err == nil {
+		t.Error("Expected reserving a port outside the range to fail")
+	}
+	if err := pa.ReservePorts("restored", []int{3004, 3005}); err != nil {
+		t.Fatalf("ReservePorts() error = %v", err)
+	}
+	if err := pa.ReservePorts("restored", []int{3006}); err == nil {
+		t.Error("Expected reserving ports for a feature that has some to fail")
+	}
+
+	// The reservation is persisted and AllocatePort keeps it
+	reloaded, err := NewPortAllocations(tempDir, 3000, 10)
+	if err != nil {
+		t.Fatalf("Failed to reload PortAllocations: %v", err)
+	}
+	if ports, _ := reloaded.AllocatePort("restored", 2); len(ports) != 2 || ports[0] != 3004 {
+		t.Errorf("Expected restored to keep ports [3004 3005], got %v", ports)
+	}
+}
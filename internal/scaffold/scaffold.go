@@ -101,6 +101,13 @@ trees/
 
 # Feature metadata (not committed to git)
 .ramp/feature_metadata.json
+
+# Encrypted local secrets and caches (not committed to git)
+.ramp/secrets.enc
+.ramp/cache/
+
+# Journals of operations in progress (not committed to git)
+.ramp/journal/
 `
 
 	if err := os.WriteFile(gitignorePath, []byte(content), 0644); err != nil {
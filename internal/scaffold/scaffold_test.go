@@ -67,6 +67,7 @@ func TestCreateGitignore(t *testing.T) {
 		"trees/",
 		".ramp/local.yaml",
 		".ramp/port_allocations.json",
+		".ramp/secrets.enc",
 	}
 
 	for _, entry := range expectedEntries {
@@ -0,0 +1,52 @@
+package ui
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// SecretMask replaces registered secret values in output
+const SecretMask = "********"
+
+var (
+	secretsMu sync.RWMutex
+	secrets   = make(map[string]bool)
+)
+
+// RegisterSecret records a value that must never be shown in progress output.
+// Very short values are ignored to avoid masking unrelated text.
+func RegisterSecret(value string) {
+	value = strings.TrimSpace(value)
+	if len(value) < 4 {
+		return
+	}
+
+	secretsMu.Lock()
+	secrets[value] = true
+	secretsMu.Unlock()
+}
+
+// MaskSecrets replaces every registered secret value in s with SecretMask.
+func MaskSecrets(s string) string {
+	secretsMu.RLock()
+	defer secretsMu.RUnlock()
+
+	if len(secrets) == 0 {
+		return s
+	}
+
+	// Replace longer values first so a secret containing another is fully masked
+	values := make([]string, 0, len(secrets))
+	for value := range secrets {
+		values = append(values, value)
+	}
+	sort.Slice(values, func(i, j int) bool {
+		return len(values[i]) > len(values[j])
+	})
+
+	for _, value := range values {
+		s = strings.ReplaceAll(s, value, SecretMask)
+	}
+	return s
+}
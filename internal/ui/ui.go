@@ -26,6 +26,7 @@ func NewProgress() *ProgressUI {
 }
 
 func (p *ProgressUI) Start(message string) {
+	message = MaskSecrets(message)
 	if Verbose {
 		fmt.Printf("%s\n", message)
 		return
@@ -35,6 +36,7 @@ func (p *ProgressUI) Start(message string) {
 }
 
 func (p *ProgressUI) Success(message string) {
+	message = MaskSecrets(message)
 	if Verbose {
 		fmt.Printf("✓ %s\n", message)
 		return
@@ -44,6 +46,7 @@ func (p *ProgressUI) Success(message string) {
 }
 
 func (p *ProgressUI) Warning(message string) {
+	message = MaskSecrets(message)
 	if Verbose {
 		fmt.Printf("Warning: %s\n", message)
 		return
@@ -53,6 +56,7 @@ func (p *ProgressUI) Warning(message string) {
 }
 
 func (p *ProgressUI) Error(message string) {
+	message = MaskSecrets(message)
 	if Verbose {
 		fmt.Printf("Error: %s\n", message)
 		return
@@ -62,6 +66,7 @@ func (p *ProgressUI) Error(message string) {
 }
 
 func (p *ProgressUI) Info(message string) {
+	message = MaskSecrets(message)
 	if Verbose {
 		fmt.Printf("  %s\n", message)
 	}
@@ -76,6 +81,7 @@ func (p *ProgressUI) Stop() {
 
 // Update changes the spinner message without stopping it
 func (p *ProgressUI) Update(message string) {
+	message = MaskSecrets(message)
 	if Verbose {
 		fmt.Printf("%s\n", message)
 		return
@@ -198,5 +204,6 @@ func RunCommandWithProgressQuiet(cmd *exec.Cmd, message string) error {
 
 // Package-level warning function for standalone warnings
 func Warning(message string) {
+	message = MaskSecrets(message)
 	fmt.Printf("⚠️  %s\n", message)
 }
\ No newline at end of file
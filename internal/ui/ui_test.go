@@ -517,3 +517,22 @@ func TestRunCommandWithProgress(t *testing.T) {
 		}
 	})
 }
+
+func TestMaskSecrets(t *testing.T) {
+	RegisterSecret("hunter2-token")
+	RegisterSecret("hunter2-token-extended")
+	RegisterSecret("ab") // too short to mask
+
+	got := MaskSecrets("key=hunter2-token-extended other=hunter2-token ab")
+	want := "key=" + SecretMask + " other=" + SecretMask + " ab"
+	if got != want {
+		t.Errorf("MaskSecrets() = %q, want %q", got, want)
+	}
+
+	stdout, _ := captureOutput(func() {
+		NewProgress().Error("failed with hunter2-token")
+	})
+	if strings.Contains(stdout, "hunter2-token") {
+		t.Errorf("progress output leaked secret: %q", stdout)
+	}
+}
@@ -0,0 +1,164 @@
+package uiapi
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"ramp/internal/config"
+	"ramp/internal/operations"
+)
+
+// ListArchives returns the archived features of a project (ramp archive)
+func (s *Server) ListArchives(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	ref, err := GetProjectRefByID(id)
+	if err != nil || ref == nil {
+		writeError(w, http.StatusNotFound, "Project not found", id)
+		return
+	}
+
+	archives, err := operations.ListArchives(ref.Path)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to read archives", err.Error())
+		return
+	}
+
+	result := make([]ArchivedFeature, len(archives))
+	for i, a := range archives {
+		result[i] = ArchivedFeature{
+			Name:            a.Feature,
+			DisplayName:     a.DisplayName,
+			Archived:        a.Archived,
+			Branches:        []string{},
+			Ports:           a.Ports,
+			RecreateCommand: a.Command,
+		}
+		for _, repo := range a.Repos {
+			result[i].Branches = append(result[i].Branches, repo.Branch)
+			if repo.Snapshot != "" {
+				result[i].UncommittedIn = append(result[i].UncommittedIn, repo.Repo)
+			}
+		}
+	}
+
+	writeJSON(w, http.StatusOK, ArchivesResponse{Archives: result})
+}
+
+// ArchiveFeature saves a feature's branches and uncommitted changes, then removes it (ramp archive)
+func (s *Server) ArchiveFeature(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	name := vars["name"]
+
+	// Acquire project lock to prevent concurrent feature operations
+	unlock := s.acquireProjectLock(id)
+	defer unlock()
+
+	ref, err := GetProjectRefByID(id)
+	if err != nil || ref == nil {
+		writeError(w, http.StatusNotFound, "Project not found", id)
+		return
+	}
+
+	cfg, err := config.LoadConfig(ref.Path)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to load project config", err.Error())
+		return
+	}
+
+	progress := operations.NewWSProgressReporter("archive", name, func(msg interface{}) {
+		s.broadcast(msg)
+	})
+
+	_, err = operations.ArchiveFeature(operations.ArchiveOptions{
+		FeatureName: name,
+		ProjectDir:  ref.Path,
+		Config:      cfg,
+		Progress:    progress,
+	})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to archive feature", err.Error())
+		return
+	}
+	progress.Complete("Archive complete")
+
+	writeJSON(w, http.StatusOK, SuccessResponse{Success: true, Message: "Feature archived"})
+}
+
+// RestoreFeature recreates an archived feature (ramp restore)
+func (s *Server) RestoreFeature(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	name := vars["name"]
+
+	// Acquire project lock to prevent concurrent feature operations
+	unlock := s.acquireProjectLock(id)
+	defer unlock()
+
+	ref, err := GetProjectRefByID(id)
+	if err != nil || ref == nil {
+		writeError(w, http.StatusNotFound, "Project not found", id)
+		return
+	}
+
+	cfg, err := config.LoadConfig(ref.Path)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to load project config", err.Error())
+		return
+	}
+
+	result, err := operations.RestoreFeature(operations.RestoreOptions{
+		FeatureName: name,
+		ProjectDir:  ref.Path,
+		Config:      cfg,
+		Progress: operations.NewWSProgressReporter("up", name, func(msg interface{}) {
+			s.broadcast(msg)
+		}),
+		Output: operations.NewWSOutputStreamerWithContext("up", name, "", func(msg interface{}) {
+			s.broadcast(msg)
+		}),
+	})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to restore feature", err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, Feature{
+		Name:                  result.FeatureName,
+		DisplayName:           result.DisplayName,
+		Repos:                 result.Repos,
+		HasUncommittedChanges: len(result.Restored) > 0,
+	})
+}
+
+// DeleteArchive permanently discards an archived feature (ramp archive --delete)
+func (s *Server) DeleteArchive(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	name := vars["name"]
+
+	unlock := s.acquireProjectLock(id)
+	defer unlock()
+
+	ref, err := GetProjectRefByID(id)
+	if err != nil || ref == nil {
+		writeError(w, http.StatusNotFound, "Project not found", id)
+		return
+	}
+
+	cfg, err := config.LoadConfig(ref.Path)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to load project config", err.Error())
+		return
+	}
+
+	if err := operations.DeleteArchive(ref.Path, cfg, name); err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to delete archive", err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, SuccessResponse{Success: true, Message: "Archive deleted"})
+}
@@ -0,0 +1,57 @@
+package uiapi
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"ramp/internal/config"
+	"ramp/internal/operations"
+)
+
+// CheckProject reports the inconsistencies ramp doctor finds in a project
+func (s *Server) CheckProject(w http.ResponseWriter, r *http.Request) {
+	s.runDoctor(w, r, false)
+}
+
+// FixProject repairs the inconsistencies of a project that can be repaired
+// automatically and reports what's left
+func (s *Server) FixProject(w http.ResponseWriter, r *http.Request) {
+	s.runDoctor(w, r, true)
+}
+
+func (s *Server) runDoctor(w http.ResponseWriter, r *http.Request, fix bool) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	// Acquire project lock so features aren't created or removed mid-check
+	unlock := s.acquireProjectLock(id)
+	defer unlock()
+
+	ref, err := GetProjectRefByID(id)
+	if err != nil || ref == nil {
+		writeError(w, http.StatusNotFound, "Project not found", id)
+		return
+	}
+
+	cfg, err := config.LoadConfig(ref.Path)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to load project config", err.Error())
+		return
+	}
+
+	result, err := operations.Doctor(operations.DoctorOptions{
+		ProjectDir: ref.Path,
+		Config:     cfg,
+		Progress: operations.NewWSProgressReporter("doctor", "", func(msg interface{}) {
+			s.broadcast(msg)
+		}),
+		Fix: fix,
+	})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to check project", err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, result)
+}
@@ -2,12 +2,13 @@ package uiapi
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
-	"sync"
 
 	"ramp/internal/config"
 	"ramp/internal/features"
@@ -34,7 +35,43 @@ func (s *Server) ListFeatures(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	writeJSON(w, http.StatusOK, FeaturesResponse{Features: features})
+	features, err = filterFeaturesByTag(features, r.URL.Query().Get("tag"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid tag", err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, FeaturesResponse{Features: features, ByTag: groupFeaturesByTag(features)})
+}
+
+// filterFeaturesByTag keeps the features with a tag; all of them when tag is empty
+func filterFeaturesByTag(featuresList []Feature, tag string) ([]Feature, error) {
+	if tag == "" {
+		return featuresList, nil
+	}
+	tag, err := features.NormalizeTag(tag)
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := []Feature{}
+	for _, feature := range featuresList {
+		if slices.Contains(feature.Tags, tag) {
+			filtered = append(filtered, feature)
+		}
+	}
+	return filtered, nil
+}
+
+// groupFeaturesByTag lists the names of features under each of their tags
+func groupFeaturesByTag(featuresList []Feature) map[string][]string {
+	names := make([]string, len(featuresList))
+	metadata := make(map[string]features.FeatureMetadata, len(featuresList))
+	for i, feature := range featuresList {
+		names[i] = feature.Name
+		metadata[feature.Name] = features.FeatureMetadata{Tags: feature.Tags}
+	}
+	return operations.GroupFeaturesByTag(names, metadata)
 }
 
 // CreateFeature creates a new feature (ramp up)
@@ -53,40 +90,10 @@ func (s *Server) CreateFeature(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Process FromBranch if set (like CLI --from flag)
-	var featureName, prefix, target string
-	if req.FromBranch != "" {
-		// Parse the from branch to derive prefix and feature name
-		lastSlash := strings.LastIndex(req.FromBranch, "/")
-		if lastSlash == -1 {
-			// No slash found - entire string is feature name, no prefix
-			prefix = ""
-			if req.Name == "" {
-				featureName = req.FromBranch
-			} else {
-				featureName = req.Name
-			}
-		} else {
-			// Found slash - split into prefix and feature name
-			prefix = req.FromBranch[:lastSlash+1] // Include trailing slash
-			derivedName := req.FromBranch[lastSlash+1:]
-			if req.Name == "" {
-				featureName = derivedName
-			} else {
-				featureName = req.Name
-			}
-		}
-		// Always prepend origin/ to the from value for the target
-		target = "origin/" + req.FromBranch
-	} else {
-		// Standard flow
-		if req.Name == "" {
-			writeError(w, http.StatusBadRequest, "Feature name is required", "")
-			return
-		}
-		featureName = req.Name
-		prefix = req.Prefix
-		target = req.Target
+	featureName, prefix, target := req.branchOptions()
+	if featureName == "" {
+		writeError(w, http.StatusBadRequest, "Feature name is required", "")
+		return
 	}
 
 	ref, err := GetProjectRefByID(id)
@@ -241,6 +248,351 @@ func (s *Server) RenameFeature(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, SuccessResponse{Success: true, Message: "Display name updated"})
 }
 
+// GetFeatureInfo returns a feature's creation info, branches, ports, notes, tags and links (ramp feature info)
+func (s *Server) GetFeatureInfo(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	name := vars["name"]
+
+	ref, err := GetProjectRefByID(id)
+	if err != nil || ref == nil {
+		writeError(w, http.StatusNotFound, "Project not found", id)
+		return
+	}
+
+	// Verify feature exists
+	if _, err := os.Stat(filepath.Join(ref.Path, "trees", name)); os.IsNotExist(err) {
+		writeError(w, http.StatusNotFound, "Feature not found", name)
+		return
+	}
+
+	cfg, err := config.LoadConfig(ref.Path)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to load project config", err.Error())
+		return
+	}
+
+	details, err := operations.GetFeatureDetails(ref.Path, cfg, name)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to get feature info", err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, details)
+}
+
+// AnnotateFeature changes a feature's notes, tags and links (ramp feature note/tag)
+func (s *Server) AnnotateFeature(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	name := vars["name"]
+
+	var req AnnotateFeatureRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	ref, err := GetProjectRefByID(id)
+	if err != nil || ref == nil {
+		writeError(w, http.StatusNotFound, "Project not found", id)
+		return
+	}
+
+	// Verify feature exists
+	if _, err := os.Stat(filepath.Join(ref.Path, "trees", name)); os.IsNotExist(err) {
+		writeError(w, http.StatusNotFound, "Feature not found", name)
+		return
+	}
+
+	// Everything is validated before anything is saved, so a failure is a bad request
+	err = operations.AnnotateFeature(operations.AnnotateOptions{
+		FeatureName: name,
+		ProjectDir:  ref.Path,
+		Note:        req.Note,
+		ClearNotes:  req.ClearNotes,
+		AddTags:     req.AddTags,
+		RemoveTags:  req.RemoveTags,
+		Links:       req.Links,
+	})
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Failed to update feature metadata", err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, SuccessResponse{Success: true, Message: "Feature metadata updated"})
+}
+
+// MoveFeature renames a feature's directory, branches, ports and metadata (ramp mv)
+func (s *Server) MoveFeature(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	name := vars["name"]
+
+	var req MoveFeatureRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	// Acquire project lock to prevent concurrent feature operations
+	unlock := s.acquireProjectLock(id)
+	defer unlock()
+
+	ref, err := GetProjectRefByID(id)
+	if err != nil || ref == nil {
+		writeError(w, http.StatusNotFound, "Project not found", id)
+		return
+	}
+
+	cfg, err := config.LoadConfig(ref.Path)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to load project config", err.Error())
+		return
+	}
+
+	progress := operations.NewWSProgressReporter("mv", name, func(msg interface{}) {
+		s.broadcast(msg)
+	})
+
+	_, err = operations.MoveFeature(operations.MoveOptions{
+		FeatureName:  name,
+		NewName:      req.NewName,
+		ProjectDir:   ref.Path,
+		Config:       cfg,
+		Progress:     progress,
+		RenameRemote: req.RenameRemote,
+	})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to move feature", err.Error())
+		return
+	}
+	progress.Complete("Move complete")
+
+	writeJSON(w, http.StatusOK, SuccessResponse{Success: true, Message: "Feature moved to " + req.NewName})
+}
+
+// CloneFeature creates a new feature from another feature's branches (ramp clone-feature)
+func (s *Server) CloneFeature(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	name := vars["name"]
+
+	var req CloneFeatureRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+	if req.Name == "" {
+		writeError(w, http.StatusBadRequest, "Feature name is required", "")
+		return
+	}
+
+	// Acquire project lock to prevent concurrent feature operations
+	unlock := s.acquireProjectLock(id)
+	defer unlock()
+
+	ref, err := GetProjectRefByID(id)
+	if err != nil || ref == nil {
+		writeError(w, http.StatusNotFound, "Project not found", id)
+		return
+	}
+
+	cfg, err := config.LoadConfig(ref.Path)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to load project config", err.Error())
+		return
+	}
+
+	result, err := operations.CloneFeature(operations.CloneFeatureOptions{
+		SourceFeature: name,
+		FeatureName:   req.Name,
+		ProjectDir:    ref.Path,
+		Config:        cfg,
+		Progress: operations.NewWSProgressReporter("up", req.Name, func(msg interface{}) {
+			s.broadcast(msg)
+		}),
+		Output: operations.NewWSOutputStreamerWithContext("up", req.Name, "", func(msg interface{}) {
+			s.broadcast(msg)
+		}),
+		DisplayName: req.DisplayName,
+		Uncommitted: req.Uncommitted,
+	})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to clone feature", err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, Feature{
+		Name:                  result.FeatureName,
+		DisplayName:           result.DisplayName,
+		Repos:                 result.Repos,
+		HasUncommittedChanges: len(result.CarriedOver) > 0,
+	})
+}
+
+// SyncFeatureEnv re-renders env files for an existing feature (ramp env sync)
+func (s *Server) SyncFeatureEnv(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	name := vars["name"]
+
+	var req EnvSyncRequest
+	if r.ContentLength > 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid request body", err.Error())
+			return
+		}
+	}
+
+	// Acquire project lock so env files aren't rewritten during create/delete
+	unlock := s.acquireProjectLock(id)
+	defer unlock()
+
+	ref, err := GetProjectRefByID(id)
+	if err != nil || ref == nil {
+		writeError(w, http.StatusNotFound, "Project not found", id)
+		return
+	}
+
+	// Verify feature exists
+	treesDir := filepath.Join(ref.Path, "trees", name)
+	if _, err := os.Stat(treesDir); os.IsNotExist(err) {
+		writeError(w, http.StatusNotFound, "Feature not found", name)
+		return
+	}
+
+	cfg, err := config.LoadConfig(ref.Path)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to load project config", err.Error())
+		return
+	}
+
+	conflict := operations.EnvConflictPolicy(req.Conflict)
+	switch conflict {
+	case operations.EnvConflictFail, operations.EnvConflictKeep, operations.EnvConflictMerge, operations.EnvConflictForce:
+	default:
+		writeError(w, http.StatusBadRequest, "Invalid conflict policy", req.Conflict)
+		return
+	}
+
+	progress := operations.NewWSProgressReporter("env-sync", name, func(msg interface{}) {
+		s.broadcast(msg)
+	})
+
+	result, err := operations.PlanEnvSync(operations.EnvSyncOptions{
+		FeatureName:  name,
+		ProjectDir:   ref.Path,
+		Config:       cfg,
+		Progress:     progress,
+		ForceRefresh: req.ForceRefresh,
+		Conflict:     conflict,
+	})
+	if err != nil {
+		var driftErr *operations.EnvDriftError
+		if errors.As(err, &driftErr) {
+			writeError(w, http.StatusConflict, "Env files were edited since they were generated", strings.Join(driftErr.Files, ", "))
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "Failed to render env files", err.Error())
+		return
+	}
+
+	response := EnvSyncResponse{
+		Changes:   []EnvFileChange{},
+		Unchanged: result.Unchanged,
+		Kept:      result.Kept,
+	}
+	for _, change := range result.Changes {
+		response.Changes = append(response.Changes, EnvFileChange{
+			Repo:    change.Repo,
+			Dest:    change.Dest,
+			Created: change.Created,
+			Drifted: change.Drifted,
+			Diff:    change.Diff(),
+		})
+	}
+
+	if !req.DryRun {
+		if err := operations.ApplyEnvSync(result, progress); err != nil {
+			writeError(w, http.StatusInternalServerError, "Failed to write env files", err.Error())
+			return
+		}
+		response.Applied = true
+		progress.Complete("Env files synced")
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// PushFeature pushes the feature's branch in every repo with unpushed commits (ramp push)
+func (s *Server) PushFeature(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	name := vars["name"]
+
+	var req PushFeatureRequest
+	if r.ContentLength > 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid request body", err.Error())
+			return
+		}
+	}
+
+	// Acquire project lock so worktrees aren't removed mid-push
+	unlock := s.acquireProjectLock(id)
+	defer unlock()
+
+	ref, err := GetProjectRefByID(id)
+	if err != nil || ref == nil {
+		writeError(w, http.StatusNotFound, "Project not found", id)
+		return
+	}
+
+	// Verify feature exists
+	treesDir := filepath.Join(ref.Path, "trees", name)
+	if _, err := os.Stat(treesDir); os.IsNotExist(err) {
+		writeError(w, http.StatusNotFound, "Feature not found", name)
+		return
+	}
+
+	cfg, err := config.LoadConfig(ref.Path)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to load project config", err.Error())
+		return
+	}
+
+	progress := operations.NewWSProgressReporter("push", name, func(msg interface{}) {
+		s.broadcast(msg)
+	})
+
+	result, err := operations.Push(operations.PushOptions{
+		FeatureName:    name,
+		ProjectDir:     ref.Path,
+		Config:         cfg,
+		Progress:       progress,
+		ForceWithLease: req.ForceWithLease,
+	})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to push feature", err.Error())
+		return
+	}
+	progress.Complete("Push complete")
+
+	response := PushResponse{Repos: []PushRepoResult{}}
+	for _, repo := range result.Repos {
+		response.Repos = append(response.Repos, PushRepoResult{
+			Repo:    repo.Repo,
+			Branch:  repo.Branch,
+			Status:  repo.Status,
+			Ahead:   repo.Ahead,
+			Message: repo.Message,
+		})
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
 // getProjectFeatures returns detailed feature information for a project
 func getProjectFeatures(projectPath string) ([]Feature, error) {
 	treesDir := filepath.Join(projectPath, "trees")
@@ -260,23 +612,21 @@ func getProjectFeatures(projectPath string) ([]Feature, error) {
 	// Try to load project config for detailed status
 	// If config loading fails, we'll fall back to basic feature info
 	cfg, cfgErr := config.LoadConfig(projectPath)
+	// Statuses are read as of the last fetch; the app fetches separately so
+	// listing features never waits on the network
 	var repos map[string]*config.Repo
+	var allStatuses map[string][]operations.FeatureRepoStatus
 	if cfgErr == nil {
 		repos = cfg.GetRepos()
 
-		// Fetch all repos in parallel for accurate ahead/behind info
-		var wg sync.WaitGroup
-		for _, repo := range repos {
-			wg.Add(1)
-			go func(r *config.Repo) {
-				defer wg.Done()
-				repoPath := r.GetRepoPath(projectPath)
-				if _, err := os.Stat(repoPath); err == nil && git.IsGitRepo(repoPath) {
-					_ = git.FetchAllQuiet(repoPath)
-				}
-			}(repo)
+		var featureNames []string
+		for _, entry := range entries {
+			if entry.IsDir() && !isHiddenDir(entry.Name()) {
+				featureNames = append(featureNames, entry.Name())
+			}
 		}
-		wg.Wait()
+		collector := operations.NewStatusCollector(projectPath, repos, cfg.GetMaxParallel())
+		allStatuses = collector.CollectFeatures(featureNames)
 	}
 
 	// Collect features with detailed status
@@ -297,6 +647,12 @@ func getProjectFeatures(projectPath string) ([]Feature, error) {
 		repoNames := []string{}
 		hasUncommitted := false
 		var worktreeStatuses []FeatureWorktreeStatus
+		for _, status := range allStatuses[featureName] {
+			worktreeStatuses = append(worktreeStatuses, newFeatureWorktreeStatus(status))
+			if status.HasUncommitted {
+				hasUncommitted = true
+			}
+		}
 
 		for _, repoEntry := range repoEntries {
 			if !repoEntry.IsDir() || isHiddenDir(repoEntry.Name()) {
@@ -305,16 +661,9 @@ func getProjectFeatures(projectPath string) ([]Feature, error) {
 			repoName := repoEntry.Name()
 			repoNames = append(repoNames, repoName)
 
-			// Get detailed worktree status if we have repo config
-			if repos != nil {
-				if repo, exists := repos[repoName]; exists {
-					status := getFeatureWorktreeStatus(projectPath, featureName, repoName, repo)
-					worktreeStatuses = append(worktreeStatuses, status)
-					if status.HasUncommitted {
-						hasUncommitted = true
-					}
-					continue
-				}
+			// Detailed status was collected above for configured repos
+			if _, exists := repos[repoName]; exists {
+				continue
 			}
 			// Fallback for repos not in config or when config not available
 			repoPath := filepath.Join(featurePath, repoName)
@@ -324,112 +673,73 @@ func getProjectFeatures(projectPath string) ([]Feature, error) {
 			}
 		}
 
-		// Get creation time from directory info
-		info, _ := entry.Info()
-		created := info.ModTime()
+		// Get display name, tags and creation info from metadata
+		var meta features.FeatureMetadata
+		if metadataStore != nil {
+			meta = metadataStore.Get(featureName)
+		}
+		var createdBy string
+		if meta.Created != nil {
+			createdBy = meta.Created.By
+		}
 
 		// Categorize the feature
 		category := categorizeFeature(worktreeStatuses)
 
-		// Get display name from metadata
-		var displayName string
-		if metadataStore != nil {
-			displayName = metadataStore.GetDisplayName(featureName)
-		}
+		// Env files edited since ramp generated them
+		driftedEnvFiles, _ := operations.DetectEnvFileDrift(projectPath, featureName)
 
 		featuresList = append(featuresList, Feature{
 			Name:                  featureName,
-			DisplayName:           displayName,
+			DisplayName:           meta.DisplayName,
 			Repos:                 repoNames,
-			Created:               created,
+			Created:               operations.FeatureCreated(projectPath, featureName, meta),
+			CreatedBy:             createdBy,
+			Tags:                  meta.Tags,
+			Links:                 meta.Links,
 			HasUncommittedChanges: hasUncommitted,
 			Category:              category,
 			WorktreeStatuses:      worktreeStatuses,
+			DriftedEnvFiles:       driftedEnvFiles,
 		})
 	}
 
 	return featuresList, nil
 }
 
-// getFeatureWorktreeStatus collects detailed status for a single repo worktree
-func getFeatureWorktreeStatus(projectDir, featureName, repoName string, repo *config.Repo) FeatureWorktreeStatus {
-	worktreePath := filepath.Join(projectDir, "trees", featureName, repoName)
-	sourceRepoPath := repo.GetRepoPath(projectDir)
-
-	status := FeatureWorktreeStatus{
-		RepoName: repoName,
-	}
-
-	// Check if worktree exists
-	if _, err := os.Stat(worktreePath); os.IsNotExist(err) {
-		status.Error = "worktree not found"
-		return status
-	}
-
-	// Get branch name
-	branchName, err := git.GetWorktreeBranch(worktreePath)
-	if err != nil {
-		status.Error = fmt.Sprintf("failed to get branch: %v", err)
-		return status
-	}
-	status.BranchName = branchName
-
-	// Get default branch from source repo
-	defaultBranch, err := git.GetDefaultBranch(sourceRepoPath)
-	if err != nil {
-		status.Error = fmt.Sprintf("failed to get default branch: %v", err)
-		return status
-	}
-
-	// Check for uncommitted changes
-	hasUncommitted, err := git.HasUncommittedChanges(worktreePath)
-	if err != nil {
-		status.Error = fmt.Sprintf("failed to check uncommitted changes: %v", err)
-		return status
-	}
-	status.HasUncommitted = hasUncommitted
-
-	// Get diff stats and status stats if there are uncommitted changes
-	if hasUncommitted {
-		if diffStats, err := git.GetDiffStats(worktreePath); err == nil && diffStats != nil {
-			status.DiffStats = &DiffStats{
-				FilesChanged: diffStats.FilesChanged,
-				Insertions:   diffStats.Insertions,
-				Deletions:    diffStats.Deletions,
-			}
-		}
-
-		if statusStats, err := git.GetStatusStats(worktreePath); err == nil && statusStats != nil {
-			status.StatusStats = &StatusStats{
-				UntrackedFiles: statusStats.UntrackedFiles,
-				StagedFiles:    statusStats.StagedFiles,
-				ModifiedFiles:  statusStats.ModifiedFiles,
-			}
+// newFeatureWorktreeStatus converts a collected worktree status to its API model
+func newFeatureWorktreeStatus(status operations.FeatureRepoStatus) FeatureWorktreeStatus {
+	result := FeatureWorktreeStatus{
+		RepoName:       status.Repo,
+		BranchName:     status.Branch,
+		HasUncommitted: status.HasUncommitted,
+		AheadCount:     status.Ahead,
+		BehindCount:    status.Behind,
+		IsMerged:       status.IsMerged(),
+		MergeMethod:    string(status.MergeMethod),
+		Sparse:         status.Sparse,
+		Error:          status.Error,
+	}
+
+	if status.DiffStats != nil {
+		result.DiffStats = &DiffStats{
+			FilesChanged: status.DiffStats.FilesChanged,
+			Insertions:   status.DiffStats.Insertions,
+			Deletions:    status.DiffStats.Deletions,
 		}
 	}
 
-	// Get ahead/behind count compared to default branch
-	ahead, behind, err := git.GetAheadBehindCount(worktreePath, defaultBranch)
-	if err != nil {
-		status.AheadCount = 0
-		status.BehindCount = 0
-	} else {
-		status.AheadCount = ahead
-		status.BehindCount = behind
-	}
-
-	// Check if merged into default branch
-	isMerged, err := git.IsMergedInto(worktreePath, defaultBranch)
-	if err != nil {
-		status.IsMerged = false
-	} else {
-		status.IsMerged = isMerged
+	if status.StatusStats != nil {
+		result.StatusStats = &StatusStats{
+			UntrackedFiles: status.StatusStats.UntrackedFiles,
+			StagedFiles:    status.StatusStats.StagedFiles,
+			ModifiedFiles:  status.StatusStats.ModifiedFiles,
+		}
 	}
 
-	return status
+	return result
 }
 
-// categorizeFeature determines the category for a feature based on worktree statuses
 func categorizeFeature(statuses []FeatureWorktreeStatus) string {
 	if len(statuses) == 0 {
 		return "clean"
@@ -465,9 +775,10 @@ func isMerged(statuses []FeatureWorktreeStatus) bool {
 	anyBehind := false
 
 	for _, status := range statuses {
-		if status.HasUncommitted || status.AheadCount > 0 {
+		if status.HasUncommitted {
 			return false
 		}
+		// Squash-merged branches stay ahead, so rely on merge detection rather than the ahead count
 		if !status.IsMerged {
 			return false
 		}
@@ -490,11 +801,46 @@ func isClean(statuses []FeatureWorktreeStatus) bool {
 	return true
 }
 
-// PruneFeatures deletes all merged features for a project
+// branchOptions returns the feature name, branch prefix and target of a
+// request. FromBranch is parsed like the CLI's --from flag: the prefix comes
+// from the branch path, the target is origin/{fromBranch}, and the name
+// defaults to the last segment of the branch.
+func (req CreateFeatureRequest) branchOptions() (featureName, prefix, target string) {
+	if req.FromBranch == "" {
+		return req.Name, req.Prefix, req.Target
+	}
+
+	featureName = req.Name
+	if lastSlash := strings.LastIndex(req.FromBranch, "/"); lastSlash == -1 {
+		// No slash found - entire string is feature name, no prefix
+		if featureName == "" {
+			featureName = req.FromBranch
+		}
+	} else {
+		// Found slash - split into prefix and feature name
+		prefix = req.FromBranch[:lastSlash+1] // Include trailing slash
+		if featureName == "" {
+			featureName = req.FromBranch[lastSlash+1:]
+		}
+	}
+	// Always prepend origin/ to the from value for the target
+	return featureName, prefix, "origin/" + req.FromBranch
+}
+
+// PruneFeatures deletes all merged features for a project, archiving them
+// first when asked to
 func (s *Server) PruneFeatures(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 
+	var req PruneRequest
+	if r.ContentLength > 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid request body", err.Error())
+			return
+		}
+	}
+
 	// Acquire project lock to prevent concurrent feature operations
 	unlock := s.acquireProjectLock(id)
 	defer unlock()
@@ -513,19 +859,12 @@ func (s *Server) PruneFeatures(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get all features and identify merged ones
-	features, err := getProjectFeatures(ref.Path)
+	mergedFeatures, err := getMergedFeatureNames(ref.Path)
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, "Failed to list features", err.Error())
 		return
 	}
 
-	var mergedFeatures []string
-	for _, feature := range features {
-		if feature.Category == "merged" {
-			mergedFeatures = append(mergedFeatures, feature.Name)
-		}
-	}
-
 	if len(mergedFeatures) == 0 {
 		writeJSON(w, http.StatusOK, PruneResponse{
 			Pruned:  []string{},
@@ -540,32 +879,24 @@ func (s *Server) PruneFeatures(w http.ResponseWriter, r *http.Request) {
 		s.broadcast(msg)
 	})
 
-	// Delete each merged feature
-	var pruned []string
-	var failed []PruneFailure
-
 	progress.Start("Pruning merged features...")
 
-	for _, featureName := range mergedFeatures {
-		progress.Update(fmt.Sprintf("Removing %s...", featureName))
+	opts := operations.PruneOptions{
+		ProjectDir: ref.Path,
+		Config:     cfg,
+		Progress:   progress,
+		Features:   mergedFeatures,
+		Archive:    req.Archive,
+	}
+	result := operations.ApplyPrune(opts, operations.PlanPrune(opts))
 
-		_, err := operations.Down(operations.DownOptions{
-			FeatureName: featureName,
-			ProjectDir:  ref.Path,
-			Config:      cfg,
-			Progress:    progress,
-			Force:       true, // Skip confirmation - merged features are safe to delete
-			AutoInstall: false,
+	pruned := result.Pruned
+	var failed []PruneFailure
+	for _, failure := range result.Failed {
+		failed = append(failed, PruneFailure{
+			Name:  failure.Feature,
+			Error: failure.Error,
 		})
-
-		if err != nil {
-			failed = append(failed, PruneFailure{
-				Name:  featureName,
-				Error: err.Error(),
-			})
-		} else {
-			pruned = append(pruned, featureName)
-		}
 	}
 
 	progress.Complete("Prune complete")
@@ -584,3 +915,19 @@ func (s *Server) PruneFeatures(w http.ResponseWriter, r *http.Request) {
 		Message: message,
 	})
 }
+
+// getMergedFeatureNames returns the features of a project that prune removes.
+func getMergedFeatureNames(projectPath string) ([]string, error) {
+	features, err := getProjectFeatures(projectPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var mergedFeatures []string
+	for _, feature := range features {
+		if feature.Category == "merged" {
+			mergedFeatures = append(mergedFeatures, feature.Name)
+		}
+	}
+	return mergedFeatures, nil
+}
@@ -423,6 +423,106 @@ func TestDeleteFeature_FeatureNotFound(t *testing.T) {
 	}
 }
 
+// === ENV SYNC TESTS ===
+
+func TestSyncFeatureEnv_DryRunAndApply(t *testing.T) {
+	cleanup := setupTestConfig(t)
+	defer cleanup()
+
+	tp := NewTestProjectForUI(t)
+	tp.InitRepo("repo1")
+	tp.Config.Repos[0].EnvFiles = []config.EnvFile{{Source: ".env.example", Dest: ".env"}}
+	if err := config.SaveConfig(tp.Config, tp.Dir); err != nil {
+		t.Fatalf("failed to save config: %v", err)
+	}
+	sourceEnv := filepath.Join(tp.ReposDir, "repo1", ".env.example")
+	os.WriteFile(sourceEnv, []byte("NAME=${RAMP_WORKTREE_NAME}\n"), 0644)
+	id := tp.AddToAppConfig()
+
+	server := NewServer()
+
+	createBody, _ := json.Marshal(CreateFeatureRequest{Name: "env-feature", SkipRefresh: true})
+	createReq := httptest.NewRequest(http.MethodPost, "/api/projects/"+id+"/features", bytes.NewReader(createBody))
+	createReq = mux.SetURLVars(createReq, map[string]string{"id": id})
+	createW := httptest.NewRecorder()
+	server.CreateFeature(createW, createReq)
+	if createW.Code != http.StatusCreated {
+		t.Fatalf("CreateFeature() failed: %s", createW.Body.String())
+	}
+
+	// Change the source so the feature's .env is stale
+	os.WriteFile(sourceEnv, []byte("NAME=${RAMP_WORKTREE_NAME}\nDEBUG=true\n"), 0644)
+	envPath := filepath.Join(tp.TreesDir, "env-feature", "repo1", ".env")
+
+	sync := func(dryRun bool) EnvSyncResponse {
+		body, _ := json.Marshal(EnvSyncRequest{DryRun: dryRun})
+		req := httptest.NewRequest(http.MethodPost, "/api/projects/"+id+"/features/env-feature/env/sync", bytes.NewReader(body))
+		req = mux.SetURLVars(req, map[string]string{"id": id, "name": "env-feature"})
+		w := httptest.NewRecorder()
+		server.SyncFeatureEnv(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("SyncFeatureEnv() status = %d, body: %s", w.Code, w.Body.String())
+		}
+		var resp EnvSyncResponse
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		return resp
+	}
+
+	resp := sync(true)
+	if len(resp.Changes) != 1 || resp.Applied {
+		t.Fatalf("dry run response = %+v, want 1 unapplied change", resp)
+	}
+	if content, _ := os.ReadFile(envPath); string(content) != "NAME=env-feature\n" {
+		t.Errorf("dry run should not write, .env = %q", string(content))
+	}
+
+	resp = sync(false)
+	if !resp.Applied {
+		t.Error("expected changes to be applied")
+	}
+	if content, _ := os.ReadFile(envPath); string(content) != "NAME=env-feature\nDEBUG=true\n" {
+		t.Errorf(".env = %q, want regenerated content", string(content))
+	}
+
+	// Local edits are not overwritten without a conflict policy
+	os.WriteFile(envPath, []byte("NAME=local\nDEBUG=true\n"), 0644)
+	os.WriteFile(sourceEnv, []byte("NAME=${RAMP_WORKTREE_NAME}\nDEBUG=false\n"), 0644)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/projects/"+id+"/features/env-feature/env/sync", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": id, "name": "env-feature"})
+	w := httptest.NewRecorder()
+	server.SyncFeatureEnv(w, req)
+	if w.Code != http.StatusConflict {
+		t.Errorf("SyncFeatureEnv() with local edits status = %d, want %d", w.Code, http.StatusConflict)
+	}
+	if content, _ := os.ReadFile(envPath); string(content) != "NAME=local\nDEBUG=true\n" {
+		t.Errorf(".env = %q, local edits should be preserved", string(content))
+	}
+}
+
+func TestSyncFeatureEnv_FeatureNotFound(t *testing.T) {
+	cleanup := setupTestConfig(t)
+	defer cleanup()
+
+	tp := NewTestProjectForUI(t)
+	tp.InitRepo("repo1")
+	id := tp.AddToAppConfig()
+
+	server := NewServer()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/projects/"+id+"/features/nonexistent/env/sync", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": id, "name": "nonexistent"})
+	w := httptest.NewRecorder()
+
+	server.SyncFeatureEnv(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("SyncFeatureEnv() status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
+
 // === CATEGORIZATION TESTS ===
 
 func TestCategorizeFeature(t *testing.T) {
@@ -573,6 +673,13 @@ func TestIsMerged(t *testing.T) {
 			},
 			want: false,
 		},
+		{
+			name: "squash merged (still ahead)",
+			statuses: []FeatureWorktreeStatus{
+				{HasUncommitted: false, AheadCount: 2, BehindCount: 3, IsMerged: true, MergeMethod: "tree"},
+			},
+			want: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,98 @@
+package uiapi
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"ramp/internal/config"
+	"ramp/internal/operations"
+)
+
+// ListInterruptedOperations returns the operations of a project that were
+// killed part-way and can be resumed or rolled back
+func (s *Server) ListInterruptedOperations(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	ref, err := GetProjectRefByID(id)
+	if err != nil || ref == nil {
+		writeError(w, http.StatusNotFound, "Project not found", id)
+		return
+	}
+
+	journals, err := operations.PendingJournals(ref.Path)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to read operation journals", err.Error())
+		return
+	}
+
+	interrupted := make([]InterruptedOperation, len(journals))
+	for i, j := range journals {
+		interrupted[i] = InterruptedOperation{
+			Operation:      j.Operation,
+			FeatureName:    j.Feature,
+			Started:        j.Started,
+			CompletedSteps: len(j.Done),
+			CanRollback:    j.CanRollback(),
+		}
+	}
+
+	writeJSON(w, http.StatusOK, InterruptedOperationsResponse{Operations: interrupted})
+}
+
+// ResumeOperation finishes an interrupted operation on a feature
+func (s *Server) ResumeOperation(w http.ResponseWriter, r *http.Request) {
+	s.recoverOperation(w, r, "resume")
+}
+
+// RollbackOperation undoes an interrupted operation on a feature
+func (s *Server) RollbackOperation(w http.ResponseWriter, r *http.Request) {
+	s.recoverOperation(w, r, "rollback")
+}
+
+func (s *Server) recoverOperation(w http.ResponseWriter, r *http.Request, action string) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	name := vars["name"]
+
+	// Acquire project lock to prevent concurrent feature operations
+	unlock := s.acquireProjectLock(id)
+	defer unlock()
+
+	ref, err := GetProjectRefByID(id)
+	if err != nil || ref == nil {
+		writeError(w, http.StatusNotFound, "Project not found", id)
+		return
+	}
+
+	cfg, err := config.LoadConfig(ref.Path)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to load project config", err.Error())
+		return
+	}
+
+	opts := operations.RecoverOptions{
+		ProjectDir:  ref.Path,
+		Config:      cfg,
+		FeatureName: name,
+		Progress: operations.NewWSProgressReporter(action, name, func(msg interface{}) {
+			s.broadcast(msg)
+		}),
+		Output: operations.NewWSOutputStreamerWithContext(action, name, "", func(msg interface{}) {
+			s.broadcast(msg)
+		}),
+	}
+
+	if action == "resume" {
+		err = operations.ResumeOperation(opts)
+	} else {
+		err = operations.RollbackOperation(opts)
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to "+action+" operation", err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, SuccessResponse{Success: true, Message: "Operation " + action + " completed"})
+}
@@ -49,6 +49,8 @@ type FeatureWorktreeStatus struct {
 	AheadCount     int          `json:"aheadCount"`
 	BehindCount    int          `json:"behindCount"`
 	IsMerged       bool         `json:"isMerged"`
+	MergeMethod    string       `json:"mergeMethod,omitempty"` // ancestor, patch-id, tree, or remote-deleted (not merged)
+	Sparse         bool         `json:"sparse,omitempty"`      // Worktree only checks out the repo's sparse: directories
 	Error          string       `json:"error,omitempty"`
 }
 
@@ -58,9 +60,13 @@ type Feature struct {
 	DisplayName           string                  `json:"displayName,omitempty"`
 	Repos                 []string                `json:"repos"`
 	Created               time.Time               `json:"created,omitempty"`
+	CreatedBy             string                  `json:"createdBy,omitempty"`
+	Tags                  []string                `json:"tags,omitempty"`
+	Links                 map[string]string       `json:"links,omitempty"` // Label such as "ticket" or "pr" to URL
 	HasUncommittedChanges bool                    `json:"hasUncommittedChanges"`
-	Category              string                  `json:"category"`                        // "in_flight", "merged", "clean"
+	Category              string                  `json:"category"` // "in_flight", "merged", "clean"
 	WorktreeStatuses      []FeatureWorktreeStatus `json:"worktreeStatuses,omitempty"`
+	DriftedEnvFiles       []string                `json:"driftedEnvFiles,omitempty"` // Env files edited since generation (repo/dest)
 }
 
 // AppConfig is the UI application configuration stored locally
@@ -114,11 +120,84 @@ type CreateFeatureRequest struct {
 	SkipRefresh  bool `json:"skipRefresh,omitempty"`  // Skip refresh for ALL repos (override per-repo config)
 }
 
+// PlanRequest is the request body for previewing an operation without running it
+type PlanRequest struct {
+	Operation string `json:"operation"` // "up", "down", "prune" or "install"
+
+	// For up, the same fields as creating a feature; for down, Name is the feature to remove
+	CreateFeatureRequest
+}
+
 // RenameFeatureRequest is the request body for renaming a feature's display name
 type RenameFeatureRequest struct {
 	DisplayName string `json:"displayName"` // New display name (empty string to clear)
 }
 
+// AnnotateFeatureRequest is the request body for changing a feature's notes, tags and links
+type AnnotateFeatureRequest struct {
+	Note       string            `json:"note,omitempty"`       // Note to add
+	ClearNotes bool              `json:"clearNotes,omitempty"` // Remove existing notes first
+	AddTags    []string          `json:"addTags,omitempty"`
+	RemoveTags []string          `json:"removeTags,omitempty"`
+	Links      map[string]string `json:"links,omitempty"` // Label to URL; an empty URL removes the link
+}
+
+// MoveFeatureRequest is the request body for renaming a feature's directory and branches
+type MoveFeatureRequest struct {
+	NewName      string `json:"newName"`
+	RenameRemote bool   `json:"renameRemote,omitempty"` // Also rename pushed branches on origin
+}
+
+// CloneFeatureRequest is the request body for duplicating a feature into a new one
+type CloneFeatureRequest struct {
+	Name        string `json:"name"`                  // The new feature
+	DisplayName string `json:"displayName,omitempty"` // Optional human-readable name
+	Uncommitted bool   `json:"uncommitted,omitempty"` // Also copy uncommitted changes and untracked files
+}
+
+// EnvSyncRequest is the request body for re-rendering a feature's env files
+type EnvSyncRequest struct {
+	DryRun       bool   `json:"dryRun,omitempty"`       // Only return diffs, don't write files
+	ForceRefresh bool   `json:"forceRefresh,omitempty"` // Re-run script sources instead of using cached output
+	Conflict     string `json:"conflict,omitempty"`     // "keep", "merge" or "force" for locally edited files
+}
+
+// EnvFileChange is an env file whose regenerated content differs from the worktree
+type EnvFileChange struct {
+	Repo    string `json:"repo"`
+	Dest    string `json:"dest"`
+	Created bool   `json:"created"` // File does not exist yet
+	Drifted bool   `json:"drifted"` // File was edited since it was generated
+	Diff    string `json:"diff"`    // Unified diff from current to regenerated content
+}
+
+// EnvSyncResponse is the response for re-rendering a feature's env files
+type EnvSyncResponse struct {
+	Changes   []EnvFileChange `json:"changes"`
+	Unchanged int             `json:"unchanged"`
+	Kept      []string        `json:"kept,omitempty"` // Edited files left untouched (repo/dest)
+	Applied   bool            `json:"applied"`        // False for dry runs
+}
+
+// PushFeatureRequest is the request body for pushing a feature's branches
+type PushFeatureRequest struct {
+	ForceWithLease bool `json:"forceWithLease,omitempty"` // Push rewritten branches with --force-with-lease
+}
+
+// PushRepoResult is the outcome of pushing one repository's feature branch
+type PushRepoResult struct {
+	Repo    string `json:"repo"`
+	Branch  string `json:"branch,omitempty"`
+	Status  string `json:"status"` // "pushed", "skipped" or "failed"
+	Ahead   int    `json:"ahead"`  // Commits the remote didn't have
+	Message string `json:"message"`
+}
+
+// PushResponse is the response for pushing a feature's branches
+type PushResponse struct {
+	Repos []PushRepoResult `json:"repos"`
+}
+
 // ProjectsResponse is the response for listing projects
 type ProjectsResponse struct {
 	Projects []Project `json:"projects"`
@@ -126,7 +205,8 @@ type ProjectsResponse struct {
 
 // FeaturesResponse is the response for listing features
 type FeaturesResponse struct {
-	Features []Feature `json:"features"`
+	Features []Feature           `json:"features"`
+	ByTag    map[string][]string `json:"byTag"` // Tag to feature names; untagged features are under ""
 }
 
 // ErrorResponse is a standard error response
@@ -145,12 +225,12 @@ type SuccessResponse struct {
 
 // WSMessage is a WebSocket message
 type WSMessage struct {
-	Type       string `json:"type"`                  // "progress", "error", "complete", "output"
-	Operation  string `json:"operation"`             // "up", "down", "refresh", "run", etc.
+	Type       string `json:"type"`      // "progress", "error", "complete", "output"
+	Operation  string `json:"operation"` // "up", "down", "refresh", "run", etc.
 	Message    string `json:"message"`
 	Percentage int    `json:"percentage,omitempty"`
-	Target     string `json:"target,omitempty"`      // Feature name for filtering
-	Command    string `json:"command,omitempty"`     // Command name for run operations
+	Target     string `json:"target,omitempty"`  // Feature name for filtering
+	Command    string `json:"command,omitempty"` // Command name for run operations
 }
 
 // Command represents a custom command defined in ramp.yaml
@@ -267,6 +347,41 @@ type SaveAppSettingsRequest struct {
 	Theme                 string `json:"theme,omitempty"`
 }
 
+// InterruptedOperation is an up, down or prune that was killed part-way
+type InterruptedOperation struct {
+	Operation      string    `json:"operation"` // "up", "down" or "prune"
+	FeatureName    string    `json:"featureName"`
+	Started        time.Time `json:"started"`
+	CompletedSteps int       `json:"completedSteps"`
+	CanRollback    bool      `json:"canRollback"` // False once a down or prune removed worktrees or branches
+}
+
+// InterruptedOperationsResponse is the response for listing interrupted operations
+type InterruptedOperationsResponse struct {
+	Operations []InterruptedOperation `json:"operations"`
+}
+
+// ArchivedFeature is a feature saved by ramp archive that can be restored
+type ArchivedFeature struct {
+	Name            string    `json:"name"`
+	DisplayName     string    `json:"displayName,omitempty"`
+	Archived        time.Time `json:"archived"`
+	Branches        []string  `json:"branches"`
+	UncommittedIn   []string  `json:"uncommittedIn,omitempty"` // Repos whose uncommitted changes were saved
+	Ports           []int     `json:"ports,omitempty"`
+	RecreateCommand string    `json:"recreateCommand"` // ramp up command that creates a feature with the same branches
+}
+
+// ArchivesResponse is the response for listing archived features
+type ArchivesResponse struct {
+	Archives []ArchivedFeature `json:"archives"`
+}
+
+// PruneRequest is the optional request body for pruning merged features
+type PruneRequest struct {
+	Archive bool `json:"archive,omitempty"` // Archive the features instead of deleting them
+}
+
 // PruneFailure represents a feature that failed to be pruned
 type PruneFailure struct {
 	Name  string `json:"name"`
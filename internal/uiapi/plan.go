@@ -0,0 +1,105 @@
+package uiapi
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"ramp/internal/config"
+	"ramp/internal/operations"
+)
+
+// PreviewPlan returns what an up, down, prune or install would do, without
+// doing any of it. The response is the operation's plan as ramp --dry-run
+// --json prints it.
+func (s *Server) PreviewPlan(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var req PlanRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	ref, err := GetProjectRefByID(id)
+	if err != nil || ref == nil {
+		writeError(w, http.StatusNotFound, "Project not found", id)
+		return
+	}
+
+	cfg, err := config.LoadConfig(ref.Path)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to load project config", err.Error())
+		return
+	}
+
+	// Previews don't broadcast progress
+	progress := operations.DiscardProgress{}
+
+	var plan operations.Planned
+	switch req.Operation {
+	case "up":
+		featureName, prefix, target := req.branchOptions()
+		if featureName == "" {
+			writeError(w, http.StatusBadRequest, "Feature name is required", "")
+			return
+		}
+		plan, err = operations.PlanUp(operations.UpOptions{
+			FeatureName:  featureName,
+			ProjectDir:   ref.Path,
+			Config:       cfg,
+			Progress:     progress,
+			Prefix:       prefix,
+			NoPrefix:     req.NoPrefix,
+			Target:       target,
+			AutoInstall:  true, // Matches CreateFeature
+			ForceRefresh: req.ForceRefresh,
+			SkipRefresh:  req.SkipRefresh,
+			DisplayName:  req.DisplayName,
+		})
+
+	case "down":
+		if req.Name == "" {
+			writeError(w, http.StatusBadRequest, "Feature name is required", "")
+			return
+		}
+		plan, err = operations.PlanDown(operations.DownOptions{
+			FeatureName: req.Name,
+			ProjectDir:  ref.Path,
+			Config:      cfg,
+			Progress:    progress,
+		})
+
+	case "prune":
+		var mergedFeatures []string
+		mergedFeatures, err = getMergedFeatureNames(ref.Path)
+		if err == nil {
+			plan = operations.PlanPrune(operations.PruneOptions{
+				ProjectDir: ref.Path,
+				Config:     cfg,
+				Progress:   progress,
+				Features:   mergedFeatures,
+			})
+		}
+
+	case "install":
+		plan = operations.PlanInstall(operations.InstallOptions{
+			ProjectDir: ref.Path,
+			Config:     cfg,
+			Progress:   progress,
+		})
+
+	default:
+		writeError(w, http.StatusBadRequest, "Unknown operation", req.Operation)
+		return
+	}
+
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to plan operation", err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, plan)
+}
@@ -2,6 +2,7 @@ package uiapi
 
 import (
 	"net/http"
+	"strings"
 	"sync"
 
 	"ramp/internal/config"
@@ -186,3 +187,32 @@ func (s *Server) RefreshSourceRepos(w http.ResponseWriter, r *http.Request) {
 
 	writeJSON(w, http.StatusOK, SuccessResponse{Success: true, Message: "Refresh completed"})
 }
+
+// FetchSourceRepos fetches all source repositories so the next feature listing
+// reports ahead/behind and merge state against current remote branches.
+// Listing features never fetches on its own.
+func (s *Server) FetchSourceRepos(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	// Get project reference
+	ref, err := GetProjectRefByID(id)
+	if err != nil || ref == nil {
+		writeError(w, http.StatusNotFound, "Project not found", "")
+		return
+	}
+
+	// Load project config
+	cfg, err := config.LoadConfig(ref.Path)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to load project config", err.Error())
+		return
+	}
+
+	if failed := operations.FetchRepositories(ref.Path, cfg); len(failed) > 0 {
+		writeJSON(w, http.StatusOK, SuccessResponse{Success: false, Message: "Failed to fetch " + strings.Join(failed, ", ")})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, SuccessResponse{Success: true, Message: "Fetch completed"})
+}
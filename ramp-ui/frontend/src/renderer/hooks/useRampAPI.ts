@@ -8,6 +8,14 @@ import {
   AddProjectRequest,
   CreateFeatureRequest,
   RenameFeatureRequest,
+  FeatureDetails,
+  AnnotateFeatureRequest,
+  MoveFeatureRequest,
+  CloneFeatureRequest,
+  EnvSyncRequest,
+  EnvSyncResponse,
+  PushFeatureRequest,
+  PushResponse,
   SuccessResponse,
   ConfigStatusResponse,
   ConfigResponse,
@@ -22,7 +30,13 @@ import {
   OpenTerminalRequest,
   AppSettingsResponse,
   SaveAppSettingsRequest,
+  PruneRequest,
   PruneResponse,
+  ArchivesResponse,
+  Plan,
+  PlanRequest,
+  InterruptedOperationsResponse,
+  DoctorResult,
 } from '../types';
 
 // Dynamic port configuration - fetched from Electron IPC
@@ -127,6 +141,23 @@ export function useToggleFavorite() {
 
 // Features
 export function useFeatures(projectId: string) {
+  const queryClient = useQueryClient();
+
+  // Listing features never fetches, so fetch remotes in the background at most
+  // once a minute and re-list when it lands to update ahead/behind and merge state
+  useQuery<SuccessResponse>({
+    queryKey: ['projects', projectId, 'fetch'],
+    queryFn: async () => {
+      const result = await fetchAPI<SuccessResponse>(`/projects/${projectId}/source-repos/fetch`, {
+        method: 'POST',
+      });
+      queryClient.invalidateQueries({ queryKey: ['projects', projectId, 'features'] });
+      return result;
+    },
+    enabled: !!projectId,
+    staleTime: 60 * 1000,
+  });
+
   return useQuery<FeaturesResponse>({
     queryKey: ['projects', projectId, 'features'],
     queryFn: () => fetchAPI<FeaturesResponse>(`/projects/${projectId}/features`),
@@ -175,13 +206,72 @@ export function useDeleteFeature(projectId: string) {
   });
 }
 
-export function usePruneFeatures(projectId: string) {
+export function useInterruptedOperations(projectId: string) {
+  return useQuery<InterruptedOperationsResponse>({
+    queryKey: ['projects', projectId, 'interrupted'],
+    queryFn: () => fetchAPI<InterruptedOperationsResponse>(`/projects/${projectId}/interrupted`),
+    enabled: !!projectId,
+  });
+}
+
+// Resumes or rolls back an interrupted operation on a feature
+export function useRecoverOperation(projectId: string, action: 'resume' | 'rollback') {
+  const queryClient = useQueryClient();
+
+  return useMutation<SuccessResponse, Error, string>({
+    mutationFn: (featureName) =>
+      fetchAPI<SuccessResponse>(`/projects/${projectId}/interrupted/${featureName}/${action}`, {
+        method: 'POST',
+      }),
+    onSuccess: () => {
+      queryClient.invalidateQueries({ queryKey: ['projects', projectId, 'interrupted'] });
+      queryClient.invalidateQueries({ queryKey: ['projects', projectId, 'features'] });
+    },
+    onError: () => {
+      // Invalidate on error to ensure fresh state (operation may have partially completed)
+      queryClient.invalidateQueries({ queryKey: ['projects', projectId, 'interrupted'] });
+      queryClient.invalidateQueries({ queryKey: ['projects', projectId, 'features'] });
+    },
+  });
+}
+
+export function useDoctor(projectId: string) {
+  return useQuery<DoctorResult>({
+    queryKey: ['projects', projectId, 'doctor'],
+    queryFn: () => fetchAPI<DoctorResult>(`/projects/${projectId}/doctor`),
+    enabled: !!projectId,
+  });
+}
+
+// Repairs what ramp doctor can fix; features and ports may change
+export function useDoctorFix(projectId: string) {
   const queryClient = useQueryClient();
 
-  return useMutation<PruneResponse, Error, void>({
+  return useMutation<DoctorResult, Error, void>({
     mutationFn: () =>
+      fetchAPI<DoctorResult>(`/projects/${projectId}/doctor/fix`, {
+        method: 'POST',
+      }),
+    onSuccess: (result) => {
+      queryClient.setQueryData(['projects', projectId, 'doctor'], result);
+      queryClient.invalidateQueries({ queryKey: ['projects', projectId, 'features'] });
+    },
+    onError: () => {
+      // Invalidate on error to ensure fresh state (some repairs may have completed)
+      queryClient.invalidateQueries({ queryKey: ['projects', projectId, 'doctor'] });
+      queryClient.invalidateQueries({ queryKey: ['projects', projectId, 'features'] });
+    },
+  });
+}
+
+export function usePruneFeatures(projectId: string) {
+  const queryClient = useQueryClient();
+
+  return useMutation<PruneResponse, Error, PruneRequest | void>({
+    mutationFn: (request) =>
       fetchAPI<PruneResponse>(`/projects/${projectId}/features/prune`, {
         method: 'POST',
+        body: request ? JSON.stringify(request) : undefined,
       }),
     onSuccess: () => {
       // Only invalidate features - the component handles immediate cache updates via setQueryData
@@ -195,6 +285,17 @@ export function usePruneFeatures(projectId: string) {
   });
 }
 
+// Previews an operation without running it; nothing is cached or invalidated
+export function usePlanPreview(projectId: string) {
+  return useMutation<Plan, Error, PlanRequest>({
+    mutationFn: (data) =>
+      fetchAPI<Plan>(`/projects/${projectId}/plan`, {
+        method: 'POST',
+        body: JSON.stringify(data),
+      }),
+  });
+}
+
 export function useRenameFeature(projectId: string) {
   const queryClient = useQueryClient();
 
@@ -210,6 +311,163 @@ export function useRenameFeature(projectId: string) {
   });
 }
 
+// Creation info, branches, ports, notes, tags and links of a feature (ramp feature info)
+export function useFeatureInfo(projectId: string, featureName: string) {
+  return useQuery<FeatureDetails>({
+    queryKey: ['projects', projectId, 'features', featureName, 'info'],
+    queryFn: () => fetchAPI<FeatureDetails>(`/projects/${projectId}/features/${featureName}/info`),
+    enabled: !!projectId && !!featureName,
+  });
+}
+
+// Adds or removes a feature's notes, tags and links (ramp feature note/tag)
+export function useAnnotateFeature(projectId: string) {
+  const queryClient = useQueryClient();
+
+  return useMutation<SuccessResponse, Error, { featureName: string } & AnnotateFeatureRequest>({
+    mutationFn: ({ featureName, ...request }) =>
+      fetchAPI<SuccessResponse>(`/projects/${projectId}/features/${featureName}/metadata`, {
+        method: 'PUT',
+        body: JSON.stringify(request),
+      }),
+    onSuccess: () => {
+      // Also refreshes the feature's info, whose key is under the features key
+      queryClient.invalidateQueries({ queryKey: ['projects', projectId, 'features'] });
+    },
+  });
+}
+
+// Renames a feature's directory and branches (ramp mv)
+export function useMoveFeature(projectId: string) {
+  const queryClient = useQueryClient();
+
+  return useMutation<SuccessResponse, Error, { featureName: string } & MoveFeatureRequest>({
+    mutationFn: ({ featureName, ...request }) =>
+      fetchAPI<SuccessResponse>(`/projects/${projectId}/features/${featureName}/move`, {
+        method: 'POST',
+        body: JSON.stringify(request),
+      }),
+    onSuccess: () => {
+      queryClient.invalidateQueries({ queryKey: ['projects', projectId, 'features'] });
+    },
+    onError: () => {
+      // Invalidate on error to ensure fresh state (the move may not have been fully undone)
+      queryClient.invalidateQueries({ queryKey: ['projects', projectId, 'features'] });
+    },
+  });
+}
+
+// Creates a new feature from another feature's branches (ramp clone-feature)
+export function useCloneFeature(projectId: string) {
+  const queryClient = useQueryClient();
+
+  return useMutation<Feature, Error, { featureName: string } & CloneFeatureRequest>({
+    mutationFn: ({ featureName, ...request }) =>
+      fetchAPI<Feature>(`/projects/${projectId}/features/${featureName}/clone`, {
+        method: 'POST',
+        body: JSON.stringify(request),
+      }),
+    onSuccess: () => {
+      queryClient.invalidateQueries({ queryKey: ['projects', projectId, 'features'] });
+    },
+    onError: () => {
+      // Invalidate on error to ensure fresh state (the clone may have been partially created)
+      queryClient.invalidateQueries({ queryKey: ['projects', projectId, 'features'] });
+    },
+  });
+}
+
+// Archived features that can be restored (ramp archive)
+export function useArchives(projectId: string) {
+  return useQuery<ArchivesResponse>({
+    queryKey: ['projects', projectId, 'archives'],
+    queryFn: () => fetchAPI<ArchivesResponse>(`/projects/${projectId}/archives`),
+    enabled: !!projectId,
+  });
+}
+
+// Saves a feature's branches and uncommitted changes, then removes it (ramp archive)
+export function useArchiveFeature(projectId: string) {
+  const queryClient = useQueryClient();
+
+  return useMutation<SuccessResponse, Error, string>({
+    mutationFn: (featureName) =>
+      fetchAPI<SuccessResponse>(`/projects/${projectId}/features/${featureName}/archive`, {
+        method: 'POST',
+      }),
+    onSuccess: () => {
+      queryClient.invalidateQueries({ queryKey: ['projects', projectId, 'features'] });
+      queryClient.invalidateQueries({ queryKey: ['projects', projectId, 'archives'] });
+    },
+    onError: () => {
+      // Invalidate on error to ensure fresh state (the archive may be saved but the removal unfinished)
+      queryClient.invalidateQueries({ queryKey: ['projects', projectId, 'features'] });
+      queryClient.invalidateQueries({ queryKey: ['projects', projectId, 'archives'] });
+    },
+  });
+}
+
+// Recreates an archived feature (ramp restore)
+export function useRestoreFeature(projectId: string) {
+  const queryClient = useQueryClient();
+
+  return useMutation<Feature, Error, string>({
+    mutationFn: (featureName) =>
+      fetchAPI<Feature>(`/projects/${projectId}/archives/${featureName}/restore`, {
+        method: 'POST',
+      }),
+    onSuccess: () => {
+      queryClient.invalidateQueries({ queryKey: ['projects', projectId, 'features'] });
+      queryClient.invalidateQueries({ queryKey: ['projects', projectId, 'archives'] });
+    },
+    onError: () => {
+      // Invalidate on error to ensure fresh state (branches may have been recreated)
+      queryClient.invalidateQueries({ queryKey: ['projects', projectId, 'features'] });
+      queryClient.invalidateQueries({ queryKey: ['projects', projectId, 'archives'] });
+    },
+  });
+}
+
+// Permanently discards an archived feature (ramp archive --delete)
+export function useDeleteArchive(projectId: string) {
+  const queryClient = useQueryClient();
+
+  return useMutation<SuccessResponse, Error, string>({
+    mutationFn: (featureName) =>
+      fetchAPI<SuccessResponse>(`/projects/${projectId}/archives/${featureName}`, {
+        method: 'DELETE',
+      }),
+    onSuccess: () => {
+      queryClient.invalidateQueries({ queryKey: ['projects', projectId, 'archives'] });
+    },
+  });
+}
+
+export function useSyncFeatureEnv(projectId: string) {
+  return useMutation<EnvSyncResponse, Error, { featureName: string } & EnvSyncRequest>({
+    mutationFn: ({ featureName, ...request }) =>
+      fetchAPI<EnvSyncResponse>(`/projects/${projectId}/features/${featureName}/env/sync`, {
+        method: 'POST',
+        body: JSON.stringify(request),
+      }),
+  });
+}
+
+export function usePushFeature(projectId: string) {
+  const queryClient = useQueryClient();
+
+  return useMutation<PushResponse, Error, { featureName: string } & PushFeatureRequest>({
+    mutationFn: ({ featureName, ...request }) =>
+      fetchAPI<PushResponse>(`/projects/${projectId}/features/${featureName}/push`, {
+        method: 'POST',
+        body: JSON.stringify(request),
+      }),
+    onSuccess: () => {
+      queryClient.invalidateQueries({ queryKey: ['projects', projectId, 'features'] });
+    },
+  });
+}
+
 // Config (local preferences)
 export function useConfigStatus(projectId: string) {
   return useQuery<ConfigStatusResponse>({
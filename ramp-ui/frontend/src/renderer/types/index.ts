@@ -45,6 +45,8 @@ export interface FeatureWorktreeStatus {
   aheadCount: number;
   behindCount: number;
   isMerged: boolean;
+  mergeMethod?: 'ancestor' | 'patch-id' | 'tree' | 'remote-deleted';
+  sparse?: boolean;
   error?: string;
 }
 
@@ -56,9 +58,13 @@ export interface Feature {
   displayName?: string;
   repos: string[];
   created?: string;
+  createdBy?: string;
+  tags?: string[];
+  links?: Record<string, string>; // Label such as "ticket" or "pr" to URL
   hasUncommittedChanges: boolean;
   category: FeatureCategory;
   worktreeStatuses?: FeatureWorktreeStatus[];
+  driftedEnvFiles?: string[]; // Env files edited since generation (repo/dest)
 }
 
 // API Responses
@@ -68,6 +74,42 @@ export interface ProjectsResponse {
 
 export interface FeaturesResponse {
   features: Feature[];
+  byTag: Record<string, string[]>; // Tag to feature names; untagged features are under ""
+}
+
+// Feature metadata types (ramp feature info/note/tag)
+export interface FeatureCreationInfo {
+  at: string;
+  by?: string; // git user.name, or the OS user
+  target?: string; // --target the branches were created from
+  prefix: string;
+  baseCommits?: Record<string, string>; // Repo to the commit its worktree started at
+}
+
+export interface FeatureNote {
+  text: string;
+  added: string;
+}
+
+export interface FeatureDetails {
+  name: string;
+  displayName?: string;
+  treesDir: string;
+  created: string;
+  creation?: FeatureCreationInfo; // Missing for features created before it was recorded
+  branches: Record<string, string>;
+  ports?: number[];
+  tags?: string[];
+  links?: Record<string, string>;
+  notes?: FeatureNote[];
+}
+
+export interface AnnotateFeatureRequest {
+  note?: string; // Note to add
+  clearNotes?: boolean; // Remove existing notes first
+  addTags?: string[];
+  removeTags?: string[];
+  links?: Record<string, string>; // Label to URL; an empty URL removes the link
 }
 
 export interface SuccessResponse {
@@ -136,6 +178,56 @@ export interface RenameFeatureRequest {
   displayName: string; // New display name (empty string to clear)
 }
 
+export interface MoveFeatureRequest {
+  newName: string;
+  renameRemote?: boolean; // Also rename pushed branches on origin
+}
+
+export interface CloneFeatureRequest {
+  name: string; // The new feature
+  displayName?: string;
+  uncommitted?: boolean; // Also copy uncommitted changes and untracked files
+}
+
+// Env sync types (ramp env sync)
+export interface EnvSyncRequest {
+  dryRun?: boolean; // Only return diffs, don't write files
+  forceRefresh?: boolean; // Re-run script sources instead of using cached output
+  conflict?: 'keep' | 'merge' | 'force'; // How to handle locally edited files
+}
+
+export interface EnvFileChange {
+  repo: string;
+  dest: string;
+  created: boolean; // File does not exist yet
+  drifted: boolean; // File was edited since it was generated
+  diff: string; // Unified diff from current to regenerated content
+}
+
+export interface EnvSyncResponse {
+  changes: EnvFileChange[];
+  unchanged: number;
+  kept?: string[]; // Edited files left untouched (repo/dest)
+  applied: boolean; // False for dry runs
+}
+
+// Push types (ramp push)
+export interface PushFeatureRequest {
+  forceWithLease?: boolean; // Push rewritten branches with --force-with-lease
+}
+
+export interface PushRepoResult {
+  repo: string;
+  branch?: string;
+  status: 'pushed' | 'skipped' | 'failed';
+  ahead: number; // Commits the remote didn't have
+  message: string;
+}
+
+export interface PushResponse {
+  repos: PushRepoResult[];
+}
+
 // Config types for local preferences
 export interface PromptOption {
   value: string;
@@ -209,7 +301,67 @@ export interface SaveAppSettingsRequest {
   theme?: string;
 }
 
+// Interrupted operations (journaled up, down or prune that was killed part-way)
+export interface InterruptedOperation {
+  operation: 'up' | 'down' | 'prune';
+  featureName: string;
+  started: string;
+  completedSteps: number;
+  canRollback: boolean; // False once a down or prune removed worktrees or branches
+}
+
+export interface InterruptedOperationsResponse {
+  operations: InterruptedOperation[];
+}
+
+// Doctor types (project inconsistencies found by ramp doctor)
+export type DoctorIssueKind =
+  | 'missing-tool'
+  | 'unreachable-remote'
+  | 'interrupted-operation'
+  | 'moved-worktree'
+  | 'missing-worktree'
+  | 'unregistered-dir'
+  | 'leftover-branch'
+  | 'stale-ports'
+  | 'stale-metadata';
+
+export interface DoctorIssue {
+  kind: DoctorIssueKind;
+  repo?: string;
+  feature?: string;
+  path?: string;
+  detail: string;
+  fix: string; // What fixing does, or what to do by hand when it can't
+  fixable: boolean;
+  fixed: boolean;
+  fixError?: string;
+}
+
+export interface DoctorResult {
+  issues: DoctorIssue[];
+}
+
+// Archive types (features saved by ramp archive)
+export interface ArchivedFeature {
+  name: string;
+  displayName?: string;
+  archived: string;
+  branches: string[];
+  uncommittedIn?: string[]; // Repos whose uncommitted changes were saved
+  ports?: number[];
+  recreateCommand: string; // ramp up command that creates a feature with the same branches
+}
+
+export interface ArchivesResponse {
+  archives: ArchivedFeature[];
+}
+
 // Prune types
+export interface PruneRequest {
+  archive?: boolean; // Archive the features instead of deleting them
+}
+
 export interface PruneFailure {
   name: string;
   error: string;
@@ -220,3 +372,26 @@ export interface PruneResponse {
   failed: PruneFailure[];
   message: string;
 }
+
+// Plan types (previews of up, down, prune and install)
+export type PlanOperation = 'up' | 'down' | 'prune' | 'install';
+
+export interface PlanStep {
+  kind: string; // clone, add-remote, refresh, create-worktree, allocate-ports, run-hook, ...
+  feature?: string; // Set when a plan covers several features (prune)
+  repo?: string; // Empty for project-wide steps
+  detail: string;
+}
+
+// Operation-specific fields (branchName, repos, ports, ...) are passed through as is
+export interface Plan {
+  operation: PlanOperation;
+  target: string;
+  steps: PlanStep[];
+  warnings?: string[];
+  [key: string]: unknown;
+}
+
+export interface PlanRequest extends CreateFeatureRequest {
+  operation: PlanOperation;
+}